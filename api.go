@@ -0,0 +1,569 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"drive-simulation/traffic"
+)
+
+const (
+	defaultASCIIWidth = 100
+	maxASCIIWidth     = 1000
+	maxSnapshotSize   = 256 << 20 // байты, предел тела /api/restore
+	maxScenarioSize   = 1 << 20   // байты, предел тела /api/scenario
+	maxStepCount      = 100000    // шагов, предел одной команды stepN
+	maxCompareTime    = 3600.0    // секунды симуляционного времени, предел прогона одной модели в /api/compare
+)
+
+// errorBody описание ошибки для ответа клиенту: сообщение и, для ошибок проверки,
+// сообщения по полям
+func errorBody(err error) map[string]any {
+	body := map[string]any{"message": err.Error()}
+	var validation *traffic.ValidationError
+	if errors.As(err, &validation) {
+		body["fields"] = validation.Fields
+	}
+	return body
+}
+
+// commandReply сериализует ответ на команду WebSocket с идентификатором id (nil - без id):
+// {"id": ..., "ok": true} или {"id": ..., "ok": false, "error": {"message": ..., "fields": {...}}}
+func commandReply(id any, err error) []byte {
+	reply := map[string]any{"ok": err == nil}
+	if id != nil {
+		reply["id"] = id
+	}
+	if err != nil {
+		reply["error"] = errorBody(err)
+	}
+	data, _ := json.Marshal(reply)
+	return data
+}
+
+// writeError отвечает на HTTP-запрос кодом status и описанием ошибки в JSON
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]any{"error": errorBody(err)})
+}
+
+// roomFor возвращает симуляцию из параметра запроса sim (по умолчанию DefaultRoom);
+// для неизвестной симуляции отвечает 404 и возвращает nil
+func roomFor(w http.ResponseWriter, r *http.Request) *Room {
+	id := r.URL.Query().Get("sim")
+	room := rooms.get(id)
+	if room == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown simulation %q", id))
+	}
+	return room
+}
+
+// simulationRequest тело POST /api/simulations: идентификатор (пусто - случайный)
+// и начальная конфигурация, как data команды config
+type simulationRequest struct {
+	ID     string                    `json:"id"`
+	Config *traffic.SimulationConfig `json:"config"`
+}
+
+// simulationInfo описание симуляции в списке GET /api/simulations
+type simulationInfo struct {
+	ID      string  `json:"id"`
+	Clients int     `json:"clients"`
+	Running bool    `json:"running"`
+	Paused  bool    `json:"paused"`
+	Time    float64 `json:"time"`
+	Cars    int     `json:"cars"`
+}
+
+// handleCreateSimulation создаёт и запускает независимую симуляцию (POST /api/simulations);
+// клиенты подключаются к ней через /ws?sim=id, REST-запросы - с параметром ?sim=id
+func handleCreateSimulation(w http.ResponseWriter, r *http.Request) {
+	var request simulationRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && err != io.EOF {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+	simulation := traffic.New()
+	if request.Config != nil {
+		if err := request.Config.Validate(); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		simulation.UpdateConfig(*request.Config)
+	}
+
+	room, err := rooms.create(request.ID, simulation)
+	switch {
+	case errors.Is(err, errRoomExists):
+		writeError(w, http.StatusConflict, err)
+		return
+	case errors.Is(err, errTooManyRooms):
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": room.ID})
+}
+
+// handleListSimulations возвращает работающие симуляции (GET /api/simulations)
+func handleListSimulations(w http.ResponseWriter, r *http.Request) {
+	list := make([]simulationInfo, 0)
+	for _, room := range rooms.list() {
+		m := room.simulation.Metrics()
+		list = append(list, simulationInfo{
+			ID: room.ID, Clients: room.hub.count(), Running: m.Running, Paused: m.Paused, Time: m.Time, Cars: m.Cars,
+		})
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(list)
+}
+
+// handleDeleteSimulation останавливает симуляцию и отключает её клиентов
+// (DELETE /api/simulations/{id}); симуляцию по умолчанию удалить нельзя
+func handleDeleteSimulation(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	ctx, cancel := context.WithTimeout(r.Context(), ShutdownTimeout)
+	defer cancel()
+	found, err := rooms.remove(ctx, id)
+	switch {
+	case errors.Is(err, errDefaultRoom):
+		writeError(w, http.StatusBadRequest, err)
+	case !found:
+		writeError(w, http.StatusNotFound, fmt.Errorf("unknown simulation %q", id))
+	default:
+		if err != nil {
+			requestLogger(r).Error("Replay record error", "sim", id, "err", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleStart запускает симуляцию (POST /api/start)
+func handleStart(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	room.simulation.Start()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePause приостанавливает симуляцию (POST /api/pause)
+func handlePause(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	room.simulation.Pause()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleStep продвигает симуляцию на n шагов и оставляет её на паузе (POST /api/step?n=1)
+func handleStep(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	steps := 1
+	if value := r.URL.Query().Get("n"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxStepCount {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("n: must be between 1 and %d", maxStepCount))
+			return
+		}
+		steps = parsed
+	}
+	room.simulation.Advance(steps, float64(UpdateInterval)/1000.0)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// seekRequest параметры перемотки: поля команды seek и тело POST /api/seek
+type seekRequest struct {
+	Time   float64 `json:"time"`   // секунды симуляции
+	Resume bool    `json:"resume"` // продолжить прогон после перемотки (иначе пауза)
+}
+
+// handleSeek перематывает симуляцию назад в пределах истории и возвращает
+// момент восстановленного кадра (POST /api/seek)
+func handleSeek(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	var seek seekRequest
+	if err := json.NewDecoder(r.Body).Decode(&seek); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid seek: %w", err))
+		return
+	}
+	restored, err := room.simulation.Seek(seek.Time, seek.Resume)
+	if errors.Is(err, traffic.ErrOutsideHistory) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("time: %w", err))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]float64{"time": restored})
+}
+
+// handleStop останавливает симуляцию (POST /api/stop)
+func handleStop(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	room.simulation.Stop()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleReset сбрасывает симуляцию (POST /api/reset)
+func handleReset(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	room.simulation.Reset()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleConfig применяет конфигурацию, как команда config по WebSocket (PUT /api/config)
+func handleConfig(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	var config traffic.SimulationConfig
+	if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid config: %w", err))
+		return
+	}
+	if err := config.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	room.simulation.UpdateConfig(config)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleIncident устанавливает инцидент, как команда incident по WebSocket (POST /api/incidents);
+// в ответе - идентификатор перекрытия
+func handleIncident(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	var incident traffic.IncidentConfig
+	if err := json.NewDecoder(r.Body).Decode(&incident); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid incident: %w", err))
+		return
+	}
+	if err := incident.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	id := room.simulation.AddIncident(incident)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"id": id})
+}
+
+// handleAddCar добавляет машину с заданными параметрами и возвращает её
+// идентификатор (POST /api/cars)
+func handleAddCar(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	var car traffic.CarConfig
+	if err := json.NewDecoder(r.Body).Decode(&car); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid car: %w", err))
+		return
+	}
+	if err := car.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	id := room.simulation.InjectCar(car)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]int{"id": id})
+}
+
+// handleControlCar меняет желаемую скорость машины или заставляет её
+// остановиться (PATCH /api/cars/{id})
+func handleControlCar(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid car id: %w", err))
+		return
+	}
+	var control traffic.CarControl
+	if err := json.NewDecoder(r.Body).Decode(&control); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid car control: %w", err))
+		return
+	}
+	control.ID = id
+	if err := control.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if !room.simulation.ControlCar(control) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("car %d not found", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRemoveCar убирает машину с дороги (DELETE /api/cars/{id})
+func handleRemoveCar(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid car id: %w", err))
+		return
+	}
+	if !room.simulation.RemoveCar(id) {
+		writeError(w, http.StatusNotFound, fmt.Errorf("car %d not found", id))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleState возвращает текущее состояние симуляции (GET /api/state)
+func handleState(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	data, err := room.stateJSON()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
+
+// handleSnapshot возвращает полное состояние симуляции для последующего
+// восстановления (POST /api/snapshot)
+func handleSnapshot(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	data, err := room.simulation.SaveState()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="snapshot.json"`)
+	w.Write(data)
+}
+
+// handleRestore восстанавливает симуляцию из тела запроса, полученного
+// от /api/snapshot (POST /api/restore)
+func handleRestore(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSnapshotSize))
+	if err != nil {
+		http.Error(w, "invalid snapshot: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := room.simulation.LoadState(data); err != nil {
+		http.Error(w, "invalid snapshot: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleScenario сбрасывает симуляцию и загружает сценарий в формате YAML или JSON
+// (POST /api/scenario); ошибки разбора и проверки возвращаются с кодом 400
+func handleScenario(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxScenarioSize))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid scenario: %w", err))
+		return
+	}
+	scenario, err := traffic.ParseScenario(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid scenario: %w", err))
+		return
+	}
+	if err := scenario.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	room.simulation.LoadScenario(scenario)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleDetectors возвращает ряды показателей всех детекторов (GET /api/detectors)
+func handleDetectors(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(room.simulation.DetectorSeries())
+}
+
+// handleFundamentalDiagram возвращает точки фундаментальной диаграммы плотность-поток-скорость
+// по участкам дороги и окнам времени (GET /api/fundamental-diagram)
+func handleFundamentalDiagram(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(room.simulation.FundamentalDiagram())
+}
+
+// handleTrajectories отдаёт записанные траектории в CSV (GET /api/trajectories.csv)
+func handleTrajectories(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="trajectories.csv"`)
+	if err := room.simulation.WriteTrajectoriesCSV(w); err != nil {
+		requestLogger(r).Warn("CSV write error", "err", err)
+	}
+}
+
+// handleTimeSpace возвращает пространственно-временную диаграмму скоростей по
+// записанным траекториям (GET /api/timespace?from=0&to=600&resolution=10)
+func handleTimeSpace(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	params := map[string]float64{"from": 0, "to": 0, "resolution": 10}
+	for _, name := range []string{"from", "to", "resolution"} {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(parsed) || parsed < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid %s", name))
+			return
+		}
+		params[name] = parsed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(room.simulation.TimeSpace(params["from"], params["to"], params["resolution"]))
+}
+
+// handleHistograms возвращает распределения текущих скоростей, интервалов между
+// машинами и времени в пути завершённых поездок (GET /api/histograms)
+func handleHistograms(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(room.simulation.Histograms())
+}
+
+// handleSummary возвращает итоги прогона на текущий момент с перцентилями
+// показателей завершённых поездок (GET /api/summary)
+func handleSummary(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(room.simulation.Summary())
+}
+
+// handleTrips отдаёт показатели завершённых поездок в CSV (GET /api/trips.csv)
+func handleTrips(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="trips.csv"`)
+	if err := room.simulation.WriteTripsCSV(w); err != nil {
+		requestLogger(r).Warn("CSV write error", "err", err)
+	}
+}
+
+// handleCompare возвращает таблицу сравнения моделей следования
+// для текущих параметров симуляции (GET /api/compare?duration=600)
+func handleCompare(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	duration := 600.0
+	if value := r.URL.Query().Get("duration"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(parsed) || parsed <= 0 || parsed > maxCompareTime {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("duration: must be between 0 and %g seconds", maxCompareTime))
+			return
+		}
+		duration = parsed
+	}
+
+	// Прогоны прерываются, когда клиент отключается
+	results, err := traffic.CompareModels(r.Context(), room.simulation, duration)
+	if err != nil {
+		if r.Context().Err() == nil {
+			writeError(w, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
+}
+
+// handleASCII отдаёт текстовое представление дороги (GET /ascii?width=100)
+func handleASCII(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	width := defaultASCIIWidth
+	if value := r.URL.Query().Get("width"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 || parsed > maxASCIIWidth {
+			http.Error(w, "invalid width", http.StatusBadRequest)
+			return
+		}
+		width = parsed
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	fmt.Fprint(w, room.simulation.RenderASCII(width))
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"drive-simulation/traffic"
+)
+
+// archive хранилище завершённых прогонов (nil - архив не ведётся, см. флаг -archive)
+var archive Storage
+
+// archiveServer имя сервера, под которым его прогоны сохраняются в общее хранилище
+var archiveServer string
+
+// archiveTrajectories сохранять записанные траектории вместе с прогоном
+var archiveTrajectories bool
+
+// RunRecord запись архива о завершённом прогоне
+type RunRecord struct {
+	ID                int64              `json:"id"`
+	Server            string             `json:"server"`            // сервер, на котором шёл прогон
+	Sim               string             `json:"sim"`               // симуляция, в которой шёл прогон
+	FinishedAt        time.Time          `json:"finishedAt"`        // время завершения по часам сервера
+	SimTime           float64            `json:"simTime"`           // секунды симуляции
+	Seed              int64              `json:"seed"`              // зерно генератора
+	Config            json.RawMessage    `json:"config,omitempty"`  // часть состояния config на момент завершения
+	Summary           traffic.RunSummary `json:"summary"`           // итоги прогона
+	TrajectorySamples int                `json:"trajectorySamples"` // сохранённых записей траекторий
+}
+
+// storageFlusher закрывает хранилище при остановке сервера
+type storageFlusher struct {
+	storage Storage
+}
+
+func (f storageFlusher) Flush(ctx context.Context) error {
+	return f.storage.Close()
+}
+
+// saveRun сохраняет прогон симуляции room с итогами summary и, если включено,
+// записанные траектории; возвращает идентификатор записи
+func (room *Room) saveRun(summary traffic.RunSummary) (int64, error) {
+	data, err := room.simulation.StateJSON()
+	if err != nil {
+		return 0, err
+	}
+	config, err := filterState(data, []string{"config"})
+	if err != nil {
+		return 0, err
+	}
+	var seed struct {
+		Seed int64 `json:"seed"`
+	}
+	json.Unmarshal(config, &seed)
+	var frames []traffic.TrajectorySample
+	if archiveTrajectories {
+		frames = room.simulation.Trajectories()
+	}
+
+	id, err := archive.SaveRun(RunRecord{
+		Server:            archiveServer,
+		Sim:               room.ID,
+		FinishedAt:        time.Now(),
+		SimTime:           summary.Time,
+		Seed:              seed.Seed,
+		Config:            config,
+		Summary:           summary,
+		TrajectorySamples: len(frames),
+	})
+	if err != nil || len(frames) == 0 {
+		return id, err
+	}
+	return id, archive.SaveFrames(id, frames)
+}
+
+// archiveRun сохраняет завершённый прогон в фоне, чтобы запись траекторий не
+// задерживала рассылку состояния
+func (room *Room) archiveRun(summary traffic.RunSummary) {
+	if archive == nil {
+		return
+	}
+	go func() {
+		id, err := room.saveRun(summary)
+		if err != nil {
+			slog.Error("Run archive error", "sim", room.ID, "err", err)
+			return
+		}
+		slog.Info("Прогон сохранён в архив", "sim", room.ID, "run", id)
+	}()
+}
+
+// archiveFor возвращает архив; если он не ведётся, отвечает 404 и возвращает nil
+func archiveFor(w http.ResponseWriter) Storage {
+	if archive == nil {
+		writeError(w, http.StatusNotFound, errors.New("run archive is disabled (see -archive)"))
+	}
+	return archive
+}
+
+// runFor возвращает прогон по идентификатору из пути; для некорректного
+// идентификатора отвечает 400, для неизвестного - 404
+func runFor(w http.ResponseWriter, r *http.Request, storage Storage) (RunRecord, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid run id: %w", err))
+		return RunRecord{}, false
+	}
+	run, err := storage.GetRun(id)
+	if errors.Is(err, ErrRunNotFound) {
+		writeError(w, http.StatusNotFound, err)
+		return run, false
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return run, false
+	}
+	return run, true
+}
+
+// handleListRuns возвращает сохранённые прогоны, новые первыми (GET /api/runs?limit=100)
+func handleListRuns(w http.ResponseWriter, r *http.Request) {
+	storage := archiveFor(w)
+	if storage == nil {
+		return
+	}
+	limit := 100
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			writeError(w, http.StatusBadRequest, errors.New("invalid limit"))
+			return
+		}
+		limit = parsed
+	}
+	runs, err := storage.ListRuns(limit)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(runs)
+}
+
+// handleGetRun возвращает прогон с конфигурацией (GET /api/runs/{id})
+func handleGetRun(w http.ResponseWriter, r *http.Request) {
+	storage := archiveFor(w)
+	if storage == nil {
+		return
+	}
+	run, ok := runFor(w, r, storage)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(run)
+}
+
+// handleRunTrajectories отдаёт сохранённые траектории прогона в CSV
+// (GET /api/runs/{id}/trajectories.csv)
+func handleRunTrajectories(w http.ResponseWriter, r *http.Request) {
+	storage := archiveFor(w)
+	if storage == nil {
+		return
+	}
+	run, ok := runFor(w, r, storage)
+	if !ok {
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="run-%d-trajectories.csv"`, run.ID))
+	if err := storage.WriteFrames(w, run.ID); err != nil {
+		requestLogger(r).Warn("CSV write error", "err", err)
+	}
+}
@@ -0,0 +1,9 @@
+//go:build sqlite
+
+package main
+
+import _ "modernc.org/sqlite"
+
+func init() {
+	sqliteDriver = "sqlite"
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// role уровень доступа подключения
+type role int
+
+const (
+	roleNone       role = iota // доступа нет
+	roleViewer                 // только чтение состояния
+	roleController             // управление симуляцией
+)
+
+var (
+	errUnauthorized = errors.New("unauthorized: token required")
+	errForbidden    = errors.New("forbidden: controller token required")
+)
+
+// viewerCommands команды WebSocket, доступные без токена управления: они меняют
+// только то, что получает сам клиент, но не симуляцию
+var viewerCommands = map[string]bool{
+	"subscribe":    true,
+	"keyframe":     true,
+	"replay:load":  true,
+	"replay:play":  true,
+	"replay:pause": true,
+	"replay:seek":  true,
+	"replay:stop":  true,
+}
+
+// authConfig токены доступа. Без токена управления проверка выключена и любое
+// подключение управляет симуляцией; без токена просмотра состояние читают и анонимы.
+type authConfig struct {
+	controllerToken string
+	viewerToken     string
+}
+
+var auth authConfig
+
+// roleFor определяет уровень доступа запроса по токену из заголовка
+// Authorization: Bearer или параметра token (браузерный WebSocket и EventSource
+// не умеют передавать заголовки)
+func (a authConfig) roleFor(r *http.Request) role {
+	token := r.URL.Query().Get("token")
+	if header, ok := bearerToken(r.Header.Get("Authorization")); ok {
+		token = header
+	}
+	return a.roleForToken(token)
+}
+
+// bearerToken извлекает токен из значения заголовка Authorization
+func bearerToken(header string) (string, bool) {
+	return strings.CutPrefix(header, "Bearer ")
+}
+
+// roleForToken определяет уровень доступа по предъявленному токену (пусто - аноним)
+func (a authConfig) roleForToken(token string) role {
+	if a.controllerToken == "" {
+		return roleController
+	}
+	switch {
+	case tokenMatches(token, a.controllerToken):
+		return roleController
+	case a.viewerToken == "" || tokenMatches(token, a.viewerToken):
+		return roleViewer
+	}
+	return roleNone
+}
+
+// tokenMatches сравнивает токены за время, не зависящее от совпадающего префикса
+func tokenMatches(token, expected string) bool {
+	return token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
+}
+
+// requireRole пропускает к обработчику только запросы с уровнем доступа не ниже min:
+// без подходящего токена - 401 Unauthorized, с токеном просмотра вместо токена
+// управления, а на сервере-зрителе для любого управляющего запроса - 403 Forbidden
+func requireRole(min role, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch got := auth.roleFor(r); {
+		case viewerMode && min >= roleController:
+			writeError(w, http.StatusForbidden, errViewerInstance)
+		case got >= min:
+			handler(w, r)
+		case got == roleNone:
+			writeError(w, http.StatusUnauthorized, errUnauthorized)
+		default:
+			writeError(w, http.StatusForbidden, errForbidden)
+		}
+	}
+}
+
+// authorizeCommand проверяет, может ли клиент выполнить команду action
+func (c *Client) authorizeCommand(action any) error {
+	name, _ := action.(string)
+	if viewerCommands[name] {
+		return nil
+	}
+	if viewerMode {
+		return errViewerInstance
+	}
+	if c.role >= roleController {
+		return nil
+	}
+	return errForbidden
+}
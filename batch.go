@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"drive-simulation/traffic"
+)
+
+// runBatch выполняет серию прогонов из файла configPath без сервера и пишет
+// результаты в CSV-файл outPath (пустой путь - стандартный вывод)
+func runBatch(configPath, outPath string) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+	var config traffic.BatchConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("%s: %w", configPath, err)
+	}
+
+	results, err := traffic.RunBatch(context.Background(), config, func(r traffic.BatchResult) {
+		slog.Info("Прогон завершён", "spawnInterval", r.SpawnInterval, "minSpeed", r.MinSpeed, "maxSpeed", r.MaxSpeed,
+			"throughput", r.Throughput, "avgTravelTime", r.AvgTravelTime)
+	})
+	if err != nil {
+		return err
+	}
+
+	write := func(w io.Writer) error { return traffic.WriteBatchCSV(w, results) }
+	if outPath == "" {
+		return write(os.Stdout)
+	}
+	return (&fileFlusher{path: outPath, write: write}).Flush(context.Background())
+}
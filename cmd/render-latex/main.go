@@ -1,3 +1,8 @@
+// Сборка traffic_simulation.pdf из traffic_simulation.tex двумя проходами pdflatex.
+// Отдельная программа, а не файл пакета сервера, чтобы не конфликтовать с его main;
+// запускается из корня репозитория:
+//
+//	go run ./cmd/render-latex
 package main
 
 import (
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+// debugStep длительность шагов цикла одной симуляции для /debug/sim, миллисекунды
+type debugStep struct {
+	ID      string  `json:"id"`
+	Cars    int     `json:"cars"`
+	Clients int     `json:"clients"`
+	Steps   int     `json:"steps"`
+	LastMs  float64 `json:"lastMs"`
+	MeanMs  float64 `json:"meanMs"`
+	MaxMs   float64 `json:"maxMs"`
+	// Overruns - шаги, не уложившиеся в период цикла budgetMs: из-за них кадры идут с рывками
+	Overruns int `json:"overruns"`
+}
+
+// debugGC показатели памяти и сборщика мусора для /debug/sim
+type debugGC struct {
+	Cycles       uint32  `json:"cycles"`
+	PauseTotalMs float64 `json:"pauseTotalMs"`
+	LastPauseMs  float64 `json:"lastPauseMs"`
+	HeapBytes    uint64  `json:"heapBytes"`
+	HeapObjects  uint64  `json:"heapObjects"`
+	NextGCBytes  uint64  `json:"nextGCBytes"`
+}
+
+// debugReport ответ /debug/sim
+type debugReport struct {
+	BudgetMs    float64     `json:"budgetMs"`
+	Goroutines  int         `json:"goroutines"`
+	GC          debugGC     `json:"gc"`
+	Simulations []debugStep `json:"simulations"`
+}
+
+// registerDebug подключает к mux /debug/sim и, если enablePprof, профилировщик
+// net/http/pprof на /debug/pprof/. Профили раскрывают внутренности процесса,
+// поэтому доступны только с токеном управления.
+func registerDebug(mux *http.ServeMux, enablePprof bool) {
+	mux.HandleFunc("GET /debug/sim", requireRole(roleViewer, handleDebugSim))
+	if !enablePprof {
+		return
+	}
+	mux.HandleFunc("/debug/pprof/", requireRole(roleController, pprof.Index))
+	mux.HandleFunc("/debug/pprof/cmdline", requireRole(roleController, pprof.Cmdline))
+	mux.HandleFunc("/debug/pprof/profile", requireRole(roleController, pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", requireRole(roleController, pprof.Symbol))
+	mux.HandleFunc("/debug/pprof/trace", requireRole(roleController, pprof.Trace))
+}
+
+// handleDebugSim отдаёт длительность шагов циклов симуляций, показатели сборщика
+// мусора и число горутин (GET /debug/sim), чтобы найти причину рывков больших симуляций
+func handleDebugSim(w http.ResponseWriter, r *http.Request) {
+	var memory runtime.MemStats
+	runtime.ReadMemStats(&memory)
+	report := debugReport{
+		BudgetMs:   UpdateInterval,
+		Goroutines: runtime.NumGoroutine(),
+		GC: debugGC{
+			Cycles:       memory.NumGC,
+			PauseTotalMs: milliseconds(time.Duration(memory.PauseTotalNs)),
+			LastPauseMs:  milliseconds(time.Duration(memory.PauseNs[(memory.NumGC+255)%256])),
+			HeapBytes:    memory.HeapAlloc,
+			HeapObjects:  memory.HeapObjects,
+			NextGCBytes:  memory.NextGC,
+		},
+		Simulations: make([]debugStep, 0),
+	}
+	for _, m := range collectMetrics() {
+		step := debugStep{
+			ID: m.id, Cars: m.sim.Cars, Clients: m.clients, Steps: m.steps,
+			LastMs: milliseconds(m.last), MaxMs: milliseconds(m.longest), Overruns: m.overruns,
+		}
+		if m.steps > 0 {
+			step.MeanMs = milliseconds(m.total) / float64(m.steps)
+		}
+		report.Simulations = append(report.Simulations, step)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// milliseconds переводит длительность в миллисекунды
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+)
+
+// KeyframeEvery через сколько кадров клиентам в дельта-режиме (/ws?delta=1)
+// отправляется полный ключевой кадр вместо дельты
+const KeyframeEvery = 100
+
+// frameKeys служебные поля кадров дельта-режима, сохраняемые при фильтрации по подписке
+var frameKeys = []string{"seq", "keyframe", "delta"}
+
+// deltaEncoder строит ключевые кадры и дельты между соседними кадрами состояния.
+// Используется только из цикла рассылки.
+type deltaEncoder struct {
+	seq    uint64
+	fields map[string]json.RawMessage         // поля предыдущего кадра, кроме cars
+	cars   map[int]map[string]json.RawMessage // машины предыдущего кадра по id
+}
+
+// reset забывает предыдущий кадр: следующая дельта будет ключевым кадром
+func (d *deltaEncoder) reset() {
+	d.fields, d.cars = nil, nil
+}
+
+// next принимает очередной JSON-кадр состояния и возвращает ключевой кадр
+// (полное состояние с полями seq и keyframe) и дельту относительно предыдущего кадра
+// (поле delta, изменившиеся поля состояния, изменившиеся поля машин в cars
+// и идентификаторы исчезнувших машин в removed). Каждый KeyframeEvery-й кадр,
+// как и первый, вместо дельты возвращает ключевой кадр.
+func (d *deltaEncoder) next(data []byte) (keyframe, delta []byte, err error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, err
+	}
+	d.seq++
+	if keyframe, err = encodeKeyframe(fields, d.seq); err != nil {
+		return nil, nil, err
+	}
+
+	var carList []map[string]json.RawMessage
+	if raw, ok := fields["cars"]; ok {
+		if err := json.Unmarshal(raw, &carList); err != nil {
+			return nil, nil, err
+		}
+		delete(fields, "cars")
+	}
+	cars := make(map[int]map[string]json.RawMessage, len(carList))
+	for _, car := range carList {
+		var id int
+		json.Unmarshal(car["id"], &id)
+		cars[id] = car
+	}
+
+	if d.fields == nil || d.seq%KeyframeEvery == 0 {
+		d.fields, d.cars = fields, cars
+		return keyframe, keyframe, nil
+	}
+
+	changed := map[string]any{"seq": d.seq, "delta": true}
+	for key, value := range fields {
+		if !bytes.Equal(d.fields[key], value) {
+			changed[key] = value
+		}
+	}
+	carChanges := make([]map[string]json.RawMessage, 0)
+	for _, car := range carList {
+		var id int
+		json.Unmarshal(car["id"], &id)
+		prev, ok := d.cars[id]
+		if !ok {
+			carChanges = append(carChanges, car)
+			continue
+		}
+		diff := map[string]json.RawMessage{"id": car["id"]}
+		for key, value := range car {
+			if !bytes.Equal(prev[key], value) {
+				diff[key] = value
+			}
+		}
+		if len(diff) > 1 {
+			carChanges = append(carChanges, diff)
+		}
+	}
+	removed := make([]int, 0)
+	for id := range d.cars {
+		if _, ok := cars[id]; !ok {
+			removed = append(removed, id)
+		}
+	}
+	sort.Ints(removed)
+	changed["cars"] = carChanges
+	changed["removed"] = removed
+
+	d.fields, d.cars = fields, cars
+	delta, err = json.Marshal(changed)
+	return keyframe, delta, err
+}
+
+// makeKeyframe добавляет к полному JSON-кадру состояния поля seq и keyframe
+func makeKeyframe(data []byte, seq uint64) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return encodeKeyframe(fields, seq)
+}
+
+// encodeKeyframe сериализует поля состояния вместе с полями seq и keyframe, не меняя fields
+func encodeKeyframe(fields map[string]json.RawMessage, seq uint64) ([]byte, error) {
+	frame := make(map[string]json.RawMessage, len(fields)+2)
+	for key, value := range fields {
+		frame[key] = value
+	}
+	frame["seq"], _ = json.Marshal(seq)
+	frame["keyframe"] = json.RawMessage("true")
+	return json.Marshal(frame)
+}
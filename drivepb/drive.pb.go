@@ -0,0 +1,3979 @@
+// Схема gRPC API симуляции. Сообщение State повторяет JSON-состояние (GET /api/state,
+// WebSocket): имена полей в lowerCamelCase совпадают с ключами JSON, поэтому сервер
+// переводит снимок в State через protojson. Части, нужные в основном только интерфейсу
+// (schedule, vsl, demandProfile, метеринг рампы), в схему не входят - они доступны
+// в JSON-состоянии.
+//
+// Код Go генерируется командой go generate (см. grpc.go).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: proto/drive.proto
+
+package drivepb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StreamStateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sim           string                 `protobuf:"bytes,1,opt,name=sim,proto3" json:"sim,omitempty"`     // идентификатор симуляции (пусто - default)
+	Parts         []string               `protobuf:"bytes,2,rep,name=parts,proto3" json:"parts,omitempty"` // части состояния, как в команде subscribe (пусто - все)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamStateRequest) Reset() {
+	*x = StreamStateRequest{}
+	mi := &file_proto_drive_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamStateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamStateRequest) ProtoMessage() {}
+
+func (x *StreamStateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamStateRequest.ProtoReflect.Descriptor instead.
+func (*StreamStateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StreamStateRequest) GetSim() string {
+	if x != nil {
+		return x.Sim
+	}
+	return ""
+}
+
+func (x *StreamStateRequest) GetParts() []string {
+	if x != nil {
+		return x.Parts
+	}
+	return nil
+}
+
+type Empty struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Empty) Reset() {
+	*x = Empty{}
+	mi := &file_proto_drive_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Empty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Empty) ProtoMessage() {}
+
+func (x *Empty) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Empty.ProtoReflect.Descriptor instead.
+func (*Empty) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{1}
+}
+
+type StepN struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	N             int32                  `protobuf:"varint,1,opt,name=n,proto3" json:"n,omitempty"` // число шагов (1..100000)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StepN) Reset() {
+	*x = StepN{}
+	mi := &file_proto_drive_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StepN) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StepN) ProtoMessage() {}
+
+func (x *StepN) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StepN.ProtoReflect.Descriptor instead.
+func (*StepN) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *StepN) GetN() int32 {
+	if x != nil {
+		return x.N
+	}
+	return 0
+}
+
+type Seek struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          float64                `protobuf:"fixed64,1,opt,name=time,proto3" json:"time,omitempty"`    // секунды симуляции в пределах истории
+	Resume        bool                   `protobuf:"varint,2,opt,name=resume,proto3" json:"resume,omitempty"` // продолжить прогон после перемотки
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Seek) Reset() {
+	*x = Seek{}
+	mi := &file_proto_drive_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Seek) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Seek) ProtoMessage() {}
+
+func (x *Seek) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Seek.ProtoReflect.Descriptor instead.
+func (*Seek) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Seek) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *Seek) GetResume() bool {
+	if x != nil {
+		return x.Resume
+	}
+	return false
+}
+
+type ControlRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Sim   string                 `protobuf:"bytes,1,opt,name=sim,proto3" json:"sim,omitempty"`
+	// Types that are valid to be assigned to Action:
+	//
+	//	*ControlRequest_Start
+	//	*ControlRequest_Stop
+	//	*ControlRequest_Pause
+	//	*ControlRequest_Reset_
+	//	*ControlRequest_Step
+	//	*ControlRequest_Seek
+	//	*ControlRequest_TimeScale
+	//	*ControlRequest_Turbo
+	Action        isControlRequest_Action `protobuf_oneof:"action"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlRequest) Reset() {
+	*x = ControlRequest{}
+	mi := &file_proto_drive_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlRequest) ProtoMessage() {}
+
+func (x *ControlRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlRequest.ProtoReflect.Descriptor instead.
+func (*ControlRequest) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ControlRequest) GetSim() string {
+	if x != nil {
+		return x.Sim
+	}
+	return ""
+}
+
+func (x *ControlRequest) GetAction() isControlRequest_Action {
+	if x != nil {
+		return x.Action
+	}
+	return nil
+}
+
+func (x *ControlRequest) GetStart() *Empty {
+	if x != nil {
+		if x, ok := x.Action.(*ControlRequest_Start); ok {
+			return x.Start
+		}
+	}
+	return nil
+}
+
+func (x *ControlRequest) GetStop() *Empty {
+	if x != nil {
+		if x, ok := x.Action.(*ControlRequest_Stop); ok {
+			return x.Stop
+		}
+	}
+	return nil
+}
+
+func (x *ControlRequest) GetPause() *Empty {
+	if x != nil {
+		if x, ok := x.Action.(*ControlRequest_Pause); ok {
+			return x.Pause
+		}
+	}
+	return nil
+}
+
+func (x *ControlRequest) GetReset_() *Empty {
+	if x != nil {
+		if x, ok := x.Action.(*ControlRequest_Reset_); ok {
+			return x.Reset_
+		}
+	}
+	return nil
+}
+
+func (x *ControlRequest) GetStep() *StepN {
+	if x != nil {
+		if x, ok := x.Action.(*ControlRequest_Step); ok {
+			return x.Step
+		}
+	}
+	return nil
+}
+
+func (x *ControlRequest) GetSeek() *Seek {
+	if x != nil {
+		if x, ok := x.Action.(*ControlRequest_Seek); ok {
+			return x.Seek
+		}
+	}
+	return nil
+}
+
+func (x *ControlRequest) GetTimeScale() float64 {
+	if x != nil {
+		if x, ok := x.Action.(*ControlRequest_TimeScale); ok {
+			return x.TimeScale
+		}
+	}
+	return 0
+}
+
+func (x *ControlRequest) GetTurbo() bool {
+	if x != nil {
+		if x, ok := x.Action.(*ControlRequest_Turbo); ok {
+			return x.Turbo
+		}
+	}
+	return false
+}
+
+type isControlRequest_Action interface {
+	isControlRequest_Action()
+}
+
+type ControlRequest_Start struct {
+	Start *Empty `protobuf:"bytes,2,opt,name=start,proto3,oneof"`
+}
+
+type ControlRequest_Stop struct {
+	Stop *Empty `protobuf:"bytes,3,opt,name=stop,proto3,oneof"`
+}
+
+type ControlRequest_Pause struct {
+	Pause *Empty `protobuf:"bytes,4,opt,name=pause,proto3,oneof"`
+}
+
+type ControlRequest_Reset_ struct {
+	Reset_ *Empty `protobuf:"bytes,5,opt,name=reset,proto3,oneof"`
+}
+
+type ControlRequest_Step struct {
+	Step *StepN `protobuf:"bytes,6,opt,name=step,proto3,oneof"`
+}
+
+type ControlRequest_Seek struct {
+	Seek *Seek `protobuf:"bytes,7,opt,name=seek,proto3,oneof"`
+}
+
+type ControlRequest_TimeScale struct {
+	TimeScale float64 `protobuf:"fixed64,8,opt,name=time_scale,json=timeScale,proto3,oneof"`
+}
+
+type ControlRequest_Turbo struct {
+	Turbo bool `protobuf:"varint,9,opt,name=turbo,proto3,oneof"`
+}
+
+func (*ControlRequest_Start) isControlRequest_Action() {}
+
+func (*ControlRequest_Stop) isControlRequest_Action() {}
+
+func (*ControlRequest_Pause) isControlRequest_Action() {}
+
+func (*ControlRequest_Reset_) isControlRequest_Action() {}
+
+func (*ControlRequest_Step) isControlRequest_Action() {}
+
+func (*ControlRequest_Seek) isControlRequest_Action() {}
+
+func (*ControlRequest_TimeScale) isControlRequest_Action() {}
+
+func (*ControlRequest_Turbo) isControlRequest_Action() {}
+
+type ControlReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Time          float64                `protobuf:"fixed64,1,opt,name=time,proto3" json:"time,omitempty"`
+	Running       bool                   `protobuf:"varint,2,opt,name=running,proto3" json:"running,omitempty"`
+	Paused        bool                   `protobuf:"varint,3,opt,name=paused,proto3" json:"paused,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlReply) Reset() {
+	*x = ControlReply{}
+	mi := &file_proto_drive_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlReply) ProtoMessage() {}
+
+func (x *ControlReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlReply.ProtoReflect.Descriptor instead.
+func (*ControlReply) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ControlReply) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *ControlReply) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *ControlReply) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+type ConfigureRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Sim   string                 `protobuf:"bytes,1,opt,name=sim,proto3" json:"sim,omitempty"`
+	// Параметры в формате data команды config, например {"lanes": 3, "model": "idm"}
+	Config        *structpb.Struct `protobuf:"bytes,2,opt,name=config,proto3" json:"config,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureRequest) Reset() {
+	*x = ConfigureRequest{}
+	mi := &file_proto_drive_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureRequest) ProtoMessage() {}
+
+func (x *ConfigureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureRequest.ProtoReflect.Descriptor instead.
+func (*ConfigureRequest) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ConfigureRequest) GetSim() string {
+	if x != nil {
+		return x.Sim
+	}
+	return ""
+}
+
+func (x *ConfigureRequest) GetConfig() *structpb.Struct {
+	if x != nil {
+		return x.Config
+	}
+	return nil
+}
+
+type ConfigureReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConfigureReply) Reset() {
+	*x = ConfigureReply{}
+	mi := &file_proto_drive_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConfigureReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConfigureReply) ProtoMessage() {}
+
+func (x *ConfigureReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConfigureReply.ProtoReflect.Descriptor instead.
+func (*ConfigureReply) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{7}
+}
+
+type Car struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Driver        string                 `protobuf:"bytes,3,opt,name=driver,proto3" json:"driver,omitempty"`
+	Length        float64                `protobuf:"fixed64,4,opt,name=length,proto3" json:"length,omitempty"`
+	Lane          int32                  `protobuf:"varint,5,opt,name=lane,proto3" json:"lane,omitempty"`
+	Position      float64                `protobuf:"fixed64,6,opt,name=position,proto3" json:"position,omitempty"`
+	Speed         float64                `protobuf:"fixed64,7,opt,name=speed,proto3" json:"speed,omitempty"`
+	TargetSpeed   float64                `protobuf:"fixed64,8,opt,name=target_speed,json=targetSpeed,proto3" json:"target_speed,omitempty"`
+	BrakeCount    int32                  `protobuf:"varint,9,opt,name=brake_count,json=brakeCount,proto3" json:"brake_count,omitempty"`
+	Color         string                 `protobuf:"bytes,10,opt,name=color,proto3" json:"color,omitempty"`
+	State         string                 `protobuf:"bytes,11,opt,name=state,proto3" json:"state,omitempty"`
+	ReactionDelay float64                `protobuf:"fixed64,12,opt,name=reaction_delay,json=reactionDelay,proto3" json:"reaction_delay,omitempty"`
+	OverLimitTime float64                `protobuf:"fixed64,13,opt,name=over_limit_time,json=overLimitTime,proto3" json:"over_limit_time,omitempty"`
+	Fuel          float64                `protobuf:"fixed64,14,opt,name=fuel,proto3" json:"fuel,omitempty"`
+	Co2           float64                `protobuf:"fixed64,15,opt,name=co2,proto3" json:"co2,omitempty"`
+	Platoon       int32                  `protobuf:"varint,16,opt,name=platoon,proto3" json:"platoon,omitempty"`
+	PlatoonIndex  int32                  `protobuf:"varint,17,opt,name=platoon_index,json=platoonIndex,proto3" json:"platoon_index,omitempty"`
+	Player        bool                   `protobuf:"varint,18,opt,name=player,proto3" json:"player,omitempty"`
+	Overtaking    string                 `protobuf:"bytes,19,opt,name=overtaking,proto3" json:"overtaking,omitempty"`
+	Route         []string               `protobuf:"bytes,20,rep,name=route,proto3" json:"route,omitempty"`
+	Dwelling      bool                   `protobuf:"varint,21,opt,name=dwelling,proto3" json:"dwelling,omitempty"`
+	Yielding      bool                   `protobuf:"varint,22,opt,name=yielding,proto3" json:"yielding,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Car) Reset() {
+	*x = Car{}
+	mi := &file_proto_drive_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Car) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Car) ProtoMessage() {}
+
+func (x *Car) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Car.ProtoReflect.Descriptor instead.
+func (*Car) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Car) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Car) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Car) GetDriver() string {
+	if x != nil {
+		return x.Driver
+	}
+	return ""
+}
+
+func (x *Car) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *Car) GetLane() int32 {
+	if x != nil {
+		return x.Lane
+	}
+	return 0
+}
+
+func (x *Car) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Car) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *Car) GetTargetSpeed() float64 {
+	if x != nil {
+		return x.TargetSpeed
+	}
+	return 0
+}
+
+func (x *Car) GetBrakeCount() int32 {
+	if x != nil {
+		return x.BrakeCount
+	}
+	return 0
+}
+
+func (x *Car) GetColor() string {
+	if x != nil {
+		return x.Color
+	}
+	return ""
+}
+
+func (x *Car) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *Car) GetReactionDelay() float64 {
+	if x != nil {
+		return x.ReactionDelay
+	}
+	return 0
+}
+
+func (x *Car) GetOverLimitTime() float64 {
+	if x != nil {
+		return x.OverLimitTime
+	}
+	return 0
+}
+
+func (x *Car) GetFuel() float64 {
+	if x != nil {
+		return x.Fuel
+	}
+	return 0
+}
+
+func (x *Car) GetCo2() float64 {
+	if x != nil {
+		return x.Co2
+	}
+	return 0
+}
+
+func (x *Car) GetPlatoon() int32 {
+	if x != nil {
+		return x.Platoon
+	}
+	return 0
+}
+
+func (x *Car) GetPlatoonIndex() int32 {
+	if x != nil {
+		return x.PlatoonIndex
+	}
+	return 0
+}
+
+func (x *Car) GetPlayer() bool {
+	if x != nil {
+		return x.Player
+	}
+	return false
+}
+
+func (x *Car) GetOvertaking() string {
+	if x != nil {
+		return x.Overtaking
+	}
+	return ""
+}
+
+func (x *Car) GetRoute() []string {
+	if x != nil {
+		return x.Route
+	}
+	return nil
+}
+
+func (x *Car) GetDwelling() bool {
+	if x != nil {
+		return x.Dwelling
+	}
+	return false
+}
+
+func (x *Car) GetYielding() bool {
+	if x != nil {
+		return x.Yielding
+	}
+	return false
+}
+
+type Blockage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Incident      string                 `protobuf:"bytes,2,opt,name=incident,proto3" json:"incident,omitempty"`
+	Position      float64                `protobuf:"fixed64,3,opt,name=position,proto3" json:"position,omitempty"`
+	Span          float64                `protobuf:"fixed64,4,opt,name=span,proto3" json:"span,omitempty"`
+	Lanes         []int32                `protobuf:"varint,5,rep,packed,name=lanes,proto3" json:"lanes,omitempty"`
+	StartTime     float64                `protobuf:"fixed64,6,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	Duration      float64                `protobuf:"fixed64,7,opt,name=duration,proto3" json:"duration,omitempty"`
+	Rubbernecking float64                `protobuf:"fixed64,8,opt,name=rubbernecking,proto3" json:"rubbernecking,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Blockage) Reset() {
+	*x = Blockage{}
+	mi := &file_proto_drive_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Blockage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Blockage) ProtoMessage() {}
+
+func (x *Blockage) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Blockage.ProtoReflect.Descriptor instead.
+func (*Blockage) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *Blockage) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Blockage) GetIncident() string {
+	if x != nil {
+		return x.Incident
+	}
+	return ""
+}
+
+func (x *Blockage) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Blockage) GetSpan() float64 {
+	if x != nil {
+		return x.Span
+	}
+	return 0
+}
+
+func (x *Blockage) GetLanes() []int32 {
+	if x != nil {
+		return x.Lanes
+	}
+	return nil
+}
+
+func (x *Blockage) GetStartTime() float64 {
+	if x != nil {
+		return x.StartTime
+	}
+	return 0
+}
+
+func (x *Blockage) GetDuration() float64 {
+	if x != nil {
+		return x.Duration
+	}
+	return 0
+}
+
+func (x *Blockage) GetRubbernecking() float64 {
+	if x != nil {
+		return x.Rubbernecking
+	}
+	return 0
+}
+
+type LaneBins struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Density       float64                `protobuf:"fixed64,1,opt,name=density,proto3" json:"density,omitempty"`
+	Speed         float64                `protobuf:"fixed64,2,opt,name=speed,proto3" json:"speed,omitempty"`
+	Flow          float64                `protobuf:"fixed64,3,opt,name=flow,proto3" json:"flow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LaneBins) Reset() {
+	*x = LaneBins{}
+	mi := &file_proto_drive_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LaneBins) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LaneBins) ProtoMessage() {}
+
+func (x *LaneBins) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LaneBins.ProtoReflect.Descriptor instead.
+func (*LaneBins) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *LaneBins) GetDensity() float64 {
+	if x != nil {
+		return x.Density
+	}
+	return 0
+}
+
+func (x *LaneBins) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *LaneBins) GetFlow() float64 {
+	if x != nil {
+		return x.Flow
+	}
+	return 0
+}
+
+type BinStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          float64                `protobuf:"fixed64,1,opt,name=from,proto3" json:"from,omitempty"`
+	Density       float64                `protobuf:"fixed64,2,opt,name=density,proto3" json:"density,omitempty"`
+	Speed         float64                `protobuf:"fixed64,3,opt,name=speed,proto3" json:"speed,omitempty"`
+	Flow          float64                `protobuf:"fixed64,4,opt,name=flow,proto3" json:"flow,omitempty"`
+	Lanes         []*LaneBins            `protobuf:"bytes,5,rep,name=lanes,proto3" json:"lanes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BinStats) Reset() {
+	*x = BinStats{}
+	mi := &file_proto_drive_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BinStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BinStats) ProtoMessage() {}
+
+func (x *BinStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BinStats.ProtoReflect.Descriptor instead.
+func (*BinStats) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *BinStats) GetFrom() float64 {
+	if x != nil {
+		return x.From
+	}
+	return 0
+}
+
+func (x *BinStats) GetDensity() float64 {
+	if x != nil {
+		return x.Density
+	}
+	return 0
+}
+
+func (x *BinStats) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+func (x *BinStats) GetFlow() float64 {
+	if x != nil {
+		return x.Flow
+	}
+	return 0
+}
+
+func (x *BinStats) GetLanes() []*LaneBins {
+	if x != nil {
+		return x.Lanes
+	}
+	return nil
+}
+
+type SpeedCamera struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      float64                `protobuf:"fixed64,1,opt,name=position,proto3" json:"position,omitempty"`
+	Limit         float64                `protobuf:"fixed64,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Margin        float64                `protobuf:"fixed64,3,opt,name=margin,proto3" json:"margin,omitempty"`
+	Passed        int32                  `protobuf:"varint,4,opt,name=passed,proto3" json:"passed,omitempty"`
+	Violations    int32                  `protobuf:"varint,5,opt,name=violations,proto3" json:"violations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpeedCamera) Reset() {
+	*x = SpeedCamera{}
+	mi := &file_proto_drive_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpeedCamera) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeedCamera) ProtoMessage() {}
+
+func (x *SpeedCamera) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeedCamera.ProtoReflect.Descriptor instead.
+func (*SpeedCamera) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *SpeedCamera) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *SpeedCamera) GetLimit() float64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SpeedCamera) GetMargin() float64 {
+	if x != nil {
+		return x.Margin
+	}
+	return 0
+}
+
+func (x *SpeedCamera) GetPassed() int32 {
+	if x != nil {
+		return x.Passed
+	}
+	return 0
+}
+
+func (x *SpeedCamera) GetViolations() int32 {
+	if x != nil {
+		return x.Violations
+	}
+	return 0
+}
+
+type WorkZone struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Lane          int32                  `protobuf:"varint,1,opt,name=lane,proto3" json:"lane,omitempty"`
+	Start         float64                `protobuf:"fixed64,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           float64                `protobuf:"fixed64,3,opt,name=end,proto3" json:"end,omitempty"`
+	Taper         float64                `protobuf:"fixed64,4,opt,name=taper,proto3" json:"taper,omitempty"`
+	Merge         string                 `protobuf:"bytes,5,opt,name=merge,proto3" json:"merge,omitempty"`
+	Merges        int32                  `protobuf:"varint,6,opt,name=merges,proto3" json:"merges,omitempty"`
+	ForcedMerges  int32                  `protobuf:"varint,7,opt,name=forced_merges,json=forcedMerges,proto3" json:"forced_merges,omitempty"`
+	MergeDistance float64                `protobuf:"fixed64,8,opt,name=merge_distance,json=mergeDistance,proto3" json:"merge_distance,omitempty"`
+	Passed        int32                  `protobuf:"varint,9,opt,name=passed,proto3" json:"passed,omitempty"`
+	Delay         float64                `protobuf:"fixed64,10,opt,name=delay,proto3" json:"delay,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WorkZone) Reset() {
+	*x = WorkZone{}
+	mi := &file_proto_drive_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WorkZone) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WorkZone) ProtoMessage() {}
+
+func (x *WorkZone) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WorkZone.ProtoReflect.Descriptor instead.
+func (*WorkZone) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *WorkZone) GetLane() int32 {
+	if x != nil {
+		return x.Lane
+	}
+	return 0
+}
+
+func (x *WorkZone) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *WorkZone) GetEnd() float64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *WorkZone) GetTaper() float64 {
+	if x != nil {
+		return x.Taper
+	}
+	return 0
+}
+
+func (x *WorkZone) GetMerge() string {
+	if x != nil {
+		return x.Merge
+	}
+	return ""
+}
+
+func (x *WorkZone) GetMerges() int32 {
+	if x != nil {
+		return x.Merges
+	}
+	return 0
+}
+
+func (x *WorkZone) GetForcedMerges() int32 {
+	if x != nil {
+		return x.ForcedMerges
+	}
+	return 0
+}
+
+func (x *WorkZone) GetMergeDistance() float64 {
+	if x != nil {
+		return x.MergeDistance
+	}
+	return 0
+}
+
+func (x *WorkZone) GetPassed() int32 {
+	if x != nil {
+		return x.Passed
+	}
+	return 0
+}
+
+func (x *WorkZone) GetDelay() float64 {
+	if x != nil {
+		return x.Delay
+	}
+	return 0
+}
+
+type TrafficLight struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      float64                `protobuf:"fixed64,1,opt,name=position,proto3" json:"position,omitempty"`
+	Green         float64                `protobuf:"fixed64,2,opt,name=green,proto3" json:"green,omitempty"`
+	Yellow        float64                `protobuf:"fixed64,3,opt,name=yellow,proto3" json:"yellow,omitempty"`
+	Red           float64                `protobuf:"fixed64,4,opt,name=red,proto3" json:"red,omitempty"`
+	Offset        float64                `protobuf:"fixed64,5,opt,name=offset,proto3" json:"offset,omitempty"`
+	State         string                 `protobuf:"bytes,6,opt,name=state,proto3" json:"state,omitempty"`
+	Remaining     float64                `protobuf:"fixed64,7,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	Preempted     bool                   `protobuf:"varint,8,opt,name=preempted,proto3" json:"preempted,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrafficLight) Reset() {
+	*x = TrafficLight{}
+	mi := &file_proto_drive_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrafficLight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrafficLight) ProtoMessage() {}
+
+func (x *TrafficLight) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrafficLight.ProtoReflect.Descriptor instead.
+func (*TrafficLight) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *TrafficLight) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *TrafficLight) GetGreen() float64 {
+	if x != nil {
+		return x.Green
+	}
+	return 0
+}
+
+func (x *TrafficLight) GetYellow() float64 {
+	if x != nil {
+		return x.Yellow
+	}
+	return 0
+}
+
+func (x *TrafficLight) GetRed() float64 {
+	if x != nil {
+		return x.Red
+	}
+	return 0
+}
+
+func (x *TrafficLight) GetOffset() float64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *TrafficLight) GetState() string {
+	if x != nil {
+		return x.State
+	}
+	return ""
+}
+
+func (x *TrafficLight) GetRemaining() float64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *TrafficLight) GetPreempted() bool {
+	if x != nil {
+		return x.Preempted
+	}
+	return false
+}
+
+type Crosswalk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      float64                `protobuf:"fixed64,1,opt,name=position,proto3" json:"position,omitempty"`
+	Rate          float64                `protobuf:"fixed64,2,opt,name=rate,proto3" json:"rate,omitempty"`
+	CrossingTime  float64                `protobuf:"fixed64,3,opt,name=crossing_time,json=crossingTime,proto3" json:"crossing_time,omitempty"`
+	Waiting       int32                  `protobuf:"varint,4,opt,name=waiting,proto3" json:"waiting,omitempty"`
+	Crossing      int32                  `protobuf:"varint,5,opt,name=crossing,proto3" json:"crossing,omitempty"`
+	Remaining     float64                `protobuf:"fixed64,6,opt,name=remaining,proto3" json:"remaining,omitempty"`
+	Crossings     int32                  `protobuf:"varint,7,opt,name=crossings,proto3" json:"crossings,omitempty"`
+	Pedestrians   int32                  `protobuf:"varint,8,opt,name=pedestrians,proto3" json:"pedestrians,omitempty"`
+	WaitTime      float64                `protobuf:"fixed64,9,opt,name=wait_time,json=waitTime,proto3" json:"wait_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Crosswalk) Reset() {
+	*x = Crosswalk{}
+	mi := &file_proto_drive_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Crosswalk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Crosswalk) ProtoMessage() {}
+
+func (x *Crosswalk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Crosswalk.ProtoReflect.Descriptor instead.
+func (*Crosswalk) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *Crosswalk) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Crosswalk) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+func (x *Crosswalk) GetCrossingTime() float64 {
+	if x != nil {
+		return x.CrossingTime
+	}
+	return 0
+}
+
+func (x *Crosswalk) GetWaiting() int32 {
+	if x != nil {
+		return x.Waiting
+	}
+	return 0
+}
+
+func (x *Crosswalk) GetCrossing() int32 {
+	if x != nil {
+		return x.Crossing
+	}
+	return 0
+}
+
+func (x *Crosswalk) GetRemaining() float64 {
+	if x != nil {
+		return x.Remaining
+	}
+	return 0
+}
+
+func (x *Crosswalk) GetCrossings() int32 {
+	if x != nil {
+		return x.Crossings
+	}
+	return 0
+}
+
+func (x *Crosswalk) GetPedestrians() int32 {
+	if x != nil {
+		return x.Pedestrians
+	}
+	return 0
+}
+
+func (x *Crosswalk) GetWaitTime() float64 {
+	if x != nil {
+		return x.WaitTime
+	}
+	return 0
+}
+
+type OnRamp struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      float64                `protobuf:"fixed64,1,opt,name=position,proto3" json:"position,omitempty"`
+	SpawnInterval float64                `protobuf:"fixed64,2,opt,name=spawn_interval,json=spawnInterval,proto3" json:"spawn_interval,omitempty"`
+	MergeSpeed    float64                `protobuf:"fixed64,3,opt,name=merge_speed,json=mergeSpeed,proto3" json:"merge_speed,omitempty"`
+	Queue         int32                  `protobuf:"varint,4,opt,name=queue,proto3" json:"queue,omitempty"`
+	Merged        int32                  `protobuf:"varint,5,opt,name=merged,proto3" json:"merged,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OnRamp) Reset() {
+	*x = OnRamp{}
+	mi := &file_proto_drive_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OnRamp) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OnRamp) ProtoMessage() {}
+
+func (x *OnRamp) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OnRamp.ProtoReflect.Descriptor instead.
+func (*OnRamp) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *OnRamp) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *OnRamp) GetSpawnInterval() float64 {
+	if x != nil {
+		return x.SpawnInterval
+	}
+	return 0
+}
+
+func (x *OnRamp) GetMergeSpeed() float64 {
+	if x != nil {
+		return x.MergeSpeed
+	}
+	return 0
+}
+
+func (x *OnRamp) GetQueue() int32 {
+	if x != nil {
+		return x.Queue
+	}
+	return 0
+}
+
+func (x *OnRamp) GetMerged() int32 {
+	if x != nil {
+		return x.Merged
+	}
+	return 0
+}
+
+type DetectorSample struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Start         float64                `protobuf:"fixed64,1,opt,name=start,proto3" json:"start,omitempty"`
+	End           float64                `protobuf:"fixed64,2,opt,name=end,proto3" json:"end,omitempty"`
+	Count         int32                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Flow          float64                `protobuf:"fixed64,4,opt,name=flow,proto3" json:"flow,omitempty"`
+	MeanSpeed     float64                `protobuf:"fixed64,5,opt,name=mean_speed,json=meanSpeed,proto3" json:"mean_speed,omitempty"`
+	Occupancy     float64                `protobuf:"fixed64,6,opt,name=occupancy,proto3" json:"occupancy,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DetectorSample) Reset() {
+	*x = DetectorSample{}
+	mi := &file_proto_drive_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DetectorSample) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DetectorSample) ProtoMessage() {}
+
+func (x *DetectorSample) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DetectorSample.ProtoReflect.Descriptor instead.
+func (*DetectorSample) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DetectorSample) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *DetectorSample) GetEnd() float64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *DetectorSample) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *DetectorSample) GetFlow() float64 {
+	if x != nil {
+		return x.Flow
+	}
+	return 0
+}
+
+func (x *DetectorSample) GetMeanSpeed() float64 {
+	if x != nil {
+		return x.MeanSpeed
+	}
+	return 0
+}
+
+func (x *DetectorSample) GetOccupancy() float64 {
+	if x != nil {
+		return x.Occupancy
+	}
+	return 0
+}
+
+type Detector struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Position      float64                `protobuf:"fixed64,1,opt,name=position,proto3" json:"position,omitempty"`
+	Last          *DetectorSample        `protobuf:"bytes,2,opt,name=last,proto3" json:"last,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Detector) Reset() {
+	*x = Detector{}
+	mi := &file_proto_drive_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Detector) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Detector) ProtoMessage() {}
+
+func (x *Detector) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Detector.ProtoReflect.Descriptor instead.
+func (*Detector) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *Detector) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *Detector) GetLast() *DetectorSample {
+	if x != nil {
+		return x.Last
+	}
+	return nil
+}
+
+type Segment struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Start         float64                `protobuf:"fixed64,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           float64                `protobuf:"fixed64,3,opt,name=end,proto3" json:"end,omitempty"`
+	Cars          int32                  `protobuf:"varint,4,opt,name=cars,proto3" json:"cars,omitempty"`
+	AvgSpeed      float64                `protobuf:"fixed64,5,opt,name=avg_speed,json=avgSpeed,proto3" json:"avg_speed,omitempty"`
+	Density       float64                `protobuf:"fixed64,6,opt,name=density,proto3" json:"density,omitempty"`
+	Throughput    int32                  `protobuf:"varint,7,opt,name=throughput,proto3" json:"throughput,omitempty"`
+	Flow          float64                `protobuf:"fixed64,8,opt,name=flow,proto3" json:"flow,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Segment) Reset() {
+	*x = Segment{}
+	mi := &file_proto_drive_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Segment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Segment) ProtoMessage() {}
+
+func (x *Segment) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Segment.ProtoReflect.Descriptor instead.
+func (*Segment) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *Segment) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Segment) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Segment) GetEnd() float64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Segment) GetCars() int32 {
+	if x != nil {
+		return x.Cars
+	}
+	return 0
+}
+
+func (x *Segment) GetAvgSpeed() float64 {
+	if x != nil {
+		return x.AvgSpeed
+	}
+	return 0
+}
+
+func (x *Segment) GetDensity() float64 {
+	if x != nil {
+		return x.Density
+	}
+	return 0
+}
+
+func (x *Segment) GetThroughput() int32 {
+	if x != nil {
+		return x.Throughput
+	}
+	return 0
+}
+
+func (x *Segment) GetFlow() float64 {
+	if x != nil {
+		return x.Flow
+	}
+	return 0
+}
+
+type SpeedZone struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Start         float64                `protobuf:"fixed64,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           float64                `protobuf:"fixed64,3,opt,name=end,proto3" json:"end,omitempty"`
+	Limit         float64                `protobuf:"fixed64,4,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpeedZone) Reset() {
+	*x = SpeedZone{}
+	mi := &file_proto_drive_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpeedZone) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpeedZone) ProtoMessage() {}
+
+func (x *SpeedZone) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpeedZone.ProtoReflect.Descriptor instead.
+func (*SpeedZone) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *SpeedZone) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SpeedZone) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *SpeedZone) GetEnd() float64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *SpeedZone) GetLimit() float64 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type Grade struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Start         float64                `protobuf:"fixed64,2,opt,name=start,proto3" json:"start,omitempty"`
+	End           float64                `protobuf:"fixed64,3,opt,name=end,proto3" json:"end,omitempty"`
+	Grade         float64                `protobuf:"fixed64,4,opt,name=grade,proto3" json:"grade,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Grade) Reset() {
+	*x = Grade{}
+	mi := &file_proto_drive_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Grade) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Grade) ProtoMessage() {}
+
+func (x *Grade) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Grade.ProtoReflect.Descriptor instead.
+func (*Grade) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Grade) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Grade) GetStart() float64 {
+	if x != nil {
+		return x.Start
+	}
+	return 0
+}
+
+func (x *Grade) GetEnd() float64 {
+	if x != nil {
+		return x.End
+	}
+	return 0
+}
+
+func (x *Grade) GetGrade() float64 {
+	if x != nil {
+		return x.Grade
+	}
+	return 0
+}
+
+type Wave struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Lane          int32                  `protobuf:"varint,2,opt,name=lane,proto3" json:"lane,omitempty"`
+	Head          float64                `protobuf:"fixed64,3,opt,name=head,proto3" json:"head,omitempty"`
+	Tail          float64                `protobuf:"fixed64,4,opt,name=tail,proto3" json:"tail,omitempty"`
+	Cars          int32                  `protobuf:"varint,5,opt,name=cars,proto3" json:"cars,omitempty"`
+	MeanSpeed     float64                `protobuf:"fixed64,6,opt,name=mean_speed,json=meanSpeed,proto3" json:"mean_speed,omitempty"`
+	Born          float64                `protobuf:"fixed64,7,opt,name=born,proto3" json:"born,omitempty"`
+	Travel        float64                `protobuf:"fixed64,8,opt,name=travel,proto3" json:"travel,omitempty"`
+	Speed         float64                `protobuf:"fixed64,9,opt,name=speed,proto3" json:"speed,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Wave) Reset() {
+	*x = Wave{}
+	mi := &file_proto_drive_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Wave) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wave) ProtoMessage() {}
+
+func (x *Wave) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wave.ProtoReflect.Descriptor instead.
+func (*Wave) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *Wave) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Wave) GetLane() int32 {
+	if x != nil {
+		return x.Lane
+	}
+	return 0
+}
+
+func (x *Wave) GetHead() float64 {
+	if x != nil {
+		return x.Head
+	}
+	return 0
+}
+
+func (x *Wave) GetTail() float64 {
+	if x != nil {
+		return x.Tail
+	}
+	return 0
+}
+
+func (x *Wave) GetCars() int32 {
+	if x != nil {
+		return x.Cars
+	}
+	return 0
+}
+
+func (x *Wave) GetMeanSpeed() float64 {
+	if x != nil {
+		return x.MeanSpeed
+	}
+	return 0
+}
+
+func (x *Wave) GetBorn() float64 {
+	if x != nil {
+		return x.Born
+	}
+	return 0
+}
+
+func (x *Wave) GetTravel() float64 {
+	if x != nil {
+		return x.Travel
+	}
+	return 0
+}
+
+func (x *Wave) GetSpeed() float64 {
+	if x != nil {
+		return x.Speed
+	}
+	return 0
+}
+
+type SignalPhase struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Links         []string               `protobuf:"bytes,1,rep,name=links,proto3" json:"links,omitempty"`
+	Green         float64                `protobuf:"fixed64,2,opt,name=green,proto3" json:"green,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalPhase) Reset() {
+	*x = SignalPhase{}
+	mi := &file_proto_drive_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalPhase) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalPhase) ProtoMessage() {}
+
+func (x *SignalPhase) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalPhase.ProtoReflect.Descriptor instead.
+func (*SignalPhase) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SignalPhase) GetLinks() []string {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *SignalPhase) GetGreen() float64 {
+	if x != nil {
+		return x.Green
+	}
+	return 0
+}
+
+type NodeSignal struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Phases        []*SignalPhase         `protobuf:"bytes,1,rep,name=phases,proto3" json:"phases,omitempty"`
+	Yellow        float64                `protobuf:"fixed64,2,opt,name=yellow,proto3" json:"yellow,omitempty"`
+	AllRed        float64                `protobuf:"fixed64,3,opt,name=all_red,json=allRed,proto3" json:"all_red,omitempty"`
+	Offset        float64                `protobuf:"fixed64,4,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NodeSignal) Reset() {
+	*x = NodeSignal{}
+	mi := &file_proto_drive_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeSignal) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeSignal) ProtoMessage() {}
+
+func (x *NodeSignal) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeSignal.ProtoReflect.Descriptor instead.
+func (*NodeSignal) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *NodeSignal) GetPhases() []*SignalPhase {
+	if x != nil {
+		return x.Phases
+	}
+	return nil
+}
+
+func (x *NodeSignal) GetYellow() float64 {
+	if x != nil {
+		return x.Yellow
+	}
+	return 0
+}
+
+func (x *NodeSignal) GetAllRed() float64 {
+	if x != nil {
+		return x.AllRed
+	}
+	return 0
+}
+
+func (x *NodeSignal) GetOffset() float64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type Roundabout struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Circumference float64                `protobuf:"fixed64,1,opt,name=circumference,proto3" json:"circumference,omitempty"`
+	SpeedLimit    float64                `protobuf:"fixed64,2,opt,name=speed_limit,json=speedLimit,proto3" json:"speed_limit,omitempty"`
+	CriticalGap   float64                `protobuf:"fixed64,3,opt,name=critical_gap,json=criticalGap,proto3" json:"critical_gap,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Roundabout) Reset() {
+	*x = Roundabout{}
+	mi := &file_proto_drive_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Roundabout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Roundabout) ProtoMessage() {}
+
+func (x *Roundabout) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Roundabout.ProtoReflect.Descriptor instead.
+func (*Roundabout) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *Roundabout) GetCircumference() float64 {
+	if x != nil {
+		return x.Circumference
+	}
+	return 0
+}
+
+func (x *Roundabout) GetSpeedLimit() float64 {
+	if x != nil {
+		return x.SpeedLimit
+	}
+	return 0
+}
+
+func (x *Roundabout) GetCriticalGap() float64 {
+	if x != nil {
+		return x.CriticalGap
+	}
+	return 0
+}
+
+type NetworkNode struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	X             float64                `protobuf:"fixed64,2,opt,name=x,proto3" json:"x,omitempty"`
+	Y             float64                `protobuf:"fixed64,3,opt,name=y,proto3" json:"y,omitempty"`
+	Signal        *NodeSignal            `protobuf:"bytes,4,opt,name=signal,proto3" json:"signal,omitempty"`
+	Roundabout    *Roundabout            `protobuf:"bytes,5,opt,name=roundabout,proto3" json:"roundabout,omitempty"`
+	Port          string                 `protobuf:"bytes,6,opt,name=port,proto3" json:"port,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkNode) Reset() {
+	*x = NetworkNode{}
+	mi := &file_proto_drive_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkNode) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkNode) ProtoMessage() {}
+
+func (x *NetworkNode) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkNode.ProtoReflect.Descriptor instead.
+func (*NetworkNode) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *NetworkNode) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NetworkNode) GetX() float64 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *NetworkNode) GetY() float64 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *NetworkNode) GetSignal() *NodeSignal {
+	if x != nil {
+		return x.Signal
+	}
+	return nil
+}
+
+func (x *NetworkNode) GetRoundabout() *Roundabout {
+	if x != nil {
+		return x.Roundabout
+	}
+	return nil
+}
+
+func (x *NetworkNode) GetPort() string {
+	if x != nil {
+		return x.Port
+	}
+	return ""
+}
+
+type NetworkLink struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	From          string                 `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	Length        float64                `protobuf:"fixed64,4,opt,name=length,proto3" json:"length,omitempty"`
+	Lanes         int32                  `protobuf:"varint,5,opt,name=lanes,proto3" json:"lanes,omitempty"`
+	SpeedLimit    float64                `protobuf:"fixed64,6,opt,name=speed_limit,json=speedLimit,proto3" json:"speed_limit,omitempty"`
+	Cars          []*Car                 `protobuf:"bytes,7,rep,name=cars,proto3" json:"cars,omitempty"`
+	Exited        int32                  `protobuf:"varint,8,opt,name=exited,proto3" json:"exited,omitempty"`
+	Signal        string                 `protobuf:"bytes,9,opt,name=signal,proto3" json:"signal,omitempty"`
+	Delay         float64                `protobuf:"fixed64,10,opt,name=delay,proto3" json:"delay,omitempty"`
+	MeanDelay     float64                `protobuf:"fixed64,11,opt,name=mean_delay,json=meanDelay,proto3" json:"mean_delay,omitempty"`
+	MaxDelay      float64                `protobuf:"fixed64,12,opt,name=max_delay,json=maxDelay,proto3" json:"max_delay,omitempty"`
+	Queue         int32                  `protobuf:"varint,13,opt,name=queue,proto3" json:"queue,omitempty"`
+	MaxQueue      int32                  `protobuf:"varint,14,opt,name=max_queue,json=maxQueue,proto3" json:"max_queue,omitempty"`
+	SpillbackTime float64                `protobuf:"fixed64,15,opt,name=spillback_time,json=spillbackTime,proto3" json:"spillback_time,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkLink) Reset() {
+	*x = NetworkLink{}
+	mi := &file_proto_drive_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkLink) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkLink) ProtoMessage() {}
+
+func (x *NetworkLink) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkLink.ProtoReflect.Descriptor instead.
+func (*NetworkLink) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *NetworkLink) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NetworkLink) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *NetworkLink) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *NetworkLink) GetLength() float64 {
+	if x != nil {
+		return x.Length
+	}
+	return 0
+}
+
+func (x *NetworkLink) GetLanes() int32 {
+	if x != nil {
+		return x.Lanes
+	}
+	return 0
+}
+
+func (x *NetworkLink) GetSpeedLimit() float64 {
+	if x != nil {
+		return x.SpeedLimit
+	}
+	return 0
+}
+
+func (x *NetworkLink) GetCars() []*Car {
+	if x != nil {
+		return x.Cars
+	}
+	return nil
+}
+
+func (x *NetworkLink) GetExited() int32 {
+	if x != nil {
+		return x.Exited
+	}
+	return 0
+}
+
+func (x *NetworkLink) GetSignal() string {
+	if x != nil {
+		return x.Signal
+	}
+	return ""
+}
+
+func (x *NetworkLink) GetDelay() float64 {
+	if x != nil {
+		return x.Delay
+	}
+	return 0
+}
+
+func (x *NetworkLink) GetMeanDelay() float64 {
+	if x != nil {
+		return x.MeanDelay
+	}
+	return 0
+}
+
+func (x *NetworkLink) GetMaxDelay() float64 {
+	if x != nil {
+		return x.MaxDelay
+	}
+	return 0
+}
+
+func (x *NetworkLink) GetQueue() int32 {
+	if x != nil {
+		return x.Queue
+	}
+	return 0
+}
+
+func (x *NetworkLink) GetMaxQueue() int32 {
+	if x != nil {
+		return x.MaxQueue
+	}
+	return 0
+}
+
+func (x *NetworkLink) GetSpillbackTime() float64 {
+	if x != nil {
+		return x.SpillbackTime
+	}
+	return 0
+}
+
+type ODDemand struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	Rate          float64                `protobuf:"fixed64,3,opt,name=rate,proto3" json:"rate,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ODDemand) Reset() {
+	*x = ODDemand{}
+	mi := &file_proto_drive_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ODDemand) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ODDemand) ProtoMessage() {}
+
+func (x *ODDemand) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ODDemand.ProtoReflect.Descriptor instead.
+func (*ODDemand) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ODDemand) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ODDemand) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ODDemand) GetRate() float64 {
+	if x != nil {
+		return x.Rate
+	}
+	return 0
+}
+
+type Network struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Nodes         []*NetworkNode         `protobuf:"bytes,1,rep,name=nodes,proto3" json:"nodes,omitempty"`
+	Links         []*NetworkLink         `protobuf:"bytes,2,rep,name=links,proto3" json:"links,omitempty"`
+	Demand        []*ODDemand            `protobuf:"bytes,3,rep,name=demand,proto3" json:"demand,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Network) Reset() {
+	*x = Network{}
+	mi := &file_proto_drive_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Network) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Network) ProtoMessage() {}
+
+func (x *Network) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Network.ProtoReflect.Descriptor instead.
+func (*Network) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Network) GetNodes() []*NetworkNode {
+	if x != nil {
+		return x.Nodes
+	}
+	return nil
+}
+
+func (x *Network) GetLinks() []*NetworkLink {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *Network) GetDemand() []*ODDemand {
+	if x != nil {
+		return x.Demand
+	}
+	return nil
+}
+
+type BusStop struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Position      float64                `protobuf:"fixed64,2,opt,name=position,proto3" json:"position,omitempty"`
+	Dwell         float64                `protobuf:"fixed64,3,opt,name=dwell,proto3" json:"dwell,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BusStop) Reset() {
+	*x = BusStop{}
+	mi := &file_proto_drive_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BusStop) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BusStop) ProtoMessage() {}
+
+func (x *BusStop) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BusStop.ProtoReflect.Descriptor instead.
+func (*BusStop) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *BusStop) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *BusStop) GetPosition() float64 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *BusStop) GetDwell() float64 {
+	if x != nil {
+		return x.Dwell
+	}
+	return 0
+}
+
+type BusConfig struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Headway       float64                `protobuf:"fixed64,1,opt,name=headway,proto3" json:"headway,omitempty"`
+	Offset        float64                `protobuf:"fixed64,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Stops         []*BusStop             `protobuf:"bytes,3,rep,name=stops,proto3" json:"stops,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BusConfig) Reset() {
+	*x = BusConfig{}
+	mi := &file_proto_drive_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BusConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BusConfig) ProtoMessage() {}
+
+func (x *BusConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BusConfig.ProtoReflect.Descriptor instead.
+func (*BusConfig) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *BusConfig) GetHeadway() float64 {
+	if x != nil {
+		return x.Headway
+	}
+	return 0
+}
+
+func (x *BusConfig) GetOffset() float64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *BusConfig) GetStops() []*BusStop {
+	if x != nil {
+		return x.Stops
+	}
+	return nil
+}
+
+type IDMParams struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	TimeHeadway         float64                `protobuf:"fixed64,1,opt,name=time_headway,json=timeHeadway,proto3" json:"time_headway,omitempty"`
+	MaxAcceleration     float64                `protobuf:"fixed64,2,opt,name=max_acceleration,json=maxAcceleration,proto3" json:"max_acceleration,omitempty"`
+	ComfortDeceleration float64                `protobuf:"fixed64,3,opt,name=comfort_deceleration,json=comfortDeceleration,proto3" json:"comfort_deceleration,omitempty"`
+	MinGap              float64                `protobuf:"fixed64,4,opt,name=min_gap,json=minGap,proto3" json:"min_gap,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *IDMParams) Reset() {
+	*x = IDMParams{}
+	mi := &file_proto_drive_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IDMParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IDMParams) ProtoMessage() {}
+
+func (x *IDMParams) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IDMParams.ProtoReflect.Descriptor instead.
+func (*IDMParams) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *IDMParams) GetTimeHeadway() float64 {
+	if x != nil {
+		return x.TimeHeadway
+	}
+	return 0
+}
+
+func (x *IDMParams) GetMaxAcceleration() float64 {
+	if x != nil {
+		return x.MaxAcceleration
+	}
+	return 0
+}
+
+func (x *IDMParams) GetComfortDeceleration() float64 {
+	if x != nil {
+		return x.ComfortDeceleration
+	}
+	return 0
+}
+
+func (x *IDMParams) GetMinGap() float64 {
+	if x != nil {
+		return x.MinGap
+	}
+	return 0
+}
+
+type NaSchParams struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CellLength    float64                `protobuf:"fixed64,1,opt,name=cell_length,json=cellLength,proto3" json:"cell_length,omitempty"`
+	Tick          float64                `protobuf:"fixed64,2,opt,name=tick,proto3" json:"tick,omitempty"`
+	Slowdown      float64                `protobuf:"fixed64,3,opt,name=slowdown,proto3" json:"slowdown,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NaSchParams) Reset() {
+	*x = NaSchParams{}
+	mi := &file_proto_drive_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NaSchParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NaSchParams) ProtoMessage() {}
+
+func (x *NaSchParams) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NaSchParams.ProtoReflect.Descriptor instead.
+func (*NaSchParams) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *NaSchParams) GetCellLength() float64 {
+	if x != nil {
+		return x.CellLength
+	}
+	return 0
+}
+
+func (x *NaSchParams) GetTick() float64 {
+	if x != nil {
+		return x.Tick
+	}
+	return 0
+}
+
+func (x *NaSchParams) GetSlowdown() float64 {
+	if x != nil {
+		return x.Slowdown
+	}
+	return 0
+}
+
+type OVMParams struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sensitivity   float64                `protobuf:"fixed64,1,opt,name=sensitivity,proto3" json:"sensitivity,omitempty"`
+	Distance      float64                `protobuf:"fixed64,2,opt,name=distance,proto3" json:"distance,omitempty"`
+	Width         float64                `protobuf:"fixed64,3,opt,name=width,proto3" json:"width,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OVMParams) Reset() {
+	*x = OVMParams{}
+	mi := &file_proto_drive_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OVMParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OVMParams) ProtoMessage() {}
+
+func (x *OVMParams) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OVMParams.ProtoReflect.Descriptor instead.
+func (*OVMParams) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *OVMParams) GetSensitivity() float64 {
+	if x != nil {
+		return x.Sensitivity
+	}
+	return 0
+}
+
+func (x *OVMParams) GetDistance() float64 {
+	if x != nil {
+		return x.Distance
+	}
+	return 0
+}
+
+func (x *OVMParams) GetWidth() float64 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+type MOBILParams struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Politeness       float64                `protobuf:"fixed64,1,opt,name=politeness,proto3" json:"politeness,omitempty"`
+	SafeDeceleration float64                `protobuf:"fixed64,2,opt,name=safe_deceleration,json=safeDeceleration,proto3" json:"safe_deceleration,omitempty"`
+	Threshold        float64                `protobuf:"fixed64,3,opt,name=threshold,proto3" json:"threshold,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *MOBILParams) Reset() {
+	*x = MOBILParams{}
+	mi := &file_proto_drive_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MOBILParams) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MOBILParams) ProtoMessage() {}
+
+func (x *MOBILParams) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MOBILParams.ProtoReflect.Descriptor instead.
+func (*MOBILParams) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *MOBILParams) GetPoliteness() float64 {
+	if x != nil {
+		return x.Politeness
+	}
+	return 0
+}
+
+func (x *MOBILParams) GetSafeDeceleration() float64 {
+	if x != nil {
+		return x.SafeDeceleration
+	}
+	return 0
+}
+
+func (x *MOBILParams) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
+}
+
+type State struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	Cars              []*Car                 `protobuf:"bytes,1,rep,name=cars,proto3" json:"cars,omitempty"`
+	Time              float64                `protobuf:"fixed64,2,opt,name=time,proto3" json:"time,omitempty"`
+	CarsCompleted     int32                  `protobuf:"varint,3,opt,name=cars_completed,json=carsCompleted,proto3" json:"cars_completed,omitempty"`
+	TravelTime        float64                `protobuf:"fixed64,4,opt,name=travel_time,json=travelTime,proto3" json:"travel_time,omitempty"`
+	TotalCarsMade     int32                  `protobuf:"varint,5,opt,name=total_cars_made,json=totalCarsMade,proto3" json:"total_cars_made,omitempty"`
+	Running           bool                   `protobuf:"varint,6,opt,name=running,proto3" json:"running,omitempty"`
+	Paused            bool                   `protobuf:"varint,7,opt,name=paused,proto3" json:"paused,omitempty"`
+	RoadLength        float64                `protobuf:"fixed64,8,opt,name=road_length,json=roadLength,proto3" json:"road_length,omitempty"`
+	TimeScale         float64                `protobuf:"fixed64,9,opt,name=time_scale,json=timeScale,proto3" json:"time_scale,omitempty"`
+	Turbo             bool                   `protobuf:"varint,10,opt,name=turbo,proto3" json:"turbo,omitempty"`
+	Dt                float64                `protobuf:"fixed64,11,opt,name=dt,proto3" json:"dt,omitempty"`
+	MaxCars           int32                  `protobuf:"varint,12,opt,name=max_cars,json=maxCars,proto3" json:"max_cars,omitempty"`
+	ReactionTime      float64                `protobuf:"fixed64,13,opt,name=reaction_time,json=reactionTime,proto3" json:"reaction_time,omitempty"`
+	SafetyMultiplier  float64                `protobuf:"fixed64,14,opt,name=safety_multiplier,json=safetyMultiplier,proto3" json:"safety_multiplier,omitempty"`
+	BrakeDeceleration float64                `protobuf:"fixed64,15,opt,name=brake_deceleration,json=brakeDeceleration,proto3" json:"brake_deceleration,omitempty"`
+	Acceleration      float64                `protobuf:"fixed64,16,opt,name=acceleration,proto3" json:"acceleration,omitempty"`
+	HysteresisBand    float64                `protobuf:"fixed64,17,opt,name=hysteresis_band,json=hysteresisBand,proto3" json:"hysteresis_band,omitempty"`
+	SpawnSpeedMode    string                 `protobuf:"bytes,18,opt,name=spawn_speed_mode,json=spawnSpeedMode,proto3" json:"spawn_speed_mode,omitempty"`
+	SpawnDistribution string                 `protobuf:"bytes,19,opt,name=spawn_distribution,json=spawnDistribution,proto3" json:"spawn_distribution,omitempty"`
+	Blockages         []*Blockage            `protobuf:"bytes,20,rep,name=blockages,proto3" json:"blockages,omitempty"`
+	TrafficLights     []*TrafficLight        `protobuf:"bytes,21,rep,name=traffic_lights,json=trafficLights,proto3" json:"traffic_lights,omitempty"`
+	OnRamp            *OnRamp                `protobuf:"bytes,22,opt,name=on_ramp,json=onRamp,proto3" json:"on_ramp,omitempty"`
+	RoadType          string                 `protobuf:"bytes,23,opt,name=road_type,json=roadType,proto3" json:"road_type,omitempty"`
+	TruckPercentage   float64                `protobuf:"fixed64,24,opt,name=truck_percentage,json=truckPercentage,proto3" json:"truck_percentage,omitempty"`
+	CrashClearance    float64                `protobuf:"fixed64,25,opt,name=crash_clearance,json=crashClearance,proto3" json:"crash_clearance,omitempty"`
+	Collisions        int32                  `protobuf:"varint,26,opt,name=collisions,proto3" json:"collisions,omitempty"`
+	Detectors         []*Detector            `protobuf:"bytes,27,rep,name=detectors,proto3" json:"detectors,omitempty"`
+	DetectorInterval  float64                `protobuf:"fixed64,28,opt,name=detector_interval,json=detectorInterval,proto3" json:"detector_interval,omitempty"`
+	Recording         bool                   `protobuf:"varint,29,opt,name=recording,proto3" json:"recording,omitempty"`
+	TrajectorySamples int32                  `protobuf:"varint,30,opt,name=trajectory_samples,json=trajectorySamples,proto3" json:"trajectory_samples,omitempty"`
+	BusPercentage     float64                `protobuf:"fixed64,31,opt,name=bus_percentage,json=busPercentage,proto3" json:"bus_percentage,omitempty"`
+	IncidentDelay     float64                `protobuf:"fixed64,32,opt,name=incident_delay,json=incidentDelay,proto3" json:"incident_delay,omitempty"`
+	Segments          []*Segment             `protobuf:"bytes,33,rep,name=segments,proto3" json:"segments,omitempty"`
+	SpeedZones        []*SpeedZone           `protobuf:"bytes,34,rep,name=speed_zones,json=speedZones,proto3" json:"speed_zones,omitempty"`
+	Grades            []*Grade               `protobuf:"bytes,35,rep,name=grades,proto3" json:"grades,omitempty"`
+	SpeedLimit        float64                `protobuf:"fixed64,36,opt,name=speed_limit,json=speedLimit,proto3" json:"speed_limit,omitempty"`
+	OverLimitTime     float64                `protobuf:"fixed64,37,opt,name=over_limit_time,json=overLimitTime,proto3" json:"over_limit_time,omitempty"`
+	SpawnInterval     float64                `protobuf:"fixed64,38,opt,name=spawn_interval,json=spawnInterval,proto3" json:"spawn_interval,omitempty"`
+	Demand            float64                `protobuf:"fixed64,39,opt,name=demand,proto3" json:"demand,omitempty"`
+	Lanes             int32                  `protobuf:"varint,40,opt,name=lanes,proto3" json:"lanes,omitempty"`
+	LaneChanges       int32                  `protobuf:"varint,41,opt,name=lane_changes,json=laneChanges,proto3" json:"lane_changes,omitempty"`
+	BrakeEvents       int32                  `protobuf:"varint,42,opt,name=brake_events,json=brakeEvents,proto3" json:"brake_events,omitempty"`
+	AggressiveDrivers float64                `protobuf:"fixed64,43,opt,name=aggressive_drivers,json=aggressiveDrivers,proto3" json:"aggressive_drivers,omitempty"`
+	CautiousDrivers   float64                `protobuf:"fixed64,44,opt,name=cautious_drivers,json=cautiousDrivers,proto3" json:"cautious_drivers,omitempty"`
+	ReactionSpread    float64                `protobuf:"fixed64,45,opt,name=reaction_spread,json=reactionSpread,proto3" json:"reaction_spread,omitempty"`
+	AvShare           float64                `protobuf:"fixed64,46,opt,name=av_share,json=avShare,proto3" json:"av_share,omitempty"`
+	WaveThreshold     float64                `protobuf:"fixed64,47,opt,name=wave_threshold,json=waveThreshold,proto3" json:"wave_threshold,omitempty"`
+	HistoryWindow     float64                `protobuf:"fixed64,48,opt,name=history_window,json=historyWindow,proto3" json:"history_window,omitempty"`
+	HistoryStart      float64                `protobuf:"fixed64,49,opt,name=history_start,json=historyStart,proto3" json:"history_start,omitempty"`
+	Waves             []*Wave                `protobuf:"bytes,50,rep,name=waves,proto3" json:"waves,omitempty"`
+	WavesDetected     int32                  `protobuf:"varint,51,opt,name=waves_detected,json=wavesDetected,proto3" json:"waves_detected,omitempty"`
+	FuelUsed          float64                `protobuf:"fixed64,52,opt,name=fuel_used,json=fuelUsed,proto3" json:"fuel_used,omitempty"`
+	Co2Emitted        float64                `protobuf:"fixed64,53,opt,name=co2_emitted,json=co2Emitted,proto3" json:"co2_emitted,omitempty"`
+	Platoons          int32                  `protobuf:"varint,54,opt,name=platoons,proto3" json:"platoons,omitempty"`
+	Weather           string                 `protobuf:"bytes,55,opt,name=weather,proto3" json:"weather,omitempty"`
+	Model             string                 `protobuf:"bytes,56,opt,name=model,proto3" json:"model,omitempty"`
+	Idm               *IDMParams             `protobuf:"bytes,57,opt,name=idm,proto3" json:"idm,omitempty"`
+	Seed              int64                  `protobuf:"varint,58,opt,name=seed,proto3" json:"seed,omitempty"`
+	Nasch             *NaSchParams           `protobuf:"bytes,59,opt,name=nasch,proto3" json:"nasch,omitempty"`
+	Ovm               *OVMParams             `protobuf:"bytes,60,opt,name=ovm,proto3" json:"ovm,omitempty"`
+	LaneChange        string                 `protobuf:"bytes,61,opt,name=lane_change,json=laneChange,proto3" json:"lane_change,omitempty"`
+	Mobil             *MOBILParams           `protobuf:"bytes,62,opt,name=mobil,proto3" json:"mobil,omitempty"`
+	Oncoming          []*Car                 `protobuf:"bytes,63,rep,name=oncoming,proto3" json:"oncoming,omitempty"`
+	OncomingDemand    float64                `protobuf:"fixed64,64,opt,name=oncoming_demand,json=oncomingDemand,proto3" json:"oncoming_demand,omitempty"`
+	Overtakes         int32                  `protobuf:"varint,65,opt,name=overtakes,proto3" json:"overtakes,omitempty"`
+	OvertakesAborted  int32                  `protobuf:"varint,66,opt,name=overtakes_aborted,json=overtakesAborted,proto3" json:"overtakes_aborted,omitempty"`
+	HeadOnCollisions  int32                  `protobuf:"varint,67,opt,name=head_on_collisions,json=headOnCollisions,proto3" json:"head_on_collisions,omitempty"`
+	Network           *Network               `protobuf:"bytes,68,opt,name=network,proto3" json:"network,omitempty"`
+	Buses             *BusConfig             `protobuf:"bytes,69,opt,name=buses,proto3" json:"buses,omitempty"`
+	BusesDispatched   int32                  `protobuf:"varint,70,opt,name=buses_dispatched,json=busesDispatched,proto3" json:"buses_dispatched,omitempty"`
+	BusStopsServed    int32                  `protobuf:"varint,71,opt,name=bus_stops_served,json=busStopsServed,proto3" json:"bus_stops_served,omitempty"`
+	Crosswalks        []*Crosswalk           `protobuf:"bytes,72,rep,name=crosswalks,proto3" json:"crosswalks,omitempty"`
+	WorkZone          *WorkZone              `protobuf:"bytes,73,opt,name=work_zone,json=workZone,proto3" json:"work_zone,omitempty"`
+	Cameras           []*SpeedCamera         `protobuf:"bytes,74,rep,name=cameras,proto3" json:"cameras,omitempty"`
+	Bins              []*BinStats            `protobuf:"bytes,75,rep,name=bins,proto3" json:"bins,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *State) Reset() {
+	*x = State{}
+	mi := &file_proto_drive_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *State) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*State) ProtoMessage() {}
+
+func (x *State) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_drive_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use State.ProtoReflect.Descriptor instead.
+func (*State) Descriptor() ([]byte, []int) {
+	return file_proto_drive_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *State) GetCars() []*Car {
+	if x != nil {
+		return x.Cars
+	}
+	return nil
+}
+
+func (x *State) GetTime() float64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *State) GetCarsCompleted() int32 {
+	if x != nil {
+		return x.CarsCompleted
+	}
+	return 0
+}
+
+func (x *State) GetTravelTime() float64 {
+	if x != nil {
+		return x.TravelTime
+	}
+	return 0
+}
+
+func (x *State) GetTotalCarsMade() int32 {
+	if x != nil {
+		return x.TotalCarsMade
+	}
+	return 0
+}
+
+func (x *State) GetRunning() bool {
+	if x != nil {
+		return x.Running
+	}
+	return false
+}
+
+func (x *State) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *State) GetRoadLength() float64 {
+	if x != nil {
+		return x.RoadLength
+	}
+	return 0
+}
+
+func (x *State) GetTimeScale() float64 {
+	if x != nil {
+		return x.TimeScale
+	}
+	return 0
+}
+
+func (x *State) GetTurbo() bool {
+	if x != nil {
+		return x.Turbo
+	}
+	return false
+}
+
+func (x *State) GetDt() float64 {
+	if x != nil {
+		return x.Dt
+	}
+	return 0
+}
+
+func (x *State) GetMaxCars() int32 {
+	if x != nil {
+		return x.MaxCars
+	}
+	return 0
+}
+
+func (x *State) GetReactionTime() float64 {
+	if x != nil {
+		return x.ReactionTime
+	}
+	return 0
+}
+
+func (x *State) GetSafetyMultiplier() float64 {
+	if x != nil {
+		return x.SafetyMultiplier
+	}
+	return 0
+}
+
+func (x *State) GetBrakeDeceleration() float64 {
+	if x != nil {
+		return x.BrakeDeceleration
+	}
+	return 0
+}
+
+func (x *State) GetAcceleration() float64 {
+	if x != nil {
+		return x.Acceleration
+	}
+	return 0
+}
+
+func (x *State) GetHysteresisBand() float64 {
+	if x != nil {
+		return x.HysteresisBand
+	}
+	return 0
+}
+
+func (x *State) GetSpawnSpeedMode() string {
+	if x != nil {
+		return x.SpawnSpeedMode
+	}
+	return ""
+}
+
+func (x *State) GetSpawnDistribution() string {
+	if x != nil {
+		return x.SpawnDistribution
+	}
+	return ""
+}
+
+func (x *State) GetBlockages() []*Blockage {
+	if x != nil {
+		return x.Blockages
+	}
+	return nil
+}
+
+func (x *State) GetTrafficLights() []*TrafficLight {
+	if x != nil {
+		return x.TrafficLights
+	}
+	return nil
+}
+
+func (x *State) GetOnRamp() *OnRamp {
+	if x != nil {
+		return x.OnRamp
+	}
+	return nil
+}
+
+func (x *State) GetRoadType() string {
+	if x != nil {
+		return x.RoadType
+	}
+	return ""
+}
+
+func (x *State) GetTruckPercentage() float64 {
+	if x != nil {
+		return x.TruckPercentage
+	}
+	return 0
+}
+
+func (x *State) GetCrashClearance() float64 {
+	if x != nil {
+		return x.CrashClearance
+	}
+	return 0
+}
+
+func (x *State) GetCollisions() int32 {
+	if x != nil {
+		return x.Collisions
+	}
+	return 0
+}
+
+func (x *State) GetDetectors() []*Detector {
+	if x != nil {
+		return x.Detectors
+	}
+	return nil
+}
+
+func (x *State) GetDetectorInterval() float64 {
+	if x != nil {
+		return x.DetectorInterval
+	}
+	return 0
+}
+
+func (x *State) GetRecording() bool {
+	if x != nil {
+		return x.Recording
+	}
+	return false
+}
+
+func (x *State) GetTrajectorySamples() int32 {
+	if x != nil {
+		return x.TrajectorySamples
+	}
+	return 0
+}
+
+func (x *State) GetBusPercentage() float64 {
+	if x != nil {
+		return x.BusPercentage
+	}
+	return 0
+}
+
+func (x *State) GetIncidentDelay() float64 {
+	if x != nil {
+		return x.IncidentDelay
+	}
+	return 0
+}
+
+func (x *State) GetSegments() []*Segment {
+	if x != nil {
+		return x.Segments
+	}
+	return nil
+}
+
+func (x *State) GetSpeedZones() []*SpeedZone {
+	if x != nil {
+		return x.SpeedZones
+	}
+	return nil
+}
+
+func (x *State) GetGrades() []*Grade {
+	if x != nil {
+		return x.Grades
+	}
+	return nil
+}
+
+func (x *State) GetSpeedLimit() float64 {
+	if x != nil {
+		return x.SpeedLimit
+	}
+	return 0
+}
+
+func (x *State) GetOverLimitTime() float64 {
+	if x != nil {
+		return x.OverLimitTime
+	}
+	return 0
+}
+
+func (x *State) GetSpawnInterval() float64 {
+	if x != nil {
+		return x.SpawnInterval
+	}
+	return 0
+}
+
+func (x *State) GetDemand() float64 {
+	if x != nil {
+		return x.Demand
+	}
+	return 0
+}
+
+func (x *State) GetLanes() int32 {
+	if x != nil {
+		return x.Lanes
+	}
+	return 0
+}
+
+func (x *State) GetLaneChanges() int32 {
+	if x != nil {
+		return x.LaneChanges
+	}
+	return 0
+}
+
+func (x *State) GetBrakeEvents() int32 {
+	if x != nil {
+		return x.BrakeEvents
+	}
+	return 0
+}
+
+func (x *State) GetAggressiveDrivers() float64 {
+	if x != nil {
+		return x.AggressiveDrivers
+	}
+	return 0
+}
+
+func (x *State) GetCautiousDrivers() float64 {
+	if x != nil {
+		return x.CautiousDrivers
+	}
+	return 0
+}
+
+func (x *State) GetReactionSpread() float64 {
+	if x != nil {
+		return x.ReactionSpread
+	}
+	return 0
+}
+
+func (x *State) GetAvShare() float64 {
+	if x != nil {
+		return x.AvShare
+	}
+	return 0
+}
+
+func (x *State) GetWaveThreshold() float64 {
+	if x != nil {
+		return x.WaveThreshold
+	}
+	return 0
+}
+
+func (x *State) GetHistoryWindow() float64 {
+	if x != nil {
+		return x.HistoryWindow
+	}
+	return 0
+}
+
+func (x *State) GetHistoryStart() float64 {
+	if x != nil {
+		return x.HistoryStart
+	}
+	return 0
+}
+
+func (x *State) GetWaves() []*Wave {
+	if x != nil {
+		return x.Waves
+	}
+	return nil
+}
+
+func (x *State) GetWavesDetected() int32 {
+	if x != nil {
+		return x.WavesDetected
+	}
+	return 0
+}
+
+func (x *State) GetFuelUsed() float64 {
+	if x != nil {
+		return x.FuelUsed
+	}
+	return 0
+}
+
+func (x *State) GetCo2Emitted() float64 {
+	if x != nil {
+		return x.Co2Emitted
+	}
+	return 0
+}
+
+func (x *State) GetPlatoons() int32 {
+	if x != nil {
+		return x.Platoons
+	}
+	return 0
+}
+
+func (x *State) GetWeather() string {
+	if x != nil {
+		return x.Weather
+	}
+	return ""
+}
+
+func (x *State) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *State) GetIdm() *IDMParams {
+	if x != nil {
+		return x.Idm
+	}
+	return nil
+}
+
+func (x *State) GetSeed() int64 {
+	if x != nil {
+		return x.Seed
+	}
+	return 0
+}
+
+func (x *State) GetNasch() *NaSchParams {
+	if x != nil {
+		return x.Nasch
+	}
+	return nil
+}
+
+func (x *State) GetOvm() *OVMParams {
+	if x != nil {
+		return x.Ovm
+	}
+	return nil
+}
+
+func (x *State) GetLaneChange() string {
+	if x != nil {
+		return x.LaneChange
+	}
+	return ""
+}
+
+func (x *State) GetMobil() *MOBILParams {
+	if x != nil {
+		return x.Mobil
+	}
+	return nil
+}
+
+func (x *State) GetOncoming() []*Car {
+	if x != nil {
+		return x.Oncoming
+	}
+	return nil
+}
+
+func (x *State) GetOncomingDemand() float64 {
+	if x != nil {
+		return x.OncomingDemand
+	}
+	return 0
+}
+
+func (x *State) GetOvertakes() int32 {
+	if x != nil {
+		return x.Overtakes
+	}
+	return 0
+}
+
+func (x *State) GetOvertakesAborted() int32 {
+	if x != nil {
+		return x.OvertakesAborted
+	}
+	return 0
+}
+
+func (x *State) GetHeadOnCollisions() int32 {
+	if x != nil {
+		return x.HeadOnCollisions
+	}
+	return 0
+}
+
+func (x *State) GetNetwork() *Network {
+	if x != nil {
+		return x.Network
+	}
+	return nil
+}
+
+func (x *State) GetBuses() *BusConfig {
+	if x != nil {
+		return x.Buses
+	}
+	return nil
+}
+
+func (x *State) GetBusesDispatched() int32 {
+	if x != nil {
+		return x.BusesDispatched
+	}
+	return 0
+}
+
+func (x *State) GetBusStopsServed() int32 {
+	if x != nil {
+		return x.BusStopsServed
+	}
+	return 0
+}
+
+func (x *State) GetCrosswalks() []*Crosswalk {
+	if x != nil {
+		return x.Crosswalks
+	}
+	return nil
+}
+
+func (x *State) GetWorkZone() *WorkZone {
+	if x != nil {
+		return x.WorkZone
+	}
+	return nil
+}
+
+func (x *State) GetCameras() []*SpeedCamera {
+	if x != nil {
+		return x.Cameras
+	}
+	return nil
+}
+
+func (x *State) GetBins() []*BinStats {
+	if x != nil {
+		return x.Bins
+	}
+	return nil
+}
+
+var File_proto_drive_proto protoreflect.FileDescriptor
+
+const file_proto_drive_proto_rawDesc = "" +
+	"\n" +
+	"\x11proto/drive.proto\x12\bdrive.v1\x1a\x1cgoogle/protobuf/struct.proto\"<\n" +
+	"\x12StreamStateRequest\x12\x10\n" +
+	"\x03sim\x18\x01 \x01(\tR\x03sim\x12\x14\n" +
+	"\x05parts\x18\x02 \x03(\tR\x05parts\"\a\n" +
+	"\x05Empty\"\x15\n" +
+	"\x05StepN\x12\f\n" +
+	"\x01n\x18\x01 \x01(\x05R\x01n\"2\n" +
+	"\x04Seek\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x01R\x04time\x12\x16\n" +
+	"\x06resume\x18\x02 \x01(\bR\x06resume\"\xd4\x02\n" +
+	"\x0eControlRequest\x12\x10\n" +
+	"\x03sim\x18\x01 \x01(\tR\x03sim\x12'\n" +
+	"\x05start\x18\x02 \x01(\v2\x0f.drive.v1.EmptyH\x00R\x05start\x12%\n" +
+	"\x04stop\x18\x03 \x01(\v2\x0f.drive.v1.EmptyH\x00R\x04stop\x12'\n" +
+	"\x05pause\x18\x04 \x01(\v2\x0f.drive.v1.EmptyH\x00R\x05pause\x12'\n" +
+	"\x05reset\x18\x05 \x01(\v2\x0f.drive.v1.EmptyH\x00R\x05reset\x12%\n" +
+	"\x04step\x18\x06 \x01(\v2\x0f.drive.v1.StepNH\x00R\x04step\x12$\n" +
+	"\x04seek\x18\a \x01(\v2\x0e.drive.v1.SeekH\x00R\x04seek\x12\x1f\n" +
+	"\n" +
+	"time_scale\x18\b \x01(\x01H\x00R\ttimeScale\x12\x16\n" +
+	"\x05turbo\x18\t \x01(\bH\x00R\x05turboB\b\n" +
+	"\x06action\"T\n" +
+	"\fControlReply\x12\x12\n" +
+	"\x04time\x18\x01 \x01(\x01R\x04time\x12\x18\n" +
+	"\arunning\x18\x02 \x01(\bR\arunning\x12\x16\n" +
+	"\x06paused\x18\x03 \x01(\bR\x06paused\"U\n" +
+	"\x10ConfigureRequest\x12\x10\n" +
+	"\x03sim\x18\x01 \x01(\tR\x03sim\x12/\n" +
+	"\x06config\x18\x02 \x01(\v2\x17.google.protobuf.StructR\x06config\"\x10\n" +
+	"\x0eConfigureReply\"\xc9\x04\n" +
+	"\x03Car\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06driver\x18\x03 \x01(\tR\x06driver\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x01R\x06length\x12\x12\n" +
+	"\x04lane\x18\x05 \x01(\x05R\x04lane\x12\x1a\n" +
+	"\bposition\x18\x06 \x01(\x01R\bposition\x12\x14\n" +
+	"\x05speed\x18\a \x01(\x01R\x05speed\x12!\n" +
+	"\ftarget_speed\x18\b \x01(\x01R\vtargetSpeed\x12\x1f\n" +
+	"\vbrake_count\x18\t \x01(\x05R\n" +
+	"brakeCount\x12\x14\n" +
+	"\x05color\x18\n" +
+	" \x01(\tR\x05color\x12\x14\n" +
+	"\x05state\x18\v \x01(\tR\x05state\x12%\n" +
+	"\x0ereaction_delay\x18\f \x01(\x01R\rreactionDelay\x12&\n" +
+	"\x0fover_limit_time\x18\r \x01(\x01R\roverLimitTime\x12\x12\n" +
+	"\x04fuel\x18\x0e \x01(\x01R\x04fuel\x12\x10\n" +
+	"\x03co2\x18\x0f \x01(\x01R\x03co2\x12\x18\n" +
+	"\aplatoon\x18\x10 \x01(\x05R\aplatoon\x12#\n" +
+	"\rplatoon_index\x18\x11 \x01(\x05R\fplatoonIndex\x12\x16\n" +
+	"\x06player\x18\x12 \x01(\bR\x06player\x12\x1e\n" +
+	"\n" +
+	"overtaking\x18\x13 \x01(\tR\n" +
+	"overtaking\x12\x14\n" +
+	"\x05route\x18\x14 \x03(\tR\x05route\x12\x1a\n" +
+	"\bdwelling\x18\x15 \x01(\bR\bdwelling\x12\x1a\n" +
+	"\byielding\x18\x16 \x01(\bR\byielding\"\xdd\x01\n" +
+	"\bBlockage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x1a\n" +
+	"\bincident\x18\x02 \x01(\tR\bincident\x12\x1a\n" +
+	"\bposition\x18\x03 \x01(\x01R\bposition\x12\x12\n" +
+	"\x04span\x18\x04 \x01(\x01R\x04span\x12\x14\n" +
+	"\x05lanes\x18\x05 \x03(\x05R\x05lanes\x12\x1d\n" +
+	"\n" +
+	"start_time\x18\x06 \x01(\x01R\tstartTime\x12\x1a\n" +
+	"\bduration\x18\a \x01(\x01R\bduration\x12$\n" +
+	"\rrubbernecking\x18\b \x01(\x01R\rrubbernecking\"N\n" +
+	"\bLaneBins\x12\x18\n" +
+	"\adensity\x18\x01 \x01(\x01R\adensity\x12\x14\n" +
+	"\x05speed\x18\x02 \x01(\x01R\x05speed\x12\x12\n" +
+	"\x04flow\x18\x03 \x01(\x01R\x04flow\"\x8c\x01\n" +
+	"\bBinStats\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\x01R\x04from\x12\x18\n" +
+	"\adensity\x18\x02 \x01(\x01R\adensity\x12\x14\n" +
+	"\x05speed\x18\x03 \x01(\x01R\x05speed\x12\x12\n" +
+	"\x04flow\x18\x04 \x01(\x01R\x04flow\x12(\n" +
+	"\x05lanes\x18\x05 \x03(\v2\x12.drive.v1.LaneBinsR\x05lanes\"\x8f\x01\n" +
+	"\vSpeedCamera\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x01R\bposition\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x01R\x05limit\x12\x16\n" +
+	"\x06margin\x18\x03 \x01(\x01R\x06margin\x12\x16\n" +
+	"\x06passed\x18\x04 \x01(\x05R\x06passed\x12\x1e\n" +
+	"\n" +
+	"violations\x18\x05 \x01(\x05R\n" +
+	"violations\"\x84\x02\n" +
+	"\bWorkZone\x12\x12\n" +
+	"\x04lane\x18\x01 \x01(\x05R\x04lane\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x01R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x01R\x03end\x12\x14\n" +
+	"\x05taper\x18\x04 \x01(\x01R\x05taper\x12\x14\n" +
+	"\x05merge\x18\x05 \x01(\tR\x05merge\x12\x16\n" +
+	"\x06merges\x18\x06 \x01(\x05R\x06merges\x12#\n" +
+	"\rforced_merges\x18\a \x01(\x05R\fforcedMerges\x12%\n" +
+	"\x0emerge_distance\x18\b \x01(\x01R\rmergeDistance\x12\x16\n" +
+	"\x06passed\x18\t \x01(\x05R\x06passed\x12\x14\n" +
+	"\x05delay\x18\n" +
+	" \x01(\x01R\x05delay\"\xd4\x01\n" +
+	"\fTrafficLight\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x01R\bposition\x12\x14\n" +
+	"\x05green\x18\x02 \x01(\x01R\x05green\x12\x16\n" +
+	"\x06yellow\x18\x03 \x01(\x01R\x06yellow\x12\x10\n" +
+	"\x03red\x18\x04 \x01(\x01R\x03red\x12\x16\n" +
+	"\x06offset\x18\x05 \x01(\x01R\x06offset\x12\x14\n" +
+	"\x05state\x18\x06 \x01(\tR\x05state\x12\x1c\n" +
+	"\tremaining\x18\a \x01(\x01R\tremaining\x12\x1c\n" +
+	"\tpreempted\x18\b \x01(\bR\tpreempted\"\x91\x02\n" +
+	"\tCrosswalk\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x01R\bposition\x12\x12\n" +
+	"\x04rate\x18\x02 \x01(\x01R\x04rate\x12#\n" +
+	"\rcrossing_time\x18\x03 \x01(\x01R\fcrossingTime\x12\x18\n" +
+	"\awaiting\x18\x04 \x01(\x05R\awaiting\x12\x1a\n" +
+	"\bcrossing\x18\x05 \x01(\x05R\bcrossing\x12\x1c\n" +
+	"\tremaining\x18\x06 \x01(\x01R\tremaining\x12\x1c\n" +
+	"\tcrossings\x18\a \x01(\x05R\tcrossings\x12 \n" +
+	"\vpedestrians\x18\b \x01(\x05R\vpedestrians\x12\x1b\n" +
+	"\twait_time\x18\t \x01(\x01R\bwaitTime\"\x9a\x01\n" +
+	"\x06OnRamp\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x01R\bposition\x12%\n" +
+	"\x0espawn_interval\x18\x02 \x01(\x01R\rspawnInterval\x12\x1f\n" +
+	"\vmerge_speed\x18\x03 \x01(\x01R\n" +
+	"mergeSpeed\x12\x14\n" +
+	"\x05queue\x18\x04 \x01(\x05R\x05queue\x12\x16\n" +
+	"\x06merged\x18\x05 \x01(\x05R\x06merged\"\x9f\x01\n" +
+	"\x0eDetectorSample\x12\x14\n" +
+	"\x05start\x18\x01 \x01(\x01R\x05start\x12\x10\n" +
+	"\x03end\x18\x02 \x01(\x01R\x03end\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x05R\x05count\x12\x12\n" +
+	"\x04flow\x18\x04 \x01(\x01R\x04flow\x12\x1d\n" +
+	"\n" +
+	"mean_speed\x18\x05 \x01(\x01R\tmeanSpeed\x12\x1c\n" +
+	"\toccupancy\x18\x06 \x01(\x01R\toccupancy\"T\n" +
+	"\bDetector\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x01R\bposition\x12,\n" +
+	"\x04last\x18\x02 \x01(\v2\x18.drive.v1.DetectorSampleR\x04last\"\xc4\x01\n" +
+	"\aSegment\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x01R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x01R\x03end\x12\x12\n" +
+	"\x04cars\x18\x04 \x01(\x05R\x04cars\x12\x1b\n" +
+	"\tavg_speed\x18\x05 \x01(\x01R\bavgSpeed\x12\x18\n" +
+	"\adensity\x18\x06 \x01(\x01R\adensity\x12\x1e\n" +
+	"\n" +
+	"throughput\x18\a \x01(\x05R\n" +
+	"throughput\x12\x12\n" +
+	"\x04flow\x18\b \x01(\x01R\x04flow\"]\n" +
+	"\tSpeedZone\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x01R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x01R\x03end\x12\x14\n" +
+	"\x05limit\x18\x04 \x01(\x01R\x05limit\"Y\n" +
+	"\x05Grade\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05start\x18\x02 \x01(\x01R\x05start\x12\x10\n" +
+	"\x03end\x18\x03 \x01(\x01R\x03end\x12\x14\n" +
+	"\x05grade\x18\x04 \x01(\x01R\x05grade\"\xc7\x01\n" +
+	"\x04Wave\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04lane\x18\x02 \x01(\x05R\x04lane\x12\x12\n" +
+	"\x04head\x18\x03 \x01(\x01R\x04head\x12\x12\n" +
+	"\x04tail\x18\x04 \x01(\x01R\x04tail\x12\x12\n" +
+	"\x04cars\x18\x05 \x01(\x05R\x04cars\x12\x1d\n" +
+	"\n" +
+	"mean_speed\x18\x06 \x01(\x01R\tmeanSpeed\x12\x12\n" +
+	"\x04born\x18\a \x01(\x01R\x04born\x12\x16\n" +
+	"\x06travel\x18\b \x01(\x01R\x06travel\x12\x14\n" +
+	"\x05speed\x18\t \x01(\x01R\x05speed\"9\n" +
+	"\vSignalPhase\x12\x14\n" +
+	"\x05links\x18\x01 \x03(\tR\x05links\x12\x14\n" +
+	"\x05green\x18\x02 \x01(\x01R\x05green\"\x84\x01\n" +
+	"\n" +
+	"NodeSignal\x12-\n" +
+	"\x06phases\x18\x01 \x03(\v2\x15.drive.v1.SignalPhaseR\x06phases\x12\x16\n" +
+	"\x06yellow\x18\x02 \x01(\x01R\x06yellow\x12\x17\n" +
+	"\aall_red\x18\x03 \x01(\x01R\x06allRed\x12\x16\n" +
+	"\x06offset\x18\x04 \x01(\x01R\x06offset\"v\n" +
+	"\n" +
+	"Roundabout\x12$\n" +
+	"\rcircumference\x18\x01 \x01(\x01R\rcircumference\x12\x1f\n" +
+	"\vspeed_limit\x18\x02 \x01(\x01R\n" +
+	"speedLimit\x12!\n" +
+	"\fcritical_gap\x18\x03 \x01(\x01R\vcriticalGap\"\xb1\x01\n" +
+	"\vNetworkNode\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\f\n" +
+	"\x01x\x18\x02 \x01(\x01R\x01x\x12\f\n" +
+	"\x01y\x18\x03 \x01(\x01R\x01y\x12,\n" +
+	"\x06signal\x18\x04 \x01(\v2\x14.drive.v1.NodeSignalR\x06signal\x124\n" +
+	"\n" +
+	"roundabout\x18\x05 \x01(\v2\x14.drive.v1.RoundaboutR\n" +
+	"roundabout\x12\x12\n" +
+	"\x04port\x18\x06 \x01(\tR\x04port\"\x8f\x03\n" +
+	"\vNetworkLink\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04from\x18\x02 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x03 \x01(\tR\x02to\x12\x16\n" +
+	"\x06length\x18\x04 \x01(\x01R\x06length\x12\x14\n" +
+	"\x05lanes\x18\x05 \x01(\x05R\x05lanes\x12\x1f\n" +
+	"\vspeed_limit\x18\x06 \x01(\x01R\n" +
+	"speedLimit\x12!\n" +
+	"\x04cars\x18\a \x03(\v2\r.drive.v1.CarR\x04cars\x12\x16\n" +
+	"\x06exited\x18\b \x01(\x05R\x06exited\x12\x16\n" +
+	"\x06signal\x18\t \x01(\tR\x06signal\x12\x14\n" +
+	"\x05delay\x18\n" +
+	" \x01(\x01R\x05delay\x12\x1d\n" +
+	"\n" +
+	"mean_delay\x18\v \x01(\x01R\tmeanDelay\x12\x1b\n" +
+	"\tmax_delay\x18\f \x01(\x01R\bmaxDelay\x12\x14\n" +
+	"\x05queue\x18\r \x01(\x05R\x05queue\x12\x1b\n" +
+	"\tmax_queue\x18\x0e \x01(\x05R\bmaxQueue\x12%\n" +
+	"\x0espillback_time\x18\x0f \x01(\x01R\rspillbackTime\"B\n" +
+	"\bODDemand\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x12\n" +
+	"\x04rate\x18\x03 \x01(\x01R\x04rate\"\x8f\x01\n" +
+	"\aNetwork\x12+\n" +
+	"\x05nodes\x18\x01 \x03(\v2\x15.drive.v1.NetworkNodeR\x05nodes\x12+\n" +
+	"\x05links\x18\x02 \x03(\v2\x15.drive.v1.NetworkLinkR\x05links\x12*\n" +
+	"\x06demand\x18\x03 \x03(\v2\x12.drive.v1.ODDemandR\x06demand\"O\n" +
+	"\aBusStop\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1a\n" +
+	"\bposition\x18\x02 \x01(\x01R\bposition\x12\x14\n" +
+	"\x05dwell\x18\x03 \x01(\x01R\x05dwell\"f\n" +
+	"\tBusConfig\x12\x18\n" +
+	"\aheadway\x18\x01 \x01(\x01R\aheadway\x12\x16\n" +
+	"\x06offset\x18\x02 \x01(\x01R\x06offset\x12'\n" +
+	"\x05stops\x18\x03 \x03(\v2\x11.drive.v1.BusStopR\x05stops\"\xa5\x01\n" +
+	"\tIDMParams\x12!\n" +
+	"\ftime_headway\x18\x01 \x01(\x01R\vtimeHeadway\x12)\n" +
+	"\x10max_acceleration\x18\x02 \x01(\x01R\x0fmaxAcceleration\x121\n" +
+	"\x14comfort_deceleration\x18\x03 \x01(\x01R\x13comfortDeceleration\x12\x17\n" +
+	"\amin_gap\x18\x04 \x01(\x01R\x06minGap\"^\n" +
+	"\vNaSchParams\x12\x1f\n" +
+	"\vcell_length\x18\x01 \x01(\x01R\n" +
+	"cellLength\x12\x12\n" +
+	"\x04tick\x18\x02 \x01(\x01R\x04tick\x12\x1a\n" +
+	"\bslowdown\x18\x03 \x01(\x01R\bslowdown\"_\n" +
+	"\tOVMParams\x12 \n" +
+	"\vsensitivity\x18\x01 \x01(\x01R\vsensitivity\x12\x1a\n" +
+	"\bdistance\x18\x02 \x01(\x01R\bdistance\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x01R\x05width\"x\n" +
+	"\vMOBILParams\x12\x1e\n" +
+	"\n" +
+	"politeness\x18\x01 \x01(\x01R\n" +
+	"politeness\x12+\n" +
+	"\x11safe_deceleration\x18\x02 \x01(\x01R\x10safeDeceleration\x12\x1c\n" +
+	"\tthreshold\x18\x03 \x01(\x01R\tthreshold\"\x9f\x16\n" +
+	"\x05State\x12!\n" +
+	"\x04cars\x18\x01 \x03(\v2\r.drive.v1.CarR\x04cars\x12\x12\n" +
+	"\x04time\x18\x02 \x01(\x01R\x04time\x12%\n" +
+	"\x0ecars_completed\x18\x03 \x01(\x05R\rcarsCompleted\x12\x1f\n" +
+	"\vtravel_time\x18\x04 \x01(\x01R\n" +
+	"travelTime\x12&\n" +
+	"\x0ftotal_cars_made\x18\x05 \x01(\x05R\rtotalCarsMade\x12\x18\n" +
+	"\arunning\x18\x06 \x01(\bR\arunning\x12\x16\n" +
+	"\x06paused\x18\a \x01(\bR\x06paused\x12\x1f\n" +
+	"\vroad_length\x18\b \x01(\x01R\n" +
+	"roadLength\x12\x1d\n" +
+	"\n" +
+	"time_scale\x18\t \x01(\x01R\ttimeScale\x12\x14\n" +
+	"\x05turbo\x18\n" +
+	" \x01(\bR\x05turbo\x12\x0e\n" +
+	"\x02dt\x18\v \x01(\x01R\x02dt\x12\x19\n" +
+	"\bmax_cars\x18\f \x01(\x05R\amaxCars\x12#\n" +
+	"\rreaction_time\x18\r \x01(\x01R\freactionTime\x12+\n" +
+	"\x11safety_multiplier\x18\x0e \x01(\x01R\x10safetyMultiplier\x12-\n" +
+	"\x12brake_deceleration\x18\x0f \x01(\x01R\x11brakeDeceleration\x12\"\n" +
+	"\facceleration\x18\x10 \x01(\x01R\facceleration\x12'\n" +
+	"\x0fhysteresis_band\x18\x11 \x01(\x01R\x0ehysteresisBand\x12(\n" +
+	"\x10spawn_speed_mode\x18\x12 \x01(\tR\x0espawnSpeedMode\x12-\n" +
+	"\x12spawn_distribution\x18\x13 \x01(\tR\x11spawnDistribution\x120\n" +
+	"\tblockages\x18\x14 \x03(\v2\x12.drive.v1.BlockageR\tblockages\x12=\n" +
+	"\x0etraffic_lights\x18\x15 \x03(\v2\x16.drive.v1.TrafficLightR\rtrafficLights\x12)\n" +
+	"\aon_ramp\x18\x16 \x01(\v2\x10.drive.v1.OnRampR\x06onRamp\x12\x1b\n" +
+	"\troad_type\x18\x17 \x01(\tR\broadType\x12)\n" +
+	"\x10truck_percentage\x18\x18 \x01(\x01R\x0ftruckPercentage\x12'\n" +
+	"\x0fcrash_clearance\x18\x19 \x01(\x01R\x0ecrashClearance\x12\x1e\n" +
+	"\n" +
+	"collisions\x18\x1a \x01(\x05R\n" +
+	"collisions\x120\n" +
+	"\tdetectors\x18\x1b \x03(\v2\x12.drive.v1.DetectorR\tdetectors\x12+\n" +
+	"\x11detector_interval\x18\x1c \x01(\x01R\x10detectorInterval\x12\x1c\n" +
+	"\trecording\x18\x1d \x01(\bR\trecording\x12-\n" +
+	"\x12trajectory_samples\x18\x1e \x01(\x05R\x11trajectorySamples\x12%\n" +
+	"\x0ebus_percentage\x18\x1f \x01(\x01R\rbusPercentage\x12%\n" +
+	"\x0eincident_delay\x18  \x01(\x01R\rincidentDelay\x12-\n" +
+	"\bsegments\x18! \x03(\v2\x11.drive.v1.SegmentR\bsegments\x124\n" +
+	"\vspeed_zones\x18\" \x03(\v2\x13.drive.v1.SpeedZoneR\n" +
+	"speedZones\x12'\n" +
+	"\x06grades\x18# \x03(\v2\x0f.drive.v1.GradeR\x06grades\x12\x1f\n" +
+	"\vspeed_limit\x18$ \x01(\x01R\n" +
+	"speedLimit\x12&\n" +
+	"\x0fover_limit_time\x18% \x01(\x01R\roverLimitTime\x12%\n" +
+	"\x0espawn_interval\x18& \x01(\x01R\rspawnInterval\x12\x16\n" +
+	"\x06demand\x18' \x01(\x01R\x06demand\x12\x14\n" +
+	"\x05lanes\x18( \x01(\x05R\x05lanes\x12!\n" +
+	"\flane_changes\x18) \x01(\x05R\vlaneChanges\x12!\n" +
+	"\fbrake_events\x18* \x01(\x05R\vbrakeEvents\x12-\n" +
+	"\x12aggressive_drivers\x18+ \x01(\x01R\x11aggressiveDrivers\x12)\n" +
+	"\x10cautious_drivers\x18, \x01(\x01R\x0fcautiousDrivers\x12'\n" +
+	"\x0freaction_spread\x18- \x01(\x01R\x0ereactionSpread\x12\x19\n" +
+	"\bav_share\x18. \x01(\x01R\aavShare\x12%\n" +
+	"\x0ewave_threshold\x18/ \x01(\x01R\rwaveThreshold\x12%\n" +
+	"\x0ehistory_window\x180 \x01(\x01R\rhistoryWindow\x12#\n" +
+	"\rhistory_start\x181 \x01(\x01R\fhistoryStart\x12$\n" +
+	"\x05waves\x182 \x03(\v2\x0e.drive.v1.WaveR\x05waves\x12%\n" +
+	"\x0ewaves_detected\x183 \x01(\x05R\rwavesDetected\x12\x1b\n" +
+	"\tfuel_used\x184 \x01(\x01R\bfuelUsed\x12\x1f\n" +
+	"\vco2_emitted\x185 \x01(\x01R\n" +
+	"co2Emitted\x12\x1a\n" +
+	"\bplatoons\x186 \x01(\x05R\bplatoons\x12\x18\n" +
+	"\aweather\x187 \x01(\tR\aweather\x12\x14\n" +
+	"\x05model\x188 \x01(\tR\x05model\x12%\n" +
+	"\x03idm\x189 \x01(\v2\x13.drive.v1.IDMParamsR\x03idm\x12\x12\n" +
+	"\x04seed\x18: \x01(\x03R\x04seed\x12+\n" +
+	"\x05nasch\x18; \x01(\v2\x15.drive.v1.NaSchParamsR\x05nasch\x12%\n" +
+	"\x03ovm\x18< \x01(\v2\x13.drive.v1.OVMParamsR\x03ovm\x12\x1f\n" +
+	"\vlane_change\x18= \x01(\tR\n" +
+	"laneChange\x12+\n" +
+	"\x05mobil\x18> \x01(\v2\x15.drive.v1.MOBILParamsR\x05mobil\x12)\n" +
+	"\boncoming\x18? \x03(\v2\r.drive.v1.CarR\boncoming\x12'\n" +
+	"\x0foncoming_demand\x18@ \x01(\x01R\x0eoncomingDemand\x12\x1c\n" +
+	"\tovertakes\x18A \x01(\x05R\tovertakes\x12+\n" +
+	"\x11overtakes_aborted\x18B \x01(\x05R\x10overtakesAborted\x12,\n" +
+	"\x12head_on_collisions\x18C \x01(\x05R\x10headOnCollisions\x12+\n" +
+	"\anetwork\x18D \x01(\v2\x11.drive.v1.NetworkR\anetwork\x12)\n" +
+	"\x05buses\x18E \x01(\v2\x13.drive.v1.BusConfigR\x05buses\x12)\n" +
+	"\x10buses_dispatched\x18F \x01(\x05R\x0fbusesDispatched\x12(\n" +
+	"\x10bus_stops_served\x18G \x01(\x05R\x0ebusStopsServed\x123\n" +
+	"\n" +
+	"crosswalks\x18H \x03(\v2\x13.drive.v1.CrosswalkR\n" +
+	"crosswalks\x12/\n" +
+	"\twork_zone\x18I \x01(\v2\x12.drive.v1.WorkZoneR\bworkZone\x12/\n" +
+	"\acameras\x18J \x03(\v2\x15.drive.v1.SpeedCameraR\acameras\x12&\n" +
+	"\x04bins\x18K \x03(\v2\x12.drive.v1.BinStatsR\x04bins2\xcc\x01\n" +
+	"\n" +
+	"Simulation\x12>\n" +
+	"\vStreamState\x12\x1c.drive.v1.StreamStateRequest\x1a\x0f.drive.v1.State0\x01\x12;\n" +
+	"\aControl\x12\x18.drive.v1.ControlRequest\x1a\x16.drive.v1.ControlReply\x12A\n" +
+	"\tConfigure\x12\x1a.drive.v1.ConfigureRequest\x1a\x18.drive.v1.ConfigureReplyB\"Z drive-simulation/drivepb;drivepbb\x06proto3"
+
+var (
+	file_proto_drive_proto_rawDescOnce sync.Once
+	file_proto_drive_proto_rawDescData []byte
+)
+
+func file_proto_drive_proto_rawDescGZIP() []byte {
+	file_proto_drive_proto_rawDescOnce.Do(func() {
+		file_proto_drive_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_drive_proto_rawDesc), len(file_proto_drive_proto_rawDesc)))
+	})
+	return file_proto_drive_proto_rawDescData
+}
+
+var file_proto_drive_proto_msgTypes = make([]protoimpl.MessageInfo, 37)
+var file_proto_drive_proto_goTypes = []any{
+	(*StreamStateRequest)(nil), // 0: drive.v1.StreamStateRequest
+	(*Empty)(nil),              // 1: drive.v1.Empty
+	(*StepN)(nil),              // 2: drive.v1.StepN
+	(*Seek)(nil),               // 3: drive.v1.Seek
+	(*ControlRequest)(nil),     // 4: drive.v1.ControlRequest
+	(*ControlReply)(nil),       // 5: drive.v1.ControlReply
+	(*ConfigureRequest)(nil),   // 6: drive.v1.ConfigureRequest
+	(*ConfigureReply)(nil),     // 7: drive.v1.ConfigureReply
+	(*Car)(nil),                // 8: drive.v1.Car
+	(*Blockage)(nil),           // 9: drive.v1.Blockage
+	(*LaneBins)(nil),           // 10: drive.v1.LaneBins
+	(*BinStats)(nil),           // 11: drive.v1.BinStats
+	(*SpeedCamera)(nil),        // 12: drive.v1.SpeedCamera
+	(*WorkZone)(nil),           // 13: drive.v1.WorkZone
+	(*TrafficLight)(nil),       // 14: drive.v1.TrafficLight
+	(*Crosswalk)(nil),          // 15: drive.v1.Crosswalk
+	(*OnRamp)(nil),             // 16: drive.v1.OnRamp
+	(*DetectorSample)(nil),     // 17: drive.v1.DetectorSample
+	(*Detector)(nil),           // 18: drive.v1.Detector
+	(*Segment)(nil),            // 19: drive.v1.Segment
+	(*SpeedZone)(nil),          // 20: drive.v1.SpeedZone
+	(*Grade)(nil),              // 21: drive.v1.Grade
+	(*Wave)(nil),               // 22: drive.v1.Wave
+	(*SignalPhase)(nil),        // 23: drive.v1.SignalPhase
+	(*NodeSignal)(nil),         // 24: drive.v1.NodeSignal
+	(*Roundabout)(nil),         // 25: drive.v1.Roundabout
+	(*NetworkNode)(nil),        // 26: drive.v1.NetworkNode
+	(*NetworkLink)(nil),        // 27: drive.v1.NetworkLink
+	(*ODDemand)(nil),           // 28: drive.v1.ODDemand
+	(*Network)(nil),            // 29: drive.v1.Network
+	(*BusStop)(nil),            // 30: drive.v1.BusStop
+	(*BusConfig)(nil),          // 31: drive.v1.BusConfig
+	(*IDMParams)(nil),          // 32: drive.v1.IDMParams
+	(*NaSchParams)(nil),        // 33: drive.v1.NaSchParams
+	(*OVMParams)(nil),          // 34: drive.v1.OVMParams
+	(*MOBILParams)(nil),        // 35: drive.v1.MOBILParams
+	(*State)(nil),              // 36: drive.v1.State
+	(*structpb.Struct)(nil),    // 37: google.protobuf.Struct
+}
+var file_proto_drive_proto_depIdxs = []int32{
+	1,  // 0: drive.v1.ControlRequest.start:type_name -> drive.v1.Empty
+	1,  // 1: drive.v1.ControlRequest.stop:type_name -> drive.v1.Empty
+	1,  // 2: drive.v1.ControlRequest.pause:type_name -> drive.v1.Empty
+	1,  // 3: drive.v1.ControlRequest.reset:type_name -> drive.v1.Empty
+	2,  // 4: drive.v1.ControlRequest.step:type_name -> drive.v1.StepN
+	3,  // 5: drive.v1.ControlRequest.seek:type_name -> drive.v1.Seek
+	37, // 6: drive.v1.ConfigureRequest.config:type_name -> google.protobuf.Struct
+	10, // 7: drive.v1.BinStats.lanes:type_name -> drive.v1.LaneBins
+	17, // 8: drive.v1.Detector.last:type_name -> drive.v1.DetectorSample
+	23, // 9: drive.v1.NodeSignal.phases:type_name -> drive.v1.SignalPhase
+	24, // 10: drive.v1.NetworkNode.signal:type_name -> drive.v1.NodeSignal
+	25, // 11: drive.v1.NetworkNode.roundabout:type_name -> drive.v1.Roundabout
+	8,  // 12: drive.v1.NetworkLink.cars:type_name -> drive.v1.Car
+	26, // 13: drive.v1.Network.nodes:type_name -> drive.v1.NetworkNode
+	27, // 14: drive.v1.Network.links:type_name -> drive.v1.NetworkLink
+	28, // 15: drive.v1.Network.demand:type_name -> drive.v1.ODDemand
+	30, // 16: drive.v1.BusConfig.stops:type_name -> drive.v1.BusStop
+	8,  // 17: drive.v1.State.cars:type_name -> drive.v1.Car
+	9,  // 18: drive.v1.State.blockages:type_name -> drive.v1.Blockage
+	14, // 19: drive.v1.State.traffic_lights:type_name -> drive.v1.TrafficLight
+	16, // 20: drive.v1.State.on_ramp:type_name -> drive.v1.OnRamp
+	18, // 21: drive.v1.State.detectors:type_name -> drive.v1.Detector
+	19, // 22: drive.v1.State.segments:type_name -> drive.v1.Segment
+	20, // 23: drive.v1.State.speed_zones:type_name -> drive.v1.SpeedZone
+	21, // 24: drive.v1.State.grades:type_name -> drive.v1.Grade
+	22, // 25: drive.v1.State.waves:type_name -> drive.v1.Wave
+	32, // 26: drive.v1.State.idm:type_name -> drive.v1.IDMParams
+	33, // 27: drive.v1.State.nasch:type_name -> drive.v1.NaSchParams
+	34, // 28: drive.v1.State.ovm:type_name -> drive.v1.OVMParams
+	35, // 29: drive.v1.State.mobil:type_name -> drive.v1.MOBILParams
+	8,  // 30: drive.v1.State.oncoming:type_name -> drive.v1.Car
+	29, // 31: drive.v1.State.network:type_name -> drive.v1.Network
+	31, // 32: drive.v1.State.buses:type_name -> drive.v1.BusConfig
+	15, // 33: drive.v1.State.crosswalks:type_name -> drive.v1.Crosswalk
+	13, // 34: drive.v1.State.work_zone:type_name -> drive.v1.WorkZone
+	12, // 35: drive.v1.State.cameras:type_name -> drive.v1.SpeedCamera
+	11, // 36: drive.v1.State.bins:type_name -> drive.v1.BinStats
+	0,  // 37: drive.v1.Simulation.StreamState:input_type -> drive.v1.StreamStateRequest
+	4,  // 38: drive.v1.Simulation.Control:input_type -> drive.v1.ControlRequest
+	6,  // 39: drive.v1.Simulation.Configure:input_type -> drive.v1.ConfigureRequest
+	36, // 40: drive.v1.Simulation.StreamState:output_type -> drive.v1.State
+	5,  // 41: drive.v1.Simulation.Control:output_type -> drive.v1.ControlReply
+	7,  // 42: drive.v1.Simulation.Configure:output_type -> drive.v1.ConfigureReply
+	40, // [40:43] is the sub-list for method output_type
+	37, // [37:40] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
+}
+
+func init() { file_proto_drive_proto_init() }
+func file_proto_drive_proto_init() {
+	if File_proto_drive_proto != nil {
+		return
+	}
+	file_proto_drive_proto_msgTypes[4].OneofWrappers = []any{
+		(*ControlRequest_Start)(nil),
+		(*ControlRequest_Stop)(nil),
+		(*ControlRequest_Pause)(nil),
+		(*ControlRequest_Reset_)(nil),
+		(*ControlRequest_Step)(nil),
+		(*ControlRequest_Seek)(nil),
+		(*ControlRequest_TimeScale)(nil),
+		(*ControlRequest_Turbo)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_drive_proto_rawDesc), len(file_proto_drive_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   37,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_drive_proto_goTypes,
+		DependencyIndexes: file_proto_drive_proto_depIdxs,
+		MessageInfos:      file_proto_drive_proto_msgTypes,
+	}.Build()
+	File_proto_drive_proto = out.File
+	file_proto_drive_proto_goTypes = nil
+	file_proto_drive_proto_depIdxs = nil
+}
@@ -0,0 +1,215 @@
+// Схема gRPC API симуляции. Сообщение State повторяет JSON-состояние (GET /api/state,
+// WebSocket): имена полей в lowerCamelCase совпадают с ключами JSON, поэтому сервер
+// переводит снимок в State через protojson. Части, нужные в основном только интерфейсу
+// (schedule, vsl, demandProfile, метеринг рампы), в схему не входят - они доступны
+// в JSON-состоянии.
+//
+// Код Go генерируется командой go generate (см. grpc.go).
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: proto/drive.proto
+
+package drivepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Simulation_StreamState_FullMethodName = "/drive.v1.Simulation/StreamState"
+	Simulation_Control_FullMethodName     = "/drive.v1.Simulation/Control"
+	Simulation_Configure_FullMethodName   = "/drive.v1.Simulation/Configure"
+)
+
+// SimulationClient is the client API for Simulation service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SimulationClient interface {
+	// StreamState передаёт состояние симуляции с периодом рассылки WebSocket
+	StreamState(ctx context.Context, in *StreamStateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[State], error)
+	// Control выполняет команду управления прогоном
+	Control(ctx context.Context, in *ControlRequest, opts ...grpc.CallOption) (*ControlReply, error)
+	// Configure меняет параметры симуляции, как команда config
+	Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*ConfigureReply, error)
+}
+
+type simulationClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSimulationClient(cc grpc.ClientConnInterface) SimulationClient {
+	return &simulationClient{cc}
+}
+
+func (c *simulationClient) StreamState(ctx context.Context, in *StreamStateRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[State], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Simulation_ServiceDesc.Streams[0], Simulation_StreamState_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamStateRequest, State]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Simulation_StreamStateClient = grpc.ServerStreamingClient[State]
+
+func (c *simulationClient) Control(ctx context.Context, in *ControlRequest, opts ...grpc.CallOption) (*ControlReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlReply)
+	err := c.cc.Invoke(ctx, Simulation_Control_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *simulationClient) Configure(ctx context.Context, in *ConfigureRequest, opts ...grpc.CallOption) (*ConfigureReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConfigureReply)
+	err := c.cc.Invoke(ctx, Simulation_Configure_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SimulationServer is the server API for Simulation service.
+// All implementations must embed UnimplementedSimulationServer
+// for forward compatibility.
+type SimulationServer interface {
+	// StreamState передаёт состояние симуляции с периодом рассылки WebSocket
+	StreamState(*StreamStateRequest, grpc.ServerStreamingServer[State]) error
+	// Control выполняет команду управления прогоном
+	Control(context.Context, *ControlRequest) (*ControlReply, error)
+	// Configure меняет параметры симуляции, как команда config
+	Configure(context.Context, *ConfigureRequest) (*ConfigureReply, error)
+	mustEmbedUnimplementedSimulationServer()
+}
+
+// UnimplementedSimulationServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSimulationServer struct{}
+
+func (UnimplementedSimulationServer) StreamState(*StreamStateRequest, grpc.ServerStreamingServer[State]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamState not implemented")
+}
+func (UnimplementedSimulationServer) Control(context.Context, *ControlRequest) (*ControlReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Control not implemented")
+}
+func (UnimplementedSimulationServer) Configure(context.Context, *ConfigureRequest) (*ConfigureReply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Configure not implemented")
+}
+func (UnimplementedSimulationServer) mustEmbedUnimplementedSimulationServer() {}
+func (UnimplementedSimulationServer) testEmbeddedByValue()                    {}
+
+// UnsafeSimulationServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SimulationServer will
+// result in compilation errors.
+type UnsafeSimulationServer interface {
+	mustEmbedUnimplementedSimulationServer()
+}
+
+func RegisterSimulationServer(s grpc.ServiceRegistrar, srv SimulationServer) {
+	// If the following call pancis, it indicates UnimplementedSimulationServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Simulation_ServiceDesc, srv)
+}
+
+func _Simulation_StreamState_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamStateRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SimulationServer).StreamState(m, &grpc.GenericServerStream[StreamStateRequest, State]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Simulation_StreamStateServer = grpc.ServerStreamingServer[State]
+
+func _Simulation_Control_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ControlRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulationServer).Control(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Simulation_Control_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulationServer).Control(ctx, req.(*ControlRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Simulation_Configure_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfigureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SimulationServer).Configure(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Simulation_Configure_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SimulationServer).Configure(ctx, req.(*ConfigureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Simulation_ServiceDesc is the grpc.ServiceDesc for Simulation service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Simulation_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "drive.v1.Simulation",
+	HandlerType: (*SimulationServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Control",
+			Handler:    _Simulation_Control_Handler,
+		},
+		{
+			MethodName: "Configure",
+			Handler:    _Simulation_Configure_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamState",
+			Handler:       _Simulation_StreamState_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/drive.proto",
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sort"
+
+	"drive-simulation/traffic"
+)
+
+// eventExporter внешний потребитель событий симуляций (Kafka, NATS); реализации
+// собираются со своими тегами и регистрируются в exporters при инициализации
+type eventExporter interface {
+	// start подключается к брокеру по флагам; false - экспорт не настроен
+	start(ctx context.Context) (bool, error)
+	// publish отправляет сообщения о событиях симуляции sim, не задерживая рассылку
+	publish(sim string, events []exportedEvent)
+}
+
+// exportedEvent сообщение о событии для внешних потребителей
+type exportedEvent struct {
+	Type string // тип события, например "brake"
+	Data []byte // сообщение в JSON: поля traffic.Event и идентификатор симуляции sim
+}
+
+var (
+	// exporters потребители событий, вошедшие в сборку
+	exporters []eventExporter
+	// activeExporters потребители, настроенные флагами запуска
+	activeExporters []eventExporter
+)
+
+// startExport подключает потребителей событий, настроенных флагами; вызывается до
+// создания симуляций, чтобы они сразу записывали подробные события
+func startExport(ctx context.Context) error {
+	for _, exporter := range exporters {
+		ok, err := exporter.start(ctx)
+		if err != nil {
+			return err
+		}
+		if ok {
+			activeExporters = append(activeExporters, exporter)
+		}
+	}
+	return nil
+}
+
+// exportEnabled сообщает, настроен ли хотя бы один потребитель событий
+func exportEnabled() bool {
+	return len(activeExporters) > 0
+}
+
+// exportEvents передаёт потребителям события симуляции и накопленные подробные
+// события (см. traffic.Simulation.SetEventExport) в порядке времени симуляции
+func (room *Room) exportEvents(events []traffic.Event) {
+	if !exportEnabled() {
+		return
+	}
+	events = append(events, room.simulation.DrainExportEvents()...)
+	if len(events) == 0 {
+		return
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time < events[j].Time })
+
+	messages := make([]exportedEvent, 0, len(events))
+	for _, event := range events {
+		data, err := json.Marshal(struct {
+			Sim string `json:"sim"`
+			traffic.Event
+		}{room.ID, event})
+		if err != nil {
+			slog.Error("JSON marshal error", "sim", room.ID, "err", err)
+			continue
+		}
+		messages = append(messages, exportedEvent{Type: event.Type, Data: data})
+	}
+	for _, exporter := range activeExporters {
+		exporter.publish(room.ID, messages)
+	}
+}
@@ -0,0 +1,444 @@
+//go:build graphql
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"drive-simulation/traffic"
+)
+
+// graphQLSchema схема GraphQL API: запросы читают симуляции, подписка state
+// присылает новое состояние симуляции не чаще частоты рассылки
+const graphQLSchema = `
+schema {
+	query: Query
+	subscription: Subscription
+}
+
+type Query {
+	simulations: [Simulation!]!
+	simulation(id: String! = "default"): Simulation
+}
+
+type Subscription {
+	# rate - кадров в секунду (по умолчанию и не больше частоты рассылки)
+	state(sim: String! = "default", rate: Float): State!
+}
+
+type Simulation {
+	id: String!
+	clients: Int!
+	state: State!
+	summary: Summary!
+}
+
+type State {
+	time: Float!
+	running: Boolean!
+	paused: Boolean!
+	model: String!
+	lanes: Int!
+	carsCompleted: Int!
+	totalCarsMade: Int!
+	collisions: Int!
+	brakeEvents: Int!
+	laneChanges: Int!
+	incidentDelay: Float!
+	wavesDetected: Int!
+	cars(lane: Int): [Car!]!
+	waves: [Wave!]!
+}
+
+type Car {
+	id: Int!
+	type: String!
+	driver: String!
+	lane: Int!
+	position: Float!
+	speed: Float!
+	targetSpeed: Float!
+	state: String!
+}
+
+type Wave {
+	id: Int!
+	lane: Int!
+	head: Float!
+	tail: Float!
+	cars: Int!
+	meanSpeed: Float!
+	speed: Float!
+}
+
+type Summary {
+	time: Float!
+	carsMade: Int!
+	carsCompleted: Int!
+	throughput: Float!
+	brakeEvents: Int!
+	laneChanges: Int!
+	collisions: Int!
+	incidentDelay: Float!
+	fuelUsed: Float!
+	co2Emitted: Float!
+	trips: Int!
+	travelTime: Percentiles!
+	delay: Percentiles!
+}
+
+type Percentiles {
+	mean: Float!
+	p50: Float!
+	p90: Float!
+	p99: Float!
+}
+`
+
+// graphQLProtocol подпротокол WebSocket для подписок (graphql-transport-ws)
+const graphQLProtocol = "graphql-transport-ws"
+
+var schema = graphql.MustParseSchema(graphQLSchema, &gqlResolver{}, graphql.UseFieldResolvers())
+
+// graphQLUpgrader принимает подключения подписок с подпротоколом graphQLProtocol
+var graphQLUpgrader = websocket.Upgrader{CheckOrigin: checkOrigin, Subprotocols: []string{graphQLProtocol}}
+
+func init() {
+	registerGraphQL = func(mux *http.ServeMux) {
+		mux.HandleFunc("/graphql", requireRole(roleViewer, handleGraphQL))
+	}
+}
+
+// gqlResolver корневой резольвер запросов и подписок
+type gqlResolver struct{}
+
+func (*gqlResolver) Simulations() []*gqlSimulation {
+	list := rooms.list()
+	simulations := make([]*gqlSimulation, len(list))
+	for i, room := range list {
+		simulations[i] = &gqlSimulation{room}
+	}
+	return simulations
+}
+
+func (*gqlResolver) Simulation(args struct{ ID string }) *gqlSimulation {
+	if room := rooms.get(args.ID); room != nil {
+		return &gqlSimulation{room}
+	}
+	return nil
+}
+
+// State присылает состояние симуляции при каждом его изменении, но не чаще rate кадров в секунду
+func (*gqlResolver) State(ctx context.Context, args struct {
+	Sim  string
+	Rate *float64
+}) (<-chan *gqlState, error) {
+	room := rooms.get(args.Sim)
+	if room == nil {
+		return nil, fmt.Errorf("unknown simulation %q", args.Sim)
+	}
+	interval := time.Millisecond * UpdateInterval
+	if args.Rate != nil && *args.Rate > 0 {
+		interval = max(interval, time.Duration(float64(time.Second) / *args.Rate))
+	}
+	states := make(chan *gqlState)
+	go func() {
+		defer close(states)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		last := -1.0
+		for {
+			state, err := readState(room)
+			if err != nil {
+				slog.Error("GraphQL state error", "sim", room.ID, "err", err)
+				return
+			}
+			if state.Time != last {
+				select {
+				case states <- state:
+					last = state.Time
+				case <-ctx.Done():
+					return
+				}
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return states, nil
+}
+
+// gqlSimulation резольвер симуляции
+type gqlSimulation struct {
+	room *Room
+}
+
+func (s *gqlSimulation) ID() string {
+	return s.room.ID
+}
+
+func (s *gqlSimulation) Clients() int32 {
+	return int32(s.room.hub.count())
+}
+
+func (s *gqlSimulation) State() (*gqlState, error) {
+	return readState(s.room)
+}
+
+func (s *gqlSimulation) Summary() *gqlSummary {
+	summary := s.room.simulation.Summary()
+	return &gqlSummary{
+		Time:          summary.Time,
+		CarsMade:      int32(summary.CarsMade),
+		CarsCompleted: int32(summary.CarsCompleted),
+		Throughput:    summary.Throughput,
+		BrakeEvents:   int32(summary.BrakeEvents),
+		LaneChanges:   int32(summary.LaneChanges),
+		Collisions:    int32(summary.Collisions),
+		IncidentDelay: summary.IncidentDelay,
+		FuelUsed:      summary.FuelUsed,
+		CO2Emitted:    summary.CO2Emitted,
+		Trips:         int32(summary.Trips.Count),
+		TravelTime:    gqlPercentiles(summary.Trips.TravelTime),
+		Delay:         gqlPercentiles(summary.Trips.Delay),
+	}
+}
+
+// gqlState состояние симуляции для GraphQL; читается из готового кадра состояния,
+// так как машины снимка Snapshot меняются симуляцией
+type gqlState struct {
+	Time          float64    `json:"time"`
+	Running       bool       `json:"running"`
+	Paused        bool       `json:"paused"`
+	Model         string     `json:"model"`
+	Lanes         int32      `json:"lanes"`
+	CarsCompleted int32      `json:"carsCompleted"`
+	TotalCarsMade int32      `json:"totalCarsMade"`
+	Collisions    int32      `json:"collisions"`
+	BrakeEvents   int32      `json:"brakeEvents"`
+	LaneChanges   int32      `json:"laneChanges"`
+	IncidentDelay float64    `json:"incidentDelay"`
+	WavesDetected int32      `json:"wavesDetected"`
+	CarList       []*gqlCar  `json:"cars"`
+	Waves         []*gqlWave `json:"waves"`
+}
+
+// readState разбирает текущий кадр состояния симуляции room
+func readState(room *Room) (*gqlState, error) {
+	data, err := room.stateJSON()
+	if err != nil {
+		return nil, err
+	}
+	state := &gqlState{}
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// Cars возвращает машины, при заданной полосе - только машины этой полосы
+func (s *gqlState) Cars(args struct{ Lane *int32 }) []*gqlCar {
+	if args.Lane == nil {
+		return s.CarList
+	}
+	cars := make([]*gqlCar, 0)
+	for _, car := range s.CarList {
+		if car.Lane == *args.Lane {
+			cars = append(cars, car)
+		}
+	}
+	return cars
+}
+
+// gqlCar машина: position - метры, speed и targetSpeed - м/с
+type gqlCar struct {
+	ID          int32   `json:"id"`
+	Type        string  `json:"type"`
+	Driver      string  `json:"driver"`
+	Lane        int32   `json:"lane"`
+	Position    float64 `json:"position"`
+	Speed       float64 `json:"speed"`
+	TargetSpeed float64 `json:"targetSpeed"`
+	State       string  `json:"state"`
+}
+
+// gqlWave волна «стоп-старт», см. traffic.Wave
+type gqlWave struct {
+	ID        int32   `json:"id"`
+	Lane      int32   `json:"lane"`
+	Head      float64 `json:"head"`
+	Tail      float64 `json:"tail"`
+	Cars      int32   `json:"cars"`
+	MeanSpeed float64 `json:"meanSpeed"`
+	Speed     float64 `json:"speed"`
+}
+
+// gqlSummary итоги прогона, см. traffic.RunSummary
+type gqlSummary struct {
+	Time          float64
+	CarsMade      int32
+	CarsCompleted int32
+	Throughput    float64
+	BrakeEvents   int32
+	LaneChanges   int32
+	Collisions    int32
+	IncidentDelay float64
+	FuelUsed      float64
+	CO2Emitted    float64
+	Trips         int32
+	TravelTime    *gqlPercentileSet
+	Delay         *gqlPercentileSet
+}
+
+// gqlPercentileSet среднее и перцентили показателя поездок
+type gqlPercentileSet struct {
+	Mean, P50, P90, P99 float64
+}
+
+func gqlPercentiles(p traffic.Percentiles) *gqlPercentileSet {
+	return &gqlPercentileSet{Mean: p.Mean, P50: p.P50, P90: p.P90, P99: p.P99}
+}
+
+// graphQLRequest тело запроса GraphQL и данные сообщения subscribe
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// handleGraphQL выполняет запрос GraphQL (POST /graphql) или принимает подключение
+// подписок по протоколу graphql-transport-ws (GET /graphql с заголовком Upgrade)
+func handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		serveGraphQLSubscriptions(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, errors.New("use POST or a graphql-transport-ws WebSocket"))
+		return
+	}
+	var req graphQLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+	response := schema.Exec(r.Context(), req.Query, req.OperationName, req.Variables)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// graphQLMessage сообщение протокола graphql-transport-ws
+type graphQLMessage struct {
+	ID      string          `json:"id,omitempty"`
+	Type    string          `json:"type"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+// serveGraphQLSubscriptions обслуживает подписки одного соединения: каждая операция
+// subscribe выполняется в своей горутине до сообщения complete или отключения
+func serveGraphQLSubscriptions(w http.ResponseWriter, r *http.Request) {
+	conn, err := graphQLUpgrader.Upgrade(w, r, nil)
+	if err != nil {
+		requestLogger(r).Warn("WebSocket upgrade error", "err", err)
+		return
+	}
+	defer conn.Close()
+	if conn.Subprotocol() != graphQLProtocol {
+		conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4406, "subprotocol not acceptable"), time.Now().Add(WriteWait))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	var writeMu sync.Mutex
+	send := func(message graphQLMessage) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.SetWriteDeadline(time.Now().Add(WriteWait))
+		return conn.WriteJSON(message)
+	}
+	var mu sync.Mutex
+	operations := make(map[string]context.CancelFunc)
+	acknowledged := false
+
+	for {
+		var message graphQLMessage
+		if err := conn.ReadJSON(&message); err != nil {
+			return
+		}
+		switch message.Type {
+		case "connection_init":
+			acknowledged = true
+			send(graphQLMessage{Type: "connection_ack"})
+		case "ping":
+			send(graphQLMessage{Type: "pong"})
+		case "subscribe":
+			if !acknowledged {
+				conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(4401, "unauthorized"), time.Now().Add(WriteWait))
+				return
+			}
+			var req graphQLRequest
+			if err := json.Unmarshal(message.Payload, &req); err != nil {
+				payload, _ := json.Marshal([]map[string]string{{"message": err.Error()}})
+				send(graphQLMessage{ID: message.ID, Type: "error", Payload: payload})
+				continue
+			}
+			opCtx, opCancel := context.WithCancel(ctx)
+			responses, err := schema.Subscribe(opCtx, req.Query, req.OperationName, req.Variables)
+			if err != nil {
+				opCancel()
+				payload, _ := json.Marshal([]map[string]string{{"message": err.Error()}})
+				send(graphQLMessage{ID: message.ID, Type: "error", Payload: payload})
+				continue
+			}
+			mu.Lock()
+			if previous, ok := operations[message.ID]; ok {
+				previous()
+			}
+			operations[message.ID] = opCancel
+			mu.Unlock()
+			go func(id string) {
+				defer opCancel()
+				for {
+					select {
+					case response, ok := <-responses:
+						if !ok {
+							send(graphQLMessage{ID: id, Type: "complete"})
+							return
+						}
+						payload, err := json.Marshal(response)
+						if err == nil {
+							err = send(graphQLMessage{ID: id, Type: "next", Payload: payload})
+						}
+						if err != nil {
+							cancel()
+							return
+						}
+					case <-opCtx.Done():
+						return
+					}
+				}
+			}(message.ID)
+		case "complete":
+			mu.Lock()
+			if stop, ok := operations[message.ID]; ok {
+				stop()
+				delete(operations, message.ID)
+			}
+			mu.Unlock()
+		}
+	}
+}
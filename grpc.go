@@ -0,0 +1,207 @@
+//go:build grpc
+
+package main
+
+//go:generate protoc --go_out=. --go_opt=module=drive-simulation --go-grpc_out=. --go-grpc_opt=module=drive-simulation proto/drive.proto
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"drive-simulation/drivepb"
+	"drive-simulation/traffic"
+)
+
+var grpcAddr = flag.String("grpc", ":9090", "адрес gRPC-сервера (пусто - не запускать)")
+
+// stateUnmarshal переводит JSON-состояние в drivepb.State; поля, не вошедшие в схему, пропускаются
+var stateUnmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+func init() {
+	startGRPC = serveGRPC
+}
+
+// serveGRPC запускает gRPC-сервер на адресе флага -grpc; сервер останавливается по отмене ctx
+func serveGRPC(ctx context.Context) {
+	if *grpcAddr == "" {
+		return
+	}
+	listener, err := net.Listen("tcp", *grpcAddr)
+	if err != nil {
+		fatal("gRPC listen error", "err", err)
+	}
+	server := grpc.NewServer()
+	drivepb.RegisterSimulationServer(server, grpcService{})
+	go func() {
+		slog.Info("gRPC-сервер запущен", "addr", *grpcAddr)
+		if err := server.Serve(listener); err != nil {
+			slog.Error("gRPC server error", "err", err)
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		server.Stop()
+	}()
+}
+
+// grpcService реализует drivepb.SimulationServer поверх комнат сервера
+type grpcService struct {
+	drivepb.UnimplementedSimulationServer
+}
+
+// grpcRoom возвращает симуляцию по идентификатору из запроса (пусто - default)
+func grpcRoom(id string) (*Room, error) {
+	room := rooms.get(id)
+	if room == nil {
+		return nil, status.Errorf(codes.NotFound, "unknown simulation %q", id)
+	}
+	return room, nil
+}
+
+// grpcAuthorize проверяет уровень доступа по токену из метаданных authorization
+// ("Bearer <токен>"), как requireRole для HTTP
+func grpcAuthorize(ctx context.Context, min role) error {
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("authorization"); len(values) > 0 {
+			token, _ = bearerToken(values[0])
+		}
+	}
+	switch got := auth.roleForToken(token); {
+	case got >= min:
+		return nil
+	case got == roleNone:
+		return status.Error(codes.Unauthenticated, errUnauthorized.Error())
+	default:
+		return status.Error(codes.PermissionDenied, errForbidden.Error())
+	}
+}
+
+// stateMessage переводит JSON состояния (StateJSON) в сообщение State, оставляя только поля частей parts
+func stateMessage(data []byte, parts []string) (*drivepb.State, error) {
+	data, err := filterState(data, parts)
+	if err != nil {
+		return nil, err
+	}
+	message := &drivepb.State{}
+	if err := stateUnmarshal.Unmarshal(data, message); err != nil {
+		return nil, err
+	}
+	return message, nil
+}
+
+// StreamState передаёт состояние симуляции раз в UpdateInterval, пока клиент не отменит вызов;
+// неизменившееся состояние повторно не передаётся (соединение поддерживает keepalive gRPC)
+func (grpcService) StreamState(req *drivepb.StreamStateRequest, stream drivepb.Simulation_StreamStateServer) error {
+	if err := grpcAuthorize(stream.Context(), roleViewer); err != nil {
+		return err
+	}
+	room, err := grpcRoom(req.GetSim())
+	if err != nil {
+		return err
+	}
+	if err := validateParts(req.GetParts()); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ticker := time.NewTicker(time.Millisecond * UpdateInterval)
+	defer ticker.Stop()
+	var last *drivepb.State
+	for {
+		data, err := room.simulation.StateJSON()
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		message, err := stateMessage(data, req.GetParts())
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		if !proto.Equal(message, last) {
+			if err := stream.Send(message); err != nil {
+				return err
+			}
+			last = message
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Control выполняет команду управления прогоном и возвращает состояние прогона после неё
+func (grpcService) Control(ctx context.Context, req *drivepb.ControlRequest) (*drivepb.ControlReply, error) {
+	if err := grpcAuthorize(ctx, roleController); err != nil {
+		return nil, err
+	}
+	room, err := grpcRoom(req.GetSim())
+	if err != nil {
+		return nil, err
+	}
+	simulation := room.simulation
+	switch action := req.GetAction().(type) {
+	case *drivepb.ControlRequest_Start:
+		simulation.Start()
+	case *drivepb.ControlRequest_Stop:
+		simulation.Stop()
+	case *drivepb.ControlRequest_Pause:
+		simulation.Pause()
+	case *drivepb.ControlRequest_Reset_:
+		simulation.Reset()
+	case *drivepb.ControlRequest_Step:
+		n := int(action.Step.GetN())
+		if n <= 0 || n > maxStepCount {
+			return nil, status.Errorf(codes.InvalidArgument, "n: must be between 1 and %d", maxStepCount)
+		}
+		simulation.Advance(n, float64(UpdateInterval)/1000.0)
+	case *drivepb.ControlRequest_Seek:
+		if _, err := simulation.Seek(action.Seek.GetTime(), action.Seek.GetResume()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "time: %v", err)
+		}
+	case *drivepb.ControlRequest_TimeScale:
+		simulation.SetTimeScale(action.TimeScale)
+	case *drivepb.ControlRequest_Turbo:
+		simulation.SetTurbo(action.Turbo)
+	default:
+		return nil, status.Error(codes.InvalidArgument, "action: required")
+	}
+
+	m := simulation.Metrics()
+	return &drivepb.ControlReply{Time: m.Time, Running: m.Running, Paused: m.Paused}, nil
+}
+
+// Configure применяет параметры в формате data команды config после проверки
+func (grpcService) Configure(ctx context.Context, req *drivepb.ConfigureRequest) (*drivepb.ConfigureReply, error) {
+	if err := grpcAuthorize(ctx, roleController); err != nil {
+		return nil, err
+	}
+	room, err := grpcRoom(req.GetSim())
+	if err != nil {
+		return nil, err
+	}
+	data, err := protojson.Marshal(req.GetConfig())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	var config traffic.SimulationConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid config: %v", err)
+	}
+	if err := config.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	room.simulation.UpdateConfig(config)
+	return &drivepb.ConfigureReply{}, nil
+}
@@ -0,0 +1,343 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// SendBufferSize число кадров в очереди отправки одного клиента
+	SendBufferSize = 16
+	// WriteWait максимальное время записи одного кадра в соединение
+	WriteWait = 2 * time.Second
+	// MaxDroppedFrames число подряд отброшенных кадров, после которого медленный клиент отключается
+	MaxDroppedFrames = 40
+	// PongWait время, за которое клиент должен ответить на ping или прислать сообщение;
+	// иначе соединение считается оборванным и закрывается
+	PongWait = 60 * time.Second
+	// PingPeriod период отправки ping, меньше PongWait с запасом на доставку ответа
+	PingPeriod = PongWait * 9 / 10
+	// HeartbeatInterval период сообщения heartbeat клиенту, у которого уже есть текущее
+	// состояние, пока оно не меняется (симуляция остановлена или на паузе)
+	HeartbeatInterval = time.Second
+)
+
+// heartbeatMessage сообщение, которое получает клиент вместо повторного кадра неизменившегося состояния
+var heartbeatMessage = []byte(`{"event":"heartbeat"}`)
+
+// Hub хранит подключенных клиентов и раздаёт им кадры состояния через
+// очереди отправки, чтобы медленный клиент не задерживал остальных
+type Hub struct {
+	mu      sync.RWMutex
+	clients map[*Client]bool
+	deltas  deltaEncoder
+	// last - предыдущий кадр состояния, generation - номер его версии (растёт при каждом
+	// изменении состояния); меняются только рассылкой
+	last       []byte
+	generation uint64
+}
+
+func newHub() *Hub {
+	return &Hub{clients: make(map[*Client]bool)}
+}
+
+// register добавляет клиента WebSocket и запускает его горутину записи
+func (h *Hub) register(c *Client) {
+	h.attach(c)
+	go c.writePump()
+}
+
+// attach добавляет клиента в рассылку; кадры из его очереди забирает вызывающий
+func (h *Hub) attach(c *Client) {
+	h.mu.Lock()
+	h.clients[c] = true
+	h.mu.Unlock()
+}
+
+// unregister удаляет клиента и закрывает его очередь; повторный вызов ничего не делает
+func (h *Hub) unregister(c *Client) {
+	h.mu.Lock()
+	if _, ok := h.clients[c]; ok {
+		delete(h.clients, c)
+		close(c.send)
+	}
+	h.mu.Unlock()
+}
+
+// count возвращает число подключенных клиентов
+func (h *Hub) count() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.clients)
+}
+
+// broadcast ставит сериализованное состояние в очереди всех клиентов с учётом подписок.
+// Если очередь клиента заполнена, кадр отбрасывается (следующий всё равно содержит
+// полное состояние, а клиенту в дельта-режиме следующим отправляется ключевой кадр);
+// после MaxDroppedFrames отброшенных подряд кадров клиент отключается.
+// Клиент, уже получивший это состояние со своей подпиской, вместо повторного кадра
+// раз в HeartbeatInterval получает сообщение heartbeat.
+func (h *Hub) broadcast(data []byte) {
+	// Кадры для каждого сочетания вида кадра, формата и подписки строятся один раз за цикл рассылки
+	sources := map[string][]byte{"full": data}
+	frames := map[string][]byte{"full|" + FormatJSON + "|": data}
+	heartbeats := map[string][]byte{FormatJSON: heartbeatMessage}
+	var slow []*Client
+	now := time.Now()
+	changed := !bytes.Equal(data, h.last)
+	if changed {
+		h.last = data
+		h.generation++
+	}
+
+	h.mu.RLock()
+	switch {
+	case !h.hasDeltaClients():
+		h.deltas.reset()
+	case changed:
+		keyframe, delta, err := h.deltas.next(data)
+		if err != nil {
+			slog.Error("Delta encode error", "err", err)
+			h.deltas.reset()
+		}
+		sources["keyframe"], sources["delta"] = keyframe, delta
+	default:
+		// Дельта не меняется, а ждущим ключевой кадр он строится с номером последней дельты
+		keyframe, err := makeKeyframe(data, h.deltas.seq)
+		if err != nil {
+			slog.Error("Delta encode error", "err", err)
+		}
+		sources["keyframe"] = keyframe
+	}
+
+	for client := range h.clients {
+		if !client.due(now) {
+			// Пропущенный кадр изменившегося состояния делает следующую дельту неприменимой
+			if client.delta && changed {
+				client.requestKeyframe()
+			}
+			continue
+		}
+		key := client.subscriptionKey()
+		var frame []byte
+		var err error
+		live := false
+		if replay := client.activeReplay(); replay != nil {
+			// Клиенту, смотрящему запись, отправляется её кадр вместо живого состояния;
+			// в дельта-режиме - ключевым кадром, и после записи живое состояние тоже начнётся с ключевого
+			source := replay.Frame(now)
+			if client.delta {
+				client.requestKeyframe()
+				source, err = makeKeyframe(source, h.deltas.seq)
+			}
+			if err == nil {
+				frame, err = client.encode(source, key)
+			}
+			// После записи клиенту нужен кадр живого состояния, даже если оно не менялось
+			client.generation = 0
+		} else if client.upToDate(h.generation, key) {
+			if now.Sub(client.lastSent) < HeartbeatInterval {
+				continue
+			}
+			if frame = heartbeats[client.format]; frame == nil {
+				frame, err = client.encode(heartbeatMessage, "")
+				heartbeats[client.format] = frame
+			}
+		} else {
+			live = true
+			kind := client.frameKind()
+			cacheKey := kind + "|" + client.format + "|" + key
+			frame = frames[cacheKey]
+			if frame == nil && sources[kind] != nil {
+				frame, err = client.encode(sources[kind], key)
+				frames[cacheKey] = frame
+			}
+			if frame == nil && err == nil {
+				// Дельту построить не удалось: клиент получит ключевой кадр в следующий раз
+				client.requestKeyframe()
+				continue
+			}
+		}
+		if err != nil {
+			client.log.Error("Frame encode error", "err", err)
+			continue
+		}
+
+		if !client.enqueue(frame) {
+			slow = append(slow, client)
+		}
+		if client.dropped > 0 {
+			if client.delta {
+				client.requestKeyframe()
+			}
+			continue
+		}
+		client.lastSent = now
+		if live {
+			client.generation, client.sentKey = h.generation, key
+		}
+	}
+	h.mu.RUnlock()
+
+	h.disconnect(slow)
+}
+
+// reply отправляет клиенту ответ на команду (см. commandReply); ответ не учитывается
+// в счётчике отброшенных кадров и теряется только при заполненной очереди
+func (h *Hub) reply(c *Client, id any, err error) {
+	frame, encodeErr := c.encode(commandReply(id, err), "")
+	if encodeErr != nil {
+		c.log.Error("Frame encode error", "err", encodeErr)
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if !h.clients[c] {
+		return
+	}
+	select {
+	case c.send <- frame:
+	default:
+		c.log.Warn("Reply dropped: send queue full")
+	}
+}
+
+// hasDeltaClients сообщает, есть ли клиенты в дельта-режиме; вызывается под блокировкой хаба
+func (h *Hub) hasDeltaClients() bool {
+	for client := range h.clients {
+		if client.delta {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcastEvent ставит сообщение о событии в очереди всех клиентов независимо от подписки
+func (h *Hub) broadcastEvent(data []byte) {
+	frames := map[string][]byte{FormatJSON: data}
+	var slow []*Client
+
+	h.mu.RLock()
+	for client := range h.clients {
+		frame, ok := frames[client.format]
+		if !ok {
+			var err error
+			frame, err = client.encode(data, "")
+			if err != nil {
+				client.log.Error("Frame encode error", "err", err)
+				continue
+			}
+			frames[client.format] = frame
+		}
+		if !client.enqueue(frame) {
+			slow = append(slow, client)
+		}
+	}
+	h.mu.RUnlock()
+
+	h.disconnect(slow)
+}
+
+// closeAll отключает всех клиентов: их очереди дописываются, после чего клиент получает
+// кадр закрытия с причиной reason. Ждёт завершения записи не дольше дедлайна ctx.
+func (h *Hub) closeAll(ctx context.Context, reason string) {
+	h.mu.RLock()
+	clients := make([]*Client, 0, len(h.clients))
+	for client := range h.clients {
+		clients = append(clients, client)
+	}
+	h.mu.RUnlock()
+
+	for _, client := range clients {
+		client.setCloseReason(reason)
+		h.unregister(client)
+	}
+	for _, client := range clients {
+		select {
+		case <-client.done:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// disconnect отключает клиентов, не успевающих забирать кадры
+func (h *Hub) disconnect(slow []*Client) {
+	for _, client := range slow {
+		client.log.Warn("Client too slow, disconnecting")
+		client.setCloseReason("client too slow")
+		h.unregister(client)
+	}
+}
+
+// encode готовит JSON-кадр для клиента: оставляет части подписки key
+// и перекодирует в формат клиента
+func (c *Client) encode(data []byte, key string) ([]byte, error) {
+	frame, err := filterState(data, subscriptionParts(key))
+	if err != nil || c.format != FormatMsgpack {
+		return frame, err
+	}
+	return jsonToMsgpack(frame)
+}
+
+// enqueue ставит кадр в очередь клиента или отбрасывает его при заполненной очереди;
+// возвращает false, когда клиент отбросил MaxDroppedFrames кадров подряд.
+// Вызывается под блокировкой чтения хаба.
+func (c *Client) enqueue(frame []byte) bool {
+	select {
+	case c.send <- frame:
+		c.dropped = 0
+	default:
+		c.dropped++
+	}
+	return c.dropped < MaxDroppedFrames
+}
+
+// writePump пишет кадры из очереди клиента в соединение и раз в PingPeriod
+// отправляет ping; завершается, когда хаб закрывает очередь (дописав её
+// и отправив кадр закрытия) или запись не укладывается в WriteWait
+func (c *Client) writePump() {
+	defer close(c.done)
+	defer c.conn.Close()
+
+	messageType := websocket.TextMessage
+	if c.format == FormatMsgpack {
+		messageType = websocket.BinaryMessage
+	}
+	ping := time.NewTicker(PingPeriod)
+	defer ping.Stop()
+	for {
+		var err error
+		select {
+		case frame, ok := <-c.send:
+			if !ok {
+				c.writeClose()
+				return
+			}
+			c.conn.SetWriteDeadline(time.Now().Add(WriteWait))
+			err = c.conn.WriteMessage(messageType, frame)
+		case <-ping.C:
+			err = c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(WriteWait))
+		}
+		if err != nil {
+			c.log.Warn("WebSocket write error", "err", err)
+			c.room.hub.unregister(c)
+			return
+		}
+	}
+}
+
+// writeClose отправляет кадр закрытия с причиной отключения
+func (c *Client) writeClose() {
+	code, reason := websocket.CloseNormalClosure, c.disconnectReason()
+	if reason != "" {
+		code = websocket.CloseGoingAway
+	}
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, reason), time.Now().Add(WriteWait))
+}
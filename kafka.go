@@ -0,0 +1,70 @@
+//go:build kafka
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var (
+	kafkaBrokers = flag.String("kafka", envOr("DRIVE_KAFKA", ""), "брокеры Kafka через запятую для экспорта событий (пусто - не экспортировать)")
+	kafkaTopic   = flag.String("kafka-topic", "drive-events", "топик Kafka для событий симуляций")
+)
+
+func init() {
+	exporters = append(exporters, &kafkaExporter{})
+}
+
+// kafkaExporter экспорт событий в топик Kafka: ключ сообщения - идентификатор
+// симуляции (события одной симуляции попадают в один раздел по порядку),
+// заголовок type - тип события
+type kafkaExporter struct {
+	writer *kafka.Writer
+}
+
+func (e *kafkaExporter) start(ctx context.Context) (bool, error) {
+	if *kafkaBrokers == "" {
+		return false, nil
+	}
+	e.writer = &kafka.Writer{
+		Addr:         kafka.TCP(strings.Split(*kafkaBrokers, ",")...),
+		Topic:        *kafkaTopic,
+		Balancer:     &kafka.Hash{},
+		BatchTimeout: time.Millisecond * UpdateInterval,
+		// Запись не ждёт брокера, ошибки доставки только попадают в журнал
+		Async: true,
+		Completion: func(messages []kafka.Message, err error) {
+			if err != nil {
+				slog.Error("Kafka write error", "topic", *kafkaTopic, "messages", len(messages), "err", err)
+			}
+		},
+	}
+	RegisterFlusher(e)
+	slog.Info("События экспортируются в Kafka", "brokers", *kafkaBrokers, "topic", *kafkaTopic)
+	return true, nil
+}
+
+func (e *kafkaExporter) publish(sim string, events []exportedEvent) {
+	messages := make([]kafka.Message, len(events))
+	for i, event := range events {
+		messages[i] = kafka.Message{
+			Key:     []byte(sim),
+			Value:   event.Data,
+			Headers: []kafka.Header{{Key: "type", Value: []byte(event.Type)}},
+		}
+	}
+	if err := e.writer.WriteMessages(context.Background(), messages...); err != nil {
+		slog.Error("Kafka write error", "topic", *kafkaTopic, "err", err)
+	}
+}
+
+// Flush дописывает накопленные сообщения и закрывает соединения при остановке сервера
+func (e *kafkaExporter) Flush(ctx context.Context) error {
+	return e.writer.Close()
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"drive-simulation/traffic"
+)
+
+// ConfigPollInterval период проверки файла -config в сборке без тега fsnotify
+const ConfigPollInterval = time.Second
+
+// watchConfigFile вызывает changed после каждого изменения файла path, пока не отменён
+// ctx. По умолчанию файл опрашивается раз в ConfigPollInterval; сборка с тегом fsnotify
+// заменяет опрос уведомлениями файловой системы (см. liveconfig_fsnotify.go).
+var watchConfigFile = pollConfigFile
+
+// configReloader применяет к симуляции параметры потока из файла -config и пишет
+// в журнал запись аудита о каждом применённом изменении
+type configReloader struct {
+	path       string
+	simulation *traffic.Simulation
+	applied    map[string]json.RawMessage // поля последней применённой версии файла
+}
+
+// startConfigReload применяет файл параметров потока path к симуляции и следит за его
+// изменениями до отмены ctx. Ошибка первой загрузки возвращается; файл с ошибкой,
+// сохранённый позже, не применяется, и симуляция работает с прежними значениями.
+func startConfigReload(ctx context.Context, path string, simulation *traffic.Simulation) error {
+	reloader := &configReloader{path: path, simulation: simulation}
+	if err := reloader.reload(); err != nil {
+		return err
+	}
+	go func() {
+		err := watchConfigFile(ctx, path, func() {
+			if err := reloader.reload(); err != nil {
+				slog.Warn("Config reload rejected, previous values kept", "path", path, "err", err)
+			}
+		})
+		if err != nil {
+			slog.Error("Config watch error", "path", path, "err", err)
+		}
+	}()
+	return nil
+}
+
+// reload читает файл и, если его поля изменились, применяет все заданные в нём параметры:
+// файл - источник этих значений и после изменения их командой config
+func (c *configReloader) reload() error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return err
+	}
+	config, err := traffic.ParseLiveConfig(data)
+	if err != nil {
+		return fmt.Errorf("%s: %w", c.path, err)
+	}
+	fields, err := liveConfigFields(config)
+	if err != nil {
+		return err
+	}
+	changed := changedFields(c.applied, fields)
+	if len(changed) == 0 {
+		return nil
+	}
+
+	// Профиль спроса, удалённый из файла, отключается; прочие удалённые поля сохраняют значения
+	if config.DemandProfile == nil && config.DemandBins == nil && (c.applied["demandProfile"] != nil || c.applied["demandBins"] != nil) {
+		config.DemandProfile = []traffic.DemandPoint{}
+	}
+	// Диапазон скоростей проверяется вместе с текущими значениями симуляции
+	if err := c.simulation.UpdateLiveConfig(config); err != nil {
+		return fmt.Errorf("%s: %w", c.path, err)
+	}
+	for _, name := range changed {
+		slog.Info("Config change", "path", c.path, "field", name, "old", auditValue(c.applied[name]), "new", auditValue(fields[name]))
+	}
+	c.applied = fields
+	return nil
+}
+
+// liveConfigFields возвращает заданные поля параметров потока в JSON
+func liveConfigFields(config traffic.LiveConfig) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	maps.DeleteFunc(fields, func(_ string, value json.RawMessage) bool { return string(value) == "null" })
+	return fields, nil
+}
+
+// changedFields возвращает по алфавиту поля, добавленные, удалённые или изменённые в next
+func changedFields(prev, next map[string]json.RawMessage) []string {
+	var changed []string
+	for name, value := range next {
+		if string(prev[name]) != string(value) {
+			changed = append(changed, name)
+		}
+	}
+	for name := range prev {
+		if _, ok := next[name]; !ok {
+			changed = append(changed, name)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
+
+// auditValue значение поля для записи аудита; "unset" - поля нет в файле
+func auditValue(value json.RawMessage) string {
+	if value == nil {
+		return "unset"
+	}
+	return string(value)
+}
+
+// pollConfigFile опрашивает время изменения и размер файла path
+func pollConfigFile(ctx context.Context, path string, changed func()) error {
+	ticker := time.NewTicker(ConfigPollInterval)
+	defer ticker.Stop()
+
+	last, _ := os.Stat(path)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			// Файл заменяется редактором или удалён: ждём, пока он появится снова
+			continue
+		}
+		if last == nil || !info.ModTime().Equal(last.ModTime()) || info.Size() != last.Size() {
+			last = info
+			changed()
+		}
+	}
+}
@@ -0,0 +1,59 @@
+//go:build fsnotify
+
+package main
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// configDebounce пауза после последнего уведомления об изменении файла -config перед
+// его чтением: редактор сохраняет файл несколькими операциями
+const configDebounce = 100 * time.Millisecond
+
+func init() {
+	watchConfigFile = notifyConfigFile
+}
+
+// notifyConfigFile следит за файлом path через уведомления файловой системы. Наблюдается
+// каталог файла: редакторы, сохраняющие через временный файл и переименование,
+// заменяют сам файл, и наблюдение за ним оборвалось бы после первого сохранения.
+func notifyConfigFile(ctx context.Context, path string, changed func()) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+	path = filepath.Clean(path)
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	debounce := time.NewTimer(configDebounce)
+	debounce.Stop()
+	defer debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if filepath.Clean(event.Name) == path && event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename) != 0 {
+				debounce.Reset(configDebounce)
+			}
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			slog.Warn("Config watch error", "path", path, "err", err)
+		case <-debounce.C:
+			changed()
+		}
+	}
+}
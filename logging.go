@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// requestSeq счётчик идентификаторов запросов и соединений
+var requestSeq atomic.Int64
+
+// loggerKey ключ журнала запроса в контексте
+type loggerKey struct{}
+
+// setupLogging направляет журнал сервера (и пакета log) в stderr в текстовом формате
+// slog с уровнем level: "debug", "info", "warn" или "error"
+func setupLogging(level string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: lvl})))
+	return nil
+}
+
+// fatal пишет ошибку в журнал и завершает процесс
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
+
+// withRequestLog присваивает запросу идентификатор (X-Request-ID от прокси или новый),
+// возвращает его в заголовке ответа, кладёт в контекст журнал с этим идентификатором
+// и пишет запрос в журнал на уровне debug. Для WebSocket и /events идентификатор
+// запроса служит идентификатором соединения, а запись появляется при его закрытии.
+func withRequestLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if id == "" {
+			id = strconv.FormatInt(requestSeq.Add(1), 10)
+		}
+		w.Header().Set("X-Request-ID", id)
+		logger := slog.With("request", id)
+
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), loggerKey{}, logger)))
+		logger.Debug("HTTP request", "method", r.Method, "path", r.URL.Path, "remote", r.RemoteAddr,
+			"status", recorder.status, "duration", time.Since(start))
+	})
+}
+
+// requestLogger возвращает журнал запроса с его идентификатором
+func requestLogger(r *http.Request) *slog.Logger {
+	if logger, ok := r.Context().Value(loggerKey{}).(*slog.Logger); ok {
+		return logger
+	}
+	return slog.Default()
+}
+
+// statusRecorder запоминает код ответа; Unwrap и Hijack сохраняют доступ
+// к Flush для /events и к переходу на WebSocket
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	r.status = http.StatusSwitchingProtocols
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
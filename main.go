@@ -1,471 +1,972 @@
 package main
 
 import (
+	"context"
+	"embed"
 	"encoding/json"
-	"log"
-	"math"
-	"math/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net"
 	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"slices"
+	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"github.com/gorilla/websocket"
-)
 
-const (
-	RoadLength     = 5000.0 // метры (5 км)
-	CarLength      = 4.5    // метры
-	UpdateInterval = 50     // миллисекунды
+	"drive-simulation/traffic"
 )
 
-// Car представляет автомобиль
-type Car struct {
-	ID              int     `json:"id"`
-	Position        float64 `json:"position"`        // метры от начала
-	Speed           float64 `json:"speed"`           // м/с
-	TargetSpeed     float64 `json:"targetSpeed"`     // желаемая скорость
-	BrakeCount      int     `json:"brakeCount"`      // количество торможений
-	Color           string  `json:"color"`           // цвет для визуализации
-	State           string  `json:"state"`           // "normal", "braking", "accelerating"
-	ReactionDelay   float64 `json:"reactionDelay"`   // время задержки реакции
-	lastBrakeTime   float64 // для отслеживания задержки
-}
-
-// Simulation представляет симуляцию движения
-type Simulation struct {
-	Cars              []*Car       `json:"cars"`
-	Time              float64      `json:"time"`
-	CarsCompleted     int          `json:"carsCompleted"`
-	TotalCarsMade     int          `json:"totalCarsMade"`
-	Running           bool         `json:"running"`
-	SpawnInterval     float64      `json:"spawnInterval"`     // секунды между машинами
-	MinSpeed          float64      `json:"minSpeed"`          // м/с
-	MaxSpeed          float64      `json:"maxSpeed"`          // м/с
-	TimeScale         float64      `json:"timeScale"`         // множитель скорости времени (1.0 = нормально)
-	MaxCars           int          `json:"maxCars"`           // максимальное количество машин для генерации
-	ReactionTime      float64      `json:"reactionTime"`      // секунды задержки реакции
-	SafetyMultiplier  float64      `json:"safetyMultiplier"`  // коэффициент безопасной дистанции
-	BrakeDeceleration float64      `json:"brakeDeceleration"` // м/с² торможение
-	Acceleration      float64      `json:"acceleration"`      // м/с² ускорение
-	mu                sync.RWMutex
-	lastSpawn         float64
-	nextCarID         int
-}
-
-// SimulationConfig конфигурация симуляции
-type SimulationConfig struct {
-	SpawnInterval float64 `json:"spawnInterval"` // секунды
-	MinSpeed      float64 `json:"minSpeed"`      // км/ч
-	MaxSpeed      float64 `json:"maxSpeed"`      // км/ч
-	MaxCars       int     `json:"maxCars"`       // максимальное количество машин
-}
-
-// PhysicsConfig конфигурация параметров физики
-type PhysicsConfig struct {
-	ReactionTime      float64 `json:"reactionTime"`      // секунды
-	SafetyMultiplier  float64 `json:"safetyMultiplier"`  // коэффициент
-	BrakeDeceleration float64 `json:"brakeDeceleration"` // м/с²
-	Acceleration      float64 `json:"acceleration"`      // м/с²
-}
+// UpdateInterval период цикла симуляции и рассылки состояния, миллисекунды
+const UpdateInterval = 50
 
 var (
+	// assets веб-интерфейс, встроенный в бинарный файл
+	//go:embed index.html
+	assets embed.FS
+	// devAssets - веб-интерфейс читается с диска (флаг -dev)
+	devAssets bool
+
 	upgrader = websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true
-		},
-	}
-	simulation *Simulation
-	clients    = make(map[*websocket.Conn]bool)
-	clientsMu  sync.RWMutex
-	broadcast  = make(chan []byte)
+		CheckOrigin: checkOrigin,
+	}
+	// allowedOrigins источники, с которых браузер может подключиться к /ws (пусто - любые)
+	allowedOrigins []string
 )
 
-func init() {
-	rand.Seed(time.Now().UnixNano())
+// checkOrigin разрешает подключение WebSocket не из браузера (без заголовка Origin),
+// со страницы самого сервера и с источников allowedOrigins
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if len(allowedOrigins) == 0 || origin == "" {
+		return true
+	}
+	if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+		return true
+	}
+	return slices.Contains(allowedOrigins, origin)
 }
 
-// NewSimulation создает новую симуляцию
-func NewSimulation() *Simulation {
-	return &Simulation{
-		Cars:              make([]*Car, 0),
-		SpawnInterval:     2.0,
-		MinSpeed:          kmhToMs(50),
-		MaxSpeed:          kmhToMs(80),
-		TimeScale:         1.0,
-		MaxCars:           100,
-		Running:           false,
-		ReactionTime:      0.2,  // секунды
-		SafetyMultiplier:  3.0,  // коэффициент
-		BrakeDeceleration: 6.67, // м/с²
-		Acceleration:      2.0,  // м/с²
+// parseOrigins разбирает список источников через запятую, например
+// "https://example.com, https://demo.example.com"
+func parseOrigins(list string) []string {
+	var origins []string
+	for _, origin := range strings.Split(list, ",") {
+		if origin = strings.TrimSuffix(strings.TrimSpace(origin), "/"); origin != "" {
+			origins = append(origins, origin)
+		}
 	}
+	return origins
 }
 
-// kmhToMs конвертирует км/ч в м/с
-func kmhToMs(kmh float64) float64 {
-	return kmh / 3.6
+// envOr возвращает значение переменной окружения key или fallback, если она не задана
+func envOr(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
 }
 
-// msToKmh конвертирует м/с в км/ч
-func msToKmh(ms float64) float64 {
-	return ms * 3.6
+// startGRPC запускает gRPC API; сервер собирается только с тегом grpc (см. grpc.go)
+var startGRPC = func(ctx context.Context) {}
+
+// startTelemetry запускает публикацию телеметрии машин в MQTT; она собирается
+// только с тегом mqtt (см. mqtt.go)
+var startTelemetry = func(ctx context.Context) error { return nil }
+
+// registerGraphQL подключает GraphQL API (/graphql); оно собирается только
+// с тегом graphql (см. graphql.go)
+var registerGraphQL = func(mux *http.ServeMux) {}
+
+// stateParts группирует поля состояния для фильтрации по подписке
+var stateParts = map[string][]string{
+	"cars":      {"cars", "removed", "oncoming", "network"},
+	"stats":     {"time", "carsCompleted", "travelTime", "totalCarsMade", "running", "paused", "incidentDelay", "overLimitTime", "spawnInterval", "demand", "laneChanges", "brakeEvents", "collisions", "recording", "trajectorySamples", "wavesDetected", "fuelUsed", "co2Emitted", "platoons", "historyStart", "overtakes", "overtakesAborted", "headOnCollisions", "busesDispatched", "busStopsServed"},
+	"blockages": {"blockages", "schedule", "workZone"},
+	"segments":  {"segments", "bins"},
+	"zones":     {"speedZones", "vsl", "cameras"},
+	"grades":    {"grades"},
+	"lights":    {"trafficLights", "crosswalks"},
+	"buses":     {"buses"},
+	"ramp":      {"onRamp"},
+	"detectors": {"detectors", "detectorInterval"},
+	"waves":     {"waves"},
+	"jams":      {"waves", "wavesDetected", "blockages", "incidentDelay"},
+	"config":    {"roadLength", "timeScale", "turbo", "dt", "maxCars", "reactionTime", "safetyMultiplier", "brakeDeceleration", "acceleration", "hysteresisBand", "spawnSpeedMode", "spawnDistribution", "speedLimit", "demandProfile", "lanes", "model", "idm", "nasch", "ovm", "laneChange", "mobil", "seed", "roadType", "oncomingDemand", "weather", "truckPercentage", "busPercentage", "aggressiveDrivers", "cautiousDrivers", "reactionSpread", "avShare", "crashClearance", "waveThreshold", "historyWindow"},
 }
 
-// randomSpeed возвращает случайную скорость в диапазоне
-func (s *Simulation) randomSpeed() float64 {
-	return s.MinSpeed + rand.Float64()*(s.MaxSpeed-s.MinSpeed)
+// Client оборачивает WebSocket-соединение, его очередь отправки и подписку
+type Client struct {
+	conn *websocket.Conn
+	send chan []byte
+	// room - симуляция, к которой подключен клиент (/ws?sim=id)
+	room *Room
+	// format - формат кадров (FormatJSON или FormatMsgpack), выбирается при подключении
+	format string
+	// delta - клиент получает ключевые кадры и дельты вместо полного состояния
+	delta bool
+	// dropped - число подряд отброшенных кадров, меняется только рассылкой
+	dropped int
+	mu      sync.RWMutex
+	// parts - части состояния, на которые подписан клиент (nil = всё состояние)
+	parts []string
+	// replay - воспроизводимая клиенту запись (nil = живое состояние)
+	replay *Replay
+	// needKeyframe - в дельта-режиме следующим отправляется ключевой кадр
+	needKeyframe bool
+	// interval - минимальный промежуток между кадрами состояния (0 = каждый шаг рассылки)
+	interval time.Duration
+	// lastFrame - время последнего кадра состояния, меняется только рассылкой
+	lastFrame time.Time
+	// lastSent - время последнего поставленного в очередь кадра или heartbeat,
+	// generation и sentKey - версия живого состояния и подписка этого кадра;
+	// меняются только рассылкой
+	lastSent   time.Time
+	generation uint64
+	sentKey    string
+	// closeReason - причина отключения сервером, передаётся в кадре закрытия
+	closeReason string
+	// role - уровень доступа по токену подключения
+	role role
+	// log - журнал с идентификатором соединения (запроса подключения) и симуляции
+	log *slog.Logger
+	// commands - ограничитель частоты команд управления, меняется только
+	// горутиной чтения команд
+	commands tokenBucket
+	// playerCar - машина, которой управляет клиент (-1 - нет); меняется только
+	// горутиной чтения команд
+	playerCar int
+	// done закрывается, когда горутина записи завершилась
+	done chan struct{}
 }
 
-// randomColor возвращает случайный цвет для автомобиля
-func randomColor() string {
-	colors := []string{"#FF6B6B", "#4ECDC4", "#45B7D1", "#FFA07A", "#98D8C8", "#F7DC6F", "#BB8FCE", "#85C1E2"}
-	return colors[rand.Intn(len(colors))]
+// validateParts проверяет имена частей состояния; неизвестная часть - ошибка поля
+// parts[i], чтобы опечатка не превращала подписку в полное состояние
+func validateParts(parts []string) error {
+	errs := &traffic.ValidationError{}
+	for i, part := range parts {
+		if _, ok := stateParts[part]; !ok {
+			addFieldError(errs, fmt.Sprintf("parts[%d]", i), fmt.Sprintf("must be one of %v", slices.Sorted(maps.Keys(stateParts))))
+		}
+	}
+	if len(errs.Fields) > 0 {
+		return errs
+	}
+	return nil
 }
 
-// SpawnCar создает новый автомобиль
-func (s *Simulation) SpawnCar() {
-	speed := s.randomSpeed()
-	car := &Car{
-		ID:            s.nextCarID,
-		Position:      0,
-		Speed:         speed,
-		TargetSpeed:   speed,
-		Color:         randomColor(),
-		State:         "normal",
-		ReactionDelay: 0,
-	}
-	s.Cars = append(s.Cars, car)
-	s.nextCarID++
-	s.TotalCarsMade++
-}
+// Subscribe задаёт части состояния, которые получает клиент; пустой список возвращает
+// полное состояние. Подписка с неизвестной частью не применяется.
+func (c *Client) Subscribe(parts []string) error {
+	if err := validateParts(parts); err != nil {
+		return err
+	}
+	sorted := slices.Sorted(slices.Values(parts))
 
-// getSafeDistance вычисляет безопасную дистанцию
-func getSafeDistance(speedDiff float64, safetyMultiplier float64) float64 {
-	// Преобразуем в км/ч для расчета (как в оригинале: 1 фут на милю/час разницы)
-	speedDiffKmh := msToKmh(math.Abs(speedDiff))
-	// 1 миля/час ≈ 1.6 км/ч, 1 фут ≈ 0.3 м
-	safeDistance := (speedDiffKmh / 1.6) * 0.3 * safetyMultiplier
-	return math.Max(safeDistance, CarLength*2)
+	c.mu.Lock()
+	if len(sorted) == 0 {
+		c.parts = nil
+	} else {
+		c.parts = sorted
+	}
+	// Дельты не содержат неизменившихся полей новых частей
+	c.needKeyframe = true
+	c.mu.Unlock()
+	return nil
 }
 
-// Update обновляет состояние симуляции
-func (s *Simulation) Update(dt float64) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// SetReplay переключает клиента на воспроизведение записи; nil возвращает живое состояние
+func (c *Client) SetReplay(replay *Replay) {
+	c.mu.Lock()
+	c.replay = replay
+	c.mu.Unlock()
+}
 
-	if !s.Running {
-		return
+// SetRate задаёт частоту кадров состояния клиента в герцах; ноль, отрицательное значение
+// или частота не ниже частоты рассылки возвращают полную частоту
+func (c *Client) SetRate(rate float64) {
+	var interval time.Duration
+	if rate > 0 {
+		interval = time.Duration(float64(time.Second) / rate)
 	}
-
-	// Применяем множитель скорости времени
-	dt = dt * s.TimeScale
-	s.Time += dt
-
-	// Создаем новые автомобили
-	if s.Time-s.lastSpawn >= s.SpawnInterval && s.TotalCarsMade < s.MaxCars {
-		// Проверяем, что начало дороги свободно
-		canSpawn := true
-		for _, car := range s.Cars {
-			if car.Position < 50 { // минимум 50м от начала
-				canSpawn = false
-				break
-			}
-		}
-		if canSpawn {
-			s.SpawnCar()
-			s.lastSpawn = s.Time
-		}
+	if interval <= time.Millisecond*UpdateInterval {
+		interval = 0
 	}
+	c.mu.Lock()
+	c.interval = interval
+	c.mu.Unlock()
+}
 
-	// Обновляем каждый автомобиль
-	for i, car := range s.Cars {
-		// Находим автомобиль впереди
-		var carAhead *Car
-		minDistance := math.MaxFloat64
-
-		for j, other := range s.Cars {
-			if i != j && other.Position > car.Position {
-				distance := other.Position - car.Position
-				if distance < minDistance {
-					minDistance = distance
-					carAhead = other
-				}
-			}
-		}
+// due сообщает, пора ли отправить клиенту кадр состояния в момент now, и запоминает отправку.
+// Допуск в половину периода рассылки не даёт дрожанию цикла пропускать кадры.
+func (c *Client) due(now time.Time) bool {
+	c.mu.RLock()
+	interval := c.interval
+	c.mu.RUnlock()
+	if interval > 0 && now.Sub(c.lastFrame) < interval-time.Millisecond*UpdateInterval/2 {
+		return false
+	}
+	c.lastFrame = now
+	return true
+}
 
-		// Логика торможения и ускорения
-		if carAhead != nil {
-			distance := carAhead.Position - car.Position - CarLength
-			speedDiff := car.Speed - carAhead.Speed
-			safeDistance := getSafeDistance(speedDiff, s.SafetyMultiplier)
-
-			if distance < safeDistance {
-				// Нужно тормозить
-				if car.State != "braking" || s.Time-car.lastBrakeTime > s.ReactionTime {
-					car.State = "braking"
-					car.Speed = math.Max(0, car.Speed-s.BrakeDeceleration*dt)
-					if car.lastBrakeTime == 0 || s.Time-car.lastBrakeTime > 1.0 {
-						car.BrakeCount++
-						car.lastBrakeTime = s.Time
-					}
-				}
-			} else if car.Speed < car.TargetSpeed {
-				// Можно ускоряться
-				car.State = "accelerating"
-				car.Speed = math.Min(car.TargetSpeed, car.Speed+s.Acceleration*dt)
-			} else {
-				car.State = "normal"
-			}
-		} else {
-			// Нет машины впереди - движемся к целевой скорости
-			if car.Speed < car.TargetSpeed {
-				car.State = "accelerating"
-				car.Speed = math.Min(car.TargetSpeed, car.Speed+s.Acceleration*dt)
-			} else {
-				car.State = "normal"
-			}
-		}
+// setCloseReason задаёт причину отключения клиента сервером
+func (c *Client) setCloseReason(reason string) {
+	c.mu.Lock()
+	c.closeReason = reason
+	c.mu.Unlock()
+}
 
-		// Обновляем позицию
-		car.Position += car.Speed * dt
+// releaseCar возвращает машину, которой управляет клиент, под управление модели
+func (c *Client) releaseCar() {
+	if c.playerCar >= 0 {
+		c.room.simulation.ReleaseCar(c.playerCar)
+		c.playerCar = -1
 	}
+}
 
-	// Удаляем автомобили, которые прошли дорогу
-	newCars := make([]*Car, 0)
-	for _, car := range s.Cars {
-		if car.Position < RoadLength {
-			newCars = append(newCars, car)
-		} else {
-			s.CarsCompleted++
-		}
-	}
-	s.Cars = newCars
+// disconnectReason возвращает причину отключения (пусто - клиент отключился сам)
+func (c *Client) disconnectReason() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.closeReason
+}
 
-	// Автоматически останавливаем симуляцию, если достигнут лимит машин и все прошли дорогу
-	if s.TotalCarsMade >= s.MaxCars && len(s.Cars) == 0 {
-		s.Running = false
-	}
+// requestKeyframe просит отправить клиенту в дельта-режиме ключевой кадр
+func (c *Client) requestKeyframe() {
+	c.mu.Lock()
+	c.needKeyframe = true
+	c.mu.Unlock()
 }
 
-// GetState возвращает текущее состояние симуляции
-func (s *Simulation) GetState() interface{} {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
-
-	return struct {
-		Cars              []*Car  `json:"cars"`
-		Time              float64 `json:"time"`
-		CarsCompleted     int     `json:"carsCompleted"`
-		TotalCarsMade     int     `json:"totalCarsMade"`
-		Running           bool    `json:"running"`
-		RoadLength        float64 `json:"roadLength"`
-		TimeScale         float64 `json:"timeScale"`
-		MaxCars           int     `json:"maxCars"`
-		ReactionTime      float64 `json:"reactionTime"`
-		SafetyMultiplier  float64 `json:"safetyMultiplier"`
-		BrakeDeceleration float64 `json:"brakeDeceleration"`
-		Acceleration      float64 `json:"acceleration"`
-	}{
-		Cars:              s.Cars,
-		Time:              s.Time,
-		CarsCompleted:     s.CarsCompleted,
-		TotalCarsMade:     s.TotalCarsMade,
-		Running:           s.Running,
-		RoadLength:        RoadLength,
-		TimeScale:         s.TimeScale,
-		MaxCars:           s.MaxCars,
-		ReactionTime:      s.ReactionTime,
-		SafetyMultiplier:  s.SafetyMultiplier,
-		BrakeDeceleration: s.BrakeDeceleration,
-		Acceleration:      s.Acceleration,
+// frameKind возвращает вид следующего кадра живого состояния для клиента:
+// "full", "keyframe" или "delta"
+func (c *Client) frameKind() string {
+	if !c.delta {
+		return "full"
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.needKeyframe {
+		c.needKeyframe = false
+		return "keyframe"
+	}
+	return "delta"
 }
 
-// Start запускает симуляцию
-func (s *Simulation) Start() {
-	s.mu.Lock()
-	s.Running = true
-	s.mu.Unlock()
+// upToDate сообщает, получил ли клиент версию generation живого состояния с подпиской key
+// и не ждёт ли ключевого кадра, то есть нужен ли ему повторный кадр
+func (c *Client) upToDate(generation uint64, key string) bool {
+	if c.generation != generation || c.sentKey != key {
+		return false
+	}
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.delta || !c.needKeyframe
 }
 
-// Stop останавливает симуляцию
-func (s *Simulation) Stop() {
-	s.mu.Lock()
-	s.Running = false
-	s.mu.Unlock()
+// activeReplay возвращает воспроизводимую клиенту запись
+func (c *Client) activeReplay() *Replay {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.replay
 }
 
-// Reset сбрасывает симуляцию
-func (s *Simulation) Reset() {
-	s.mu.Lock()
-	s.Cars = make([]*Car, 0)
-	s.Time = 0
-	s.CarsCompleted = 0
-	s.TotalCarsMade = 0
-	s.Running = false
-	s.lastSpawn = 0
-	s.nextCarID = 0
-	s.mu.Unlock()
+// subscriptionKey возвращает ключ подписки для кеширования отфильтрованных кадров
+func (c *Client) subscriptionKey() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return strings.Join(c.parts, ",")
 }
 
-// UpdateConfig обновляет конфигурацию
-func (s *Simulation) UpdateConfig(config SimulationConfig) {
-	s.mu.Lock()
-	s.SpawnInterval = config.SpawnInterval
-	s.MinSpeed = kmhToMs(config.MinSpeed)
-	s.MaxSpeed = kmhToMs(config.MaxSpeed)
-	if config.MaxCars > 0 {
-		s.MaxCars = config.MaxCars
+// subscriptionParts разбирает ключ подписки обратно в список частей (nil = всё состояние)
+func subscriptionParts(key string) []string {
+	if key == "" {
+		return nil
 	}
-	s.mu.Unlock()
+	return strings.Split(key, ",")
 }
 
-// UpdatePhysics обновляет параметры физики
-func (s *Simulation) UpdatePhysics(config PhysicsConfig) {
-	s.mu.Lock()
-	if config.ReactionTime > 0 {
-		s.ReactionTime = config.ReactionTime
+// filterState оставляет в сериализованном состоянии только поля выбранных частей
+func filterState(data []byte, parts []string) ([]byte, error) {
+	if len(parts) == 0 {
+		return data, nil
 	}
-	if config.SafetyMultiplier > 0 {
-		s.SafetyMultiplier = config.SafetyMultiplier
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
 	}
-	if config.BrakeDeceleration > 0 {
-		s.BrakeDeceleration = config.BrakeDeceleration
+	filtered := make(map[string]json.RawMessage)
+	for _, key := range frameKeys {
+		if value, ok := fields[key]; ok {
+			filtered[key] = value
+		}
 	}
-	if config.Acceleration > 0 {
-		s.Acceleration = config.Acceleration
+	for _, part := range parts {
+		for _, key := range stateParts[part] {
+			if value, ok := fields[key]; ok {
+				filtered[key] = value
+			}
+		}
 	}
-	s.mu.Unlock()
+	return json.Marshal(filtered)
 }
 
-// SetTimeScale устанавливает скорость времени
-func (s *Simulation) SetTimeScale(scale float64) {
-	s.mu.Lock()
-	// Ограничиваем значения от 0.2x до 20x
-	if scale < 0.2 {
-		scale = 0.2
+// newClient создаёт клиента симуляции room с форматом и дельта-режимом из параметров
+// запроса format и delta. Без дельта-режима начальное состояние ставится в очередь
+// до регистрации в хабе, поэтому приходит первым.
+func newClient(room *Room, r *http.Request) *Client {
+	client := &Client{send: make(chan []byte, SendBufferSize), room: room, format: FormatJSON, role: auth.roleFor(r), playerCar: -1, done: make(chan struct{})}
+	client.log = requestLogger(r).With("sim", room.ID)
+	if r.URL.Query().Get("format") == FormatMsgpack {
+		client.format = FormatMsgpack
 	}
-	if scale > 20.0 {
-		scale = 20.0
+	if r.URL.Query().Get("delta") == "1" {
+		// Клиент в дельта-режиме начинает с ключевого кадра очередной рассылки
+		client.delta = true
+		client.needKeyframe = true
+	} else {
+		if data, err := room.stateJSON(); err == nil {
+			if frame, err := client.encode(data, ""); err == nil {
+				client.send <- frame
+			}
+		}
 	}
-	s.TimeScale = scale
-	s.mu.Unlock()
+	return client
 }
 
 // Handlers
 func handleWebSocket(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Println("WebSocket upgrade error:", err)
+		requestLogger(r).Warn("WebSocket upgrade error", "err", err)
 		return
 	}
 	defer conn.Close()
 
-	clientsMu.Lock()
-	clients[conn] = true
-	clientsMu.Unlock()
-
-	defer func() {
-		clientsMu.Lock()
-		delete(clients, conn)
-		clientsMu.Unlock()
-	}()
-
-	// Отправляем начальное состояние
-	state := simulation.GetState()
-	data, _ := json.Marshal(state)
-	conn.WriteMessage(websocket.TextMessage, data)
-
-	// Слушаем команды от клиента
+	client := newClient(room, r)
+	client.conn = conn
+	room.hub.register(client)
+	defer room.hub.unregister(client)
+	client.log.Info("WebSocket client connected", "remote", r.RemoteAddr, "format", client.format, "delta", client.delta)
+	defer func() { client.log.Info("WebSocket client disconnected", "reason", client.disconnectReason()) }()
+	// Машина отключившегося игрока возвращается под управление модели
+	defer client.releaseCar()
+
+	// Слушаем команды от клиента; соединение, от которого за PongWait не пришло
+	// ни сообщения, ни ответа на ping, считается оборванным
+	conn.SetReadDeadline(time.Now().Add(PongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(PongWait))
+	})
 	for {
 		_, message, err := conn.ReadMessage()
 		if err != nil {
 			break
 		}
+		conn.SetReadDeadline(time.Now().Add(PongWait))
 
 		var cmd map[string]interface{}
 		if err := json.Unmarshal(message, &cmd); err != nil {
+			room.hub.reply(client, nil, fmt.Errorf("invalid command: %w", err))
 			continue
 		}
 
+		err = handleCommand(client, cmd)
+		client.log.Debug("Command", "action", cmd["action"], "id", cmd["id"], "err", err)
+		if id, ok := cmd["id"]; ok {
+			room.hub.reply(client, id, err)
+		} else if err != nil && !errors.Is(err, errRateLimited) {
+			client.log.Warn("WebSocket command error", "action", cmd["action"], "err", err)
+		}
+	}
+}
+
+// handleCommand выполняет команду клиента; ошибка описывает некорректные данные
+// и возвращается клиенту в ответе на команду с полем id
+func handleCommand(client *Client, cmd map[string]interface{}) error {
+	if err := client.authorizeCommand(cmd["action"]); err != nil {
+		return err
+	}
+	if name, _ := cmd["action"].(string); !unlimitedCommands[name] && !client.commands.allow(time.Now(), CommandRate, CommandBurst) {
+		return errRateLimited
+	}
+	if err := validateCommand(cmd); err != nil {
+		return err
+	}
+	simulation, recorder := client.room.simulation, client.room.recorder
+	switch cmd["action"] {
+	case "start":
+		simulation.Start()
+	case "stop":
+		simulation.Stop()
+	case "pause":
+		simulation.Pause()
+	case "step":
+		// Один шаг живого цикла симуляции
+		simulation.Advance(1, float64(UpdateInterval)/1000.0)
+	case "seek":
+		var seek seekRequest
+		if err := decodeCommandFields(cmd, &seek); err != nil {
+			return err
+		}
+		if _, err := simulation.Seek(seek.Time, seek.Resume); err != nil {
+			return fmt.Errorf("time: %w", err)
+		}
+	case "stepN":
+		var steps struct {
+			N int `json:"n"`
+		}
+		if err := decodeCommandFields(cmd, &steps); err != nil {
+			return err
+		}
+		if steps.N <= 0 || steps.N > maxStepCount {
+			return fmt.Errorf("n: must be between 1 and %d", maxStepCount)
+		}
+		simulation.Advance(steps.N, float64(UpdateInterval)/1000.0)
+	case "reset":
+		simulation.Reset()
+	case "config":
+		var config traffic.SimulationConfig
+		if err := decodeCommandData(cmd, &config); err != nil {
+			return err
+		}
+		if err := config.Validate(); err != nil {
+			return err
+		}
+		simulation.UpdateConfig(config)
+	case "physics":
+		var physics traffic.PhysicsConfig
+		if err := decodeCommandData(cmd, &physics); err != nil {
+			return err
+		}
+		if err := physics.Validate(); err != nil {
+			return err
+		}
+		simulation.UpdatePhysics(physics)
+	case "blockage":
+		var blockage traffic.BlockageConfig
+		if err := decodeCommandData(cmd, &blockage); err != nil {
+			return err
+		}
+		if err := blockage.Validate(); err != nil {
+			return err
+		}
+		simulation.AddBlockage(blockage)
+	case "incident":
+		// Параметры инцидента передаются полями самой команды
+		var incident traffic.IncidentConfig
+		if err := decodeCommandFields(cmd, &incident); err != nil {
+			return err
+		}
+		if err := incident.Validate(); err != nil {
+			return err
+		}
+		simulation.AddIncident(incident)
+	case "car:add":
+		// Параметры машины передаются полями самой команды
+		var car traffic.CarConfig
+		if err := decodeCommandFields(cmd, &car); err != nil {
+			return err
+		}
+		if err := car.Validate(); err != nil {
+			return err
+		}
+		simulation.InjectCar(car)
+	case "car:remove":
+		var target struct {
+			Car int `json:"car"`
+		}
+		if err := decodeCommandFields(cmd, &target); err != nil {
+			return err
+		}
+		if !simulation.RemoveCar(target.Car) {
+			return fmt.Errorf("car %d not found", target.Car)
+		}
+	case "car":
+		// Идентификатор машины в поле id служит и идентификатором ответа на команду
+		var control traffic.CarControl
+		if err := decodeCommandFields(cmd, &control); err != nil {
+			return err
+		}
+		if err := control.Validate(); err != nil {
+			return err
+		}
+		if !simulation.ControlCar(control) {
+			return fmt.Errorf("car %d not found", control.ID)
+		}
+	case "emergency":
+		// Параметры спецмашины передаются полями самой команды
+		var emergency traffic.EmergencyConfig
+		if err := decodeCommandFields(cmd, &emergency); err != nil {
+			return err
+		}
+		if err := emergency.Validate(); err != nil {
+			return err
+		}
+		if _, err := simulation.DispatchEmergency(emergency); err != nil {
+			return err
+		}
+	case "player:claim":
+		var target struct {
+			Car int `json:"car"`
+		}
+		if err := decodeCommandFields(cmd, &target); err != nil {
+			return err
+		}
+		client.releaseCar()
+		if err := simulation.ClaimCar(target.Car); err != nil {
+			return fmt.Errorf("car %d: %w", target.Car, err)
+		}
+		client.playerCar = target.Car
+	case "player:drive":
+		var pedals traffic.Pedals
+		if err := decodeCommandFields(cmd, &pedals); err != nil {
+			return err
+		}
+		if err := pedals.Validate(); err != nil {
+			return err
+		}
+		if client.playerCar < 0 {
+			return errors.New("no car claimed")
+		}
+		if err := simulation.DriveCar(client.playerCar, pedals); err != nil {
+			return fmt.Errorf("car %d: %w", client.playerCar, err)
+		}
+	case "player:release":
+		client.releaseCar()
+	case "segments":
+		var segments []traffic.Segment
+		if err := decodeCommandData(cmd, &segments); err != nil {
+			return err
+		}
+		if err := traffic.ValidateSegments(segments); err != nil {
+			return err
+		}
+		simulation.SetSegments(segments)
+	case "zones":
+		var zones []traffic.SpeedZone
+		if err := decodeCommandData(cmd, &zones); err != nil {
+			return err
+		}
+		if err := traffic.ValidateSpeedZones(zones); err != nil {
+			return err
+		}
+		simulation.SetSpeedZones(zones)
+	case "cameras":
+		var cameras []traffic.SpeedCamera
+		if err := decodeCommandData(cmd, &cameras); err != nil {
+			return err
+		}
+		if err := traffic.ValidateCameras(cameras); err != nil {
+			return err
+		}
+		simulation.SetCameras(cameras)
+	case "grades":
+		var grades []traffic.GradeSegment
+		if err := decodeCommandData(cmd, &grades); err != nil {
+			return err
+		}
+		if err := traffic.ValidateGrades(grades); err != nil {
+			return err
+		}
+		simulation.SetGrades(grades)
+	case "vsl":
+		var vsl traffic.VSLConfig
+		if err := decodeCommandData(cmd, &vsl); err != nil {
+			return err
+		}
+		if err := vsl.Validate(); err != nil {
+			return err
+		}
+		simulation.SetVSL(vsl)
+	case "buses":
+		var buses traffic.BusConfig
+		if err := decodeCommandData(cmd, &buses); err != nil {
+			return err
+		}
+		if err := buses.Validate(); err != nil {
+			return err
+		}
+		simulation.SetBuses(buses)
+	case "workzone":
+		var zone traffic.WorkZone
+		if err := decodeCommandData(cmd, &zone); err != nil {
+			return err
+		}
+		if err := zone.Validate(); err != nil {
+			return err
+		}
+		simulation.SetWorkZone(zone)
+	case "lights":
+		var lights []traffic.TrafficLight
+		if err := decodeCommandData(cmd, &lights); err != nil {
+			return err
+		}
+		if err := traffic.ValidateTrafficLights(lights); err != nil {
+			return err
+		}
+		simulation.SetTrafficLights(lights)
+	case "crosswalks":
+		var crosswalks []traffic.Crosswalk
+		if err := decodeCommandData(cmd, &crosswalks); err != nil {
+			return err
+		}
+		if err := traffic.ValidateCrosswalks(crosswalks); err != nil {
+			return err
+		}
+		simulation.SetCrosswalks(crosswalks)
+	case "detectors":
+		var detectors traffic.DetectorConfig
+		if err := decodeCommandData(cmd, &detectors); err != nil {
+			return err
+		}
+		if err := detectors.Validate(); err != nil {
+			return err
+		}
+		simulation.SetDetectors(detectors)
+	case "subscribe":
+		// Команда только с частотой сохраняет выбранные части
+		if rate, ok := cmd["rate"].(float64); ok {
+			client.SetRate(rate)
+			if _, ok := cmd["parts"]; !ok {
+				break
+			}
+		}
+		var parts []string
+		partsData, _ := json.Marshal(cmd["parts"])
+		if err := json.Unmarshal(partsData, &parts); err != nil {
+			return fmt.Errorf("invalid parts: %w", err)
+		}
+		if err := client.Subscribe(parts); err != nil {
+			return err
+		}
+	case "keyframe":
+		client.requestKeyframe()
+	case "record:start":
+		name, _ := cmd["name"].(string)
+		if name == "" {
+			name = time.Now().Format("20060102-150405")
+		}
+		return recorder.Start(name)
+	case "record:stop":
+		return recorder.Stop()
+	case "replay:load":
+		name, _ := cmd["name"].(string)
+		replay, err := loadReplay(name)
+		if err != nil {
+			return err
+		}
+		client.SetReplay(replay)
+	case "replay:play", "replay:pause", "replay:seek":
+		replay := client.activeReplay()
+		if replay == nil {
+			return errors.New("no replay loaded")
+		}
 		switch cmd["action"] {
-		case "start":
-			simulation.Start()
-		case "stop":
-			simulation.Stop()
-		case "reset":
-			simulation.Reset()
-		case "config":
-			var config SimulationConfig
-			configData, _ := json.Marshal(cmd["data"])
-			json.Unmarshal(configData, &config)
-			simulation.UpdateConfig(config)
-		case "physics":
-			var physics PhysicsConfig
-			physicsData, _ := json.Marshal(cmd["data"])
-			json.Unmarshal(physicsData, &physics)
-			simulation.UpdatePhysics(physics)
-		case "timescale":
-			if scale, ok := cmd["value"].(float64); ok {
-				simulation.SetTimeScale(scale)
+		case "replay:play":
+			speed, _ := cmd["value"].(float64)
+			replay.Play(speed)
+		case "replay:pause":
+			replay.Pause()
+		case "replay:seek":
+			t, ok := cmd["value"].(float64)
+			if !ok {
+				return errors.New("value: must be a number")
 			}
+			replay.Seek(t)
 		}
+	case "replay:stop":
+		client.SetReplay(nil)
+	case "timescale":
+		scale, ok := cmd["value"].(float64)
+		if !ok {
+			return errors.New("value: must be a number")
+		}
+		simulation.SetTimeScale(scale)
+	case "turbo":
+		turbo, ok := cmd["value"].(bool)
+		if !ok {
+			return errors.New("value: must be a boolean")
+		}
+		simulation.SetTurbo(turbo)
+	case "weather":
+		weather, _ := cmd["value"].(string)
+		if _, ok := traffic.WeatherEffects[weather]; !ok {
+			return fmt.Errorf("value: must be one of %q", []string{traffic.WeatherDry, traffic.WeatherRain, traffic.WeatherSnow, traffic.WeatherFog})
+		}
+		simulation.SetWeather(weather)
+	default:
+		return fmt.Errorf("unknown action %v", cmd["action"])
+	}
+	return nil
+}
+
+// decodeCommandData разбирает поле data команды в v
+func decodeCommandData(cmd map[string]interface{}, v any) error {
+	data, _ := json.Marshal(cmd["data"])
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid data: %w", err)
 	}
+	return nil
 }
 
+// decodeCommandFields разбирает поля самой команды в v
+func decodeCommandFields(cmd map[string]interface{}, v any) error {
+	data, _ := json.Marshal(cmd)
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("invalid command: %w", err)
+	}
+	return nil
+}
+
+// handleIndex отдаёт веб-интерфейс: встроенный в бинарный файл или, с флагом -dev,
+// файл index.html из рабочего каталога для правки без пересборки
 func handleIndex(w http.ResponseWriter, r *http.Request) {
-	http.ServeFile(w, r, "index.html")
+	if devAssets {
+		http.ServeFile(w, r, "index.html")
+		return
+	}
+	http.ServeFileFS(w, r, assets, "index.html")
 }
 
-// broadcastState отправляет состояние всем подключенным клиентам
-func broadcastState() {
+// broadcastState периодически рассылает состояние симуляции всем её клиентам
+func (room *Room) broadcastState(ctx context.Context) {
+	ticker := time.NewTicker(time.Millisecond * UpdateInterval)
+	defer ticker.Stop()
+
+	// Ожидание в начале цикла: ошибка сериализации не должна превращать его в холостой
+	// цикл без проверки ctx
 	for {
-		state := simulation.GetState()
-		data, err := json.Marshal(state)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		data, err := room.simulation.StateJSON()
 		if err != nil {
-			log.Println("JSON marshal error:", err)
+			slog.Error("JSON marshal error", "sim", room.ID, "err", err)
 			continue
 		}
 
-		clientsMu.RLock()
-		for client := range clients {
-			err := client.WriteMessage(websocket.TextMessage, data)
+		if err := room.recorder.Write(data); err != nil {
+			slog.Error("Replay record error", "sim", room.ID, "err", err)
+		}
+		room.hub.broadcast(data)
+		publishFrame(room.ID, data)
+
+		events := room.simulation.DrainEvents()
+		for _, event := range events {
+			message, err := json.Marshal(map[string]interface{}{"event": event.Type, "data": event})
 			if err != nil {
-				log.Println("WebSocket write error:", err)
-				client.Close()
-				clientsMu.Lock()
-				delete(clients, client)
-				clientsMu.Unlock()
+				slog.Error("JSON marshal error", "sim", room.ID, "err", err)
+				continue
+			}
+			room.hub.broadcastEvent(message)
+			publishEvent(room.ID, message)
+			webhooks.notify(room.ID, event)
+			if event.Type == "runComplete" && event.Summary != nil {
+				room.archiveRun(*event.Summary)
 			}
 		}
-		clientsMu.RUnlock()
-
-		time.Sleep(time.Millisecond * UpdateInterval)
+		room.exportEvents(events)
 	}
 }
 
 // simulationLoop главный цикл симуляции
-func simulationLoop() {
+func (room *Room) simulationLoop(ctx context.Context) {
 	ticker := time.NewTicker(time.Millisecond * UpdateInterval)
 	defer ticker.Stop()
 
-	for range ticker.C {
-		simulation.Update(float64(UpdateInterval) / 1000.0)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		start := time.Now()
+		if room.simulation.StepTurbo() {
+			room.timer.observe(time.Since(start))
+			room.runTurbo(start.Add(time.Millisecond * UpdateInterval))
+			continue
+		}
+		room.simulation.Step(float64(UpdateInterval) / 1000.0)
+		room.timer.observe(time.Since(start))
+	}
+}
+
+// runTurbo в ускоренном режиме выполняет шаги физики подряд без ожидания
+// до момента deadline; состояние тем временем рассылается по своему таймеру
+func (room *Room) runTurbo(deadline time.Time) {
+	for time.Now().Before(deadline) {
+		start := time.Now()
+		if !room.simulation.StepTurbo() {
+			return
+		}
+		room.timer.observe(time.Since(start))
+	}
+}
+
+// loadScenario загружает в симуляцию сценарий из файла path
+func loadScenario(path string, simulation *traffic.Simulation) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	scenario, err := traffic.ParseScenario(data)
+	if err == nil {
+		err = scenario.Validate()
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
 	}
+	simulation.LoadScenario(scenario)
+	slog.Info("Загружен сценарий", "path", path)
+	return nil
 }
 
 func main() {
-	simulation = NewSimulation()
+	trajectoriesPath := flag.String("trajectories", "", "файл, в который при остановке сервера сохраняются записанные траектории (CSV)")
+	batchPath := flag.String("batch", "", "файл серии прогонов (JSON): прогнать без сервера и вывести результаты в CSV")
+	snapshotPath := flag.String("snapshot", "", "файл, в который при остановке сервера сохраняется итоговый снимок состояния (JSON для /api/restore)")
+	batchOut := flag.String("batch-out", "", "CSV-файл результатов пакетного режима (по умолчанию стандартный вывод)")
+	scenarioPath := flag.String("scenario", "", "файл сценария (YAML или JSON), загружаемый в симуляцию по умолчанию при запуске")
+	configPath := flag.String("config", os.Getenv("DRIVE_CONFIG"), "файл параметров потока (YAML или JSON): интервал появления, скорости и спрос симуляции по умолчанию; изменения файла применяются на ходу")
+	archivePath := flag.String("archive", os.Getenv("DRIVE_ARCHIVE"), "хранилище завершённых прогонов: файл SQLite или адрес postgres:// (нужна сборка с -tags sqlite или postgres)")
+	flag.BoolVar(&archiveTrajectories, "archive-trajectories", false, "сохранять в архив вместе с прогоном записанные траектории")
+	hostname, _ := os.Hostname()
+	flag.StringVar(&archiveServer, "archive-server", envOr("DRIVE_ARCHIVE_SERVER", hostname), "имя сервера в хранилище прогонов, общем для нескольких серверов")
+	addr := flag.String("addr", envOr("DRIVE_ADDR", ":8080"), "адрес HTTP-сервера")
+	tlsCert := flag.String("tls-cert", os.Getenv("DRIVE_TLS_CERT"), "файл сертификата TLS (PEM); вместе с -tls-key включает HTTPS")
+	tlsKey := flag.String("tls-key", os.Getenv("DRIVE_TLS_KEY"), "файл закрытого ключа TLS (PEM)")
+	origins := flag.String("origins", os.Getenv("DRIVE_ORIGINS"), "источники, с которых браузер может подключиться к WebSocket, через запятую (пусто - любые)")
+	webhookURLs := flag.String("webhook", os.Getenv("DRIVE_WEBHOOK"), "адреса веб-хуков через запятую, получающих все уведомления о событиях (добавляются и через POST /api/webhooks)")
+	flag.BoolVar(&viewerMode, "viewer", false, "сервер-зритель: ретранслировать клиентам кадры сервера симуляции из Redis (нужна сборка с -tags redis)")
+	flag.BoolVar(&devAssets, "dev", false, "отдавать index.html из рабочего каталога вместо встроенного (для правки интерфейса)")
+	flag.StringVar(&auth.controllerToken, "controller-token", os.Getenv("DRIVE_CONTROLLER_TOKEN"), "токен управления симуляцией (пусто - управлять может любой клиент)")
+	flag.StringVar(&auth.viewerToken, "viewer-token", os.Getenv("DRIVE_VIEWER_TOKEN"), "токен просмотра состояния (пусто - состояние доступно без токена)")
+	enablePprof := flag.Bool("pprof", os.Getenv("DRIVE_PPROF") == "1", "включить профилировщик net/http/pprof на /debug/pprof/")
+	logLevel := flag.String("log-level", envOr("DRIVE_LOG_LEVEL", "info"), "уровень журнала: debug, info, warn или error")
+	flag.Parse()
+	if err := setupLogging(*logLevel); err != nil {
+		fatal("-log-level: must be debug, info, warn or error", "value", *logLevel)
+	}
+	if auth.viewerToken != "" && auth.controllerToken == "" {
+		fatal("-viewer-token requires -controller-token")
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		fatal("-tls-cert and -tls-key must be set together")
+	}
+	allowedOrigins = parseOrigins(*origins)
 
-	// Запускаем цикл симуляции
-	go simulationLoop()
+	if *batchPath != "" {
+		if err := runBatch(*batchPath, *batchOut); err != nil {
+			fatal("Batch run error", "err", err)
+		}
+		return
+	}
 
-	// Запускаем broadcast
-	go broadcastState()
+	// Циклы симуляции и рассылки всех симуляций завершаются по сигналу остановки
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if err := startExport(ctx); err != nil {
+		fatal("Event export start error", "err", err)
+	}
+	rooms = newRoomRegistry(ctx)
+	webhooks.ctx = ctx
+	for _, u := range strings.Split(*webhookURLs, ",") {
+		if u = strings.TrimSpace(u); u == "" {
+			continue
+		}
+		req := webhookRequest{URL: u}
+		if err := req.Validate(); err != nil {
+			fatal("-webhook: invalid webhook", "url", u, "err", err)
+		}
+		webhooks.add(req)
+	}
+
+	// Флаги сохранения при остановке относятся к симуляции по умолчанию
+	simulation := rooms.get(DefaultRoom).simulation
+	if *scenarioPath != "" {
+		if err := loadScenario(*scenarioPath, simulation); err != nil {
+			fatal("Scenario load error", "err", err)
+		}
+	}
+	if *configPath != "" {
+		if err := startConfigReload(ctx, *configPath, simulation); err != nil {
+			fatal("Config load error", "err", err)
+		}
+	}
+	RegisterFlusher(rooms)
+	if *archivePath != "" {
+		storage, err := openStorage(*archivePath)
+		if err != nil {
+			fatal("Run archive open error", "err", err)
+		}
+		archive = storage
+		RegisterFlusher(storageFlusher{storage})
+	}
+	if *trajectoriesPath != "" {
+		RegisterFlusher(&fileFlusher{path: *trajectoriesPath, write: simulation.WriteTrajectoriesCSV})
+	}
+	if *snapshotPath != "" {
+		RegisterFlusher(&fileFlusher{path: *snapshotPath, write: snapshotWriter(simulation)})
+	}
+
+	if err := startRelay(ctx); err != nil {
+		fatal("Relay start error", "err", err)
+	}
+	if err := startTelemetry(ctx); err != nil {
+		fatal("MQTT telemetry start error", "err", err)
+	}
+	startGRPC(ctx)
+
+	mux := http.NewServeMux()
+	// Страница открыта всем: состояние она получает через /ws, где проверяется токен
+	mux.HandleFunc("/", handleIndex)
+	registerAPI(mux)
+	registerDebug(mux, *enablePprof)
+	registerGraphQL(mux)
+
+	server := &http.Server{Addr: *addr, Handler: withRequestLog(mux)}
+	go func() {
+		scheme := "http"
+		if *tlsCert != "" {
+			scheme = "https"
+		}
+		host, port, _ := net.SplitHostPort(*addr)
+		if host == "" {
+			host = "localhost"
+		}
+		slog.Info("Сервер запущен", "url", scheme+"://"+net.JoinHostPort(host, port))
+
+		var err error
+		if *tlsCert != "" {
+			err = server.ListenAndServeTLS(*tlsCert, *tlsKey)
+		} else {
+			err = server.ListenAndServe()
+		}
+		if err != nil && err != http.ErrServerClosed {
+			fatal("Server error", "err", err)
+		}
+	}()
 
-	http.HandleFunc("/", handleIndex)
-	http.HandleFunc("/ws", handleWebSocket)
+	<-ctx.Done()
+	slog.Info("Остановка сервера...")
 
-	log.Println("Сервер запущен на http://localhost:8080")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), ShutdownTimeout)
+	defer cancel()
+	shutdown(shutdownCtx, server)
 }
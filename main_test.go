@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"drive-simulation/traffic"
+)
+
+// TestFilterState проверяет, что подписчик получает поля только выбранных частей состояния
+func TestFilterState(t *testing.T) {
+	sim := traffic.New()
+	sim.Start()
+	sim.Advance(200, traffic.DefaultStep)
+	data, err := sim.StateJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		parts   []string
+		want    []string
+		notWant []string
+	}{
+		{name: "stats only", parts: []string{"stats"}, want: []string{"time", "carsCompleted"}, notWant: []string{"cars", "segments", "roadLength"}},
+		{name: "cars only", parts: []string{"cars"}, want: []string{"cars"}, notWant: []string{"time", "blockages"}},
+		{name: "jams only", parts: []string{"jams"}, want: []string{"waves", "wavesDetected", "blockages", "incidentDelay"}, notWant: []string{"cars", "carsCompleted", "roadLength"}},
+		{name: "stats and config", parts: []string{"config", "stats"}, want: []string{"time", "roadLength"}, notWant: []string{"cars"}},
+		{name: "full state", parts: nil, want: []string{"cars", "time", "roadLength"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{}
+			if err := client.Subscribe(tt.parts); err != nil {
+				t.Fatal(err)
+			}
+			filtered, err := filterState(data, client.parts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(filtered, &fields); err != nil {
+				t.Fatal(err)
+			}
+			for _, key := range tt.want {
+				if _, ok := fields[key]; !ok {
+					t.Errorf("key %q missing", key)
+				}
+			}
+			for _, key := range tt.notWant {
+				if _, ok := fields[key]; ok {
+					t.Errorf("unexpected key %q", key)
+				}
+			}
+		})
+	}
+}
+
+// TestSubscribeUnknownParts проверяет, что подписка с неизвестной частью отвергается
+// ошибкой проверки с индексом части и не меняет прежнюю подписку
+func TestSubscribeUnknownParts(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []string
+		field string
+	}{
+		{name: "typo of jams", parts: []string{"jam"}, field: "parts[0]"},
+		{name: "unknown part next to a valid one", parts: []string{"stats", "bogus"}, field: "parts[1]"},
+		{name: "empty name", parts: []string{"cars", ""}, field: "parts[1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &Client{}
+			if err := client.Subscribe([]string{"waves"}); err != nil {
+				t.Fatal(err)
+			}
+			err := client.Subscribe(tt.parts)
+			var validation *traffic.ValidationError
+			if !errors.As(err, &validation) || validation.Fields[tt.field] == "" {
+				t.Fatalf("err = %v, want a validation error for %s", err, tt.field)
+			}
+			if len(client.parts) != 1 || client.parts[0] != "waves" {
+				t.Fatalf("parts = %v after a rejected subscription, want [waves]", client.parts)
+			}
+		})
+	}
+}
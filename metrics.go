@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"drive-simulation/traffic"
+)
+
+// stepTimer накапливает длительность шагов цикла симуляции
+type stepTimer struct {
+	mu    sync.Mutex
+	count int
+	total time.Duration
+	last  time.Duration
+	// max - самый долгий шаг, overruns - шаги дольше периода цикла UpdateInterval
+	max      time.Duration
+	overruns int
+}
+
+// observe учитывает длительность очередного шага
+func (t *stepTimer) observe(d time.Duration) {
+	t.mu.Lock()
+	t.count++
+	t.total += d
+	t.last = d
+	t.max = max(t.max, d)
+	if d > time.Millisecond*UpdateInterval {
+		t.overruns++
+	}
+	t.mu.Unlock()
+}
+
+func (t *stepTimer) values() (count int, total, last time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.count, t.total, t.last
+}
+
+// extremes возвращает самый долгий шаг и число шагов, не уложившихся в период цикла
+func (t *stepTimer) extremes() (longest time.Duration, overruns int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.max, t.overruns
+}
+
+// roomMetrics показатели одной симуляции для /metrics
+type roomMetrics struct {
+	id       string
+	sim      traffic.Metrics
+	clients  int
+	steps    int
+	total    time.Duration
+	last     time.Duration
+	longest  time.Duration
+	overruns int
+}
+
+// collectMetrics снимает показатели всех симуляций
+func collectMetrics() []roomMetrics {
+	var metrics []roomMetrics
+	for _, room := range rooms.list() {
+		m := roomMetrics{id: room.ID, sim: room.simulation.Metrics(), clients: room.hub.count()}
+		m.steps, m.total, m.last = room.timer.values()
+		m.longest, m.overruns = room.timer.extremes()
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// writeMetric пишет метрику в текстовом формате Prometheus: по значению на симуляцию с меткой sim
+func writeMetric(w http.ResponseWriter, name, kind, help string, metrics []roomMetrics, value func(m *roomMetrics) float64) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+	for i := range metrics {
+		fmt.Fprintf(w, "%s{sim=%q} %g\n", name, metrics[i].id, value(&metrics[i]))
+	}
+}
+
+// handleMetrics отдаёт показатели симуляций и сервера в текстовом формате Prometheus (GET /metrics).
+// Торможения в секунду получаются запросом rate(drive_brake_events_total[1m]).
+func handleMetrics(w http.ResponseWriter, r *http.Request) {
+	metrics := collectMetrics()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	writeMetric(w, "drive_simulation_time_seconds", "gauge", "Simulated time since reset.", metrics,
+		func(m *roomMetrics) float64 { return m.sim.Time })
+	writeMetric(w, "drive_active_cars", "gauge", "Cars currently on the road.", metrics,
+		func(m *roomMetrics) float64 { return float64(m.sim.Cars) })
+	writeMetric(w, "drive_cars_completed_total", "counter", "Cars that left the road (laps on a ring road).", metrics,
+		func(m *roomMetrics) float64 { return float64(m.sim.CarsCompleted) })
+	writeMetric(w, "drive_mean_speed_meters_per_second", "gauge", "Mean speed of cars on the road.", metrics,
+		func(m *roomMetrics) float64 { return m.sim.MeanSpeed })
+	writeMetric(w, "drive_brake_events_total", "counter", "Braking events of all cars.", metrics,
+		func(m *roomMetrics) float64 { return float64(m.sim.BrakeEvents) })
+	writeMetric(w, "drive_collisions_total", "counter", "Collisions between cars.", metrics,
+		func(m *roomMetrics) float64 { return float64(m.sim.Collisions) })
+	writeMetric(w, "drive_websocket_clients", "gauge", "Connected WebSocket clients.", metrics,
+		func(m *roomMetrics) float64 { return float64(m.clients) })
+	writeMetric(w, "drive_update_duration_seconds", "gauge", "Duration of the last simulation update.", metrics,
+		func(m *roomMetrics) float64 { return m.last.Seconds() })
+	fmt.Fprintf(w, "# HELP drive_update_seconds Duration of simulation updates.\n# TYPE drive_update_seconds summary\n")
+	for _, m := range metrics {
+		fmt.Fprintf(w, "drive_update_seconds_sum{sim=%q} %g\ndrive_update_seconds_count{sim=%q} %d\n", m.id, m.total.Seconds(), m.id, m.steps)
+	}
+}
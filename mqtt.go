@@ -0,0 +1,138 @@
+//go:build mqtt
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"log/slog"
+	"strconv"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+)
+
+var (
+	mqttBroker = flag.String("mqtt", envOr("DRIVE_MQTT", ""), "адрес брокера MQTT (tcp://host:1883) для телеметрии машин (пусто - не публиковать)")
+	mqttPrefix = flag.String("mqtt-prefix", "traffic", "префикс тем MQTT: машина публикуется в <префикс>/cars/<id>, в других симуляциях - в <префикс>/<sim>/cars/<id>")
+	mqttRate   = flag.Float64("mqtt-rate", 1, "частота телеметрии каждой машины, Гц (не выше частоты рассылки)")
+	mqttQoS    = flag.Int("mqtt-qos", 0, "уровень QoS сообщений телеметрии: 0, 1 или 2")
+)
+
+// carTelemetry сообщение телеметрии одной машины
+type carTelemetry struct {
+	ID       int     `json:"id"`
+	Sim      string  `json:"sim"`
+	Time     float64 `json:"time"` // секунды симуляции
+	Type     string  `json:"type"`
+	Lane     int     `json:"lane"`
+	Position float64 `json:"position"` // метры
+	Speed    float64 `json:"speed"`    // м/с
+	State    string  `json:"state"`
+	Link     string  `json:"link,omitempty"` // звено дорожной сети
+}
+
+func init() {
+	startTelemetry = serveTelemetry
+}
+
+// serveTelemetry подключается к брокеру и с частотой -mqtt-rate публикует
+// телеметрию машин всех симуляций; публикация завершается по отмене ctx
+func serveTelemetry(ctx context.Context) error {
+	if *mqttBroker == "" {
+		return nil
+	}
+	if *mqttRate <= 0 || *mqttRate > 1000/UpdateInterval {
+		return errors.New("-mqtt-rate: must be positive and not above the broadcast rate")
+	}
+	if *mqttQoS < 0 || *mqttQoS > 2 {
+		return errors.New("-mqtt-qos: must be 0, 1 or 2")
+	}
+	options := mqtt.NewClientOptions().
+		AddBroker(*mqttBroker).
+		SetClientID("drive-sim-" + randomRoomID()).
+		SetAutoReconnect(true).
+		SetConnectionLostHandler(func(_ mqtt.Client, err error) {
+			slog.Warn("MQTT connection lost", "err", err)
+		})
+	client := mqtt.NewClient(options)
+	if token := client.Connect(); token.Wait() && token.Error() != nil {
+		return token.Error()
+	}
+	RegisterFlusher(&mqttFlusher{client})
+	go publishTelemetry(ctx, client)
+	slog.Info("Телеметрия машин публикуется в MQTT", "broker", *mqttBroker, "prefix", *mqttPrefix, "rate", *mqttRate)
+	return nil
+}
+
+// publishTelemetry раз в период -mqtt-rate публикует телеметрию машин симуляций,
+// состояние которых изменилось с прошлой публикации
+func publishTelemetry(ctx context.Context, client mqtt.Client) {
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *mqttRate))
+	defer ticker.Stop()
+	published := make(map[string]float64)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// Удалённые симуляции забываются
+		list := rooms.list()
+		current := make(map[string]float64, len(list))
+		for _, room := range list {
+			if last, ok := published[room.ID]; ok {
+				current[room.ID] = last
+			}
+		}
+		published = current
+
+		for _, room := range list {
+			data, err := room.stateJSON()
+			if err != nil {
+				continue
+			}
+			var state struct {
+				Time float64        `json:"time"`
+				Cars []carTelemetry `json:"cars"`
+			}
+			if err := json.Unmarshal(data, &state); err != nil {
+				slog.Error("JSON unmarshal error", "sim", room.ID, "err", err)
+				continue
+			}
+			// Остановленная или поставленная на паузу симуляция повторно не публикуется
+			if last, ok := published[room.ID]; ok && last == state.Time {
+				continue
+			}
+			published[room.ID] = state.Time
+
+			prefix := *mqttPrefix + "/"
+			if room.ID != DefaultRoom {
+				prefix += room.ID + "/"
+			}
+			for _, car := range state.Cars {
+				car.Sim, car.Time = room.ID, state.Time
+				payload, _ := json.Marshal(car)
+				client.Publish(prefix+"cars/"+strconv.Itoa(car.ID), byte(*mqttQoS), false, payload)
+			}
+		}
+	}
+}
+
+// mqttFlusher отключается от брокера при остановке сервера, дождавшись
+// отправки поставленных в очередь сообщений
+type mqttFlusher struct {
+	client mqtt.Client
+}
+
+func (f *mqttFlusher) Flush(ctx context.Context) error {
+	wait := int64(250)
+	if deadline, ok := ctx.Deadline(); ok {
+		wait = max(0, min(wait, time.Until(deadline).Milliseconds()))
+	}
+	f.client.Disconnect(uint(wait))
+	return nil
+}
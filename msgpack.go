@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// Форматы кадров, выбираемые клиентом при подключении (/ws?format=msgpack)
+const (
+	FormatJSON    = "json"
+	FormatMsgpack = "msgpack"
+)
+
+// carFields порядок полей автомобиля в компактном представлении MessagePack:
+// каждая машина передаётся массивом значений в этом порядке вместо объекта
+var carFields = []string{
+	"id", "type", "length", "lane", "position", "speed", "targetSpeed",
+	"brakeCount", "color", "state", "reactionDelay", "overLimitTime", "driver",
+	"fuel", "co2", "platoon", "platoonIndex", "player",
+}
+
+// jsonToMsgpack перекодирует JSON-кадр в MessagePack. Массивы машин (поле "cars")
+// заменяются массивами значений в порядке carFields с дробными числами float32.
+func jsonToMsgpack(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+	if fields, ok := value.(map[string]any); ok {
+		if cars, ok := fields["cars"].([]any); ok {
+			fields["cars"] = compactCars(cars)
+		}
+	}
+
+	var out bytes.Buffer
+	if err := writeMsgpack(&out, value, false); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// compactCars заменяет объекты машин массивами значений в порядке carFields
+func compactCars(cars []any) []any {
+	compact := make([]any, len(cars))
+	for i, car := range cars {
+		fields, ok := car.(map[string]any)
+		if !ok {
+			compact[i] = car
+			continue
+		}
+		values := make([]any, len(carFields))
+		for j, name := range carFields {
+			values[j] = fields[name]
+		}
+		compact[i] = carValues(values)
+	}
+	return compact
+}
+
+// carValues помечает массив значений машины: дробные числа в нём пишутся как float32
+type carValues []any
+
+// writeMsgpack пишет значение, полученное из JSON, в формате MessagePack.
+// Ключи объектов сортируются, чтобы одинаковое состояние давало одинаковые байты.
+func writeMsgpack(out *bytes.Buffer, value any, float32s bool) error {
+	switch v := value.(type) {
+	case nil:
+		out.WriteByte(0xc0)
+	case bool:
+		if v {
+			out.WriteByte(0xc3)
+		} else {
+			out.WriteByte(0xc2)
+		}
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			writeMsgpackInt(out, n)
+			return nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return err
+		}
+		if float32s {
+			out.WriteByte(0xca)
+			binary.Write(out, binary.BigEndian, math.Float32bits(float32(f)))
+		} else {
+			out.WriteByte(0xcb)
+			binary.Write(out, binary.BigEndian, math.Float64bits(f))
+		}
+	case string:
+		writeMsgpackHeader(out, len(v), 0xa0, 31, 0xd9, 0xda, 0xdb)
+		out.WriteString(v)
+	case carValues:
+		writeMsgpackHeader(out, len(v), 0x90, 15, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := writeMsgpack(out, item, true); err != nil {
+				return err
+			}
+		}
+	case []any:
+		writeMsgpackHeader(out, len(v), 0x90, 15, 0, 0xdc, 0xdd)
+		for _, item := range v {
+			if err := writeMsgpack(out, item, float32s); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		writeMsgpackHeader(out, len(v), 0x80, 15, 0, 0xde, 0xdf)
+		for _, key := range keys {
+			writeMsgpack(out, key, float32s)
+			if err := writeMsgpack(out, v[key], float32s); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("msgpack: unsupported type %T", value)
+	}
+	return nil
+}
+
+// writeMsgpackInt пишет целое число в самом коротком представлении
+func writeMsgpackInt(out *bytes.Buffer, n int64) {
+	switch {
+	case n >= 0 && n <= 127:
+		out.WriteByte(byte(n))
+	case n < 0 && n >= -32:
+		out.WriteByte(byte(n))
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		out.WriteByte(0xd0)
+		out.WriteByte(byte(n))
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		out.WriteByte(0xd1)
+		binary.Write(out, binary.BigEndian, int16(n))
+	case n >= math.MinInt32 && n <= math.MaxInt32:
+		out.WriteByte(0xd2)
+		binary.Write(out, binary.BigEndian, int32(n))
+	default:
+		out.WriteByte(0xd3)
+		binary.Write(out, binary.BigEndian, n)
+	}
+}
+
+// writeMsgpackHeader пишет заголовок строки, массива или словаря длины n: короткая
+// форма fix (код fix | n при n <= fixMax), иначе 8-, 16- или 32-битная длина
+// (code8 = 0, если 8-битной формы нет)
+func writeMsgpackHeader(out *bytes.Buffer, n int, fix byte, fixMax int, code8, code16, code32 byte) {
+	switch {
+	case n <= fixMax:
+		out.WriteByte(fix | byte(n))
+	case code8 != 0 && n <= math.MaxUint8:
+		out.WriteByte(code8)
+		out.WriteByte(byte(n))
+	case n <= math.MaxUint16:
+		out.WriteByte(code16)
+		binary.Write(out, binary.BigEndian, uint16(n))
+	default:
+		out.WriteByte(code32)
+		binary.Write(out, binary.BigEndian, uint32(n))
+	}
+}
@@ -0,0 +1,59 @@
+//go:build nats
+
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+var (
+	natsURL    = flag.String("nats", envOr("DRIVE_NATS", ""), "адрес NATS (nats://...) для экспорта событий (пусто - не экспортировать)")
+	natsPrefix = flag.String("nats-prefix", "drive", "префикс тем NATS: события публикуются в <префикс>.<sim>.<тип>")
+)
+
+func init() {
+	exporters = append(exporters, &natsExporter{})
+}
+
+// natsExporter экспорт событий в темы NATS вида <префикс>.<sim>.<тип>, где двоеточие
+// в типе заменено точкой (drive.default.car.spawned), чтобы на события можно было
+// подписываться шаблонами (drive.*.collision, drive.default.>)
+type natsExporter struct {
+	conn *nats.Conn
+}
+
+func (e *natsExporter) start(ctx context.Context) (bool, error) {
+	if *natsURL == "" {
+		return false, nil
+	}
+	conn, err := nats.Connect(*natsURL, nats.Name("drive-sim"))
+	if err != nil {
+		return false, err
+	}
+	e.conn = conn
+	RegisterFlusher(e)
+	slog.Info("События экспортируются в NATS", "url", *natsURL, "prefix", *natsPrefix)
+	return true, nil
+}
+
+func (e *natsExporter) publish(sim string, events []exportedEvent) {
+	for _, event := range events {
+		subject := *natsPrefix + "." + sim + "." + strings.ReplaceAll(event.Type, ":", ".")
+		// Publish только ставит сообщение в буфер соединения
+		if err := e.conn.Publish(subject, event.Data); err != nil {
+			slog.Error("NATS publish error", "subject", subject, "err", err)
+			return
+		}
+	}
+}
+
+// Flush дописывает буфер соединения и закрывает его при остановке сервера
+func (e *natsExporter) Flush(ctx context.Context) error {
+	defer e.conn.Close()
+	return e.conn.FlushWithContext(ctx)
+}
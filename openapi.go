@@ -0,0 +1,493 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"drive-simulation/traffic"
+)
+
+// maxRequestSize байты, предел JSON-тела запроса, проверяемого по схеме
+const maxRequestSize = 1 << 20
+
+// apiRoute HTTP-эндпоинт: маршрут сервера и его описание в OpenAPI
+type apiRoute struct {
+	pattern  string // шаблон http.ServeMux; без метода описывается как GET
+	role     role   // минимальный уровень доступа
+	handler  http.HandlerFunc
+	summary  string
+	room     bool         // работает с симуляцией из параметра sim
+	query    []apiParam   // прочие параметры запроса
+	request  reflect.Type // тело запроса в JSON (nil - без тела)
+	yaml     bool         // тело запроса может быть и в YAML
+	response reflect.Type // тело ответа в JSON (nil - без тела или content)
+	content  string       // тип тела ответа, если это не JSON
+	status   int          // код успешного ответа (0 - 200, а без тела ответа - 204)
+}
+
+// apiParam параметр запроса
+type apiParam struct {
+	name    string
+	kind    string // тип JSON Schema
+	summary string
+}
+
+// apiRoutes эндпоинты сервера кроме веб-интерфейса, диагностики и GraphQL; по этой
+// таблице регистрируются маршруты и строится /api/openapi.json
+func apiRoutes() []apiRoute {
+	anyJSON := reflect.TypeFor[json.RawMessage]()
+	return []apiRoute{
+		{pattern: "/ws", role: roleViewer, handler: handleWebSocket, room: true, status: http.StatusSwitchingProtocols,
+			summary: "WebSocket: кадры состояния, события и команды; схема сообщений - /api/schema/ws.json",
+			query:   []apiParam{{"format", "string", "json или msgpack"}, {"delta", "string", "1 - дельта-кадры"}}},
+		{pattern: "GET /events", role: roleViewer, handler: handleEvents, room: true, content: "text/event-stream",
+			summary: "поток состояния Server-Sent Events",
+			query:   []apiParam{{"parts", "string", "части состояния через запятую"}, {"rate", "number", "кадров в секунду"}, {"delta", "string", "1 - дельта-кадры"}}},
+		{pattern: "POST /api/simulations", role: roleController, handler: handleCreateSimulation,
+			summary: "создание независимой симуляции", request: reflect.TypeFor[simulationRequest](),
+			response: reflect.TypeFor[struct {
+				ID string `json:"id"`
+			}](), status: http.StatusCreated},
+		{pattern: "GET /api/simulations", role: roleViewer, handler: handleListSimulations,
+			summary: "список работающих симуляций", response: reflect.TypeFor[[]simulationInfo]()},
+		{pattern: "DELETE /api/simulations/{id}", role: roleController, handler: handleDeleteSimulation,
+			summary: "остановка и удаление симуляции"},
+		{pattern: "POST /api/start", role: roleController, handler: handleStart, room: true, summary: "запуск симуляции"},
+		{pattern: "POST /api/stop", role: roleController, handler: handleStop, room: true, summary: "остановка"},
+		{pattern: "POST /api/pause", role: roleController, handler: handlePause, room: true, summary: "пауза"},
+		{pattern: "POST /api/step", role: roleController, handler: handleStep, room: true,
+			summary: "n шагов с паузой после них", query: []apiParam{{"n", "integer", fmt.Sprintf("шагов, от 1 до %d", maxStepCount)}}},
+		{pattern: "POST /api/seek", role: roleController, handler: handleSeek, room: true,
+			summary: "перемотка назад в пределах истории", request: reflect.TypeFor[seekRequest](),
+			response: reflect.TypeFor[struct {
+				Time float64 `json:"time"`
+			}]()},
+		{pattern: "POST /api/reset", role: roleController, handler: handleReset, room: true, summary: "сброс"},
+		{pattern: "PUT /api/config", role: roleController, handler: handleConfig, room: true,
+			summary: "конфигурация, тело как data команды config", request: reflect.TypeFor[traffic.SimulationConfig]()},
+		{pattern: "GET /api/state", role: roleViewer, handler: handleState, room: true,
+			summary: "полное текущее состояние", response: reflect.TypeFor[traffic.State]()},
+		{pattern: "POST /api/snapshot", role: roleViewer, handler: handleSnapshot, room: true,
+			summary: "снимок полного состояния для восстановления", response: anyJSON},
+		{pattern: "POST /api/restore", role: roleController, handler: handleRestore, room: true,
+			summary: "восстановление из снимка", request: anyJSON},
+		{pattern: "POST /api/incidents", role: roleController, handler: handleIncident, room: true,
+			summary: "инцидент, тело как у команды incident", request: reflect.TypeFor[traffic.IncidentConfig](),
+			response: reflect.TypeFor[struct {
+				ID int `json:"id"`
+			}](), status: http.StatusCreated},
+		{pattern: "POST /api/scenario", role: roleController, handler: handleScenario, room: true,
+			summary: "сброс и загрузка сценария", request: reflect.TypeFor[traffic.Scenario](), yaml: true},
+		{pattern: "POST /api/cars", role: roleController, handler: handleAddCar, room: true,
+			summary: "добавление машины, тело как у команды car:add", request: reflect.TypeFor[traffic.CarConfig](),
+			response: reflect.TypeFor[struct {
+				ID int `json:"id"`
+			}](), status: http.StatusCreated},
+		{pattern: "PATCH /api/cars/{id}", role: roleController, handler: handleControlCar, room: true,
+			summary: "управление машиной, тело как у команды car", request: reflect.TypeFor[traffic.CarControl]()},
+		{pattern: "DELETE /api/cars/{id}", role: roleController, handler: handleRemoveCar, room: true,
+			summary: "удаление машины"},
+		{pattern: "GET /api/detectors", role: roleViewer, handler: handleDetectors, room: true,
+			summary: "ряды показателей детекторов", response: reflect.TypeFor[[]traffic.Detector]()},
+		{pattern: "GET /api/fundamental-diagram", role: roleViewer, handler: handleFundamentalDiagram, room: true,
+			summary: "точки фундаментальной диаграммы по участкам и окнам времени", response: reflect.TypeFor[[]traffic.DiagramPoint]()},
+		{pattern: "GET /api/trajectories.csv", role: roleViewer, handler: handleTrajectories, room: true,
+			summary: "записанные траектории", content: "text/csv"},
+		{pattern: "GET /api/timespace", role: roleViewer, handler: handleTimeSpace, room: true,
+			summary: "пространственно-временная диаграмма скоростей", response: reflect.TypeFor[traffic.TimeSpaceDiagram](),
+			query: []apiParam{{"from", "number", "секунды"}, {"to", "number", "секунды (0 - до конца записи)"}, {"resolution", "number", "секунды на ячейку"}}},
+		{pattern: "GET /api/histograms", role: roleViewer, handler: handleHistograms, room: true,
+			summary: "распределения скоростей, интервалов и времени в пути", response: reflect.TypeFor[traffic.Histograms]()},
+		{pattern: "GET /api/summary", role: roleViewer, handler: handleSummary, room: true,
+			summary: "итоги прогона с перцентилями показателей поездок", response: reflect.TypeFor[traffic.RunSummary]()},
+		{pattern: "GET /api/trips.csv", role: roleViewer, handler: handleTrips, room: true,
+			summary: "показатели завершённых поездок", content: "text/csv"},
+		{pattern: "POST /api/webhooks", role: roleController, handler: handleAddWebhook,
+			summary: "регистрация веб-хука", request: reflect.TypeFor[webhookRequest](),
+			response: reflect.TypeFor[struct {
+				ID string `json:"id"`
+			}](), status: http.StatusCreated},
+		{pattern: "GET /api/webhooks", role: roleController, handler: handleListWebhooks,
+			summary: "веб-хуки со счётчиками доставки", response: reflect.TypeFor[[]Webhook]()},
+		{pattern: "DELETE /api/webhooks/{id}", role: roleController, handler: handleDeleteWebhook,
+			summary: "удаление веб-хука"},
+		{pattern: "GET /api/runs", role: roleViewer, handler: handleListRuns,
+			summary: "архив завершённых прогонов, новые первыми", response: reflect.TypeFor[[]RunRecord](),
+			query: []apiParam{{"limit", "integer", "не больше прогонов"}}},
+		{pattern: "GET /api/runs/{id}", role: roleViewer, handler: handleGetRun,
+			summary: "прогон из архива с конфигурацией", response: reflect.TypeFor[RunRecord]()},
+		{pattern: "GET /api/runs/{id}/trajectories.csv", role: roleViewer, handler: handleRunTrajectories,
+			summary: "траектории прогона из архива", content: "text/csv"},
+		{pattern: "GET /api/compare", role: roleController, handler: handleCompare, room: true,
+			summary: "сравнение моделей следования для текущих параметров", response: reflect.TypeFor[[]traffic.ModelResult](),
+			query: []apiParam{{"duration", "number", fmt.Sprintf("секунды прогона каждой модели, до %g", maxCompareTime)}}},
+		{pattern: "GET /api/openapi.json", role: roleViewer, handler: handleOpenAPI,
+			summary: "описание HTTP API в формате OpenAPI 3.1", response: anyJSON},
+		{pattern: "GET /api/schema/ws.json", role: roleViewer, handler: handleWSSchema,
+			summary: "JSON Schema сообщений WebSocket", response: anyJSON},
+		{pattern: "/ascii", role: roleViewer, handler: handleASCII, room: true,
+			summary: "текстовое представление дороги", content: "text/plain",
+			query: []apiParam{{"width", "integer", "символов в строке"}}},
+		{pattern: "GET /metrics", role: roleViewer, handler: handleMetrics,
+			summary: "метрики в формате Prometheus", content: "text/plain"},
+	}
+}
+
+// registerAPI регистрирует эндпоинты apiRoutes с проверкой уровня доступа и,
+// для JSON-тел, проверкой тела по схеме
+func registerAPI(mux *http.ServeMux) {
+	schemas := newSchemaGenerator("#/$defs/")
+	for _, route := range apiRoutes() {
+		handler := route.handler
+		// Снимок для /api/restore может быть больше maxRequestSize, а сценарий - в YAML
+		if route.request != nil && route.request != rawMessageType && !route.yaml {
+			handler = validateBody(schemas, schemas.schemaFor(route.request), handler)
+		}
+		mux.HandleFunc(route.pattern, requireRole(route.role, handler))
+	}
+}
+
+// validateBody проверяет JSON-тело запроса по схеме до обработчика: нарушения
+// возвращаются кодом 400 с полями, как ошибки проверки конфигурации. Пустое тело
+// и некорректный JSON обработчик разбирает сам.
+func validateBody(g *schemaGenerator, schema map[string]any, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestSize))
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+			return
+		}
+		var body any
+		if json.Unmarshal(data, &body) == nil {
+			errs := &traffic.ValidationError{}
+			g.validateSchema(schema, body, "", errs)
+			if len(errs.Fields) > 0 {
+				writeError(w, http.StatusBadRequest, errs)
+				return
+			}
+		}
+		r.Body = io.NopCloser(bytes.NewReader(data))
+		handler(w, r)
+	}
+}
+
+// errorDetails описание ошибки в ответах REST и на команды WebSocket (errorBody)
+type errorDetails struct {
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"` // сообщение для каждого недопустимого поля
+}
+
+// apiError тело ответа REST с ошибкой (writeError)
+type apiError struct {
+	Error errorDetails `json:"error"`
+}
+
+var pathParam = regexp.MustCompile(`\{(\w+)\}`)
+
+// openAPIDocument строит описание HTTP API в формате OpenAPI 3.1 по apiRoutes
+func openAPIDocument() map[string]any {
+	schemas := newSchemaGenerator("#/components/schemas/")
+	errorResponse := map[string]any{
+		"description": "ошибка",
+		"content":     map[string]any{"application/json": map[string]any{"schema": schemas.schemaFor(reflect.TypeFor[apiError]())}},
+	}
+	paths := make(map[string]any)
+	for _, route := range apiRoutes() {
+		method, path, ok := strings.Cut(route.pattern, " ")
+		if !ok {
+			method, path = "GET", route.pattern
+		}
+		operation := map[string]any{
+			"summary":      route.summary,
+			"operationId":  strings.TrimPrefix(runtimeName(route.handler), "handle"),
+			"x-drive-role": roleName(route.role),
+			"security":     []any{map[string]any{}, map[string]any{"bearer": []any{}}, map[string]any{"token": []any{}}},
+		}
+
+		var parameters []any
+		for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+			parameters = append(parameters, map[string]any{
+				"name": match[1], "in": "path", "required": true, "schema": map[string]any{"type": "string"},
+			})
+		}
+		query := route.query
+		if route.room {
+			query = append([]apiParam{{"sim", "string", "идентификатор симуляции (по умолчанию " + DefaultRoom + ")"}}, query...)
+		}
+		for _, param := range query {
+			parameters = append(parameters, map[string]any{
+				"name": param.name, "in": "query", "description": param.summary, "schema": map[string]any{"type": param.kind},
+			})
+		}
+		if parameters != nil {
+			operation["parameters"] = parameters
+		}
+
+		if route.request != nil {
+			content := map[string]any{"application/json": map[string]any{"schema": schemas.schemaFor(route.request)}}
+			if route.yaml {
+				content["application/yaml"] = content["application/json"]
+			}
+			operation["requestBody"] = map[string]any{"required": true, "content": content}
+		}
+
+		status, success := route.status, map[string]any{"description": "успешно"}
+		switch {
+		case route.response != nil:
+			success["content"] = map[string]any{"application/json": map[string]any{"schema": schemas.schemaFor(route.response)}}
+		case route.content != "":
+			success["content"] = map[string]any{route.content: map[string]any{"schema": map[string]any{"type": "string"}}}
+		case status == 0:
+			status = http.StatusNoContent
+		}
+		if status == 0 {
+			status = http.StatusOK
+		}
+		operation["responses"] = map[string]any{fmt.Sprint(status): success, "default": errorResponse}
+
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = make(map[string]any)
+			paths[path] = item
+		}
+		item[strings.ToLower(method)] = operation
+	}
+
+	return map[string]any{
+		"openapi": "3.1.0",
+		"info": map[string]any{
+			"title":       "Drive traffic simulation",
+			"version":     "1.0",
+			"description": "Без токена управления проверка доступа выключена; x-drive-role - нужный уровень доступа (viewer или controller)",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.defs,
+			"securitySchemes": map[string]any{
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+				"token":  map[string]any{"type": "apiKey", "in": "query", "name": "token"},
+			},
+		},
+	}
+}
+
+// runtimeName возвращает имя функции обработчика для operationId
+func runtimeName(handler http.HandlerFunc) string {
+	name := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()).Name()
+	return name[strings.LastIndex(name, ".")+1:]
+}
+
+// roleName имя уровня доступа в описании API
+func roleName(r role) string {
+	if r >= roleController {
+		return "controller"
+	}
+	return "viewer"
+}
+
+// handleOpenAPI отдаёт описание HTTP API (GET /api/openapi.json)
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(openAPIDocument())
+}
+
+// commandPayload способ передачи параметров команды WebSocket
+type commandPayload int
+
+const (
+	payloadNone   commandPayload = iota // без параметров
+	payloadData                         // в поле data
+	payloadFields                       // полями самой команды
+	payloadValue                        // в поле value
+)
+
+// commandSpec описание команды WebSocket для схемы сообщений и проверки команд
+type commandSpec struct {
+	summary string
+	payload commandPayload
+	params  reflect.Type   // тип data или полей команды
+	value   map[string]any // схема value
+}
+
+// commandSpecs команды WebSocket, которые выполняет handleCommand
+var commandSpecs = map[string]commandSpec{
+	"start":    {summary: "запуск симуляции"},
+	"stop":     {summary: "остановка"},
+	"pause":    {summary: "пауза"},
+	"step":     {summary: "один шаг с паузой после него"},
+	"reset":    {summary: "сброс"},
+	"keyframe": {summary: "ключевой кадр для клиента в дельта-режиме"},
+	"seek":     {summary: "перемотка назад в пределах истории", payload: payloadFields, params: reflect.TypeFor[seekRequest]()},
+	"stepN": {summary: "n шагов с паузой после них", payload: payloadFields, params: reflect.TypeFor[struct {
+		N int `json:"n"`
+	}]()},
+	"config":   {summary: "конфигурация симуляции", payload: payloadData, params: reflect.TypeFor[traffic.SimulationConfig]()},
+	"physics":  {summary: "параметры физики", payload: payloadData, params: reflect.TypeFor[traffic.PhysicsConfig]()},
+	"blockage": {summary: "временное перекрытие дороги", payload: payloadData, params: reflect.TypeFor[traffic.BlockageConfig]()},
+	"incident": {summary: "инцидент", payload: payloadFields, params: reflect.TypeFor[traffic.IncidentConfig]()},
+	"car:add":  {summary: "добавление машины", payload: payloadFields, params: reflect.TypeFor[traffic.CarConfig]()},
+	"car:remove": {summary: "удаление машины", payload: payloadFields, params: reflect.TypeFor[struct {
+		Car int `json:"car"`
+	}]()},
+	"car":       {summary: "управление машиной с идентификатором id", payload: payloadFields, params: reflect.TypeFor[traffic.CarControl]()},
+	"emergency": {summary: "вызов спецмашины", payload: payloadFields, params: reflect.TypeFor[traffic.EmergencyConfig]()},
+	"player:claim": {summary: "управление машиной игроком", payload: payloadFields,
+		params: reflect.TypeFor[struct {
+			Car int `json:"car"`
+		}]()},
+	"player:drive":   {summary: "педали машины игрока", payload: payloadFields, params: reflect.TypeFor[traffic.Pedals]()},
+	"player:release": {summary: "возврат машины игрока модели следования"},
+	"segments":       {summary: "именованные участки", payload: payloadData, params: reflect.TypeFor[[]traffic.Segment]()},
+	"zones":          {summary: "зоны ограничения скорости", payload: payloadData, params: reflect.TypeFor[[]traffic.SpeedZone]()},
+	"cameras":        {summary: "камеры контроля скорости", payload: payloadData, params: reflect.TypeFor[[]traffic.SpeedCamera]()},
+	"grades":         {summary: "участки с уклоном", payload: payloadData, params: reflect.TypeFor[[]traffic.GradeSegment]()},
+	"vsl":            {summary: "переменное ограничение скорости", payload: payloadData, params: reflect.TypeFor[traffic.VSLConfig]()},
+	"buses":          {summary: "автобусный маршрут", payload: payloadData, params: reflect.TypeFor[traffic.BusConfig]()},
+	"workzone":       {summary: "участок дорожных работ", payload: payloadData, params: reflect.TypeFor[traffic.WorkZone]()},
+	"lights":         {summary: "светофоры", payload: payloadData, params: reflect.TypeFor[[]traffic.TrafficLight]()},
+	"crosswalks":     {summary: "пешеходные переходы", payload: payloadData, params: reflect.TypeFor[[]traffic.Crosswalk]()},
+	"detectors":      {summary: "детекторы", payload: payloadData, params: reflect.TypeFor[traffic.DetectorConfig]()},
+	"subscribe": {summary: "подписка на части состояния и частота кадров", payload: payloadFields,
+		params: reflect.TypeFor[struct {
+			Parts []string `json:"parts"`
+			Rate  float64  `json:"rate"`
+		}]()},
+	"record:start": {summary: "начало записи рассылаемых кадров", payload: payloadFields,
+		params: reflect.TypeFor[struct {
+			Name string `json:"name"`
+		}]()},
+	"record:stop": {summary: "завершение записи"},
+	"replay:load": {summary: "переключение клиента на запись", payload: payloadFields,
+		params: reflect.TypeFor[struct {
+			Name string `json:"name"`
+		}]()},
+	"replay:play":  {summary: "воспроизведение с множителем скорости value", payload: payloadValue, value: map[string]any{"type": "number"}},
+	"replay:pause": {summary: "пауза воспроизведения"},
+	"replay:seek":  {summary: "переход к моменту value секунд симуляции", payload: payloadValue, value: map[string]any{"type": "number"}},
+	"replay:stop":  {summary: "возврат к живому состоянию"},
+	"timescale":    {summary: "множитель скорости времени", payload: payloadValue, value: map[string]any{"type": "number"}},
+	"turbo":        {summary: "ускоренный режим", payload: payloadValue, value: map[string]any{"type": "boolean"}},
+	"weather": {summary: "погода", payload: payloadValue,
+		value: map[string]any{"type": "string", "enum": []any{traffic.WeatherDry, traffic.WeatherRain, traffic.WeatherSnow, traffic.WeatherFog}}},
+}
+
+// commandSchema строит схему команды action; id - необязательный идентификатор ответа
+func (g *schemaGenerator) commandSchema(action string, spec commandSpec) map[string]any {
+	properties := map[string]any{
+		"action": map[string]any{"const": action},
+		"id":     map[string]any{"type": []any{"number", "string"}},
+	}
+	switch spec.payload {
+	case payloadData:
+		properties["data"] = g.schemaFor(spec.params)
+	case payloadFields:
+		g.addFields(spec.params, properties)
+	case payloadValue:
+		properties["value"] = spec.value
+	}
+	return map[string]any{
+		"type":        "object",
+		"description": spec.summary,
+		"required":    []any{"action"},
+		"properties":  properties,
+	}
+}
+
+// commandResult ответ на команду с полем id (commandReply)
+type commandResult struct {
+	ID    any           `json:"id,omitempty"`
+	OK    bool          `json:"ok"`
+	Error *errorDetails `json:"error,omitempty"`
+}
+
+// eventMessage сообщение о событии симуляции; heartbeat приходит без data
+type eventMessage struct {
+	Event string         `json:"event"`
+	Data  *traffic.Event `json:"data,omitempty"`
+}
+
+// wsSchemaDocument строит JSON Schema сообщений WebSocket в формате json:
+// command - команды клиента, serverMessage - кадры состояния, события и ответы
+func wsSchemaDocument() map[string]any {
+	schemas := newSchemaGenerator("#/$defs/")
+	commands := make([]any, 0, len(commandSpecs))
+	for _, action := range slices.Sorted(maps.Keys(commandSpecs)) {
+		commands = append(commands, schemas.commandSchema(action, commandSpecs[action]))
+	}
+	schemas.defs["command"] = map[string]any{"oneOf": commands}
+
+	delta := map[string]any{
+		"type":        "object",
+		"description": "дельта-кадр: изменившиеся поля состояния и машин, исчезнувшие машины",
+		"required":    []any{"delta"},
+		"properties": map[string]any{
+			"seq":     map[string]any{"type": "integer"},
+			"delta":   map[string]any{"const": true},
+			"cars":    map[string]any{"type": "array", "items": map[string]any{"type": "object"}},
+			"removed": map[string]any{"type": "array", "items": map[string]any{"type": "integer"}},
+		},
+	}
+	schemas.defs["serverMessage"] = map[string]any{"anyOf": []any{
+		schemas.schemaFor(reflect.TypeFor[eventMessage]()),
+		schemas.schemaFor(reflect.TypeFor[commandResult]()),
+		delta,
+		schemas.schemaFor(reflect.TypeFor[traffic.State]()),
+	}}
+
+	return map[string]any{
+		"$schema":     "https://json-schema.org/draft/2020-12/schema",
+		"title":       "Drive WebSocket messages",
+		"description": "Сообщения /ws в формате json; ключевые кадры дельта-режима - состояние с полями seq и keyframe",
+		"anyOf":       []any{map[string]any{"$ref": "#/$defs/command"}, map[string]any{"$ref": "#/$defs/serverMessage"}},
+		"$defs":       schemas.defs,
+	}
+}
+
+// handleWSSchema отдаёт JSON Schema сообщений WebSocket (GET /api/schema/ws.json)
+func handleWSSchema(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	json.NewEncoder(w).Encode(wsSchemaDocument())
+}
+
+// commandSchemas схемы команд для проверки, строятся при первой команде
+var commandSchemas = sync.OnceValues(func() (*schemaGenerator, map[string]map[string]any) {
+	schemas := newSchemaGenerator("#/$defs/")
+	commands := make(map[string]map[string]any, len(commandSpecs))
+	for action, spec := range commandSpecs {
+		commands[action] = schemas.commandSchema(action, spec)
+	}
+	return schemas, commands
+})
+
+// validateCommand проверяет команду по её схеме до разбора: неизвестная команда -
+// ошибка с сообщением, поля неподходящего типа - ValidationError с путём до поля
+func validateCommand(cmd map[string]interface{}) error {
+	schemas, commands := commandSchemas()
+	action, _ := cmd["action"].(string)
+	schema, ok := commands[action]
+	if !ok {
+		return fmt.Errorf("unknown action %v", cmd["action"])
+	}
+	errs := &traffic.ValidationError{}
+	schemas.validateSchema(schema, map[string]any(cmd), "", errs)
+	if len(errs.Fields) > 0 {
+		return errs
+	}
+	return nil
+}
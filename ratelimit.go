@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// CommandRate число команд управления в секунду, которое может отправлять одно соединение
+	CommandRate = 20
+	// CommandBurst число команд управления, которое соединение может отправить подряд
+	CommandBurst = 40
+)
+
+var errRateLimited = fmt.Errorf("rate limited: at most %d commands per second", CommandRate)
+
+// unlimitedCommands команды, не расходующие токены: они дёшевы и меняют только то,
+// что получает сам клиент. Команды replay:* тоже меняют только его кадры, но
+// replay:load читает и разбирает файл записи, поэтому они ограничиваются как остальные.
+var unlimitedCommands = map[string]bool{
+	"subscribe": true,
+	"keyframe":  true,
+}
+
+// tokenBucket ограничитель частоты «корзина токенов»: токены пополняются со скоростью
+// rate до burst, каждая команда забирает один
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// allow забирает токен в момент now и сообщает, разрешена ли команда
+func (b *tokenBucket) allow(now time.Time, rate, burst float64) bool {
+	if b.last.IsZero() {
+		b.tokens = burst
+	} else {
+		b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
@@ -0,0 +1,134 @@
+//go:build redis
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+
+	"drive-simulation/traffic"
+)
+
+var (
+	redisURL    = flag.String("redis", envOr("DRIVE_REDIS", ""), "адрес Redis (redis://...) для шины кадров между сервером симуляции и серверами-зрителями (пусто - не использовать)")
+	redisPrefix = flag.String("redis-prefix", "drive", "префикс каналов Redis: кадры публикуются в <префикс>:state:<sim>, события - в <префикс>:events:<sim>")
+)
+
+// RelayQueueSize число сообщений в очереди публикации; при заполненной очереди
+// сообщение отбрасывается, чтобы Redis не задерживал рассылку своим клиентам
+const RelayQueueSize = 64
+
+// relayMessage сообщение для публикации в канал Redis
+type relayMessage struct {
+	channel string
+	data    []byte
+}
+
+func init() {
+	startRelay = serveRelay
+}
+
+// serveRelay подключается к Redis: сервер симуляции публикует кадры и события,
+// сервер-зритель подписывается на них. Связь закрывается по отмене ctx.
+func serveRelay(ctx context.Context) error {
+	if *redisURL == "" {
+		if viewerMode {
+			return errors.New("-viewer requires -redis")
+		}
+		return nil
+	}
+	options, err := redis.ParseURL(*redisURL)
+	if err != nil {
+		return err
+	}
+	client := redis.NewClient(options)
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		client.Close()
+	}()
+
+	if viewerMode {
+		go subscribeRelay(ctx, client)
+		slog.Info("Сервер-зритель подписан на кадры Redis", "prefix", *redisPrefix)
+		return nil
+	}
+	queue := make(chan relayMessage, RelayQueueSize)
+	go publishRelay(ctx, client, queue)
+	enqueue := func(message relayMessage) {
+		select {
+		case queue <- message:
+		default:
+			slog.Warn("Relay queue full, message dropped", "channel", message.channel)
+		}
+	}
+	// Кадры рассылаются каждые UpdateInterval, но публикуются только изменившиеся:
+	// зритель сам повторяет последний кадр своим клиентам. Вызывается из циклов
+	// рассылки разных симуляций, поэтому последний кадр хранится на симуляцию.
+	var last sync.Map
+	publishFrame = func(sim string, data []byte) {
+		if previous, ok := last.Load(sim); ok && bytes.Equal(previous.([]byte), data) {
+			return
+		}
+		last.Store(sim, data)
+		enqueue(relayMessage{channel: *redisPrefix + ":state:" + sim, data: data})
+	}
+	publishEvent = func(sim string, message []byte) {
+		enqueue(relayMessage{channel: *redisPrefix + ":events:" + sim, data: message})
+	}
+	slog.Info("Кадры публикуются в Redis", "prefix", *redisPrefix)
+	return nil
+}
+
+// publishRelay публикует сообщения очереди queue, пока не отменён ctx
+func publishRelay(ctx context.Context, client *redis.Client, queue <-chan relayMessage) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case message := <-queue:
+			if err := client.Publish(ctx, message.channel, message.data).Err(); err != nil && ctx.Err() == nil {
+				slog.Error("Redis publish error", "channel", message.channel, "err", err)
+			}
+		}
+	}
+}
+
+// subscribeRelay передаёт кадры и события из Redis симуляциям сервера-зрителя;
+// симуляция создаётся при первом кадре с её идентификатором
+func subscribeRelay(ctx context.Context, client *redis.Client) {
+	pubsub := client.PSubscribe(ctx, *redisPrefix+":state:*", *redisPrefix+":events:*")
+	defer pubsub.Close()
+
+	for message := range pubsub.Channel() {
+		kind, sim, ok := strings.Cut(strings.TrimPrefix(message.Channel, *redisPrefix+":"), ":")
+		if !ok {
+			continue
+		}
+		// Симуляции зрителя создаёт только эта горутина: управляющие запросы он отклоняет
+		room := rooms.get(sim)
+		if room == nil {
+			var err error
+			if room, err = rooms.create(sim, traffic.New()); err != nil {
+				slog.Warn("Relay simulation error", "sim", sim, "err", err)
+				continue
+			}
+		}
+		switch kind {
+		case "state":
+			room.relay([]byte(message.Payload))
+		case "events":
+			room.hub.broadcastEvent([]byte(message.Payload))
+		}
+	}
+}
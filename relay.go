@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// viewerMode сервер-зритель: симуляции не считаются, а кадры и события приходят
+// от сервера симуляции через шину Redis и ретранслируются своим клиентам (флаг -viewer)
+var viewerMode bool
+
+// errViewerInstance отказ в управлении на сервере-зрителе
+var errViewerInstance = errors.New("forbidden: read-only viewer instance")
+
+// Хуки шины кадров; мост собирается только с тегом redis (см. redis.go)
+var (
+	// startRelay подключает сервер к шине: публикует кадры или, в режиме зрителя, подписывается на них
+	startRelay = func(ctx context.Context) error {
+		if viewerMode {
+			return errors.New("-viewer requires a build with -tags redis")
+		}
+		return nil
+	}
+	// publishFrame публикует кадр состояния симуляции sim для серверов-зрителей
+	publishFrame = func(sim string, data []byte) {}
+	// publishEvent публикует сообщение о событии симуляции sim для серверов-зрителей
+	publishEvent = func(sim string, message []byte) {}
+)
+
+// relay принимает кадр состояния, полученный от сервера симуляции; его
+// раздаёт клиентам relayLoop
+func (room *Room) relay(data []byte) {
+	room.relayed.Store(&data)
+}
+
+// stateJSON возвращает текущий кадр состояния: на сервере-зрителе - последний
+// полученный от сервера симуляции, иначе - собственной симуляции
+func (room *Room) stateJSON() ([]byte, error) {
+	if viewerMode {
+		if data := room.relayed.Load(); data != nil {
+			return *data, nil
+		}
+	}
+	return room.simulation.StateJSON()
+}
+
+// relayLoop цикл рассылки сервера-зрителя: раздаёт клиентам последний полученный
+// кадр с той же частотой, что broadcastState, поэтому подписки, дельты и heartbeat
+// работают как на сервере симуляции
+func (room *Room) relayLoop(ctx context.Context) {
+	ticker := time.NewTicker(time.Millisecond * UpdateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if data := room.relayed.Load(); data != nil {
+			room.hub.broadcast(*data)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReplayDir каталог, в котором хранятся записи прогонов
+const ReplayDir = "replays"
+
+// replayName допустимые имена записей
+var replayName = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// replayPath возвращает путь к файлу записи name
+func replayPath(name string) (string, error) {
+	if !replayName.MatchString(name) {
+		return "", fmt.Errorf("invalid replay name %q", name)
+	}
+	return filepath.Join(ReplayDir, name+".jsonl.gz"), nil
+}
+
+// frameTime извлекает время симуляции из сериализованного состояния
+func frameTime(frame []byte) (float64, error) {
+	var header struct {
+		Time float64 `json:"time"`
+	}
+	err := json.Unmarshal(frame, &header)
+	return header.Time, err
+}
+
+// Recorder пишет кадры рассылки в сжатый журнал: по одному JSON-состоянию на строку
+type Recorder struct {
+	mu       sync.Mutex
+	file     *os.File
+	gz       *gzip.Writer
+	lastTime float64
+}
+
+// Start начинает запись в файл name, завершая предыдущую запись
+func (r *Recorder) Start(name string) error {
+	path, err := replayPath(name)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(ReplayDir, 0o755); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.close(); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	r.file, r.gz, r.lastTime = file, gzip.NewWriter(file), -1
+	return nil
+}
+
+// Write добавляет кадр в запись; кадры без продвижения времени (пауза) пропускаются
+func (r *Recorder) Write(frame []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.gz == nil {
+		return nil
+	}
+	t, err := frameTime(frame)
+	if err != nil || t == r.lastTime {
+		return err
+	}
+	r.lastTime = t
+	if _, err := r.gz.Write(frame); err != nil {
+		return err
+	}
+	_, err = r.gz.Write([]byte{'\n'})
+	return err
+}
+
+// Stop завершает запись и закрывает файл
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.close()
+}
+
+// Flush завершает запись при остановке сервера
+func (r *Recorder) Flush(ctx context.Context) error {
+	return r.Stop()
+}
+
+func (r *Recorder) close() error {
+	if r.gz == nil {
+		return nil
+	}
+	err := errors.Join(r.gz.Close(), r.file.Close())
+	r.file, r.gz = nil, nil
+	return err
+}
+
+// replayFrame кадр записи и его время симуляции
+type replayFrame struct {
+	time float64
+	data []byte
+}
+
+// Replay воспроизведение записанного прогона для одного клиента
+type Replay struct {
+	mu       sync.Mutex
+	frames   []replayFrame
+	position float64 // текущее время симуляции воспроизведения
+	speed    float64 // множитель скорости воспроизведения
+	playing  bool
+	lastTick time.Time
+}
+
+// loadReplay читает запись name с диска
+func loadReplay(name string) (*Replay, error) {
+	path, err := replayPath(name)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	replay := &Replay{speed: 1}
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		data := append([]byte(nil), scanner.Bytes()...)
+		t, err := frameTime(data)
+		if err != nil {
+			return nil, err
+		}
+		replay.frames = append(replay.frames, replayFrame{time: t, data: data})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(replay.frames) == 0 {
+		return nil, fmt.Errorf("replay %q is empty", name)
+	}
+	sort.SliceStable(replay.frames, func(i, j int) bool { return replay.frames[i].time < replay.frames[j].time })
+	replay.position = replay.frames[0].time
+	return replay, nil
+}
+
+// Play запускает воспроизведение со скоростью speed (неположительная - без изменения)
+func (r *Replay) Play(speed float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if speed > 0 {
+		r.speed = speed
+	}
+	r.playing = true
+	r.lastTick = time.Now()
+}
+
+// Pause приостанавливает воспроизведение
+func (r *Replay) Pause() {
+	r.mu.Lock()
+	r.playing = false
+	r.mu.Unlock()
+}
+
+// Seek переходит к моменту t времени симуляции
+func (r *Replay) Seek(t float64) {
+	r.mu.Lock()
+	r.position = t
+	r.mu.Unlock()
+}
+
+// Frame продвигает воспроизведение до момента now и возвращает последний кадр,
+// записанный не позже текущей позиции; в конце записи воспроизведение останавливается
+func (r *Replay) Frame(now time.Time) []byte {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.playing {
+		r.position += now.Sub(r.lastTick).Seconds() * r.speed
+		r.lastTick = now
+	}
+	last := r.frames[len(r.frames)-1]
+	if r.position >= last.time {
+		r.position, r.playing = last.time, false
+	}
+	i := sort.Search(len(r.frames), func(i int) bool { return r.frames[i].time > r.position })
+	if i > 0 {
+		i--
+	}
+	return r.frames[i].data
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"drive-simulation/traffic"
+)
+
+const (
+	// DefaultRoom идентификатор симуляции, к которой относятся запросы без параметра sim
+	DefaultRoom = "default"
+	// MaxRooms предельное число одновременно работающих симуляций
+	MaxRooms = 32
+)
+
+var (
+	errRoomExists   = errors.New("simulation already exists")
+	errTooManyRooms = fmt.Errorf("too many simulations (limit %d)", MaxRooms)
+	errDefaultRoom  = errors.New("default simulation cannot be deleted")
+)
+
+// Room независимая симуляция со своими циклами симуляции и рассылки,
+// клиентами WebSocket и записью прогонов
+type Room struct {
+	ID         string
+	simulation *traffic.Simulation
+	hub        *Hub
+	recorder   *Recorder
+	timer      stepTimer
+	cancel     context.CancelFunc
+	loops      sync.WaitGroup
+	// relayed - последний кадр, полученный от сервера симуляции (режим зрителя)
+	relayed atomic.Pointer[[]byte]
+}
+
+// start запускает циклы комнаты; они завершаются по отмене ctx или вызовом stop.
+// На сервере-зрителе симуляция не считается, а клиентам раздаются полученные кадры.
+func (room *Room) start(ctx context.Context) {
+	ctx, room.cancel = context.WithCancel(ctx)
+	if viewerMode {
+		room.loops.Go(func() { room.relayLoop(ctx) })
+		return
+	}
+	room.loops.Go(func() { room.simulationLoop(ctx) })
+	room.loops.Go(func() { room.broadcastState(ctx) })
+}
+
+// stop останавливает циклы комнаты, дожидаясь их не дольше дедлайна ctx,
+// и отключает её клиентов кадром закрытия с причиной reason
+func (room *Room) stop(ctx context.Context, reason string) {
+	room.cancel()
+	done := make(chan struct{})
+	go func() {
+		room.loops.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("Loop shutdown timeout", "sim", room.ID, "err", ctx.Err())
+	}
+
+	room.hub.closeAll(ctx, reason)
+}
+
+// roomRegistry хранит работающие симуляции по идентификаторам
+type roomRegistry struct {
+	mu    sync.RWMutex
+	ctx   context.Context
+	rooms map[string]*Room
+}
+
+var rooms *roomRegistry
+
+// newRoomRegistry создаёт реестр с симуляцией DefaultRoom; циклы всех симуляций
+// реестра завершаются по отмене ctx
+func newRoomRegistry(ctx context.Context) *roomRegistry {
+	r := &roomRegistry{ctx: ctx, rooms: make(map[string]*Room)}
+	r.create(DefaultRoom, traffic.New())
+	return r
+}
+
+// create регистрирует и запускает симуляцию sim под идентификатором id
+// (пустой id - случайный). Идентификаторы подчиняются тем же правилам, что имена записей.
+func (r *roomRegistry) create(id string, sim *traffic.Simulation) (*Room, error) {
+	if id == "" {
+		id = randomRoomID()
+	} else if !replayName.MatchString(id) {
+		return nil, fmt.Errorf("invalid simulation id %q", id)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.rooms[id]; ok {
+		return nil, errRoomExists
+	}
+	if len(r.rooms) >= MaxRooms {
+		return nil, errTooManyRooms
+	}
+	if exportEnabled() {
+		sim.SetEventExport(true)
+	}
+	room := &Room{ID: id, simulation: sim, hub: newHub(), recorder: &Recorder{}}
+	r.rooms[id] = room
+	room.start(r.ctx)
+	return room, nil
+}
+
+// get возвращает симуляцию id (пустой id - DefaultRoom) или nil, если её нет
+func (r *roomRegistry) get(id string) *Room {
+	if id == "" {
+		id = DefaultRoom
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.rooms[id]
+}
+
+// list возвращает симуляции, упорядоченные по идентификатору
+func (r *roomRegistry) list() []*Room {
+	r.mu.RLock()
+	list := make([]*Room, 0, len(r.rooms))
+	for _, room := range r.rooms {
+		list = append(list, room)
+	}
+	r.mu.RUnlock()
+
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list
+}
+
+// remove останавливает и удаляет симуляцию id, отключая её клиентов и завершая запись
+func (r *roomRegistry) remove(ctx context.Context, id string) (bool, error) {
+	if id == DefaultRoom {
+		return false, errDefaultRoom
+	}
+	r.mu.Lock()
+	room, ok := r.rooms[id]
+	delete(r.rooms, id)
+	r.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+
+	room.stop(ctx, "simulation deleted")
+	return true, room.recorder.Stop()
+}
+
+// stopAll останавливает все симуляции перед остановкой сервера
+func (r *roomRegistry) stopAll(ctx context.Context, reason string) {
+	var wg sync.WaitGroup
+	for _, room := range r.list() {
+		wg.Go(func() { room.stop(ctx, reason) })
+	}
+	wg.Wait()
+}
+
+// Flush завершает записи прогонов всех симуляций
+func (r *roomRegistry) Flush(ctx context.Context) error {
+	var errs []error
+	for _, room := range r.list() {
+		errs = append(errs, room.recorder.Flush(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// randomRoomID возвращает случайный идентификатор симуляции из 12 шестнадцатеричных цифр
+func randomRoomID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
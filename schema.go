@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"drive-simulation/traffic"
+)
+
+var (
+	rawMessageType = reflect.TypeFor[json.RawMessage]()
+	timeType       = reflect.TypeFor[time.Time]()
+)
+
+// schemaGenerator строит JSON Schema (диалект 2020-12, общий с OpenAPI 3.1) по типам Go
+// так же, как их разбирает encoding/json: имена полей из тегов json, неэкспортируемые
+// поля и поля с тегом "-" пропускаются, поля встроенных структур поднимаются наверх.
+// Именованные структуры попадают в defs и подставляются ссылкой refPrefix+имя.
+// Null допускается только там, где Go сам его отдаёт: в указателях, срезах и картах.
+// Лишние поля не запрещаются - разбор команд их пропускает.
+type schemaGenerator struct {
+	refPrefix string
+	defs      map[string]any
+}
+
+func newSchemaGenerator(refPrefix string) *schemaGenerator {
+	return &schemaGenerator{refPrefix: refPrefix, defs: make(map[string]any)}
+}
+
+// schemaFor возвращает схему значения типа t
+func (g *schemaGenerator) schemaFor(t reflect.Type) map[string]any {
+	switch t {
+	case rawMessageType:
+		return map[string]any{}
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schemaFor(t.Elem()))
+	case reflect.Interface:
+		return map[string]any{}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "contentEncoding": "base64"}
+		}
+		schema := map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+		if t.Kind() == reflect.Slice {
+			return nullable(schema)
+		}
+		return schema
+	case reflect.Map:
+		return nullable(map[string]any{"type": "object", "additionalProperties": g.schemaFor(t.Elem())})
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		if _, ok := g.defs[t.Name()]; !ok {
+			// Заглушка до построения схемы обрывает рекурсию в самоссылающихся типах
+			g.defs[t.Name()] = map[string]any{}
+			g.defs[t.Name()] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": g.refPrefix + t.Name()}
+	}
+	return map[string]any{}
+}
+
+// structSchema строит схему объекта по полям структуры t
+func (g *schemaGenerator) structSchema(t reflect.Type) map[string]any {
+	properties := make(map[string]any)
+	g.addFields(t, properties)
+	return map[string]any{"type": "object", "properties": properties}
+}
+
+// addFields добавляет в properties схемы полей структуры t и встроенных в неё структур
+func (g *schemaGenerator) addFields(t reflect.Type, properties map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				g.addFields(embedded, properties)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = g.schemaFor(field.Type)
+	}
+}
+
+// nullable разрешает в схеме значение null
+func nullable(schema map[string]any) map[string]any {
+	if kind, ok := schema["type"].(string); ok {
+		schema["type"] = []any{kind, "null"}
+		return schema
+	}
+	return map[string]any{"anyOf": []any{schema, map[string]any{"type": "null"}}}
+}
+
+// validateSchema проверяет разобранное из JSON значение value по схеме, построенной
+// schemaGenerator (type, const, enum, required, properties, items, additionalProperties,
+// anyOf, oneOf, $ref), и записывает нарушения в errs с путём до поля вида "data[0].green"
+func (g *schemaGenerator) validateSchema(schema map[string]any, value any, path string, errs *traffic.ValidationError) {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, _ := g.defs[strings.TrimPrefix(ref, g.refPrefix)].(map[string]any)
+		g.validateSchema(def, value, path, errs)
+		return
+	}
+	for _, key := range []string{"anyOf", "oneOf"} {
+		if branches, ok := schema[key].([]any); ok {
+			g.validateBranches(branches, value, path, errs)
+			return
+		}
+	}
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !matchesType(types, value) {
+		addFieldError(errs, path, "must be "+describeTypes(types))
+		return
+	}
+	if constant, ok := schema["const"]; ok && value != constant {
+		addFieldError(errs, path, fmt.Sprintf("must be %v", constant))
+		return
+	}
+	if enum, ok := schema["enum"].([]any); ok && !containsValue(enum, value) {
+		addFieldError(errs, path, fmt.Sprintf("must be one of %v", enum))
+		return
+	}
+	switch value := value.(type) {
+	case map[string]any:
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := value[name.(string)]; !ok {
+				addFieldError(errs, joinPath(path, name.(string)), "is required")
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		for name, field := range value {
+			if property, ok := properties[name].(map[string]any); ok {
+				g.validateSchema(property, field, joinPath(path, name), errs)
+			} else if additional, ok := schema["additionalProperties"].(map[string]any); ok {
+				g.validateSchema(additional, field, joinPath(path, name), errs)
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				g.validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+}
+
+// validateBranches принимает значение, подходящее хотя бы к одной из схем; иначе
+// сообщает нарушения первой схемы, которая не сводится к null
+func (g *schemaGenerator) validateBranches(branches []any, value any, path string, errs *traffic.ValidationError) {
+	var first *traffic.ValidationError
+	for _, branch := range branches {
+		schema, _ := branch.(map[string]any)
+		branchErrs := &traffic.ValidationError{}
+		g.validateSchema(schema, value, path, branchErrs)
+		if len(branchErrs.Fields) == 0 {
+			return
+		}
+		if first == nil && schema["type"] != "null" {
+			first = branchErrs
+		}
+	}
+	if first != nil {
+		for field, message := range first.Fields {
+			addFieldError(errs, field, message)
+		}
+	}
+}
+
+// schemaTypes возвращает допустимые типы из ключа type схемы
+func schemaTypes(value any) []string {
+	switch value := value.(type) {
+	case string:
+		return []string{value}
+	case []any:
+		types := make([]string, 0, len(value))
+		for _, kind := range value {
+			types = append(types, fmt.Sprint(kind))
+		}
+		return types
+	}
+	return nil
+}
+
+// matchesType сообщает, подходит ли значение из encoding/json к одному из типов JSON Schema
+func matchesType(types []string, value any) bool {
+	for _, kind := range types {
+		switch v := value.(type) {
+		case nil:
+			if kind == "null" {
+				return true
+			}
+		case bool:
+			if kind == "boolean" {
+				return true
+			}
+		case float64:
+			if kind == "number" || kind == "integer" && v == math.Trunc(v) {
+				return true
+			}
+		case string:
+			if kind == "string" {
+				return true
+			}
+		case []any:
+			if kind == "array" {
+				return true
+			}
+		case map[string]any:
+			if kind == "object" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// describeTypes описывает допустимые типы для сообщения об ошибке: "a number or null"
+func describeTypes(types []string) string {
+	names := make([]string, 0, len(types))
+	for _, kind := range types {
+		switch kind {
+		case "null":
+			names = append(names, "null")
+		case "integer", "array", "object":
+			names = append(names, "an "+kind)
+		default:
+			names = append(names, "a "+kind)
+		}
+	}
+	return strings.Join(names, " or ")
+}
+
+// containsValue сообщает, есть ли value среди допустимых значений enum
+func containsValue(enum []any, value any) bool {
+	for _, allowed := range enum {
+		if allowed == value {
+			return true
+		}
+	}
+	return false
+}
+
+// joinPath добавляет к пути поля имя вложенного поля
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// addFieldError запоминает нарушение поля path (пустой путь - всё сообщение)
+func addFieldError(errs *traffic.ValidationError, path, message string) {
+	if errs.Fields == nil {
+		errs.Fields = make(map[string]string)
+	}
+	if path == "" {
+		path = "message"
+	}
+	errs.Fields[path] = message
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"drive-simulation/traffic"
+)
+
+// ShutdownTimeout время, отведённое на остановку сервера и сброс буферов
+const ShutdownTimeout = 5 * time.Second
+
+// Flusher источник буферизованных данных (записи, CSV, траектории),
+// который должен быть сброшен перед остановкой сервера
+type Flusher interface {
+	Flush(ctx context.Context) error
+}
+
+var (
+	flushers   []Flusher
+	flushersMu sync.Mutex
+)
+
+// RegisterFlusher регистрирует источник данных для сброса при остановке
+func RegisterFlusher(f Flusher) {
+	flushersMu.Lock()
+	flushers = append(flushers, f)
+	flushersMu.Unlock()
+}
+
+// fileFlusher при сбросе записывает данные в файл path функцией write
+type fileFlusher struct {
+	path  string
+	write func(w io.Writer) error
+}
+
+func (f *fileFlusher) Flush(ctx context.Context) error {
+	file, err := os.Create(f.path)
+	if err != nil {
+		return err
+	}
+	out := bufio.NewWriter(file)
+	if err := f.write(out); err != nil {
+		file.Close()
+		return err
+	}
+	if err := out.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// flushAll параллельно вызывает каждый зарегистрированный Flusher ровно один раз
+// и ждёт их завершения не дольше дедлайна ctx
+func flushAll(ctx context.Context) {
+	flushersMu.Lock()
+	pending := make([]Flusher, len(flushers))
+	copy(pending, flushers)
+	flushersMu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, f := range pending {
+		wg.Add(1)
+		go func(f Flusher) {
+			defer wg.Done()
+			if err := f.Flush(ctx); err != nil {
+				slog.Error("Flush error", "err", err)
+			}
+		}(f)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Warn("Flush timeout", "err", ctx.Err())
+	}
+}
+
+// snapshotWriter возвращает функцию, пишущую в w полное состояние симуляции
+// для последующего восстановления
+func snapshotWriter(simulation *traffic.Simulation) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		data, err := simulation.SaveState()
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+}
+
+// shutdown останавливает HTTP-сервер, дожидается завершения циклов симуляции
+// и рассылки всех симуляций (их контекст уже отменён), закрывает WebSocket-соединения
+// кадром закрытия и сбрасывает буферы в пределах ctx
+func shutdown(ctx context.Context, server *http.Server) {
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("Server shutdown error", "err", err)
+	}
+
+	rooms.stopAll(ctx, "server shutting down")
+	flushAll(ctx)
+}
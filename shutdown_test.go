@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeFlusher считает вызовы Flush и выполняется delay (или до отмены ctx, если
+// honorCtx), возвращая err
+type fakeFlusher struct {
+	calls    atomic.Int32
+	delay    time.Duration
+	honorCtx bool
+	err      error
+}
+
+func (f *fakeFlusher) Flush(ctx context.Context) error {
+	f.calls.Add(1)
+	if !f.honorCtx {
+		time.Sleep(f.delay)
+		return f.err
+	}
+	select {
+	case <-time.After(f.delay):
+		return f.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TestFlushAll проверяет, что каждый зарегистрированный Flusher вызывается ровно один раз,
+// а медленный Flusher не задерживает остановку дольше дедлайна
+func TestFlushAll(t *testing.T) {
+	const deadline = 100 * time.Millisecond
+	tests := []struct {
+		name     string
+		flushers []*fakeFlusher
+		slow     bool // один из Flusher не успевает до дедлайна
+	}{
+		{name: "single", flushers: []*fakeFlusher{{}}},
+		{name: "several with an error", flushers: []*fakeFlusher{{}, {err: errors.New("disk full")}, {delay: 10 * time.Millisecond}}},
+		{name: "slow flusher honoring ctx", flushers: []*fakeFlusher{{}, {delay: time.Minute, honorCtx: true}}, slow: true},
+		{name: "slow flusher ignoring ctx", flushers: []*fakeFlusher{{}, {delay: time.Second}}, slow: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flushersMu.Lock()
+			saved := flushers
+			flushers = nil
+			flushersMu.Unlock()
+			t.Cleanup(func() {
+				flushersMu.Lock()
+				flushers = saved
+				flushersMu.Unlock()
+			})
+			for _, f := range tt.flushers {
+				RegisterFlusher(f)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), deadline)
+			defer cancel()
+			start := time.Now()
+			flushAll(ctx)
+			elapsed := time.Since(start)
+
+			if elapsed > deadline+50*time.Millisecond {
+				t.Errorf("flushAll took %v, deadline %v", elapsed, deadline)
+			}
+			if tt.slow && elapsed < deadline {
+				t.Errorf("flushAll returned after %v before the slow flusher or the deadline", elapsed)
+			}
+			for i, f := range tt.flushers {
+				if calls := f.calls.Load(); calls != 1 {
+					t.Errorf("flusher %d called %d times, want 1", i, calls)
+				}
+			}
+		})
+	}
+}
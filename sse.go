@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// handleEvents отдаёт состояние симуляции потоком Server-Sent Events для клиентов,
+// которым прокси не даёт установить WebSocket. Клиент получает те же кадры, что
+// и клиент WebSocket (состояние и события, каждый в поле data отдельного сообщения),
+// через ту же очередь отправки хаба. Подписка задаётся параметрами parts (части
+// через запятую) и rate, дельта-режим - параметром delta=1; команды по этому
+// каналу не принимаются.
+func handleEvents(w http.ResponseWriter, r *http.Request) {
+	room := roomFor(w, r)
+	if room == nil {
+		return
+	}
+	query := r.URL.Query()
+	if query.Get("format") == FormatMsgpack {
+		writeError(w, http.StatusBadRequest, errors.New("format: msgpack is not supported over SSE"))
+		return
+	}
+	var rate float64
+	if value := query.Get("rate"); value != "" {
+		var err error
+		if rate, err = strconv.ParseFloat(value, 64); err != nil {
+			writeError(w, http.StatusBadRequest, errors.New("rate: must be a number"))
+			return
+		}
+	}
+
+	client := newClient(room, r)
+	if parts := query.Get("parts"); parts != "" {
+		if err := client.Subscribe(strings.Split(parts, ",")); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+	client.SetRate(rate)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Прокси nginx иначе буферизует поток
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	room.hub.attach(client)
+	defer room.hub.unregister(client)
+	defer close(client.done)
+	client.log.Info("SSE client connected", "remote", r.RemoteAddr, "delta", client.delta)
+	defer func() { client.log.Info("SSE client disconnected", "reason", client.disconnectReason()) }()
+
+	controller := http.NewResponseController(w)
+	// closeStream сообщает клиенту причину отключения, как кадр закрытия WebSocket
+	closeStream := func(reason string) {
+		controller.SetWriteDeadline(time.Now().Add(WriteWait))
+		fmt.Fprintf(w, "event: close\ndata: %s\n\n", reason)
+		controller.Flush()
+	}
+	for {
+		select {
+		case frame, ok := <-client.send:
+			if !ok {
+				closeStream(client.disconnectReason())
+				return
+			}
+			controller.SetWriteDeadline(time.Now().Add(WriteWait))
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", frame); err != nil {
+				client.log.Warn("SSE write error", "err", err)
+				return
+			}
+			if err := controller.Flush(); err != nil {
+				client.log.Warn("SSE write error", "err", err)
+				return
+			}
+		case <-r.Context().Done():
+			return
+		case <-rooms.ctx.Done():
+			// Остановка сервера ждёт завершения обработчиков, поэтому поток закрывается сам
+			closeStream("server shutting down")
+			return
+		}
+	}
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"drive-simulation/traffic"
+)
+
+// Драйверы database/sql хранилища прогонов; каждый подключается только при сборке
+// со своим тегом (см. archive_sqlite.go и archive_postgres.go)
+var (
+	sqliteDriver   string
+	postgresDriver string
+)
+
+// ErrRunNotFound возвращается хранилищем для отсутствующего прогона
+var ErrRunNotFound = errors.New("run not found")
+
+// Storage хранилище завершённых прогонов. Реализации должны допускать вызовы из
+// нескольких горутин: прогоны разных симуляций сохраняются параллельно.
+type Storage interface {
+	// SaveRun сохраняет прогон и возвращает присвоенный ему идентификатор
+	SaveRun(run RunRecord) (int64, error)
+	// SaveFrames сохраняет траектории прогона id
+	SaveFrames(id int64, frames []traffic.TrajectorySample) error
+	// ListRuns возвращает до limit последних прогонов без конфигурации, новые первыми
+	ListRuns(limit int) ([]RunRecord, error)
+	// GetRun возвращает прогон с конфигурацией или ErrRunNotFound
+	GetRun(id int64) (RunRecord, error)
+	// WriteFrames пишет траектории прогона id в CSV
+	WriteFrames(w io.Writer, id int64) error
+	// Close закрывает соединение с хранилищем
+	Close() error
+}
+
+// openStorage открывает хранилище по адресу dsn: адрес postgres:// или
+// postgresql:// выбирает PostgreSQL, остальное считается путём к файлу SQLite
+func openStorage(dsn string) (Storage, error) {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		if postgresDriver == "" {
+			return nil, errors.New("PostgreSQL storage requires a build with -tags postgres")
+		}
+		return openSQLStorage(postgresDriver, dsn, postgresDialect)
+	}
+	if sqliteDriver == "" {
+		return nil, errors.New("SQLite storage requires a build with -tags sqlite")
+	}
+	return openSQLStorage(sqliteDriver, dsn, sqliteDialect)
+}
+
+// sqlDialect различия SQL между базами хранилища
+type sqlDialect struct {
+	schema []string                  // создание таблиц, по оператору на элемент
+	bind   func(query string) string // переписывает параметры ? в синтаксис базы
+}
+
+var sqliteDialect = sqlDialect{
+	schema: []string{
+		`CREATE TABLE IF NOT EXISTS runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			server TEXT NOT NULL,
+			sim TEXT NOT NULL,
+			finished_at TEXT NOT NULL,
+			sim_time REAL NOT NULL,
+			seed INTEGER NOT NULL,
+			config TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			trajectory_samples INTEGER NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS trajectories (
+			run_id INTEGER NOT NULL REFERENCES runs(id),
+			seq INTEGER NOT NULL,
+			time REAL NOT NULL,
+			car_id INTEGER NOT NULL,
+			lane INTEGER NOT NULL,
+			position REAL NOT NULL,
+			speed REAL NOT NULL,
+			state TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS trajectories_run ON trajectories(run_id, seq)`,
+	},
+	bind: func(query string) string { return query },
+}
+
+var postgresDialect = sqlDialect{
+	schema: []string{
+		`CREATE TABLE IF NOT EXISTS runs (
+			id BIGSERIAL PRIMARY KEY,
+			server TEXT NOT NULL,
+			sim TEXT NOT NULL,
+			finished_at TEXT NOT NULL,
+			sim_time DOUBLE PRECISION NOT NULL,
+			seed BIGINT NOT NULL,
+			config TEXT NOT NULL,
+			summary TEXT NOT NULL,
+			trajectory_samples INTEGER NOT NULL
+		)`,
+		`CREATE TABLE IF NOT EXISTS trajectories (
+			run_id BIGINT NOT NULL REFERENCES runs(id),
+			seq INTEGER NOT NULL,
+			time DOUBLE PRECISION NOT NULL,
+			car_id INTEGER NOT NULL,
+			lane INTEGER NOT NULL,
+			position DOUBLE PRECISION NOT NULL,
+			speed DOUBLE PRECISION NOT NULL,
+			state TEXT NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS trajectories_run ON trajectories(run_id, seq)`,
+	},
+	bind: func(query string) string {
+		var b strings.Builder
+		n := 0
+		for _, r := range query {
+			if r == '?' {
+				n++
+				b.WriteString("$" + strconv.Itoa(n))
+				continue
+			}
+			b.WriteRune(r)
+		}
+		return b.String()
+	},
+}
+
+// sqlStorage хранилище прогонов в базе database/sql (SQLite или PostgreSQL)
+type sqlStorage struct {
+	db      *sql.DB
+	dialect sqlDialect
+}
+
+// openSQLStorage открывает базу драйвером driver и создаёт таблицы
+func openSQLStorage(driver, dsn string, dialect sqlDialect) (*sqlStorage, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	for _, statement := range dialect.schema {
+		if _, err := db.Exec(statement); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return &sqlStorage{db: db, dialect: dialect}, nil
+}
+
+func (s *sqlStorage) SaveRun(run RunRecord) (int64, error) {
+	summary, err := json.Marshal(run.Summary)
+	if err != nil {
+		return 0, err
+	}
+	var id int64
+	err = s.db.QueryRow(s.dialect.bind(`INSERT INTO runs (server, sim, finished_at, sim_time, seed, config, summary, trajectory_samples)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`),
+		run.Server, run.Sim, run.FinishedAt.UTC().Format(time.RFC3339), run.SimTime, run.Seed,
+		string(run.Config), string(summary), run.TrajectorySamples).Scan(&id)
+	return id, err
+}
+
+func (s *sqlStorage) SaveFrames(id int64, frames []traffic.TrajectorySample) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	insert, err := tx.Prepare(s.dialect.bind(`INSERT INTO trajectories (run_id, seq, time, car_id, lane, position, speed, state)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+	for i, t := range frames {
+		if _, err := insert.Exec(id, i, t.Time, t.CarID, t.Lane, t.Position, t.Speed, t.State); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// runColumns столбцы runs в порядке scanRun
+const runColumns = `id, server, sim, finished_at, sim_time, seed, config, summary, trajectory_samples`
+
+// scanRun читает строку runs; config читается, только если withConfig
+func scanRun(row interface{ Scan(...any) error }, withConfig bool) (RunRecord, error) {
+	var run RunRecord
+	var finished, config, summary string
+	if err := row.Scan(&run.ID, &run.Server, &run.Sim, &finished, &run.SimTime, &run.Seed, &config, &summary, &run.TrajectorySamples); err != nil {
+		return run, err
+	}
+	run.FinishedAt, _ = time.Parse(time.RFC3339, finished)
+	if withConfig {
+		run.Config = json.RawMessage(config)
+	}
+	return run, json.Unmarshal([]byte(summary), &run.Summary)
+}
+
+func (s *sqlStorage) ListRuns(limit int) ([]RunRecord, error) {
+	rows, err := s.db.Query(s.dialect.bind(`SELECT `+runColumns+` FROM runs ORDER BY id DESC LIMIT ?`), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	runs := []RunRecord{}
+	for rows.Next() {
+		run, err := scanRun(rows, false)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+func (s *sqlStorage) GetRun(id int64) (RunRecord, error) {
+	run, err := scanRun(s.db.QueryRow(s.dialect.bind(`SELECT `+runColumns+` FROM runs WHERE id = ?`), id), true)
+	if errors.Is(err, sql.ErrNoRows) {
+		return run, fmt.Errorf("%w: %d", ErrRunNotFound, id)
+	}
+	return run, err
+}
+
+func (s *sqlStorage) WriteFrames(w io.Writer, id int64) error {
+	rows, err := s.db.Query(s.dialect.bind(`SELECT time, car_id, lane, position, speed, state FROM trajectories
+		WHERE run_id = ? ORDER BY seq`), id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	var frames []traffic.TrajectorySample
+	for rows.Next() {
+		var t traffic.TrajectorySample
+		if err := rows.Scan(&t.Time, &t.CarID, &t.Lane, &t.Position, &t.Speed, &t.State); err != nil {
+			return err
+		}
+		frames = append(frames, t)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return traffic.WriteTrajectorySamplesCSV(w, frames)
+}
+
+func (s *sqlStorage) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,164 @@
+package traffic
+
+import (
+	"fmt"
+	"strings"
+)
+
+// jamSpeed м/с, ниже этой скорости машина считается стоящей в заторе
+const jamSpeed = 20 / 3.6
+
+// RenderASCII рисует дорогу строками символов шириной width, по одной на полосу
+// (первой идёт левая полоса): '.' - свободно, '>' - машина в движении,
+// '<' - встречная машина двусторонней дороги, '#' - машина в заторе, 'X' - перекрытие,
+// '^' - полоса, закрытая участком работ, '|' - стоп-линия светофора на красный или жёлтый,
+// '=' - переход с пешеходами, 'o' - автобусная остановка, 'B' - автобус на остановке.
+// Дорожная сеть рисуется по звеньям.
+func RenderASCII(state State, width int) string {
+	if state.Network != nil {
+		return renderNetworkASCII(state, width)
+	}
+	lanes := state.Lanes
+	if lanes < 1 {
+		lanes = 1
+	}
+	road := make([][]byte, lanes)
+	for lane := range road {
+		road[lane] = []byte(strings.Repeat(".", width))
+	}
+	cell := func(position float64) int {
+		i := int(position / state.RoadLength * float64(width))
+		if i < 0 {
+			return 0
+		}
+		if i >= width {
+			return width - 1
+		}
+		return i
+	}
+
+	for _, b := range state.Blockages {
+		for lane := range road {
+			if !b.blocksLane(lane) {
+				continue
+			}
+			for i := cell(b.Position); i <= cell(b.Position+b.Span); i++ {
+				road[lane][i] = 'X'
+			}
+		}
+	}
+	if z := state.WorkZone; z != nil && z.Lane < lanes {
+		for i := cell(z.Start); i <= cell(z.End); i++ {
+			road[z.Lane][i] = '^'
+		}
+	}
+	for _, l := range state.TrafficLights {
+		if l.State == LightGreen {
+			continue
+		}
+		for lane := range road {
+			road[lane][cell(l.Position)] = '|'
+		}
+	}
+	for _, c := range state.Crosswalks {
+		if c.Crossing == 0 {
+			continue
+		}
+		for lane := range road {
+			road[lane][cell(c.Position)] = '='
+		}
+	}
+	if state.Buses != nil {
+		for _, stop := range state.Buses.Stops {
+			road[0][cell(stop.Position)] = 'o'
+		}
+	}
+	for _, car := range state.Cars {
+		if car.Lane < 0 || car.Lane >= lanes {
+			continue
+		}
+		line, i := road[car.Lane], cell(car.Position)
+		switch {
+		case car.Dwelling:
+			line[i] = 'B'
+		case line[i] == 'B':
+		case car.Speed < jamSpeed:
+			line[i] = '#'
+		case line[i] != '#':
+			line[i] = '>'
+		}
+	}
+	if lanes > OncomingLane {
+		line := road[OncomingLane]
+		for _, car := range state.Oncoming {
+			i := cell(state.RoadLength - car.Position)
+			if car.Speed < jamSpeed {
+				line[i] = '#'
+			} else if line[i] != '#' {
+				line[i] = '<'
+			}
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "t=%.1fs cars=%d\n", state.Time, len(state.Cars))
+	for lane := lanes - 1; lane >= 0; lane-- {
+		out.Write(road[lane])
+		out.WriteByte('\n')
+	}
+	return out.String()
+}
+
+// RenderASCII рисует текущее состояние симуляции шириной width (см. функцию RenderASCII)
+// под блокировкой: машины снимка - те же объекты, что меняет шаг симуляции
+func (s *Simulation) RenderASCII(width int) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return RenderASCII(s.currentState(), width)
+}
+
+// renderNetworkASCII рисует каждое звено сети строками шириной width, по одной на
+// полосу, под заголовком "id: from -> to"; звено растягивается на всю ширину,
+// стоп-линия светофора на красный или жёлтый - '|' в конце звена
+func renderNetworkASCII(state State, width int) string {
+	var out strings.Builder
+	cars := 0
+	for _, link := range state.Network.Links {
+		cars += len(link.Cars)
+	}
+	fmt.Fprintf(&out, "t=%.1fs cars=%d\n", state.Time, cars)
+	for _, link := range state.Network.Links {
+		lanes := max(1, link.Lanes)
+		road := make([][]byte, lanes)
+		for lane := range road {
+			road[lane] = []byte(strings.Repeat(".", width))
+		}
+		if link.Signal == LightRed || link.Signal == LightYellow {
+			for lane := range road {
+				road[lane][width-1] = '|'
+			}
+		}
+		for _, car := range link.Cars {
+			if car.Lane < 0 || car.Lane >= lanes {
+				continue
+			}
+			line, i := road[car.Lane], min(width-1, max(0, int(car.Position/link.Length*float64(width))))
+			if car.Speed < jamSpeed {
+				line[i] = '#'
+			} else if line[i] != '#' {
+				line[i] = '>'
+			}
+		}
+		fmt.Fprintf(&out, "%s: %s -> %s", link.ID, link.From, link.To)
+		if link.Signal != "" {
+			fmt.Fprintf(&out, " [%s] queue=%d delay=%.1fs", link.Signal, link.Queue, link.MeanDelay)
+		}
+		out.WriteByte('\n')
+		for lane := lanes - 1; lane >= 0; lane-- {
+			out.Write(road[lane])
+			out.WriteByte('\n')
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,111 @@
+package traffic
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// TestRenderASCII проверяет ширину строк, число строк по полосам и число маркеров
+// машин, заторов и перекрытий в текстовой картинке дороги
+func TestRenderASCII(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int
+		lanes     int
+		cars      []*Car
+		blockages []*Blockage
+		want      map[byte]int // маркер -> сколько раз он встречается на дороге
+	}{
+		{
+			name:  "empty road",
+			width: 40,
+			lanes: 2,
+			want:  map[byte]int{'>': 0, '#': 0, 'X': 0, '.': 80},
+		},
+		{
+			name:  "moving and jammed cars",
+			width: 50,
+			lanes: 2,
+			cars: []*Car{
+				{ID: 1, Lane: 0, Position: 100, Speed: 25},
+				{ID: 2, Lane: 0, Position: 2000, Speed: 1},
+				{ID: 3, Lane: 1, Position: 3000, Speed: 30},
+				{ID: 4, Lane: 1, Position: 4900, Speed: 0},
+			},
+			want: map[byte]int{'>': 2, '#': 2, '.': 96},
+		},
+		{
+			name:  "jam wins over a moving car in the same cell",
+			width: 10,
+			lanes: 1,
+			cars: []*Car{
+				{ID: 1, Lane: 0, Position: 1010, Speed: 0},
+				{ID: 2, Lane: 0, Position: 1020, Speed: 25},
+			},
+			want: map[byte]int{'>': 0, '#': 1, '.': 9},
+		},
+		{
+			name:      "closure of one lane",
+			width:     100,
+			lanes:     3,
+			cars:      []*Car{{ID: 1, Lane: 2, Position: 2500, Speed: 20}},
+			blockages: []*Blockage{{Position: 1000, Span: 200, Lanes: []int{0}, Duration: 60}},
+			// 1000-1200 м на дороге 5000 м при ширине 100 - клетки 20..24
+			want: map[byte]int{'>': 1, 'X': 5, '.': 294},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := State{Time: 12.5, RoadLength: RoadLength, Lanes: tt.lanes, Cars: tt.cars, Blockages: tt.blockages}
+			lines := strings.Split(strings.TrimSuffix(RenderASCII(state, tt.width), "\n"), "\n")
+
+			if want := fmt.Sprintf("t=12.5s cars=%d", len(tt.cars)); lines[0] != want {
+				t.Errorf("header = %q, want %q", lines[0], want)
+			}
+			road := lines[1:]
+			if len(road) != tt.lanes {
+				t.Fatalf("got %d road lines, want %d", len(road), tt.lanes)
+			}
+			counts := make(map[byte]int)
+			for _, line := range road {
+				if len(line) != tt.width {
+					t.Errorf("line %q has width %d, want %d", line, len(line), tt.width)
+				}
+				for i := 0; i < len(line); i++ {
+					counts[line[i]]++
+				}
+			}
+			for marker, want := range tt.want {
+				if counts[marker] != want {
+					t.Errorf("%q markers = %d, want %d\n%s", marker, counts[marker], want, strings.Join(road, "\n"))
+				}
+			}
+		})
+	}
+}
+
+// TestSimulationRenderASCII рисует дорогу, пока симуляция идёт в другой горутине;
+// гонку за машинами снимка ловит go test -race
+func TestSimulationRenderASCII(t *testing.T) {
+	sim := New(WithSpawnInterval(0.5))
+	sim.Start()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 500; i++ {
+			sim.Step(DefaultStep)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		out := sim.RenderASCII(60)
+		if lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n"); len(lines) != 2 || len(lines[1]) != 60 {
+			t.Fatalf("unexpected render:\n%s", out)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package traffic
+
+import "math"
+
+// Параметры адаптивного круиз-контроля (ACC) автоматизированных машин: регулятор
+// постоянного интервала по дистанции и разности скоростей (Milanés, Shladover 2014)
+const (
+	AVTimeGap         = 0.9  // секунды, желаемый интервал до лидера
+	AVMinGap          = 2.0  // метры, дистанция в заторе
+	AVGapGain         = 0.23 // 1/с², усиление по ошибке дистанции
+	AVSpeedGain       = 0.07 // 1/с, усиление по разности скоростей с лидером
+	AVCruiseGain      = 0.4  // 1/с, усиление по отклонению от целевой скорости без лидера
+	AVMaxAcceleration = 1.5  // м/с²
+	AVComfortBraking  = 2.0  // м/с², торможение регулятора без угрозы столкновения
+	AVMaxJerk         = 2.0  // м/с³, предел изменения ускорения вне экстренного торможения
+)
+
+// automated сообщает, управляется ли машина автоматически (машину игрока ведёт игрок)
+func (car *Car) automated() bool {
+	return car.Driver == DriverAutomated && !car.Player
+}
+
+// spawnAutomated решает, будет ли новая машина автоматизированной, по доле AVShare.
+// Генератор не используется при нулевой доле.
+func (s *Simulation) spawnAutomated() bool {
+	return s.AVShare > 0 && s.rng.Float64()*100 < s.AVShare
+}
+
+// accAcceleration вычисляет ускорение автоматизированной машины: регулятор постоянного
+// интервала a = k1·(s - s0 - v·h) + k2·(v_л - v), без лидера - разгон к целевой скорости.
+// Ускорение ограничено по рывку и комфортному торможению, если для остановки за лидером
+// не нужно тормозить сильнее - тогда машина тормозит с необходимым замедлением.
+func (s *Simulation) accAcceleration(car, leader *Car, dt float64) float64 {
+	effect := s.weatherEffect()
+	maxAccel, comfort := math.Min(AVMaxAcceleration, s.carAcceleration(car)), AVComfortBraking*effect.Braking
+
+	v := car.Speed
+	accel := AVCruiseGain * (car.TargetSpeed - v)
+	required := 0.0 // м/с², замедление, необходимое для остановки за лидером
+	if leader != nil {
+		gap := gapTo(car, leader)
+		accel = math.Min(accel, AVGapGain*(gap-AVMinGap-v*AVTimeGap*effect.Headway)+AVSpeedGain*(leader.Speed-v))
+		if dv := v - leader.Speed; dv > 0 {
+			required = dv * dv / (2 * math.Max(gap-AVMinGap, 0.1))
+		}
+	}
+	accel = math.Max(-comfort, math.Min(maxAccel, accel))
+	if dt > 0 {
+		// После экстренного торможения рывок ограничивается от комфортного замедления
+		previous := math.Max(-comfort, math.Min(maxAccel, car.accel))
+		accel = math.Max(previous-AVMaxJerk*dt, math.Min(previous+AVMaxJerk*dt, accel))
+	}
+	if required > comfort && -required < accel {
+		accel = math.Max(-required, -s.maxDeceleration())
+	}
+	car.accel = accel
+	return accel
+}
@@ -0,0 +1,130 @@
+package traffic
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// DefaultBatchDuration секунды симуляционного времени на один прогон пакетного режима
+const DefaultBatchDuration = 3600.0
+
+// SpeedRange диапазон целевых скоростей новых машин, км/ч
+type SpeedRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// BatchConfig описание серии прогонов: каждая комбинация интервала появления
+// и диапазона скоростей прогоняется без сервера и таймера
+type BatchConfig struct {
+	Duration       float64           `json:"duration"`       // секунды симуляционного времени на прогон
+	SpawnIntervals []float64         `json:"spawnIntervals"` // секунды между машинами
+	SpeedRanges    []SpeedRange      `json:"speedRanges"`    // км/ч
+	Base           *SimulationConfig `json:"base"`           // остальные параметры (nil - по умолчанию)
+}
+
+// BatchResult итоговые показатели одного прогона серии
+type BatchResult struct {
+	SpawnInterval float64 `json:"spawnInterval"` // секунды
+	MinSpeed      float64 `json:"minSpeed"`      // км/ч
+	MaxSpeed      float64 `json:"maxSpeed"`      // км/ч
+	CarsMade      int     `json:"carsMade"`
+	CarsCompleted int     `json:"carsCompleted"`
+	ModelResult
+}
+
+// RunBatch прогоняет все комбинации параметров серии; onResult (если задан)
+// вызывается после каждого прогона, например для вывода прогресса. Отмена ctx
+// прерывает серию и возвращает ctx.Err().
+func RunBatch(ctx context.Context, config BatchConfig, onResult func(BatchResult)) ([]BatchResult, error) {
+	duration := config.Duration
+	if duration <= 0 {
+		duration = DefaultBatchDuration
+	}
+
+	base := New()
+	if config.Base != nil {
+		// applyConfig не пропускает нулевые интервал и скорости, поэтому пустые значения заменяем умолчаниями
+		cfg := *config.Base
+		if cfg.SpawnInterval <= 0 {
+			cfg.SpawnInterval = base.SpawnInterval
+		}
+		if cfg.MinSpeed <= 0 {
+			cfg.MinSpeed = msToKmh(base.MinSpeed)
+		}
+		if cfg.MaxSpeed <= 0 {
+			cfg.MaxSpeed = msToKmh(base.MaxSpeed)
+		}
+		base.applyConfig(cfg)
+	}
+	intervals := config.SpawnIntervals
+	if len(intervals) == 0 {
+		intervals = []float64{base.SpawnInterval}
+	}
+	ranges := config.SpeedRanges
+	if len(ranges) == 0 {
+		ranges = []SpeedRange{{Min: msToKmh(base.MinSpeed), Max: msToKmh(base.MaxSpeed)}}
+	}
+
+	results := make([]BatchResult, 0, len(intervals)*len(ranges))
+	for _, interval := range intervals {
+		for _, r := range ranges {
+			sim, err := base.cloneParams()
+			if err != nil {
+				return nil, err
+			}
+			sim.SpawnInterval = interval
+			sim.MinSpeed = kmhToMs(r.Min)
+			sim.MaxSpeed = kmhToMs(r.Max)
+
+			run, err := runHeadless(ctx, sim, duration)
+			if err != nil {
+				return nil, err
+			}
+			result := BatchResult{
+				SpawnInterval: interval,
+				MinSpeed:      r.Min,
+				MaxSpeed:      r.Max,
+				ModelResult:   run,
+			}
+			result.CarsMade = sim.TotalCarsMade
+			result.CarsCompleted = sim.CarsCompleted
+			results = append(results, result)
+			if onResult != nil {
+				onResult(result)
+			}
+		}
+	}
+	return results, nil
+}
+
+// WriteBatchCSV пишет результаты серии в w в формате CSV
+func WriteBatchCSV(w io.Writer, results []BatchResult) error {
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+
+	out := csv.NewWriter(w)
+	out.Write([]string{"spawn_interval", "min_speed", "max_speed", "model", "cars_made", "cars_completed", "throughput", "avg_travel_time", "avg_speed", "brake_events", "brakes_per_car"})
+	for _, r := range results {
+		brakesPerCar := 0.0
+		if r.CarsMade > 0 {
+			brakesPerCar = float64(r.BrakeEvents) / float64(r.CarsMade)
+		}
+		out.Write([]string{
+			format(r.SpawnInterval),
+			format(r.MinSpeed),
+			format(r.MaxSpeed),
+			r.Model,
+			strconv.Itoa(r.CarsMade),
+			strconv.Itoa(r.CarsCompleted),
+			format(r.Throughput),
+			format(r.AvgTravelTime),
+			format(msToKmh(r.AvgSpeed)),
+			strconv.Itoa(r.BrakeEvents),
+			format(brakesPerCar),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
@@ -0,0 +1,68 @@
+package traffic
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+)
+
+// benchFleets размеры парков: кольцо на MaxLanes полос вмещает не больше ~3300 легковых машин
+var benchFleets = []int{250, 500, 1000, 2000, 3000}
+
+// newBenchRing создаёт запущенное кольцо на MaxLanes полос с cars машинами
+// без записи истории перемотки
+func newBenchRing(cars, workers int) *Simulation {
+	noHistory := 0.0
+	s := New(WithSeed(1), WithWorkers(workers), WithConfig(SimulationConfig{
+		RoadType:      RoadRing,
+		Lanes:         MaxLanes,
+		MaxCars:       cars,
+		MinSpeed:      60,
+		MaxSpeed:      120,
+		HistoryWindow: &noHistory,
+	}))
+	s.Start()
+	s.Step(DefaultStep)
+	return s
+}
+
+// BenchmarkUpdate измеряет шаг кольцевой дороги последовательно и параллельно
+// на GOMAXPROCS горутинах
+func BenchmarkUpdate(b *testing.B) {
+	workerCounts := []int{1}
+	if n := runtime.GOMAXPROCS(0); n > 1 {
+		workerCounts = append(workerCounts, n)
+	}
+	for _, cars := range benchFleets {
+		for _, workers := range workerCounts {
+			b.Run(fmt.Sprintf("cars=%d/workers=%d", cars, workers), func(b *testing.B) {
+				s := newBenchRing(cars, workers)
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					s.Step(DefaultStep)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkStateJSON измеряет сериализацию кадра для рассылки; шаг между кадрами
+// не входит в замер, он только сбрасывает кэш кадра
+func BenchmarkStateJSON(b *testing.B) {
+	for _, cars := range benchFleets {
+		b.Run(fmt.Sprintf("cars=%d", cars), func(b *testing.B) {
+			s := newBenchRing(cars, 0)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				s.Step(DefaultStep)
+				b.StartTimer()
+				if _, err := s.StateJSON(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package traffic
+
+import "math"
+
+// IncidentDelayRange метры перед перекрытием, на которых замедление машин
+// учитывается в IncidentDelay
+const IncidentDelayRange = 1000.0
+
+// Blockage представляет временное перекрытие участка дороги (ДТП, закрытие полосы)
+type Blockage struct {
+	ID        int     `json:"id"`
+	Incident  string  `json:"incident"`  // вид инцидента (пусто - перекрытие командой blockage)
+	Position  float64 `json:"position"`  // метры, начало перекрытия
+	Span      float64 `json:"span"`      // метры, протяжённость перекрытия
+	Lanes     []int   `json:"lanes"`     // перекрытые полосы (пусто = все)
+	StartTime float64 `json:"startTime"` // время установки, секунды симуляции
+	Duration  float64 `json:"duration"`  // секунды
+
+	Rubbernecking float64 `json:"rubbernecking,omitempty"` // доля снижения целевой скорости у машины на обочине
+}
+
+// active сообщает, действует ли перекрытие в момент времени t
+func (b *Blockage) active(t float64) bool {
+	return t < b.StartTime+b.Duration
+}
+
+// blocksLane сообщает, перекрывает ли перекрытие указанную полосу; машина на
+// обочине не перекрывает ни одной
+func (b *Blockage) blocksLane(lane int) bool {
+	if b.Incident == IncidentShoulder {
+		return false
+	}
+	if len(b.Lanes) == 0 {
+		return true
+	}
+	for _, l := range b.Lanes {
+		if l == lane {
+			return true
+		}
+	}
+	return false
+}
+
+// stopObstacle возвращает неподвижный виртуальный автомобиль, задний край которого
+// находится в точке position (начало перекрытия, стоп-линия), чтобы логика
+// следования обрабатывала препятствие как лидера
+func stopObstacle(position float64) *Car {
+	return &Car{ID: -1, Position: position + CarLength, Speed: 0}
+}
+
+// AddBlockage устанавливает временное перекрытие дороги
+func (s *Simulation) AddBlockage(config BlockageConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if config.Duration <= 0 {
+		return
+	}
+	span := config.Span
+	if span <= 0 {
+		span = CarLength
+	}
+	s.Blockages = append(s.Blockages, &Blockage{
+		ID:        s.nextBlockageID,
+		Position:  config.Position,
+		Span:      span,
+		Lanes:     config.Lanes,
+		StartTime: s.Time,
+		Duration:  config.Duration,
+	})
+	s.nextBlockageID++
+}
+
+// clearExpiredBlockages удаляет перекрытия, срок действия которых истёк
+func (s *Simulation) clearExpiredBlockages() {
+	active := make([]*Blockage, 0, len(s.Blockages))
+	for _, b := range s.Blockages {
+		if b.active(s.Time) {
+			active = append(active, b)
+		} else if b.Incident != "" {
+			s.emitIncident("incident:cleared", b)
+		}
+	}
+	s.Blockages = active
+}
+
+// blockageAhead возвращает ближайшее перекрытие впереди автомобиля на полосе lane
+func (s *Simulation) blockageAhead(car *Car, lane int) *Blockage {
+	var nearest *Blockage
+	best := math.MaxFloat64
+	for _, b := range s.Blockages {
+		if d := s.aheadDistance(car.Position, b.Position); d > 0 && d < best && b.blocksLane(lane) {
+			nearest, best = b, d
+		}
+	}
+	return nearest
+}
+
+// upstreamOfBlockage сообщает, находится ли автомобиль не дальше IncidentDelayRange
+// перед действующим перекрытием на перекрытой или соседней с ней полосе (на кольце
+// расстояние считается через начало дороги); замедление таких машин учитывается
+// как задержка из-за инцидента. Машина на обочине полос не занимает, и её учитывают
+// на всех полосах, только если водители притормаживают, разглядывая её.
+func (s *Simulation) upstreamOfBlockage(car *Car) bool {
+	for _, b := range s.Blockages {
+		if d := s.aheadDistance(car.Position, b.Position); d <= 0 || d > IncidentDelayRange {
+			continue
+		}
+		if b.Incident == IncidentShoulder {
+			if b.Rubbernecking > 0 {
+				return true
+			}
+			continue
+		}
+		if b.blocksLane(car.Lane) || b.blocksLane(car.Lane-1) || b.blocksLane(car.Lane+1) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package traffic
+
+import "testing"
+
+// TestLaneClosure проверяет, что перед перекрытием машины уходят с закрытой полосы,
+// перед ним собирается очередь, а после снятия перекрытия очередь рассасывается
+func TestLaneClosure(t *testing.T) {
+	const (
+		dt       = 0.05
+		position = 1500.0
+		span     = 50.0
+		duration = 180.0
+		queueLen = 400.0 // метры перед перекрытием, где ищется очередь
+	)
+	tests := []struct {
+		name   string
+		lanes  int
+		closed []int
+		merges bool // ожидаются перестроения с закрытой полосы
+	}{
+		{name: "one of three lanes", lanes: 3, closed: []int{0}, merges: true},
+		{name: "two of three lanes", lanes: 3, closed: []int{0, 1}, merges: true},
+		{name: "whole single-lane road", lanes: 1, closed: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed := int64(7)
+			noHistory := 0.0
+			sim := New(WithConfig(SimulationConfig{
+				SpawnInterval: 0.8,
+				MinSpeed:      80,
+				MaxSpeed:      110,
+				MaxCars:       400,
+				Lanes:         tt.lanes,
+				Model:         "idm",
+				Seed:          &seed,
+				HistoryWindow: &noHistory,
+			}), WithTimeScale(1))
+			sim.AddBlockage(BlockageConfig{Position: position, Span: span, Lanes: tt.closed, Duration: duration})
+			sim.Start()
+
+			peakQueue := 0
+			for sim.Time < duration-1 {
+				sim.Step(dt)
+				for _, car := range sim.Snapshot().Cars {
+					if closedLane(tt.closed, car.Lane) && car.Position > position && car.Position < position+span {
+						t.Fatalf("t=%.1f: car %d drives through the closure in lane %d at %.1f m",
+							sim.Time, car.ID, car.Lane, car.Position)
+					}
+				}
+				peakQueue = max(peakQueue, queuedCars(sim, position, queueLen))
+			}
+			if peakQueue == 0 {
+				t.Fatal("no queue formed upstream of the closure")
+			}
+			if tt.merges && sim.Snapshot().LaneChanges == 0 {
+				t.Fatal("no car merged out of the closed lane")
+			}
+			if sim.Snapshot().IncidentDelay <= 0 {
+				t.Fatal("closure caused no incident delay")
+			}
+
+			// После снятия перекрытия очередь должна рассосаться за разумное время
+			for sim.Time < duration+300 {
+				sim.Step(dt)
+			}
+			state := sim.Snapshot()
+			if len(state.Blockages) != 0 {
+				t.Fatalf("closure still active after %.0f s: %d blockages", duration, len(state.Blockages))
+			}
+			if queued := queuedCars(sim, position, queueLen); queued != 0 {
+				t.Fatalf("%d cars still queued after the closure was lifted (peak %d)", queued, peakQueue)
+			}
+		})
+	}
+}
+
+// TestIncidentDelayScope проверяет, что задержка из-за инцидента начисляется только
+// машинам перед перекрытием на перекрытой или соседней полосе в пределах
+// IncidentDelayRange, а на кольце - и через начало дороги
+func TestIncidentDelayScope(t *testing.T) {
+	closure := &Blockage{Position: 2000, Span: 50, Lanes: []int{0}, Duration: 60}
+	tests := []struct {
+		name     string
+		roadType string
+		blockage *Blockage
+		car      Car
+		want     bool
+	}{
+		{name: "closed lane just upstream", blockage: closure, car: Car{Lane: 0, Position: 1900}, want: true},
+		{name: "lane next to the closure", blockage: closure, car: Car{Lane: 1, Position: 1900}, want: true},
+		{name: "open lane far from the closure", blockage: closure, car: Car{Lane: 3, Position: 1900}},
+		{name: "closed lane far upstream", blockage: closure, car: Car{Lane: 0, Position: 2000 - IncidentDelayRange - 100}},
+		{name: "past the closure", blockage: closure, car: Car{Lane: 0, Position: 2100}},
+		{
+			name:     "ring seam",
+			roadType: RoadRing,
+			blockage: &Blockage{Position: 100, Span: 50, Lanes: []int{0}, Duration: 60},
+			car:      Car{Lane: 0, Position: RoadLength - 100},
+			want:     true,
+		},
+		{
+			name:     "no seam on a straight road",
+			blockage: &Blockage{Position: 100, Span: 50, Lanes: []int{0}, Duration: 60},
+			car:      Car{Lane: 0, Position: RoadLength - 100},
+		},
+		{
+			name:     "shoulder incident with rubbernecking",
+			blockage: &Blockage{Incident: IncidentShoulder, Position: 2000, Span: CarLength, Duration: 60, Rubbernecking: 0.3},
+			car:      Car{Lane: 3, Position: 1900},
+			want:     true,
+		},
+		{
+			name:     "shoulder incident without rubbernecking",
+			blockage: &Blockage{Incident: IncidentShoulder, Position: 2000, Span: CarLength, Duration: 60},
+			car:      Car{Lane: 0, Position: 1900},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := New(WithConfig(SimulationConfig{Lanes: 4, RoadType: tt.roadType, MinSpeed: 60, MaxSpeed: 110}))
+			sim.Blockages = []*Blockage{tt.blockage}
+			car := tt.car
+			if got := sim.upstreamOfBlockage(&car); got != tt.want {
+				t.Fatalf("car in lane %d at %.0f m counted as delayed = %v, want %v", car.Lane, car.Position, got, tt.want)
+			}
+		})
+	}
+}
+
+// closedLane сообщает, перекрыта ли полоса lane (пустой список - все полосы)
+func closedLane(closed []int, lane int) bool {
+	if len(closed) == 0 {
+		return true
+	}
+	for _, l := range closed {
+		if l == lane {
+			return true
+		}
+	}
+	return false
+}
+
+// queuedCars считает почти стоящие машины на участке длиной length перед точкой position
+func queuedCars(s *Simulation, position, length float64) int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	n := 0
+	for _, car := range s.Cars {
+		if car.Position < position && car.Position > position-length && car.Speed < 2 {
+			n++
+		}
+	}
+	return n
+}
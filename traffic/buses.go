@@ -0,0 +1,121 @@
+package traffic
+
+import "sort"
+
+// busStopReach метры до остановки, в пределах которых остановившийся автобус
+// считается подъехавшим к ней
+const busStopReach = 20.0
+
+// BusConfig маршрут автобусов: автобусы выходят на крайнюю правую полосу каждые
+// Headway секунд начиная с Offset и стоят на каждой остановке её время Dwell.
+// Пустой список остановок отключает маршрут.
+type BusConfig struct {
+	Headway float64   `json:"headway"` // секунды между автобусами (0 - выходит один автобус)
+	Offset  float64   `json:"offset"`  // секунды, выход первого автобуса
+	Stops   []BusStop `json:"stops"`
+}
+
+// BusStop остановка маршрута
+type BusStop struct {
+	Name     string  `json:"name"`
+	Position float64 `json:"position"` // метры, передняя часть стоящего автобуса
+	Dwell    float64 `json:"dwell"`    // секунды стоянки
+}
+
+// SetBuses задаёт маршрут автобусов; первый автобус выходит в момент Offset
+// от начала прогона. Пустой список остановок отключает маршрут, автобусы
+// на дороге доезжают без остановок.
+func (s *Simulation) SetBuses(config BusConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(config.Stops) == 0 {
+		s.Buses = nil
+		return
+	}
+	config.Stops = append(make([]BusStop, 0, len(config.Stops)), config.Stops...)
+	sort.SliceStable(config.Stops, func(i, j int) bool { return config.Stops[i].Position < config.Stops[j].Position })
+	s.Buses = &config
+	s.resetBuses()
+}
+
+// resetBuses начинает расписание маршрута заново
+func (s *Simulation) resetBuses() {
+	s.BusesDispatched = 0
+	s.BusStopsServed = 0
+	s.nextBus = 0
+	if s.Buses != nil {
+		s.nextBus = s.Buses.Offset
+	}
+}
+
+// dispatchBus выпускает автобус маршрута на крайнюю правую полосу, когда подошло
+// время по расписанию и в начале полосы есть место; опоздавший автобус выходит
+// при первой возможности, а следующий - через Headway после расписания
+func (s *Simulation) dispatchBus() {
+	b := s.Buses
+	if b == nil || s.Time < s.nextBus || s.TotalCarsMade >= s.MaxCars {
+		return
+	}
+	if b.Headway <= 0 && s.BusesDispatched > 0 {
+		return
+	}
+	if follower := s.followerIn(0, spawnClearance, nil); follower != nil {
+		return
+	}
+	car := s.newVehicle(VehicleBus, 0, 0)
+	car.busRoute = true
+	s.adaptTargetSpeed(car)
+	car.Speed = car.TargetSpeed
+	s.insertCar(car)
+	s.TotalCarsMade++
+	s.emitExport(Event{Type: "car:spawned", Time: s.Time, Cars: []int{car.ID}, Lane: car.Lane, Position: car.Position, Speed: car.Speed})
+	s.BusesDispatched++
+	s.nextBus += b.Headway
+}
+
+// routeBus сообщает, есть ли у автобуса маршрута ещё не пройденные остановки
+func (s *Simulation) routeBus(car *Car) bool {
+	return car.busRoute && s.Buses != nil && car.nextStop < len(s.Buses.Stops)
+}
+
+// busStopAhead возвращает следующую остановку автобуса маршрута как стоп-линию
+func (s *Simulation) busStopAhead(car *Car) (float64, bool) {
+	if !s.routeBus(car) {
+		return 0, false
+	}
+	return s.Buses.Stops[car.nextStop].Position, true
+}
+
+// updateBusStops ведёт автобусы маршрута по остановкам: остановившийся перед
+// остановкой автобус стоит её время Dwell и едет к следующей. Остановка, которую
+// автобус проехал, не остановившись (например, после аварии), пропускается.
+func (s *Simulation) updateBusStops() {
+	for _, car := range s.Cars {
+		if !s.routeBus(car) || car.crashed() {
+			continue
+		}
+		stop := s.Buses.Stops[car.nextStop]
+		if car.Dwelling {
+			if s.Time >= car.dwellUntil {
+				car.Dwelling = false
+				car.nextStop++
+				s.BusStopsServed++
+			}
+			continue
+		}
+		if car.Position > stop.Position {
+			car.nextStop++
+			continue
+		}
+		if car.Speed >= kmhToMs(StopSpeed) || stop.Position-car.Position > busStopReach {
+			continue
+		}
+		// Автобус в очереди перед остановкой ещё не подъехал к ней
+		if leader := s.leaderIn(car.Lane, car.Position, car); leader != nil && leader.Position-leader.length() < stop.Position {
+			continue
+		}
+		car.Dwelling = true
+		car.dwellUntil = s.Time + stop.Dwell
+	}
+}
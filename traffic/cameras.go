@@ -0,0 +1,83 @@
+package traffic
+
+import "math"
+
+// CameraBrakeDistance метры перед камерой, на которых водители-нарушители сбрасывают скорость
+const CameraBrakeDistance = 100.0
+
+// SpeedCamera камера контроля скорости в точке Position на всех полосах. Водитель,
+// собирающийся проехать камеру быстрее Limit+Margin, за CameraBrakeDistance до неё
+// резко снижает целевую скорость до Limit и после камеры снова разгоняется; машина,
+// проехавшая камеру быстрее Limit+Margin, считается нарушителем.
+type SpeedCamera struct {
+	Position   float64 `json:"position"`   // метры
+	Limit      float64 `json:"limit"`      // км/ч, контролируемое ограничение
+	Margin     float64 `json:"margin"`     // км/ч, допустимое превышение
+	Passed     int     `json:"passed"`     // машин проехали камеру с начала прогона
+	Violations int     `json:"violations"` // из них - с превышением больше допустимого
+}
+
+// SetCameras задаёт камеры контроля скорости (предыдущий набор заменяется); камеры
+// вне дороги и без ограничения отбрасываются, показатели начинаются заново
+func (s *Simulation) SetCameras(cameras []SpeedCamera) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	valid := make([]*SpeedCamera, 0, len(cameras))
+	for _, c := range cameras {
+		if c.Position <= 0 || c.Position >= RoadLength || c.Limit <= 0 {
+			continue
+		}
+		valid = append(valid, &SpeedCamera{Position: c.Position, Limit: c.Limit, Margin: math.Max(0, c.Margin)})
+	}
+	s.Cameras = valid
+}
+
+// resetCameras обнуляет показатели камер
+func (s *Simulation) resetCameras() {
+	for _, c := range s.Cameras {
+		*c = SpeedCamera{Position: c.Position, Limit: c.Limit, Margin: c.Margin}
+	}
+}
+
+// cameraLimit возвращает ограничение (м/с) ближайшей камеры в пределах
+// CameraBrakeDistance впереди машины, если целевая скорость машины превышает его
+// больше допустимого; спецмашина камер не боится
+func (s *Simulation) cameraLimit(car *Car) (float64, bool) {
+	if car.emergency() {
+		return 0, false
+	}
+	limit, found := 0.0, false
+	best := math.MaxFloat64
+	for _, c := range s.Cameras {
+		distance := s.aheadDistance(car.Position, c.Position)
+		if distance <= 0 || distance > CameraBrakeDistance || distance >= best || car.TargetSpeed <= kmhToMs(c.Limit+c.Margin) {
+			continue
+		}
+		limit, found, best = kmhToMs(c.Limit), true, distance
+	}
+	return limit, found
+}
+
+// trackCameras учитывает проезд камер машиной, сдвинувшейся из from: скорость
+// сравнивается с ограничением камеры и допустимым превышением
+func (s *Simulation) trackCameras(car *Car, from float64) {
+	for _, c := range s.Cameras {
+		if from >= c.Position || car.Position < c.Position {
+			continue
+		}
+		c.Passed++
+		if !car.emergency() && car.Speed > kmhToMs(c.Limit+c.Margin) {
+			c.Violations++
+		}
+	}
+}
+
+// cameraStates возвращает копии камер для рассылки клиентам
+func (s *Simulation) cameraStates() []SpeedCamera {
+	states := make([]SpeedCamera, len(s.Cameras))
+	for i, c := range s.Cameras {
+		states[i] = *c
+	}
+	return states
+}
@@ -0,0 +1,57 @@
+package traffic
+
+// Car представляет автомобиль
+type Car struct {
+	ID             int      `json:"id"`
+	Type           string   `json:"type"`               // тип: "car", "truck", "bus" или "emergency"
+	Driver         string   `json:"driver"`             // профиль водителя: "normal", "aggressive", "cautious" или "automated"
+	Length         float64  `json:"length"`             // метры
+	Lane           int      `json:"lane"`               // номер полосы, 0 - крайняя правая
+	Position       float64  `json:"position"`           // метры от начала
+	Speed          float64  `json:"speed"`              // м/с
+	TargetSpeed    float64  `json:"targetSpeed"`        // желаемая скорость на текущем участке
+	BrakeCount     int      `json:"brakeCount"`         // количество торможений
+	Color          string   `json:"color"`              // цвет для визуализации
+	State          string   `json:"state"`              // "normal", "braking", "accelerating"
+	ReactionDelay  float64  `json:"reactionDelay"`      // секунды, с какой задержкой водитель видит препятствие впереди
+	OverLimitTime  float64  `json:"overLimitTime"`      // секунды движения с превышением ограничения скорости
+	Fuel           float64  `json:"fuel"`               // литры топлива с появления
+	CO2            float64  `json:"co2"`                // кг CO₂ с появления
+	Platoon        int      `json:"platoon"`            // колонна автоматизированных машин (0 - вне колонны)
+	PlatoonIndex   int      `json:"platoonIndex"`       // место в колонне, 0 - лидер
+	Player         bool     `json:"player"`             // машиной управляет игрок педалями (см. ClaimCar)
+	Overtaking     string   `json:"overtaking"`         // этап обгона по встречной полосе: "passing", "aborting" или ""
+	Dwelling       bool     `json:"dwelling,omitempty"` // автобус маршрута стоит на остановке
+	Yielding       bool     `json:"yielding,omitempty"` // машина съехала на обочину, пропуская спецмашину
+	Route          []string `json:"route,omitempty"`    // звенья маршрута в дорожной сети
+	lastBrakeTime  float64  // для отслеживания задержки
+	lastLaneChange float64  // время последнего перестроения
+	crashedUntil   float64  // время расчистки аварии (0 - машина не в аварии)
+	spawnTime      float64  // время появления на дороге или начала текущего круга
+	index          int      // место в Simulation.Cars, упорядоченном по убыванию позиции
+	reactionFactor float64  // множитель ReactionTime водителя (0 - по профилю водителя)
+	desiredSpeed   float64  // желаемая скорость вне зон ограничения
+	stops          int      // остановок в текущей поездке
+	stopped        bool     // машина стоит с последней засчитанной остановки
+	slowTime       float64  // секунды медленнее SlowSpeed в текущей поездке
+	freeFlowTime   float64  // секунды проезда пройденного пути с целевой скоростью
+	tripFuel       float64  // литры топлива в текущей поездке
+	tripCO2        float64  // кг CO₂ в текущей поездке
+	accel          float64  // м/с², ускорение на прошлом шаге (для ограничения рывка ACC)
+	brakeUntil     float64  // время окончания принудительного торможения (команда car)
+	pedals         Pedals   // педали машины игрока
+	overtakeTarget int      // обгоняемая машина
+	routeStep      int      // номер текущего звена в Route
+	linkEnter      float64  // время въезда на текущее звено сети
+	linkFreeFlow   float64  // freeFlowTime при въезде на текущее звено
+	busRoute       bool     // автобус маршрута Simulation.Buses
+	nextStop       int      // номер следующей остановки маршрута
+	dwellUntil     float64  // время окончания стоянки на остановке
+	stepFrom       float64  // позиция в начале текущего шага (см. enforceSpacing)
+	perception     perceptionBuffer
+}
+
+// forcedBraking сообщает, тормозит ли машина принудительно в момент now
+func (c *Car) forcedBraking(now float64) bool {
+	return now < c.brakeUntil
+}
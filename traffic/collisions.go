@@ -0,0 +1,128 @@
+package traffic
+
+// carByID возвращает машину на дороге по идентификатору
+func (s *Simulation) carByID(id int) *Car {
+	for _, car := range s.Cars {
+		if car.ID == id {
+			return car
+		}
+	}
+	return nil
+}
+
+// crashed сообщает, стоит ли машина после аварии
+func (c *Car) crashed() bool {
+	return c.crashedUntil > 0
+}
+
+// detectCollisions находит машины, въехавшие за шаг в лидера или препятствие.
+// Машина ставится вплотную за помехой, авария учитывается и публикуется событием
+// "collision"; при CrashClearance > 0 участники останавливаются на это время
+func (s *Simulation) detectCollisions() {
+	for _, car := range s.Cars {
+		if car.crashed() {
+			continue
+		}
+		leader := s.obstacleAhead(car, car.Lane)
+		if leader == nil || gapTo(car, leader) >= 0 {
+			continue
+		}
+
+		s.collide(car, leader)
+	}
+}
+
+// collide ставит машину вплотную за лидером с его скоростью, учитывает аварию и
+// публикует событие "collision"; при CrashClearance > 0 участники останавливаются
+func (s *Simulation) collide(car, leader *Car) {
+	s.placeBehind(car, leader)
+	car.Speed = leader.Speed
+	s.Collisions++
+	s.emit(Event{
+		Type:     "collision",
+		Time:     s.Time,
+		Cars:     []int{car.ID, leader.ID},
+		Lane:     car.Lane,
+		Position: car.Position,
+	})
+
+	if s.CrashClearance > 0 {
+		s.freezeCar(car)
+		if other := s.carByID(leader.ID); other != nil {
+			s.freezeCar(other)
+		}
+	}
+}
+
+// enforceSpacing гарантирует, что ни одна машина не заканчивает шаг ближе длины лидера
+// позади него. Лидер определяется по порядку s.Cars до шага, а расстояние до него - по
+// позициям в начале шага и пройденному за шаг пути, поэтому ловится и то, что пропускает
+// detectCollisions: машина, за шаг проскочившая сквозь лидера, и машина, оказавшаяся
+// впереди соседа, которого авария отодвинула назад. Нарушитель ставится вплотную за
+// лидером с его скоростью; для разбитых машин авария повторно не учитывается.
+func (s *Simulation) enforceSpacing() {
+	// На кольце лидер первой машины полосы проверяется последним; если его отодвинуло
+	// назад, проход повторяется
+	for pass := 0; s.spacingPass() && s.ring() && pass < len(s.Cars); pass++ {
+	}
+}
+
+// spacingPass выполняет один проход enforceSpacing и сообщает, сдвинул ли он хоть одну машину
+func (s *Simulation) spacingPass() bool {
+	moved := false
+	last := make([]*Car, s.Lanes)
+	if s.ring() {
+		// На кольце лидер первой машины полосы - последняя машина той же полосы
+		for i := len(s.Cars) - 1; i >= 0; i-- {
+			if car := s.Cars[i]; last[car.Lane] == nil {
+				last[car.Lane] = car
+			}
+		}
+	}
+	for _, car := range s.Cars {
+		leader := last[car.Lane]
+		last[car.Lane] = car
+		if leader == nil || leader == car || passesBy(car, leader) {
+			continue
+		}
+		// Допуск гасит ошибку округления у машины, уже поставленной вплотную
+		if s.spacing(car, leader)-leader.length() >= -1e-9 {
+			continue
+		}
+		moved = true
+		if car.crashed() {
+			s.placeBehind(car, leader)
+			continue
+		}
+		s.collide(car, leader)
+	}
+	return moved
+}
+
+// spacing возвращает расстояние от машины до лидера в конце шага: отрицательное, если
+// машина за шаг обогнала лидера. На кольце расстояние по ходу движения неоднозначно,
+// поэтому оно отсчитывается от порядка машин в начале шага.
+func (s *Simulation) spacing(car, leader *Car) float64 {
+	return s.aheadDistance(car.stepFrom, leader.stepFrom) +
+		s.shift(leader.stepFrom, leader.Position) - s.shift(car.stepFrom, car.Position)
+}
+
+// placeBehind ставит машину вплотную за лидером; на кольце позиция остаётся в [0, RoadLength)
+func (s *Simulation) placeBehind(car, leader *Car) {
+	car.Position = leader.Position - leader.length()
+	if s.ring() {
+		car.Position = s.aheadDistance(0, car.Position)
+	}
+}
+
+// freezeCar останавливает машину до расчистки места аварии
+func (s *Simulation) freezeCar(car *Car) {
+	car.Speed = 0
+	car.State = "crashed"
+	car.crashedUntil = s.Time + s.CrashClearance
+}
+
+// cleared сообщает, что место аварии расчищено и машину пора убрать с дороги
+func (s *Simulation) cleared(car *Car) bool {
+	return car.crashed() && s.Time >= car.crashedUntil
+}
@@ -0,0 +1,105 @@
+package traffic
+
+import (
+	"context"
+	"math"
+	"slices"
+)
+
+// ModelResult итоговые показатели прогона одной модели следования
+type ModelResult struct {
+	Model         string  `json:"model"`
+	Throughput    float64 `json:"throughput"`    // авто/ч на выезде
+	AvgSpeed      float64 `json:"avgSpeed"`      // м/с, среднее по машинам и шагам
+	BrakeEvents   int     `json:"brakeEvents"`   // суммарное число торможений
+	AvgTravelTime float64 `json:"avgTravelTime"` // с, среднее время проезда дороги (на кольце - круга)
+	AccelRMS      float64 `json:"accelRms"`      // м/с², среднеквадратичное ускорение
+}
+
+// cloneParams создаёт новую остановленную симуляцию с теми же параметрами и тем же
+// зерном генератора, запущенным с начала потока. Параметры переносятся через
+// SaveState/LoadState, поэтому копия не отстаёт от новых полей симуляции.
+func (s *Simulation) cloneParams() (*Simulation, error) {
+	data, err := s.SaveState()
+	if err != nil {
+		return nil, err
+	}
+	clone := New()
+	if err := clone.LoadState(data); err != nil {
+		return nil, err
+	}
+	clone.Reset()
+	// Прогоны без сервера не перематываются
+	clone.HistoryWindow = 0
+	return clone, nil
+}
+
+// runHeadless прогоняет симуляцию без сервера и таймера в течение duration секунд
+// симуляционного времени и собирает итоговые показатели; отмена ctx прерывает прогон
+func runHeadless(ctx context.Context, s *Simulation, duration float64) (ModelResult, error) {
+	dt := s.TimeStep
+	s.TimeScale = 1.0
+	s.Running = true
+
+	prevSpeed := make(map[int]float64)
+	brakes := make(map[int]int)
+	speedSum, speedSamples := 0.0, 0
+	accelSq, accelSamples := 0.0, 0
+
+	for s.Running && s.Time < duration {
+		if err := ctx.Err(); err != nil {
+			return ModelResult{}, err
+		}
+		s.Step(dt)
+		// В режиме сети машины едут по звеньям, а не по основной дороге
+		for _, car := range slices.Concat(s.Cars, s.networkCars()) {
+			if prev, ok := prevSpeed[car.ID]; ok {
+				accel := (car.Speed - prev) / dt
+				accelSq += accel * accel
+				accelSamples++
+			}
+			prevSpeed[car.ID] = car.Speed
+			brakes[car.ID] = car.BrakeCount
+			speedSum += car.Speed
+			speedSamples++
+		}
+	}
+
+	result := ModelResult{Model: s.Model}
+	if s.Time > 0 {
+		result.Throughput = float64(s.CarsCompleted) / s.Time * 3600
+	}
+	if s.CarsCompleted > 0 {
+		result.AvgTravelTime = s.TravelTime / float64(s.CarsCompleted)
+	}
+	if speedSamples > 0 {
+		result.AvgSpeed = speedSum / float64(speedSamples)
+	}
+	for _, n := range brakes {
+		result.BrakeEvents += n
+	}
+	if accelSamples > 0 {
+		result.AccelRMS = math.Sqrt(accelSq / float64(accelSamples))
+	}
+	return result, nil
+}
+
+// CompareModels прогоняет одинаковый спрос через каждую зарегистрированную модель
+// следования; отмена ctx прерывает сравнение и возвращает ctx.Err()
+func CompareModels(ctx context.Context, base *Simulation, duration float64) ([]ModelResult, error) {
+	models := Models()
+	results := make([]ModelResult, 0, len(models))
+	for _, model := range models {
+		sim, err := base.cloneParams()
+		if err != nil {
+			return nil, err
+		}
+		sim.Model = model
+		result, err := runHeadless(ctx, sim, duration)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
@@ -0,0 +1,53 @@
+package traffic
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// TestCompareModels проверяет, что сравнение возвращает ровно одну строку на каждую
+// зарегистрированную модель следования, в порядке регистрации
+func TestCompareModels(t *testing.T) {
+	RegisterModel("compare-test", func(s *Simulation) FollowingModel { return &simpleModel{s: s} })
+
+	tests := []struct {
+		name   string
+		config SimulationConfig
+	}{
+		{name: "straight road", config: SimulationConfig{Lanes: 2}},
+		{name: "ring road", config: SimulationConfig{Lanes: 1, RoadType: RoadRing, MaxCars: 40}},
+		{name: "nasch base", config: SimulationConfig{Lanes: 3, Model: "nasch"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			base := New(WithConfig(tt.config))
+			results, err := CompareModels(context.Background(), base, 60)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			models := Models()
+			if len(results) != len(models) {
+				t.Fatalf("got %d results for %d models", len(results), len(models))
+			}
+			for i, result := range results {
+				if result.Model != models[i] {
+					t.Errorf("result %d is for model %q, want %q", i, result.Model, models[i])
+				}
+			}
+			if base.Time != 0 || len(base.Cars) != 0 {
+				t.Errorf("comparison advanced the base simulation: t=%.1f, %d cars", base.Time, len(base.Cars))
+			}
+		})
+	}
+}
+
+// TestCompareModelsCanceled проверяет, что отменённый контекст прерывает сравнение
+func TestCompareModelsCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := CompareModels(ctx, New(), 3600); !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,102 @@
+package traffic
+
+// SimulationConfig конфигурация симуляции
+type SimulationConfig struct {
+	SpawnInterval      float64       `json:"spawnInterval"`      // секунды
+	TimeStep           *float64      `json:"dt"`                 // секунды, наибольший шаг физики (MinTimeStep..MaxTimeStep)
+	Demand             float64       `json:"demand"`             // авто/ч, если больше нуля - задаёт интервал вместо spawnInterval
+	SpawnDistribution  string        `json:"spawnDistribution"`  // "fixed", "poisson" или "uniform-jitter"
+	MinSpeed           float64       `json:"minSpeed"`           // км/ч
+	MaxSpeed           float64       `json:"maxSpeed"`           // км/ч
+	MaxCars            int           `json:"maxCars"`            // максимальное количество машин
+	SpawnSpeedMode     string        `json:"spawnSpeedMode"`     // "random" или "density" (ниже скорость при плотном въезде)
+	SpeedLimit         float64       `json:"speedLimit"`         // км/ч, отрицательное значение снимает ограничение
+	DemandProfile      []DemandPoint `json:"demandProfile"`      // пустой список отключает профиль
+	DemandBins         []float64     `json:"demandBins"`         // авто/ч по интервалам demandBinLength, заменяет demandProfile
+	DemandBinLength    float64       `json:"demandBinLength"`    // секунды, длительность интервала demandBins (0 - 300)
+	Lanes              int           `json:"lanes"`              // число полос (1..MaxLanes)
+	Model              string        `json:"model"`              // модель следования: "simple", "idm", "nasch" или "ovm"
+	IDM                *IDMParams    `json:"idm"`                // параметры IDM, нулевые поля не меняются
+	NaSch              *NaSchParams  `json:"nasch"`              // параметры клеточного автомата, нулевые поля не меняются
+	OVM                *OVMParams    `json:"ovm"`                // параметры модели оптимальной скорости, нулевые поля не меняются
+	LaneChange         string        `json:"laneChange"`         // правило перестроения: "space" или "mobil"
+	MOBIL              *MOBILParams  `json:"mobil"`              // параметры перестроения MOBIL, нулевые поля не меняются
+	Seed               *int64        `json:"seed"`               // зерно генератора, перезапускает поток случайных чисел
+	OnRamp             *OnRampConfig `json:"onRamp"`             // въезд с рампы, нулевой интервал убирает рампу
+	RoadType           string        `json:"roadType"`           // "straight", "ring", "twoway" или "network"
+	Network            *Network      `json:"network"`            // граф дорожной сети для roadType "network", заменяет прежнюю сеть с машинами
+	OncomingDemand     *float64      `json:"oncomingDemand"`     // авто/ч встречного потока двусторонней дороги
+	Weather            string        `json:"weather"`            // "dry", "rain", "snow" или "fog" (пусто - не менять)
+	TruckPercentage    *float64      `json:"truckPercentage"`    // доля грузовиков среди новых машин, %
+	BusPercentage      *float64      `json:"busPercentage"`      // доля автобусов среди новых машин, %
+	AggressiveDrivers  *float64      `json:"aggressiveDrivers"`  // доля агрессивных водителей среди новых машин, %
+	CautiousDrivers    *float64      `json:"cautiousDrivers"`    // доля осторожных водителей среди новых машин, %
+	ReactionSpread     *float64      `json:"reactionSpread"`     // коэффициент вариации времени реакции новых водителей (0 - одинаковое)
+	AVShare            *float64      `json:"avShare"`            // доля автоматизированных машин (ACC) среди новых машин, %
+	CrashClearance     *float64      `json:"crashClearance"`     // секунды остановки участников аварии (0 - без остановки)
+	WaveThreshold      *float64      `json:"waveThreshold"`      // км/ч, скорость машин в волне «стоп-старт» (0 - волны не ищутся)
+	RecordTrajectories *bool         `json:"recordTrajectories"` // включает (с новой записью) или выключает запись траекторий
+	HistoryWindow      *float64      `json:"historyWindow"`      // секунды симуляции, хранимые для перемотки (0 - не хранятся)
+}
+
+// OnRampConfig параметры въезда с рампы
+type OnRampConfig struct {
+	Position      float64         `json:"position"`      // метры, точка слияния
+	SpawnInterval float64         `json:"spawnInterval"` // секунды между прибытиями на рампу
+	MergeSpeed    float64         `json:"mergeSpeed"`    // км/ч, по умолчанию 60
+	Metering      *MeteringConfig `json:"metering"`      // светофор на рампе с регулированием ALINEA (nil - нет)
+}
+
+// DetectorConfig параметры команды размещения детекторов
+type DetectorConfig struct {
+	Positions []float64 `json:"positions"` // метры
+	Spacing   float64   `json:"spacing"`   // метры, детекторы через равные промежутки (0 - нет)
+	Interval  float64   `json:"interval"`  // секунды агрегации (0 - не менять)
+}
+
+// BlockageConfig параметры команды перекрытия дороги
+type BlockageConfig struct {
+	Position float64 `json:"position"` // метры
+	Span     float64 `json:"span"`     // метры
+	Lanes    []int   `json:"lanes"`    // перекрытые полосы (пусто = все)
+	Duration float64 `json:"duration"` // секунды
+}
+
+// IncidentConfig параметры команды инцидента
+type IncidentConfig struct {
+	Type     string  `json:"type"`     // stalled (по умолчанию), closure или shoulder
+	Position float64 `json:"position"` // метры
+	Duration float64 `json:"duration"` // секунды
+	Lane     int     `json:"lane"`     // полоса заглохшей машины
+	Vehicle  string  `json:"vehicle"`  // тип заглохшей машины или машины на обочине (car)
+	Lanes    []int   `json:"lanes"`    // перекрытые полосы closure (пусто = все)
+	Span     float64 `json:"span"`     // метры, протяжённость closure (CarLength)
+
+	Rubbernecking float64 `json:"rubbernecking"` // доля снижения целевой скорости у машины на обочине (0 - DefaultRubbernecking)
+}
+
+// CarConfig параметры команды добавления машины вручную
+type CarConfig struct {
+	Position    float64 `json:"position"`    // метры
+	Lane        int     `json:"lane"`        // полоса
+	Speed       float64 `json:"speed"`       // км/ч, начальная скорость
+	TargetSpeed float64 `json:"targetSpeed"` // км/ч, желаемая скорость (0 - равна начальной)
+	Type        string  `json:"type"`        // тип машины (car)
+	Driver      string  `json:"driver"`      // профиль водителя (normal)
+}
+
+// CarControl параметры команды управления отдельной машиной
+type CarControl struct {
+	ID          int      `json:"id"`          // идентификатор машины
+	TargetSpeed *float64 `json:"targetSpeed"` // км/ч, новая желаемая скорость (nil - не менять)
+	Brake       float64  `json:"brake"`       // секунды торможения до остановки и стоянки (0 - нет)
+}
+
+// PhysicsConfig конфигурация параметров физики
+type PhysicsConfig struct {
+	ReactionTime      float64  `json:"reactionTime"`      // секунды
+	SafetyMultiplier  float64  `json:"safetyMultiplier"`  // коэффициент
+	BrakeDeceleration float64  `json:"brakeDeceleration"` // м/с²
+	Acceleration      float64  `json:"acceleration"`      // м/с²
+	HysteresisBand    *float64 `json:"hysteresisBand"`    // доля безопасной дистанции, 0 - без гистерезиса (nil - не менять)
+}
@@ -0,0 +1,36 @@
+package traffic
+
+const (
+	// CongestionCars наименьшее число машин в очереди остановившихся машин, считающейся затором
+	CongestionCars = 10
+	// CongestionDuration секунды, которые затор должен продержаться, чтобы о нём было объявлено
+	CongestionDuration = 60.0
+)
+
+// updateCongestion следит за устойчивым затором: на дороге CongestionDuration секунд
+// подряд есть очередь остановившихся машин (см. updateQueues) не короче CongestionCars
+// машин. Начало затора сообщается событием "congestion", конец - событием
+// "congestion:cleared"; оба несут самую длинную очередь шага.
+func (s *Simulation) updateCongestion(longest Queue) {
+	jammed := longest.Cars >= CongestionCars
+	longest.Time = s.Time
+
+	switch {
+	case jammed && !s.jammed:
+		s.jammed, s.jammedSince = true, s.Time
+	case jammed && !s.congested && s.Time-s.jammedSince >= CongestionDuration:
+		s.congested = true
+		s.emit(Event{Type: "congestion", Time: s.Time, Lane: longest.Lane, Position: longest.Head, Queue: &longest})
+	case !jammed && s.jammed:
+		s.jammed = false
+		if s.congested {
+			s.congested = false
+			s.emit(Event{Type: "congestion:cleared", Time: s.Time, Lane: longest.Lane, Position: longest.Head, Queue: &longest})
+		}
+	}
+}
+
+// resetCongestion забывает начало затора и объявленный затор
+func (s *Simulation) resetCongestion() {
+	s.jammed, s.jammedSince, s.congested = false, 0, false
+}
@@ -0,0 +1,125 @@
+package traffic
+
+import "math"
+
+// DefaultCrossingTime секунды перехода дороги по умолчанию
+const DefaultCrossingTime = 8.0
+
+// Crosswalk нерегулируемый пешеходный переход в точке Position через все полосы.
+// Пешеходы подходят с пуассоновскими интервалами со средним 3600/Rate секунд и ждут,
+// пока каждая приближающаяся машина сможет остановиться перед переходом; затем все
+// ждущие переходят вместе CrossingTime секунд, и переход работает как стоп-линия.
+type Crosswalk struct {
+	Position     float64 `json:"position"`     // метры
+	Rate         float64 `json:"rate"`         // пешеходов/ч (0 - пешеходов нет)
+	CrossingTime float64 `json:"crossingTime"` // секунды перехода (0 - DefaultCrossingTime)
+	Waiting      int     `json:"waiting"`      // пешеходов ждут у перехода
+	Crossing     int     `json:"crossing"`     // пешеходов на переходе
+	Remaining    float64 `json:"remaining"`    // секунды до освобождения перехода
+	Crossings    int     `json:"crossings"`    // переходов группами с начала прогона
+	Pedestrians  int     `json:"pedestrians"`  // перешедших пешеходов с начала прогона
+	WaitTime     float64 `json:"waitTime"`     // пешеходо-секунды ожидания у перехода
+
+	nextArrival float64 // время подхода следующего пешехода (<0 - ещё не выбрано)
+}
+
+// SetCrosswalks задаёт пешеходные переходы (предыдущий набор заменяется); переходы
+// вне дороги отбрасываются, показатели начинаются заново
+func (s *Simulation) SetCrosswalks(crosswalks []Crosswalk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	valid := make([]*Crosswalk, 0, len(crosswalks))
+	for _, c := range crosswalks {
+		if c.Position <= 0 || c.Position >= RoadLength {
+			continue
+		}
+		if c.CrossingTime <= 0 {
+			c.CrossingTime = DefaultCrossingTime
+		}
+		valid = append(valid, &Crosswalk{Position: c.Position, Rate: math.Max(0, c.Rate), CrossingTime: c.CrossingTime, nextArrival: -1})
+	}
+	s.Crosswalks = valid
+}
+
+// resetCrosswalks очищает переходы от пешеходов и обнуляет их показатели
+func (s *Simulation) resetCrosswalks() {
+	for _, c := range s.Crosswalks {
+		*c = Crosswalk{Position: c.Position, Rate: c.Rate, CrossingTime: c.CrossingTime, nextArrival: -1}
+	}
+}
+
+// updateCrosswalks продвигает пешеходов на dt секунд: переход освобождается по
+// истечении времени перехода, подошедшие пешеходы встают в ожидание, а ждущие
+// начинают переход, как только машины могут перед ним остановиться. Начало перехода
+// публикуется событием "crossing" с числом пешеходов.
+func (s *Simulation) updateCrosswalks(dt float64) {
+	for _, c := range s.Crosswalks {
+		if c.Crossing > 0 {
+			c.Remaining -= dt
+			if c.Remaining > 0 {
+				continue
+			}
+			c.Pedestrians += c.Crossing
+			c.Crossing, c.Remaining = 0, 0
+		}
+		if c.Rate > 0 {
+			if c.nextArrival < 0 {
+				c.nextArrival = s.Time + 3600/c.Rate*s.rng.ExpFloat64()
+			}
+			for c.nextArrival <= s.Time {
+				c.Waiting++
+				c.nextArrival += 3600 / c.Rate * s.rng.ExpFloat64()
+			}
+		}
+		c.WaitTime += float64(c.Waiting) * dt
+		if c.Waiting == 0 || !s.carsCanStop(c.Position) {
+			continue
+		}
+		c.Crossing, c.Waiting, c.Remaining = c.Waiting, 0, c.CrossingTime
+		c.Crossings++
+		s.emit(Event{Type: "crossing", Time: s.Time, Position: c.Position, Pedestrians: c.Crossing})
+	}
+}
+
+// carsCanStop сообщает, может ли каждая машина перед точкой position остановиться
+// до неё с учётом времени реакции водителя и замедления своего типа и нет ли машины
+// на самой точке
+func (s *Simulation) carsCanStop(position float64) bool {
+	for _, car := range s.Cars {
+		if car.crashed() {
+			continue
+		}
+		if car.Position >= position && car.Position-car.length() < position {
+			return false
+		}
+		if d := s.aheadDistance(car.Position, position); d > 0 && d < car.Speed*car.ReactionDelay+car.Speed*car.Speed/(2*s.carBraking(car)) {
+			return false
+		}
+	}
+	return true
+}
+
+// crosswalkAhead возвращает ближайший впереди автомобиля переход, на котором есть пешеходы
+func (s *Simulation) crosswalkAhead(car *Car) (float64, bool) {
+	nearest, found := 0.0, false
+	best := math.MaxFloat64
+	for _, c := range s.Crosswalks {
+		if c.Crossing == 0 {
+			continue
+		}
+		if distance := s.aheadDistance(car.Position, c.Position); distance > 0 && distance < best {
+			nearest, found, best = c.Position, true, distance
+		}
+	}
+	return nearest, found
+}
+
+// crosswalkStates возвращает копии переходов для рассылки клиентам
+func (s *Simulation) crosswalkStates() []Crosswalk {
+	states := make([]Crosswalk, len(s.Crosswalks))
+	for i, c := range s.Crosswalks {
+		states[i] = *c
+	}
+	return states
+}
@@ -0,0 +1,89 @@
+package traffic
+
+import "sort"
+
+// Распределения интервалов между появлениями машин
+const (
+	SpawnFixed   = "fixed"          // ровно через интервал
+	SpawnPoisson = "poisson"        // пуассоновский поток: экспоненциальные интервалы с тем же средним
+	SpawnJitter  = "uniform-jitter" // равномерно от половины до полутора интервалов
+)
+
+// DefaultDemandBin секунды, длительность интервала расписания спроса по умолчанию
+const DefaultDemandBin = 300.0
+
+// DemandPoint точка кусочно-постоянного профиля спроса: начиная с момента From
+// машины появляются с интервалом SpawnInterval или с интенсивностью Demand;
+// точка без интервала и интенсивности приостанавливает появление машин
+type DemandPoint struct {
+	From          float64 `json:"from"`          // секунды симуляции
+	SpawnInterval float64 `json:"spawnInterval"` // секунды между машинами
+	Demand        float64 `json:"demand"`        // авто/ч, если больше нуля - задаёт интервал вместо spawnInterval
+}
+
+// setDemandProfile задаёт профиль спроса; интенсивность точек пересчитывается в интервал,
+// точки с отрицательными значениями отбрасываются
+func (s *Simulation) setDemandProfile(points []DemandPoint) {
+	profile := make([]DemandPoint, 0, len(points))
+	for _, p := range points {
+		if p.SpawnInterval < 0 || p.Demand < 0 {
+			continue
+		}
+		if p.Demand > 0 {
+			p.SpawnInterval = 3600 / p.Demand
+		} else if p.SpawnInterval > 0 {
+			p.Demand = 3600 / p.SpawnInterval
+		}
+		profile = append(profile, p)
+	}
+	sort.Slice(profile, func(i, j int) bool { return profile[i].From < profile[j].From })
+	s.DemandProfile = profile
+}
+
+// demandBins возвращает профиль спроса по расписанию интенсивностей rates (авто/ч)
+// для последовательных интервалов длиной binLength секунд (0 - DefaultDemandBin)
+func demandBins(rates []float64, binLength float64) []DemandPoint {
+	if binLength <= 0 {
+		binLength = DefaultDemandBin
+	}
+	points := make([]DemandPoint, len(rates))
+	for i, rate := range rates {
+		points[i] = DemandPoint{From: float64(i) * binLength, Demand: rate}
+	}
+	return points
+}
+
+// effectiveDemand возвращает действующую интенсивность появления машин, авто/ч
+// (0 - появление приостановлено профилем спроса)
+func (s *Simulation) effectiveDemand() float64 {
+	if interval := s.effectiveSpawnInterval(); interval > 0 {
+		return 3600 / interval
+	}
+	return 0
+}
+
+// effectiveSpawnInterval возвращает интервал появления машин в текущий момент:
+// значение последней наступившей точки профиля спроса или базовый SpawnInterval
+// (0 - появление приостановлено)
+func (s *Simulation) effectiveSpawnInterval() float64 {
+	interval := s.SpawnInterval
+	for _, p := range s.DemandProfile {
+		if p.From > s.Time {
+			break
+		}
+		interval = p.SpawnInterval
+	}
+	return interval
+}
+
+// nextSpawnGap возвращает множитель действующего интервала до появления следующей машины
+// согласно SpawnDistribution; среднее значение множителя равно единице
+func (s *Simulation) nextSpawnGap() float64 {
+	switch s.SpawnDistribution {
+	case SpawnPoisson:
+		return s.rng.ExpFloat64()
+	case SpawnJitter:
+		return 0.5 + s.rng.Float64()
+	}
+	return 1
+}
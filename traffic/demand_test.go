@@ -0,0 +1,57 @@
+package traffic
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDemandProfile проверяет, что число появившихся машин на каждом отрезке профиля
+// спроса соответствует интенсивности его точки, включая паузу без появления машин
+func TestDemandProfile(t *testing.T) {
+	const window = 300.0 // секунды, длина каждого отрезка профиля
+	tests := []struct {
+		name  string
+		rates []float64 // авто/ч на последовательных отрезках
+	}{
+		{name: "rising demand", rates: []float64{600, 1200, 2400}},
+		{name: "pause in the middle", rates: []float64{1200, 0, 1800}},
+		{name: "peak and decline", rates: []float64{360, 3000, 720}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed := int64(5)
+			noHistory := 0.0
+			sim := New(WithConfig(SimulationConfig{
+				SpawnInterval: 1,
+				MinSpeed:      90,
+				MaxSpeed:      110,
+				MaxCars:       100000,
+				Lanes:         3,
+				Seed:          &seed,
+				HistoryWindow: &noHistory,
+			}))
+			sim.setDemandProfile(demandBins(tt.rates, window))
+			sim.Start()
+
+			made := 0
+			for i, rate := range tt.rates {
+				for sim.Time < (float64(i)+0.5)*window {
+					sim.Step(DefaultStep)
+				}
+				if got := sim.effectiveDemand(); math.Abs(got-rate) > 1e-6 {
+					t.Errorf("segment %d: effective demand %.1f veh/h, want %.1f", i, got, rate)
+				}
+				for sim.Time < float64(i+1)*window-1e-9 {
+					sim.Step(DefaultStep)
+				}
+				spawned := sim.TotalCarsMade - made
+				made = sim.TotalCarsMade
+				// Фиксированный интервал: расхождение не больше одной машины на границах отрезка
+				want := rate * window / 3600
+				if math.Abs(float64(spawned)-want) > 1 {
+					t.Errorf("segment %d (%.0f veh/h): %d cars spawned, want %.0f", i, rate, spawned, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package traffic
+
+import "math"
+
+const (
+	// DefaultDetectorInterval секунды агрегации детекторов по умолчанию
+	DefaultDetectorInterval = 60.0
+	// MaxDetectorSamples число хранимых интервалов на детектор
+	MaxDetectorSamples = 240
+)
+
+// DetectorSample показатели детектора за один интервал агрегации
+type DetectorSample struct {
+	Start     float64 `json:"start"`     // секунды симуляции
+	End       float64 `json:"end"`       // секунды симуляции
+	Count     int     `json:"count"`     // машин проехало
+	Flow      float64 `json:"flow"`      // авто/ч
+	MeanSpeed float64 `json:"meanSpeed"` // м/с, средняя скорость проехавших машин
+	Occupancy float64 `json:"occupancy"` // доля времени, когда над петлёй была машина (0..1, на полосу)
+}
+
+// Detector виртуальная индукционная петля поперёк всех полос в точке Position
+type Detector struct {
+	Position float64          `json:"position"` // метры
+	Series   []DetectorSample `json:"series"`   // завершённые интервалы, старые первыми
+
+	count         int
+	speedSum      float64
+	occupied      float64 // машино-секунды над петлёй в текущем интервале
+	intervalStart float64
+}
+
+// DetectorState детектор с последним завершённым интервалом для рассылки клиентам
+type DetectorState struct {
+	Position float64         `json:"position"`
+	Last     *DetectorSample `json:"last"` // nil, пока не завершён первый интервал
+}
+
+// SetDetectors размещает детекторы в точках config.Positions и, если задан
+// config.Spacing, через каждые Spacing метров; накопленные ряды сбрасываются
+func (s *Simulation) SetDetectors(config DetectorConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	positions := append([]float64(nil), config.Positions...)
+	if config.Spacing > 0 {
+		for p := config.Spacing; p < RoadLength; p += config.Spacing {
+			positions = append(positions, p)
+		}
+	}
+	detectors := make([]*Detector, 0, len(positions))
+	for _, p := range positions {
+		if p > 0 && p < RoadLength {
+			detectors = append(detectors, &Detector{Position: p, intervalStart: s.Time})
+		}
+	}
+	s.Detectors = detectors
+	if config.Interval > 0 {
+		s.DetectorInterval = config.Interval
+	}
+}
+
+// updateDetectors учитывает проезд машины через петли на отрезке (from, to]
+// и время, которое она провела над петлями за шаг dt
+func (s *Simulation) updateDetectors(car *Car, from, to, dt float64) {
+	for _, d := range s.Detectors {
+		if from < d.Position && to >= d.Position {
+			d.count++
+			d.speedSum += car.Speed
+		}
+		if to >= d.Position && to-car.length() <= d.Position {
+			d.occupied += dt
+		}
+	}
+}
+
+// closeDetectorIntervals завершает интервалы агрегации, длительность которых истекла
+func (s *Simulation) closeDetectorIntervals() {
+	for _, d := range s.Detectors {
+		length := s.Time - d.intervalStart
+		if length < s.DetectorInterval {
+			continue
+		}
+		sample := DetectorSample{
+			Start:     d.intervalStart,
+			End:       s.Time,
+			Count:     d.count,
+			Flow:      float64(d.count) / length * 3600,
+			Occupancy: math.Min(1, d.occupied/(length*float64(s.Lanes))),
+		}
+		if d.count > 0 {
+			sample.MeanSpeed = d.speedSum / float64(d.count)
+		}
+		d.Series = append(d.Series, sample)
+		if len(d.Series) > MaxDetectorSamples {
+			d.Series = d.Series[len(d.Series)-MaxDetectorSamples:]
+		}
+		d.count, d.speedSum, d.occupied, d.intervalStart = 0, 0, 0, s.Time
+	}
+}
+
+// resetDetectors очищает ряды и накопленные показатели детекторов
+func (s *Simulation) resetDetectors() {
+	for _, d := range s.Detectors {
+		d.Series = nil
+		d.count, d.speedSum, d.occupied, d.intervalStart = 0, 0, 0, 0
+	}
+}
+
+// detectorStates возвращает последние завершённые интервалы детекторов
+func (s *Simulation) detectorStates() []DetectorState {
+	states := make([]DetectorState, len(s.Detectors))
+	for i, d := range s.Detectors {
+		states[i].Position = d.Position
+		if n := len(d.Series); n > 0 {
+			last := d.Series[n-1]
+			states[i].Last = &last
+		}
+	}
+	return states
+}
+
+// DetectorSeries возвращает копию рядов всех детекторов
+func (s *Simulation) DetectorSeries() []Detector {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	series := make([]Detector, len(s.Detectors))
+	for i, d := range s.Detectors {
+		series[i] = Detector{Position: d.Position, Series: append([]DetectorSample(nil), d.Series...)}
+	}
+	return series
+}
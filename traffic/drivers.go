@@ -0,0 +1,63 @@
+package traffic
+
+// Профили водителей
+const (
+	DriverNormal     = "normal"
+	DriverAggressive = "aggressive"
+	DriverCautious   = "cautious"
+	DriverAutomated  = "automated" // автоматизированная машина, см. accAcceleration
+)
+
+// DriverProfile множители параметров водителя относительно параметров симуляции
+// и типа транспортного средства
+type DriverProfile struct {
+	Headway      float64 `json:"headway"`      // желаемая дистанция: безопасная дистанция, интервал IDM
+	Acceleration float64 `json:"acceleration"` // максимальное ускорение
+	ReactionTime float64 `json:"reactionTime"` // время реакции
+	TargetSpeed  float64 `json:"targetSpeed"`  // желаемая скорость относительно выбранной при появлении
+}
+
+// DriverProfiles параметры известных профилей водителей
+var DriverProfiles = map[string]DriverProfile{
+	DriverAggressive: {Headway: 0.7, Acceleration: 1.3, ReactionTime: 0.7, TargetSpeed: 1.1},
+	DriverNormal:     {Headway: 1, Acceleration: 1, ReactionTime: 1, TargetSpeed: 1},
+	DriverCautious:   {Headway: 1.4, Acceleration: 0.75, ReactionTime: 1.4, TargetSpeed: 0.9},
+	DriverAutomated:  {Headway: 1, Acceleration: 1, ReactionTime: 0, TargetSpeed: 1},
+}
+
+// driverProfile возвращает профиль водителя автомобиля (обычный для препятствий и неизвестных профилей)
+func driverProfile(car *Car) DriverProfile {
+	if p, ok := DriverProfiles[car.Driver]; ok {
+		return p
+	}
+	return DriverProfiles[DriverNormal]
+}
+
+// spawnDriver выбирает профиль водителя новой машины: автоматизированная по доле AVShare,
+// иначе по долям AggressiveDrivers и CautiousDrivers. Генератор не используется, если доли нулевые.
+func (s *Simulation) spawnDriver() string {
+	if s.spawnAutomated() {
+		return DriverAutomated
+	}
+	if s.AggressiveDrivers <= 0 && s.CautiousDrivers <= 0 {
+		return DriverNormal
+	}
+	roll := s.rng.Float64() * 100
+	switch {
+	case roll < s.AggressiveDrivers:
+		return DriverAggressive
+	case roll < s.AggressiveDrivers+s.CautiousDrivers:
+		return DriverCautious
+	default:
+		return DriverNormal
+	}
+}
+
+// carReactionTime возвращает время реакции водителя автомобиля
+func (s *Simulation) carReactionTime(car *Car) float64 {
+	factor := car.reactionFactor
+	if factor <= 0 {
+		factor = driverProfile(car).ReactionTime
+	}
+	return s.ReactionTime * factor
+}
@@ -0,0 +1,133 @@
+package traffic
+
+import "errors"
+
+const (
+	EmergencyYieldDistance   = 200.0 // метры, с какого расстояния водители замечают спецмашину позади
+	EmergencyPreemptDistance = 300.0 // метры до стоп-линии, с которых светофор даёт спецмашине зелёный
+	emergencyYieldSpeed      = 20.0  // км/ч, скорость машины, съехавшей на обочину
+	emergencyClearance       = 10.0  // метры перед машиной, которые спецмашина должна проехать, чтобы та вернулась на полосу
+)
+
+// EmergencyConfig параметры команды выпуска спецмашины
+type EmergencyConfig struct {
+	Position float64 `json:"position"` // метры, где появляется спецмашина
+	Speed    float64 `json:"speed"`    // км/ч, желаемая скорость (0 - наибольшая для типа)
+}
+
+// emergency сообщает, спецмашина ли это
+func (c *Car) emergency() bool {
+	return c.Type == VehicleEmergency
+}
+
+// DispatchEmergency выпускает спецмашину в точке Position на крайнюю левую полосу
+// (на двусторонней дороге - на свою). Спецмашина не перестраивается и не соблюдает
+// ограничения скорости, машины впереди уступают ей дорогу, а светофоры на подъезде
+// дают ей зелёный. Появление публикуется событием "emergency". Возвращает
+// идентификатор машины; на кольце и в дорожной сети спецмашин нет.
+func (s *Simulation) DispatchEmergency(config EmergencyConfig) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.ring() || s.networkMode() {
+		return 0, errors.New("emergency vehicles are only available on straight and two-way roads")
+	}
+	vt := VehicleTypes[VehicleEmergency]
+	speed := kmhToMs(vt.MaxSpeed)
+	if config.Speed > 0 {
+		speed = kmhToMs(config.Speed)
+	}
+	lane := s.Lanes - 1
+	if s.twoWay() {
+		lane = 0
+	}
+	car := &Car{
+		ID:             s.nextCarID,
+		Type:           VehicleEmergency,
+		Driver:         DriverNormal,
+		Length:         vt.Length,
+		Lane:           lane,
+		Position:       config.Position,
+		Speed:          speed,
+		TargetSpeed:    speed,
+		Color:          "#FFFFFF",
+		State:          "normal",
+		ReactionDelay:  s.ReactionTime,
+		spawnTime:      s.Time,
+		reactionFactor: 1,
+		desiredSpeed:   speed,
+	}
+	s.adaptTargetSpeed(car)
+	s.insertCar(car)
+	s.nextCarID++
+	s.TotalCarsMade++
+	s.emitExport(Event{Type: "car:spawned", Time: s.Time, Cars: []int{car.ID}, Lane: car.Lane, Position: car.Position, Speed: car.Speed})
+	s.emit(Event{Type: "emergency", Time: s.Time, Cars: []int{car.ID}, Lane: car.Lane, Position: car.Position})
+	return car.ID, nil
+}
+
+// emergencyVehicles возвращает спецмашины на дороге
+func (s *Simulation) emergencyVehicles() []*Car {
+	var vehicles []*Car
+	for _, car := range s.Cars {
+		if car.emergency() && !car.crashed() {
+			vehicles = append(vehicles, car)
+		}
+	}
+	return vehicles
+}
+
+// approachingEmergency возвращает спецмашину, которая догоняет машину или проезжает
+// мимо неё и ещё не отъехала вперёд на emergencyClearance (nil - такой нет)
+func approachingEmergency(car *Car, vehicles []*Car) *Car {
+	for _, e := range vehicles {
+		if e.Position > car.Position-car.length()-EmergencyYieldDistance && gapTo(car, e) < emergencyClearance {
+			return e
+		}
+	}
+	return nil
+}
+
+// yieldToEmergency уступает дорогу спецмашине: машина на её полосе перестраивается
+// вправо, а если не может (или правее полосы нет) - съезжает на обочину и сбавляет
+// скорость, и спецмашина проезжает мимо неё. Машины на других полосах не
+// перестраиваются, пока спецмашина рядом. Сообщает, рядом ли спецмашина.
+func (s *Simulation) yieldToEmergency(car *Car, vehicles []*Car) bool {
+	car.Yielding = false
+	if len(vehicles) == 0 || car.emergency() || car.crashed() || car.Player {
+		return false
+	}
+	e := approachingEmergency(car, vehicles)
+	if e == nil {
+		return false
+	}
+	if car.Lane != e.Lane {
+		// Обгоняющая по встречной полосе машина завершает обгон как обычно
+		return !s.twoWay()
+	}
+	if car.Lane > 0 && s.gapAcceptable(car, car.Lane-1) {
+		car.Lane--
+		car.lastLaneChange = s.Time
+		s.LaneChanges++
+		return true
+	}
+	car.Yielding = true
+	return true
+}
+
+// passesBy сообщает, проезжает ли машина мимо лидера, не считая его препятствием:
+// спецмашина - мимо съехавшей на обочину машины, и наоборот
+func passesBy(car, leader *Car) bool {
+	return (car.emergency() && leader.Yielding) || (car.Yielding && leader.emergency())
+}
+
+// preempted сообщает, даёт ли светофор зелёный одной из спецмашин vehicles,
+// подъезжающей к стоп-линии
+func preempted(l TrafficLight, vehicles []*Car) bool {
+	for _, e := range vehicles {
+		if d := l.Position - e.Position; d > 0 && d <= EmergencyPreemptDistance {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package traffic
+
+// FuelParams параметры мощностной модели расхода топлива Акчелика (ARRB):
+//
+//	f = α + β1·R·v + β2·M·a²·v/1000 (последнее слагаемое при a > 0), если R > 0, иначе f = α
+//	R = b1 + b2·v² + M·a/1000 + M·g·G/1000 - сила тяги, кН
+//
+// где v - скорость (м/с), a - ускорение (м/с²), G - уклон (доля), f - расход (мл/с)
+type FuelParams struct {
+	Mass  float64 `json:"mass"`  // кг
+	Idle  float64 `json:"idle"`  // α, мл/с на холостом ходу
+	Beta1 float64 `json:"beta1"` // β1, мл/кДж
+	Beta2 float64 `json:"beta2"` // β2, мл/(кДж·м/с²)
+	B1    float64 `json:"b1"`    // кН, сопротивление качению
+	B2    float64 `json:"b2"`    // кН/(м/с)², аэродинамическое сопротивление
+	CO2   float64 `json:"co2"`   // кг CO₂ на литр топлива
+}
+
+// FuelModels ориентировочные параметры модели расхода для типов транспортных средств:
+// легковые машины - бензин, грузовики и автобусы - дизель
+var FuelModels = map[string]FuelParams{
+	VehicleCar:   {Mass: 1400, Idle: 0.444, Beta1: 0.09, Beta2: 0.03, B1: 0.333, B2: 0.00108, CO2: 2.31},
+	VehicleTruck: {Mass: 11000, Idle: 1.35, Beta1: 0.085, Beta2: 0.03, B1: 0.86, B2: 0.0025, CO2: 2.68},
+	VehicleBus:   {Mass: 13000, Idle: 1.2, Beta1: 0.085, Beta2: 0.03, B1: 1.0, B2: 0.0025, CO2: 2.68},
+}
+
+// fuelParams возвращает параметры модели расхода для типа автомобиля
+func fuelParams(car *Car) FuelParams {
+	if p, ok := FuelModels[car.Type]; ok {
+		return p
+	}
+	return FuelModels[VehicleCar]
+}
+
+// fuelRate возвращает мгновенный расход топлива на уклоне grade, мл/с
+func (p FuelParams) fuelRate(v, a, grade float64) float64 {
+	traction := p.B1 + p.B2*v*v + p.Mass*(a+gravity*grade)/1000
+	if traction <= 0 {
+		return p.Idle
+	}
+	rate := p.Idle + p.Beta1*traction*v
+	if a > 0 {
+		rate += p.Beta2 * p.Mass * a * a * v / 1000
+	}
+	return rate
+}
+
+// burnFuel учитывает расход топлива и выброс CO₂ машины за шаг dt, в начале которого
+// её скорость была prevSpeed, в показателях машины, её текущей поездки и всего прогона
+func (s *Simulation) burnFuel(car *Car, prevSpeed, dt float64) {
+	if dt <= 0 {
+		return
+	}
+	p := fuelParams(car)
+	fuel := p.fuelRate(car.Speed, (car.Speed-prevSpeed)/dt, s.gradeAt(car.Position)) * dt / 1000
+	co2 := fuel * p.CO2
+	car.Fuel += fuel
+	car.CO2 += co2
+	car.tripFuel += fuel
+	car.tripCO2 += co2
+	s.FuelUsed += fuel
+	s.CO2Emitted += co2
+}
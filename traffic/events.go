@@ -0,0 +1,83 @@
+package traffic
+
+const (
+	// maxPendingEvents предел очереди событий, не забранных через DrainEvents
+	maxPendingEvents = 1000
+	// maxPendingExports предел очереди подробных событий, не забранных через DrainExportEvents
+	maxPendingExports = 20000
+)
+
+// Event событие симуляции для рассылки клиентам
+type Event struct {
+	Type     string  `json:"type"`           // тип события, например "collision"
+	Time     float64 `json:"time"`           // секунды симуляции
+	Cars     []int   `json:"cars"`           // участники (-1 - неподвижное препятствие)
+	Lane     int     `json:"lane"`           // полоса
+	Position float64 `json:"position"`       // метры
+	Link     string  `json:"link,omitempty"` // звено дорожной сети, где произошло событие
+
+	Pedestrians int `json:"pedestrians,omitempty"` // пешеходов начали переход для события "crossing"
+
+	Speed      float64 `json:"speed,omitempty"`      // м/с, скорость машины для событий "car:spawned" и "brake"
+	TravelTime float64 `json:"travelTime,omitempty"` // секунды в пути для события "car:completed"
+	Wave       int     `json:"wave,omitempty"`       // идентификатор волны для события "wave"
+	Queue      *Queue  `json:"queue,omitempty"`      // самая длинная очередь для событий "congestion" и "congestion:cleared"
+
+	Summary *RunSummary `json:"summary,omitempty"` // итоги прогона для события "runComplete"
+	Weather string      `json:"weather,omitempty"` // новая погода для события "weather"
+}
+
+// emit добавляет событие в очередь; при переполнении отбрасываются самые старые
+func (s *Simulation) emit(e Event) {
+	if len(s.events) >= maxPendingEvents {
+		s.events = s.events[1:]
+	}
+	s.events = append(s.events, e)
+}
+
+// emitExport добавляет подробное событие в очередь экспорта, если он включён;
+// при переполнении отбрасываются самые старые
+func (s *Simulation) emitExport(e Event) {
+	if !s.exportEvents {
+		return
+	}
+	if len(s.exports) >= maxPendingExports {
+		s.exports = s.exports[1:]
+	}
+	s.exports = append(s.exports, e)
+}
+
+// SetEventExport включает или выключает запись подробных событий для внешних
+// потребителей: появление машины ("car:spawned"), завершение проезда ("car:completed"),
+// начало торможения ("brake") и обнаружение волны «стоп-старт» ("wave"). Их слишком
+// много для рассылки клиентам, поэтому они копятся отдельно от DrainEvents.
+func (s *Simulation) SetEventExport(on bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.exportEvents = on
+	if !on {
+		s.exports = nil
+	}
+}
+
+// DrainExportEvents возвращает накопленные с прошлого вызова подробные события
+// и очищает их очередь
+func (s *Simulation) DrainExportEvents() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	exports := s.exports
+	s.exports = nil
+	return exports
+}
+
+// DrainEvents возвращает накопленные с прошлого вызова события и очищает очередь
+func (s *Simulation) DrainEvents() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := s.events
+	s.events = nil
+	return events
+}
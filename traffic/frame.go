@@ -0,0 +1,44 @@
+package traffic
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// revisionMutex блокировка симуляции, считающая изменения: каждое снятие блокировки
+// на запись увеличивает revision, поэтому закодированное состояние, построенное
+// при той же ревизии, заведомо актуально
+type revisionMutex struct {
+	sync.RWMutex
+	revision uint64 // меняется под блокировкой на запись
+}
+
+func (m *revisionMutex) Unlock() {
+	m.revision++
+	m.RWMutex.Unlock()
+}
+
+// stateFrame JSON состояния симуляции на ревизии revision
+type stateFrame struct {
+	revision uint64
+	data     []byte
+}
+
+// StateJSON возвращает текущее состояние (Snapshot) в JSON. Кадр кодируется один раз
+// на изменение симуляции и общий для всех вызывающих (рассылки WebSocket и SSE,
+// REST, gRPC), поэтому менять возвращённый срез нельзя. Кодирование идёт под
+// блокировкой, так что кадр не застаёт машины посреди шага.
+func (s *Simulation) StateJSON() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if frame := s.frame.Load(); frame != nil && frame.revision == s.mu.revision {
+		return frame.data, nil
+	}
+	data, err := json.Marshal(s.currentState())
+	if err != nil {
+		return nil, err
+	}
+	s.frame.Store(&stateFrame{revision: s.mu.revision, data: data})
+	return data, nil
+}
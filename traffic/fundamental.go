@@ -0,0 +1,82 @@
+package traffic
+
+import "math"
+
+const (
+	// DiagramCellLength метры, длина участка, по которому считаются точки фундаментальной диаграммы
+	DiagramCellLength = 500.0
+	// MaxDiagramWindows число хранимых окон фундаментальной диаграммы
+	MaxDiagramWindows = 240
+)
+
+// DiagramPoint точка фундаментальной диаграммы: показатели участка дороги за окно
+// времени по обобщённым определениям Эди (суммарный пробег и время машин в ячейке
+// пространство-время)
+type DiagramPoint struct {
+	Start   float64 `json:"start"`   // секунды симуляции, начало окна
+	End     float64 `json:"end"`     // секунды симуляции, конец окна
+	From    float64 `json:"from"`    // метры, начало участка
+	To      float64 `json:"to"`      // метры, конец участка
+	Density float64 `json:"density"` // авто/км, по всем полосам
+	Flow    float64 `json:"flow"`    // авто/ч, по всем полосам
+	Speed   float64 `json:"speed"`   // км/ч, пространственно-средняя скорость (0 - машин не было)
+}
+
+// diagramCells возвращает число участков фундаментальной диаграммы
+func diagramCells() int {
+	return int(math.Ceil(RoadLength / DiagramCellLength))
+}
+
+// sampleDiagram учитывает пробег и время машины на участке, где она начала шаг dt
+func (s *Simulation) sampleDiagram(position, distance, dt float64) {
+	if s.diagramMeters == nil {
+		s.diagramMeters = make([]float64, diagramCells())
+		s.diagramTime = make([]float64, diagramCells())
+	}
+	cell := min(max(int(position/DiagramCellLength), 0), len(s.diagramTime)-1)
+	s.diagramMeters[cell] += distance
+	s.diagramTime[cell] += dt
+}
+
+// closeDiagramWindow завершает окно фундаментальной диаграммы длительностью
+// DetectorInterval и добавляет по точке на каждый участок
+func (s *Simulation) closeDiagramWindow() {
+	length := s.Time - s.diagramStart
+	if length < s.DetectorInterval {
+		return
+	}
+	cells := diagramCells()
+	for i := 0; i < cells; i++ {
+		from := float64(i) * DiagramCellLength
+		to := math.Min(RoadLength, from+DiagramCellLength)
+		point := DiagramPoint{Start: s.diagramStart, End: s.Time, From: from, To: to}
+		if s.diagramTime != nil {
+			area := (to - from) * length
+			point.Density = s.diagramTime[i] / area * 1000
+			point.Flow = s.diagramMeters[i] / area * 3600
+			if s.diagramTime[i] > 0 {
+				point.Speed = msToKmh(s.diagramMeters[i] / s.diagramTime[i])
+			}
+		}
+		s.diagram = append(s.diagram, point)
+	}
+	if limit := MaxDiagramWindows * cells; len(s.diagram) > limit {
+		s.diagram = s.diagram[len(s.diagram)-limit:]
+	}
+	s.diagramMeters, s.diagramTime, s.diagramStart = nil, nil, s.Time
+}
+
+// resetDiagram очищает точки и накопленные показатели фундаментальной диаграммы
+func (s *Simulation) resetDiagram() {
+	s.diagram = nil
+	s.diagramMeters, s.diagramTime, s.diagramStart = nil, nil, 0
+}
+
+// FundamentalDiagram возвращает копию точек фундаментальной диаграммы: по точке
+// на каждый участок длиной DiagramCellLength за каждое завершённое окно, старые первыми
+func (s *Simulation) FundamentalDiagram() []DiagramPoint {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]DiagramPoint{}, s.diagram...)
+}
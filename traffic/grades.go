@@ -0,0 +1,78 @@
+package traffic
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	gravity = 9.81 // м/с²
+	// MaxGrade %, наибольший допустимый уклон участка
+	MaxGrade = 12.0
+)
+
+// GradeSegment участок дороги с продольным уклоном; положительный уклон - подъём
+// по направлению движения, отрицательный - спуск
+type GradeSegment struct {
+	Name  string  `json:"name"`
+	Start float64 `json:"start"` // метры
+	End   float64 `json:"end"`   // метры
+	Grade float64 `json:"grade"` // %, подъём на 100 м пути
+}
+
+// SetGrades задаёт участки с уклоном; участки обрезаются по длине дороги,
+// вне участков дорога горизонтальна
+func (s *Simulation) SetGrades(grades []GradeSegment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	valid := make([]GradeSegment, 0, len(grades))
+	for _, g := range grades {
+		g.Start = math.Max(0, g.Start)
+		g.End = math.Min(RoadLength, g.End)
+		if g.End > g.Start && g.Grade != 0 {
+			valid = append(valid, g)
+		}
+	}
+	sort.SliceStable(valid, func(i, j int) bool { return valid[i].Start < valid[j].Start })
+	s.Grades = valid
+}
+
+// gradeAt возвращает уклон в точке position как долю (0.05 - подъём 5%)
+func (s *Simulation) gradeAt(position float64) float64 {
+	if s.networkMode() {
+		// Уклоны заданы на основной дороге, звенья сети ровные
+		return 0
+	}
+	for _, g := range s.Grades {
+		if g.Start > position {
+			break
+		}
+		if position < g.End {
+			return g.Grade / 100
+		}
+	}
+	return 0
+}
+
+// gradeAcceleration возвращает ускорение машины на уклоне при ускорении accel,
+// выбранном моделью следования. Разгоняясь, поддерживая скорость и плавно замедляясь,
+// водитель компенсирует уклон двигателем и тормозами, но не сильнее удельной мощности
+// типа машины, поэтому тяжёлые машины на подъёме теряют скорость. При торможении
+// с полным замедлением типа машины и сильнее уклон не компенсируется: на спуске
+// замедление меньше и тормозной путь длиннее.
+func (s *Simulation) gradeAcceleration(car *Car, accel float64) float64 {
+	grade := s.gradeAt(car.Position)
+	if grade == 0 {
+		return accel
+	}
+	slope := gravity * grade
+	if accel <= -s.carBraking(car) {
+		return math.Max(accel-slope, -s.maxDeceleration())
+	}
+	traction := accel + slope
+	if power := vehicleType(car).PowerToWeight; power > 0 && traction > 0 {
+		traction = math.Min(traction, power/math.Max(car.Speed, 1))
+	}
+	return traction - slope
+}
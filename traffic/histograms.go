@@ -0,0 +1,99 @@
+package traffic
+
+const (
+	// HeadwayReference метры, точка дороги, в которой измеряются интервалы между машинами
+	HeadwayReference = RoadLength / 2
+	// MaxHeadways число хранимых последних интервалов в HeadwayReference
+	MaxHeadways = 5000
+	// MaxHistogramBins наибольшее число корзин гистограммы; последняя собирает всё, что больше
+	MaxHistogramBins = 60
+
+	speedBinWidth      = 5.0  // км/ч
+	headwayBinWidth    = 0.5  // секунды
+	travelTimeBinWidth = 10.0 // секунды
+)
+
+// Histogram распределение значений по корзинам одинаковой ширины от нуля:
+// Counts[i] - число значений в [i·Width, (i+1)·Width), последняя корзина
+// включает и все большие значения
+type Histogram struct {
+	Width  float64 `json:"width"`  // ширина корзины в единицах показателя
+	Counts []int   `json:"counts"` // число значений в корзинах
+	Count  int     `json:"count"`  // всего значений
+	Mean   float64 `json:"mean"`   // среднее значение (0 - значений нет)
+}
+
+// Histograms распределения показателей для дашбордов и отчётов
+type Histograms struct {
+	Speeds      Histogram `json:"speeds"`      // км/ч, текущие скорости машин на дороге
+	Headways    Histogram `json:"headways"`    // секунды, интервалы между машинами одной полосы в HeadwayReference
+	TravelTimes Histogram `json:"travelTimes"` // секунды, время в пути хранимых завершённых поездок
+	Reference   float64   `json:"reference"`   // метры, точка измерения интервалов
+}
+
+// newHistogram раскладывает values по корзинам ширины width; корзин столько, чтобы
+// поместилось наибольшее значение, но не больше MaxHistogramBins
+func newHistogram(values []float64, width float64) Histogram {
+	h := Histogram{Width: width, Counts: []int{}, Count: len(values)}
+	sum := 0.0
+	for _, v := range values {
+		i := min(max(int(v/width), 0), MaxHistogramBins-1)
+		for len(h.Counts) <= i {
+			h.Counts = append(h.Counts, 0)
+		}
+		h.Counts[i]++
+		sum += v
+	}
+	if h.Count > 0 {
+		h.Mean = sum / float64(h.Count)
+	}
+	return h
+}
+
+// trackHeadway запоминает интервал между машинами полосы, если машина, сдвинувшись
+// из from, пересекла HeadwayReference
+func (s *Simulation) trackHeadway(car *Car, from float64) {
+	if from >= HeadwayReference || car.Position < HeadwayReference || s.networkMode() {
+		return
+	}
+	for len(s.headwayCrossings) < s.Lanes {
+		s.headwayCrossings = append(s.headwayCrossings, -1)
+	}
+	if car.Lane >= len(s.headwayCrossings) {
+		return
+	}
+	if last := s.headwayCrossings[car.Lane]; last >= 0 {
+		s.headways = append(s.headways, s.Time-last)
+		if len(s.headways) > MaxHeadways {
+			s.headways = s.headways[len(s.headways)-MaxHeadways:]
+		}
+	}
+	s.headwayCrossings[car.Lane] = s.Time
+}
+
+// resetHeadways забывает интервалы и моменты последних пересечений
+func (s *Simulation) resetHeadways() {
+	s.headways, s.headwayCrossings = nil, nil
+}
+
+// Histograms возвращает распределения текущих скоростей машин, последних MaxHeadways
+// интервалов в HeadwayReference и времени в пути последних MaxTrips поездок
+func (s *Simulation) Histograms() Histograms {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	speeds := make([]float64, len(s.Cars))
+	for i, car := range s.Cars {
+		speeds[i] = msToKmh(car.Speed)
+	}
+	travel := make([]float64, len(s.trips))
+	for i, t := range s.trips {
+		travel[i] = t.TravelTime
+	}
+	return Histograms{
+		Speeds:      newHistogram(speeds, speedBinWidth),
+		Headways:    newHistogram(s.headways, headwayBinWidth),
+		TravelTimes: newHistogram(travel, travelTimeBinWidth),
+		Reference:   HeadwayReference,
+	}
+}
@@ -0,0 +1,105 @@
+package traffic
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+)
+
+const (
+	DefaultHistoryWindow = 60.0  // секунды симуляции, хранимые для перемотки по умолчанию
+	MaxHistoryWindow     = 600.0 // секунды, наибольшее окно перемотки
+	HistoryInterval      = 1.0   // секунды симуляции между кадрами истории
+)
+
+// ErrOutsideHistory момент перемотки вне хранимой истории
+var ErrOutsideHistory = errors.New("time is outside the history window")
+
+// historyBuffer буфер и gzip-сжатие кадра истории; переиспользуются через historyBuffers,
+// так как кадр сохраняется каждую секунду симуляции
+type historyBuffer struct {
+	compressed bytes.Buffer
+	gzip       *gzip.Writer
+}
+
+var historyBuffers = sync.Pool{New: func() any {
+	w, _ := gzip.NewWriterLevel(nil, gzip.BestSpeed)
+	return &historyBuffer{gzip: w}
+}}
+
+// historyFrame полное состояние симуляции в момент time, сжатое gzip
+type historyFrame struct {
+	time float64
+	data []byte
+}
+
+// recordHistory раз в HistoryInterval сохраняет полное состояние в кольцевой буфер
+// и забывает кадры старше HistoryWindow. В ускоренном режиме кадры не сохраняются:
+// сериализация состояния заняла бы большую часть времени прогона.
+func (s *Simulation) recordHistory() {
+	if s.HistoryWindow <= 0 {
+		s.history = nil
+		return
+	}
+	if n := len(s.history); s.Turbo || n > 0 && s.Time-s.history[n-1].time < HistoryInterval {
+		return
+	}
+	buf := historyBuffers.Get().(*historyBuffer)
+	defer historyBuffers.Put(buf)
+	buf.compressed.Reset()
+	buf.gzip.Reset(&buf.compressed)
+	if err := json.NewEncoder(buf.gzip).Encode(s.stateSnapshot()); err != nil {
+		return
+	}
+	buf.gzip.Close()
+	s.history = append(s.history, historyFrame{time: s.Time, data: bytes.Clone(buf.compressed.Bytes())})
+	expired := 0
+	for expired < len(s.history) && s.history[expired].time < s.Time-s.HistoryWindow {
+		expired++
+	}
+	s.history = s.history[expired:]
+}
+
+// historyStart возвращает самый ранний момент, к которому можно перемотать симуляцию
+func (s *Simulation) historyStart() float64 {
+	if len(s.history) == 0 {
+		return s.Time
+	}
+	return s.history[0].time
+}
+
+// Seek перематывает симуляцию назад к последнему кадру истории не позже момента time,
+// восстанавливая и поток случайных чисел: продолженный прогон повторяет исходный, пока
+// его не изменят команды. Кадры после этого момента забываются. При resume симуляция
+// продолжается, иначе остаётся на паузе. О перемотке сообщает событие "seek".
+// Возвращает момент восстановленного кадра.
+func (s *Simulation) Seek(time float64, resume bool) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := len(s.history) - 1
+	for i >= 0 && s.history[i].time > time {
+		i--
+	}
+	if i < 0 {
+		return 0, ErrOutsideHistory
+	}
+	r, err := gzip.NewReader(bytes.NewReader(s.history[i].data))
+	if err != nil {
+		return 0, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.loadState(data); err != nil {
+		return 0, err
+	}
+	s.history = s.history[:i+1]
+	s.Running, s.Paused = resume, !resume
+	s.emit(Event{Type: "seek", Time: s.Time})
+	return s.Time, nil
+}
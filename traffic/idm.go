@@ -0,0 +1,85 @@
+package traffic
+
+import "math"
+
+const (
+	idmDelta           = 4.0 // показатель степени свободного ускорения
+	idmMaxDeceleration = 9.0 // м/с², физический предел торможения
+)
+
+// IDMParams параметры модели интеллектуального водителя (Intelligent Driver Model, Treiber 2000)
+type IDMParams struct {
+	TimeHeadway         float64 `json:"timeHeadway"`         // секунды, желаемый интервал до лидера
+	MaxAcceleration     float64 `json:"maxAcceleration"`     // м/с²
+	ComfortDeceleration float64 `json:"comfortDeceleration"` // м/с²
+	MinGap              float64 `json:"minGap"`              // метры, дистанция в заторе
+}
+
+// defaultIDMParams значения, типичные для автострады
+func defaultIDMParams() IDMParams {
+	return IDMParams{
+		TimeHeadway:         1.5,
+		MaxAcceleration:     1.0,
+		ComfortDeceleration: 2.0,
+		MinGap:              2.0,
+	}
+}
+
+// merge заменяет параметры ненулевыми значениями из update
+func (p *IDMParams) merge(update IDMParams) {
+	if update.TimeHeadway > 0 {
+		p.TimeHeadway = update.TimeHeadway
+	}
+	if update.MaxAcceleration > 0 {
+		p.MaxAcceleration = update.MaxAcceleration
+	}
+	if update.ComfortDeceleration > 0 {
+		p.ComfortDeceleration = update.ComfortDeceleration
+	}
+	if update.MinGap > 0 {
+		p.MinGap = update.MinGap
+	}
+}
+
+// idmAcceleration вычисляет ускорение автомобиля по IDM:
+// a = a_max · [1 - (v/v0)^δ - (s*/s)²], s* = s0 + max(0, v·T + v·Δv / (2√(a_max·b)))
+func idmAcceleration(p IDMParams, car, leader *Car) float64 {
+	// Тип автомобиля ограничивает ускорение и комфортное торможение
+	vt := vehicleType(car)
+	if vt.MaxAcceleration > 0 {
+		p.MaxAcceleration = math.Min(p.MaxAcceleration, vt.MaxAcceleration)
+	}
+	if vt.BrakeDeceleration > 0 {
+		p.ComfortDeceleration = math.Min(p.ComfortDeceleration, vt.BrakeDeceleration)
+	}
+	// Профиль водителя меняет желаемый интервал и ускорение
+	driver := driverProfile(car)
+	p.TimeHeadway *= driver.Headway
+	p.MaxAcceleration *= driver.Acceleration
+
+	v := car.Speed
+	v0 := math.Max(car.TargetSpeed, 0.1)
+	accel := p.MaxAcceleration * (1 - math.Pow(v/v0, idmDelta))
+	if v > v0 {
+		// Выше желаемой скорости (въезд в зону ограничения) машина замедляется
+		// не резче комфортного торможения, как в свободном члене IIDM
+		accel = -p.ComfortDeceleration * (1 - math.Pow(v0/v, p.MaxAcceleration*idmDelta/p.ComfortDeceleration))
+	}
+
+	if leader != nil {
+		gap := math.Max(gapTo(car, leader), 0.1)
+		dv := v - leader.Speed
+		desiredGap := p.MinGap + math.Max(0, v*p.TimeHeadway+v*dv/(2*math.Sqrt(p.MaxAcceleration*p.ComfortDeceleration)))
+		accel -= p.MaxAcceleration * (desiredGap / gap) * (desiredGap / gap)
+	}
+	return math.Max(accel, -idmMaxDeceleration)
+}
+
+// idmModel модель IDM с параметрами s.IDM
+type idmModel struct {
+	s *Simulation
+}
+
+func (m *idmModel) Accel(car, leader *Car, dt float64) float64 {
+	return math.Max(idmAcceleration(m.s.weatherIDM(), car, leader), -m.s.maxDeceleration())
+}
@@ -0,0 +1,89 @@
+package traffic
+
+import "math"
+
+// Виды инцидентов
+const (
+	IncidentStalled  = "stalled"  // заглохшая машина на одной полосе
+	IncidentClosure  = "closure"  // перекрытие полос (ДТП, работы)
+	IncidentShoulder = "shoulder" // машина на обочине: полосы свободны, водители притормаживают
+)
+
+const (
+	DefaultRubbernecking = 0.3   // доля, на которую водители снижают целевую скорость у машины на обочине
+	RubberneckRange      = 150.0 // метры перед машиной на обочине, с которых водители её разглядывают
+)
+
+// AddIncident устанавливает инцидент: неподвижное препятствие на время Duration.
+// Заглохшая машина занимает полосу Lane на длину своего типа, перекрытие - полосы
+// Lanes на протяжении Span; машина на обочине полос не занимает, но проезжающие мимо
+// снижают целевую скорость на долю Rubbernecking. Установка и окончание инцидента
+// сообщаются событиями "incident" и "incident:cleared". Возвращает идентификатор перекрытия.
+func (s *Simulation) AddIncident(config IncidentConfig) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addIncident(config)
+}
+
+// addIncident устанавливает инцидент; вызывающий держит блокировку
+func (s *Simulation) addIncident(config IncidentConfig) int {
+	kind := config.Type
+	if kind == "" {
+		kind = IncidentStalled
+	}
+	b := &Blockage{
+		ID:        s.nextBlockageID,
+		Incident:  kind,
+		Position:  config.Position,
+		Span:      config.Span,
+		Lanes:     config.Lanes,
+		StartTime: s.Time,
+		Duration:  config.Duration,
+	}
+	if kind == IncidentStalled || kind == IncidentShoulder {
+		vehicle := config.Vehicle
+		if _, ok := VehicleTypes[vehicle]; !ok {
+			vehicle = VehicleCar
+		}
+		b.Span = VehicleTypes[vehicle].Length
+		b.Lanes = []int{min(config.Lane, s.Lanes-1)}
+	}
+	if kind == IncidentShoulder {
+		b.Lanes = nil
+		b.Rubbernecking = config.Rubbernecking
+		if b.Rubbernecking <= 0 {
+			b.Rubbernecking = DefaultRubbernecking
+		}
+	}
+	if b.Span <= 0 {
+		b.Span = CarLength
+	}
+	s.Blockages = append(s.Blockages, b)
+	s.nextBlockageID++
+	s.emitIncident("incident", b)
+	return b.ID
+}
+
+// emitIncident сообщает об инциденте событием typ; полоса события - первая
+// перекрытая полоса (-1 - перекрыты все или машина на обочине)
+func (s *Simulation) emitIncident(typ string, b *Blockage) {
+	lane := -1
+	if len(b.Lanes) > 0 {
+		lane = b.Lanes[0]
+	}
+	s.emit(Event{Type: typ, Time: s.Time, Cars: []int{-1}, Lane: lane, Position: b.Position})
+}
+
+// rubberneckFactor возвращает долю, на которую водитель в точке position снижает
+// целевую скорость, разглядывая машины на обочине (0 - их рядом нет); у нескольких
+// машин действует наибольшая доля
+func (s *Simulation) rubberneckFactor(position float64) float64 {
+	factor := 0.0
+	for _, b := range s.Blockages {
+		if b.Incident == IncidentShoulder && position >= b.Position-RubberneckRange && position < b.Position+b.Span {
+			factor = math.Max(factor, b.Rubbernecking)
+		}
+	}
+	return factor
+}
@@ -0,0 +1,88 @@
+package traffic
+
+import "slices"
+
+// InjectCar добавляет машину вручную: с заданными типом, водителем, полосой,
+// позицией и скоростью (например, медленный грузовик посреди потока, чтобы
+// вызвать затор). Машина учитывается среди созданных и появляется событием
+// "car:added". Возвращает идентификатор машины.
+func (s *Simulation) InjectCar(config CarConfig) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	vehicle := config.Type
+	if _, ok := VehicleTypes[vehicle]; !ok {
+		vehicle = VehicleCar
+	}
+	driver := config.Driver
+	if _, ok := DriverProfiles[driver]; !ok {
+		driver = DriverNormal
+	}
+	speed := kmhToMs(config.Speed)
+	target := speed
+	if config.TargetSpeed > 0 {
+		target = kmhToMs(config.TargetSpeed)
+	}
+	reactionFactor := DriverProfiles[driver].ReactionTime
+	car := &Car{
+		ID:             s.nextCarID,
+		Type:           vehicle,
+		Driver:         driver,
+		Length:         VehicleTypes[vehicle].Length,
+		Lane:           min(config.Lane, s.Lanes-1),
+		Position:       config.Position,
+		Speed:          speed,
+		TargetSpeed:    target,
+		Color:          s.randomColor(),
+		State:          "normal",
+		ReactionDelay:  s.ReactionTime * reactionFactor,
+		spawnTime:      s.Time,
+		reactionFactor: reactionFactor,
+		desiredSpeed:   target,
+	}
+	s.adaptTargetSpeed(car)
+	s.insertCar(car)
+	s.nextCarID++
+	s.TotalCarsMade++
+	s.emitExport(Event{Type: "car:spawned", Time: s.Time, Cars: []int{car.ID}, Lane: car.Lane, Position: car.Position, Speed: car.Speed})
+	s.emit(Event{Type: "car:added", Time: s.Time, Cars: []int{car.ID}, Lane: car.Lane, Position: car.Position})
+	return car.ID
+}
+
+// RemoveCar убирает машину id с дороги без учёта поездки и сообщает об этом
+// событием "car:removed"; возвращает false, если такой машины на дороге нет
+func (s *Simulation) RemoveCar(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := slices.IndexFunc(s.Cars, func(car *Car) bool { return car.ID == id })
+	if i < 0 {
+		return false
+	}
+	car := s.Cars[i]
+	s.Cars = slices.Delete(s.Cars, i, i+1)
+	s.indexCars()
+	s.emit(Event{Type: "car:removed", Time: s.Time, Cars: []int{car.ID}, Lane: car.Lane, Position: car.Position})
+	return true
+}
+
+// ControlCar меняет желаемую скорость машины или заставляет её тормозить до остановки
+// и стоять Brake секунд, после чего машина продолжает движение (например, чтобы
+// показать возникновение ударной волны). Возвращает false, если такой машины на дороге нет.
+func (s *Simulation) ControlCar(control CarControl) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	car := s.carByID(control.ID)
+	if car == nil {
+		return false
+	}
+	if control.TargetSpeed != nil {
+		car.desiredSpeed = kmhToMs(*control.TargetSpeed)
+		s.adaptTargetSpeed(car)
+	}
+	if control.Brake > 0 {
+		car.brakeUntil = s.Time + control.Brake
+	}
+	return true
+}
@@ -0,0 +1,177 @@
+package traffic
+
+import (
+	"fmt"
+	"math"
+)
+
+// NodeSignal светофор узла сети. Фазы по очереди дают зелёный своим звеньям-подходам,
+// остальные подходы стоят на красный; после зелёного фазы Yellow секунд горит жёлтый
+// и AllRed секунд - красный для всех, чтобы узел успел освободиться. Offset сдвигает
+// начало цикла относительно t=0.
+type NodeSignal struct {
+	Phases []SignalPhase `json:"phases"`
+	Yellow float64       `json:"yellow"` // секунды
+	AllRed float64       `json:"allRed"` // секунды
+	Offset float64       `json:"offset"` // секунды
+}
+
+// SignalPhase фаза светофора узла
+type SignalPhase struct {
+	Links []string `json:"links"` // звенья-подходы с зелёным
+	Green float64  `json:"green"` // секунды
+}
+
+// cycle возвращает длительность полного цикла светофора
+func (sg NodeSignal) cycle() float64 {
+	total := 0.0
+	for _, p := range sg.Phases {
+		total += p.Green + sg.Yellow + sg.AllRed
+	}
+	return total
+}
+
+// light возвращает сигнал подхода link в момент t: зелёный или жёлтый, если подход
+// входит в текущую фазу, иначе красный
+func (sg NodeSignal) light(link string, t float64) string {
+	at := math.Mod(t+sg.Offset, sg.cycle())
+	if at < 0 {
+		at += sg.cycle()
+	}
+	for _, p := range sg.Phases {
+		length := p.Green + sg.Yellow + sg.AllRed
+		if at >= length {
+			at -= length
+			continue
+		}
+		if at >= p.Green+sg.Yellow || !containsLink(p.Links, link) {
+			return LightRed
+		}
+		if at >= p.Green {
+			return LightYellow
+		}
+		return LightGreen
+	}
+	return LightRed
+}
+
+// containsLink сообщает, есть ли звено id в списке links
+func containsLink(links []string, id string) bool {
+	for _, link := range links {
+		if link == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate проверяет светофор узла node: фазы с положительным зелёным из подходов
+// узла, и каждый подход хотя бы в одной фазе, иначе его машины никогда не проедут
+func (sg NodeSignal) Validate(node string, links []*NetworkLink) error {
+	var e ValidationError
+	if len(sg.Phases) == 0 {
+		e.add("phases", "must not be empty")
+	}
+	e.nonNegative("yellow", sg.Yellow)
+	e.nonNegative("allRed", sg.AllRed)
+	approaches := make(map[string]bool)
+	for _, link := range links {
+		if link != nil && link.To == node {
+			approaches[link.ID] = false
+		}
+	}
+	for i, p := range sg.Phases {
+		if p.Green <= 0 {
+			e.add(fmt.Sprintf("phases[%d].green", i), "must be positive")
+		}
+		for j, id := range p.Links {
+			if _, ok := approaches[id]; !ok {
+				e.add(fmt.Sprintf("phases[%d].links[%d]", i, j), fmt.Sprintf("%q is not an approach of node %q", id, node))
+				continue
+			}
+			approaches[id] = true
+		}
+	}
+	for _, link := range links {
+		if link != nil && link.To == node && !approaches[link.ID] && len(sg.Phases) > 0 {
+			e.add("phases", fmt.Sprintf("approach %q never gets green", link.ID))
+		}
+	}
+	return e.result()
+}
+
+// updateSignals выставляет сигнал каждого подхода к узлу со светофором
+func (s *Simulation) updateSignals() {
+	n := s.Network
+	for _, link := range n.Links {
+		link.Signal = ""
+		if signal := n.signals[link.To]; signal != nil {
+			link.Signal = signal.light(link.ID, s.Time)
+		}
+	}
+}
+
+// signalStop возвращает стоп-линию в конце звена для первой на своей полосе машины:
+// на красный всегда, на жёлтый - если машина успевает затормозить с замедлением
+// своего типа (nil - можно проезжать)
+func (s *Simulation) signalStop(link *NetworkLink, car *Car) *Car {
+	stop := link.Signal == LightRed
+	if link.Signal == LightYellow {
+		stop = link.Length-car.Position >= car.Speed*car.Speed/(2*s.carBraking(car))
+	}
+	if !stop {
+		return nil
+	}
+	return stopObstacle(link.Length)
+}
+
+// enterLink запоминает въезд машины на звено для подсчёта задержки на нём
+func (s *Simulation) enterLink(car *Car) {
+	car.linkEnter = s.Time
+	car.linkFreeFlow = car.freeFlowTime
+}
+
+// exitLink учитывает проезд звена машиной: задержка - время на звене сверх проезда
+// пройденного пути с целевой скоростью
+func (s *Simulation) exitLink(link *NetworkLink, car *Car) {
+	delay := math.Max(0, s.Time-car.linkEnter-(car.freeFlowTime-car.linkFreeFlow))
+	link.Exited++
+	link.Delay += delay
+	link.MeanDelay = link.Delay / float64(link.Exited)
+	link.MaxDelay = math.Max(link.MaxDelay, delay)
+}
+
+// updateLinkQueues находит на каждом звене очереди остановившихся машин от конца
+// звена и время, когда очередь доходит до его начала (перелив): въезд на звено
+// закрыт, и затор распространяется на звенья перед ним
+func (s *Simulation) updateLinkQueues(dt float64) {
+	for _, link := range s.Network.Links {
+		queues := make([]int, link.Lanes)
+		open := make([]bool, link.Lanes)
+		for lane := range open {
+			open[lane] = true
+		}
+		spillback := false
+		for _, car := range link.Cars {
+			if !open[car.Lane] {
+				continue
+			}
+			if !car.stopped {
+				open[car.Lane] = false
+				continue
+			}
+			queues[car.Lane]++
+			if car.Position-car.length() < spawnClearance {
+				spillback = true
+			}
+		}
+		link.Queue = 0
+		for _, q := range queues {
+			link.Queue = max(link.Queue, q)
+		}
+		link.MaxQueue = max(link.MaxQueue, link.Queue)
+		if spillback {
+			link.SpillbackTime += dt
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package traffic
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// TestSpacingInvariant прогоняет случайные сценарии (зерно, модель следования, число полос,
+// тип дороги, спрос и TimeScale) и после каждого шага проверяет, что ни одна машина
+// не стоит ближе длины лидера позади него, в том числе через стык кольца
+func TestSpacingInvariant(t *testing.T) {
+	const steps, dt = 600, 0.05
+	scenarios := 30
+	if testing.Short() {
+		scenarios = 8
+	}
+	models := []string{"simple", "idm", "nasch", "ovm"}
+	roads := []string{RoadStraight, RoadRing}
+	gen := rand.New(rand.NewSource(1))
+	// История для перемотки к инварианту не относится, а её кодирование - самая дорогая часть шага
+	noHistory := 0.0
+
+	for i := 0; i < scenarios; i++ {
+		seed := gen.Int63()
+		config := SimulationConfig{
+			SpawnInterval: 0.3 + gen.Float64()*3,
+			MinSpeed:      30 + gen.Float64()*40,
+			MaxSpeed:      90 + gen.Float64()*60,
+			MaxCars:       50 + gen.Intn(250),
+			Lanes:         1 + gen.Intn(MaxLanes),
+			Model:         models[gen.Intn(len(models))],
+			RoadType:      roads[gen.Intn(len(roads))],
+			Seed:          &seed,
+			HistoryWindow: &noHistory,
+		}
+		scale := 0.2 + gen.Float64()*19.8
+
+		sim := New(WithConfig(config), WithTimeScale(scale))
+		sim.Start()
+		for step := 0; step < steps && sim.Running; step++ {
+			sim.Step(dt)
+			if err := spacingViolation(sim); err != "" {
+				t.Fatalf("scenario %d (seed %d, model %s, road %s, lanes %d, scale %.2f), t=%.2f: %s",
+					i, seed, config.Model, config.RoadType, config.Lanes, scale, sim.Time, err)
+			}
+		}
+	}
+}
+
+// spacingViolation возвращает описание первой машины, стоящей ближе длины лидера позади него
+func spacingViolation(s *Simulation) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lanes := make(map[int][]*Car)
+	for _, car := range s.Cars {
+		lanes[car.Lane] = append(lanes[car.Lane], car)
+	}
+	for lane, cars := range lanes {
+		sort.Slice(cars, func(i, j int) bool { return cars[i].Position > cars[j].Position })
+		for i, car := range cars {
+			var leader *Car
+			var gap float64
+			switch {
+			case i > 0:
+				leader = cars[i-1]
+				gap = leader.Position - car.Position
+			case s.ring() && len(cars) > 1:
+				// Лидер первой машины кольца - последняя машина полосы за стыком
+				leader = cars[len(cars)-1]
+				gap = leader.Position + RoadLength - car.Position
+			default:
+				continue
+			}
+			if gap < leader.length()-1e-6 {
+				return fmt.Sprintf("lane %d: car %d at %.3f is %.3f m behind car %d (length %.2f)",
+					lane, car.ID, car.Position, gap, leader.ID, leader.length())
+			}
+		}
+	}
+	return ""
+}
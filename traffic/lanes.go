@@ -0,0 +1,265 @@
+package traffic
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+const (
+	MaxLanes            = 6     // максимальное число полос
+	LaneChangeLookAhead = 200.0 // метры, дальность оценки свободного пространства впереди
+	LaneChangeThreshold = 20.0  // метры, минимальный выигрыш свободного пространства для перестроения
+	LaneChangeCooldown  = 3.0   // секунды между перестроениями одного автомобиля
+	spawnClearance      = 50.0  // метры, которые должны быть свободны в начале полосы для появления машины
+	laneChangeSpeedGain = 1.0   // м/с, на сколько лидер должен быть медленнее желаемой скорости
+)
+
+// sortCars упорядочивает машины по убыванию позиции: первой идёт машина, ближайшая к концу
+// дороги. За шаг порядок меняется мало, поэтому сортировка вставками почти линейна.
+func (s *Simulation) sortCars() {
+	for i := 1; i < len(s.Cars); i++ {
+		car, j := s.Cars[i], i
+		for j > 0 && s.Cars[j-1].Position < car.Position {
+			s.Cars[j] = s.Cars[j-1]
+			j--
+		}
+		s.Cars[j] = car
+	}
+	s.indexCars()
+}
+
+// indexCars запоминает у каждой машины её место в s.Cars
+func (s *Simulation) indexCars() {
+	for i, car := range s.Cars {
+		car.index = i
+	}
+}
+
+// insertCar вставляет машину в s.Cars, сохраняя порядок по убыванию позиции
+func (s *Simulation) insertCar(car *Car) {
+	s.Cars = slices.Insert(s.Cars, s.searchPosition(car.Position), car)
+	s.indexCars()
+}
+
+// searchPosition возвращает индекс первой машины, находящейся не впереди позиции position
+func (s *Simulation) searchPosition(position float64) int {
+	return sort.Search(len(s.Cars), func(i int) bool { return s.Cars[i].Position <= position })
+}
+
+// carIndex возвращает место автомобиля self в s.Cars и true; для машины, которой нет
+// на дороге (например, ожидающей на рампе), - место, куда она встала бы по позиции position
+func (s *Simulation) carIndex(position float64, self *Car) (int, bool) {
+	if self != nil && self.index < len(s.Cars) && s.Cars[self.index] == self {
+		return self.index, true
+	}
+	return s.searchPosition(position), false
+}
+
+// leaderIn возвращает ближайшую машину впереди позиции position на полосе lane.
+// Машины упорядочены по убыванию позиции, а на одной полосе не обгоняют друг друга,
+// поэтому лидер - первая машина полосы при просмотре от self к началу среза.
+// На кольце лидер за началом дороги возвращается копией, сдвинутой вперёд на длину кольца.
+func (s *Simulation) leaderIn(lane int, position float64, self *Car) *Car {
+	start, _ := s.carIndex(position, self)
+	n := len(s.Cars)
+	for k := 1; k <= n; k++ {
+		i := start - k
+		if i < 0 {
+			if !s.ring() {
+				break
+			}
+			i += n
+		}
+		other := s.Cars[i]
+		if other == self || other.Lane != lane {
+			continue
+		}
+		if d := s.aheadDistance(position, other.Position); d > 0 {
+			return relativeTo(other, position, d)
+		}
+	}
+	return nil
+}
+
+// followerIn возвращает ближайшую машину позади позиции position (или рядом) на полосе lane.
+// На кольце машина за концом дороги возвращается копией, сдвинутой назад на длину кольца.
+func (s *Simulation) followerIn(lane int, position float64, self *Car) *Car {
+	start, member := s.carIndex(position, self)
+	if member {
+		start++
+	}
+	n := len(s.Cars)
+	for k := 0; k < n; k++ {
+		i := start + k
+		if i >= n {
+			if !s.ring() {
+				break
+			}
+			i -= n
+		}
+		other := s.Cars[i]
+		if other == self || other.Lane != lane {
+			continue
+		}
+		if d := s.aheadDistance(other.Position, position); d >= 0 {
+			return relativeTo(other, position, -d)
+		}
+	}
+	return nil
+}
+
+// obstacleAhead возвращает ближайшее препятствие впереди автомобиля на полосе lane:
+// машину-лидера или неподвижный виртуальный автомобиль перед перекрытием, закрытой
+// полосой участка работ, стоп-линией светофора, занятым пешеходным переходом или
+// остановкой автобуса маршрута. Спецмашина и уступившая ей машина проезжают друг мимо друга.
+func (s *Simulation) obstacleAhead(car *Car, lane int) *Car {
+	leader := s.leaderIn(lane, car.Position, car)
+	for leader != nil && passesBy(car, leader) {
+		leader = s.leaderIn(lane, leader.Position, leader)
+	}
+	if b := s.blockageAhead(car, lane); b != nil {
+		if obstacle := stopObstacle(car.Position + s.aheadDistance(car.Position, b.Position)); leader == nil || obstacle.Position < leader.Position {
+			leader = obstacle
+		}
+	}
+	if start, ok := s.workZoneAhead(car, lane); ok {
+		if obstacle := stopObstacle(car.Position + s.aheadDistance(car.Position, start)); leader == nil || obstacle.Position < leader.Position {
+			leader = obstacle
+		}
+	}
+	if line, ok := s.stopLineAhead(car); ok {
+		if obstacle := stopObstacle(car.Position + s.aheadDistance(car.Position, line)); leader == nil || obstacle.Position < leader.Position {
+			leader = obstacle
+		}
+	}
+	if crosswalk, ok := s.crosswalkAhead(car); ok {
+		if obstacle := stopObstacle(car.Position + s.aheadDistance(car.Position, crosswalk)); leader == nil || obstacle.Position < leader.Position {
+			leader = obstacle
+		}
+	}
+	if stop, ok := s.busStopAhead(car); ok {
+		if obstacle := stopObstacle(stop); leader == nil || obstacle.Position < leader.Position {
+			leader = obstacle
+		}
+	}
+	return leader
+}
+
+// freeSpace возвращает расстояние до ближайшего препятствия на полосе lane,
+// ограниченное дальностью LaneChangeLookAhead
+func (s *Simulation) freeSpace(car *Car, lane int) float64 {
+	ahead := s.obstacleAhead(car, lane)
+	if ahead == nil {
+		return LaneChangeLookAhead
+	}
+	return math.Min(LaneChangeLookAhead, gapTo(car, ahead))
+}
+
+// laneBlockedBeside сообщает, перекрыта ли полоса lane рядом с автомобилем
+// перекрытием или участком работ
+func (s *Simulation) laneBlockedBeside(car *Car, lane int) bool {
+	if s.laneClosedBeside(car, lane) {
+		return true
+	}
+	for _, b := range s.Blockages {
+		if b.blocksLane(lane) && car.Position+car.length() > b.Position && car.Position < b.Position+b.Span+car.length() {
+			return true
+		}
+	}
+	return false
+}
+
+// gapAcceptable проверяет, что перестроение на полосу lane оставляет безопасную
+// дистанцию до нового лидера и для новой машины сзади
+func (s *Simulation) gapAcceptable(car *Car, lane int) bool {
+	if s.laneBlockedBeside(car, lane) {
+		return false
+	}
+	if leader := s.obstacleAhead(car, lane); leader != nil {
+		gap := gapTo(car, leader)
+		if gap < getSafeDistance(car.Speed-leader.Speed, s.SafetyMultiplier) {
+			return false
+		}
+	}
+	if follower := s.followerIn(lane, car.Position, car); follower != nil {
+		gap := gapTo(follower, car)
+		if gap < getSafeDistance(follower.Speed-car.Speed, s.SafetyMultiplier) {
+			return false
+		}
+	}
+	return true
+}
+
+// considerLaneChange перестраивает автомобиль на соседнюю полосу по правилу s.LaneChange
+func (s *Simulation) considerLaneChange(car *Car) {
+	// Машины колонны не перестраиваются, пока идут за предшественником
+	if s.Lanes <= 1 || car.inPlatoon() || (car.lastLaneChange > 0 && s.Time-car.lastLaneChange < LaneChangeCooldown) {
+		return
+	}
+	choose := s.spaceLane
+	if s.LaneChange == LaneChangeMOBIL {
+		choose = s.mobilLane
+	}
+	if lane := choose(car); lane >= 0 {
+		car.Lane = lane
+		car.lastLaneChange = s.Time
+		s.LaneChanges++
+	}
+}
+
+// spaceLane выбирает соседнюю полосу, если впереди на полосе машины есть помеха
+// (более медленный лидер или перекрытие), на соседней полосе заметно больше
+// свободного места и манёвр безопасен (-1 - перестроения нет)
+func (s *Simulation) spaceLane(car *Car) int {
+	// Стимул к перестроению есть только при более медленной помехе впереди
+	ahead := s.obstacleAhead(car, car.Lane)
+	if ahead == nil || ahead.Speed >= car.TargetSpeed-laneChangeSpeedGain {
+		return -1
+	}
+	current := s.freeSpace(car, car.Lane)
+	if current >= LaneChangeLookAhead {
+		return -1
+	}
+
+	best, bestGain := -1, LaneChangeThreshold
+	for _, lane := range []int{car.Lane - 1, car.Lane + 1} {
+		if lane < 0 || lane >= s.Lanes || !s.gapAcceptable(car, lane) {
+			continue
+		}
+		if gain := s.freeSpace(car, lane) - current; gain > bestGain {
+			best, bestGain = lane, gain
+		}
+	}
+	return best
+}
+
+// entryLane возвращает полосу с наибольшим свободным пространством в начале дороги
+// и признак того, что на ней достаточно места для появления машины
+func (s *Simulation) entryLane() (int, bool) {
+	// На двусторонней дороге машины въезжают только на свою полосу
+	lanes := s.Lanes
+	if s.twoWay() {
+		lanes = 1
+	}
+	// Машины упорядочены по убыванию позиции: задние машины полос - в конце среза
+	space := make([]float64, lanes)
+	for lane := range space {
+		space[lane] = math.MaxFloat64
+	}
+	for i, seen := len(s.Cars)-1, 0; i >= 0 && seen < lanes; i-- {
+		car := s.Cars[i]
+		if car.Lane < lanes && space[car.Lane] == math.MaxFloat64 {
+			space[car.Lane] = car.Position
+			seen++
+		}
+	}
+
+	best, bestSpace := 0, -1.0
+	for lane, free := range space {
+		if free > bestSpace {
+			best, bestSpace = lane, free
+		}
+	}
+	return best, bestSpace >= spawnClearance
+}
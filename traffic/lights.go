@@ -0,0 +1,104 @@
+package traffic
+
+import "math"
+
+// Фазы светофора
+const (
+	LightGreen  = "green"
+	LightYellow = "yellow"
+	LightRed    = "red"
+)
+
+// TrafficLight светофор со стоп-линией в точке Position, действующий на все полосы.
+// Цикл: зелёный, жёлтый, красный; Offset сдвигает начало цикла относительно t=0.
+type TrafficLight struct {
+	Position float64 `json:"position"` // метры, стоп-линия
+	Green    float64 `json:"green"`    // секунды
+	Yellow   float64 `json:"yellow"`   // секунды
+	Red      float64 `json:"red"`      // секунды
+	Offset   float64 `json:"offset"`   // секунды
+}
+
+// TrafficLightState светофор с текущей фазой для рассылки клиентам
+type TrafficLightState struct {
+	TrafficLight
+	State     string  `json:"state"`     // green, yellow или red
+	Remaining float64 `json:"remaining"` // секунды до смены фазы
+	Preempted bool    `json:"preempted"` // зелёный дан подъезжающей спецмашине вне цикла
+}
+
+// cycle возвращает длительность полного цикла светофора
+func (l TrafficLight) cycle() float64 {
+	return l.Green + l.Yellow + l.Red
+}
+
+// phase возвращает фазу светофора в момент t и время до её окончания
+func (l TrafficLight) phase(t float64) (string, float64) {
+	at := math.Mod(t+l.Offset, l.cycle())
+	if at < 0 {
+		at += l.cycle()
+	}
+	switch {
+	case at < l.Green:
+		return LightGreen, l.Green - at
+	case at < l.Green+l.Yellow:
+		return LightYellow, l.Green + l.Yellow - at
+	default:
+		return LightRed, l.cycle() - at
+	}
+}
+
+// SetTrafficLights задаёт светофоры на дороге; светофоры вне дороги
+// или с нулевым циклом отбрасываются
+func (s *Simulation) SetTrafficLights(lights []TrafficLight) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	valid := make([]TrafficLight, 0, len(lights))
+	for _, l := range lights {
+		l.Green = math.Max(0, l.Green)
+		l.Yellow = math.Max(0, l.Yellow)
+		l.Red = math.Max(0, l.Red)
+		if l.Position > 0 && l.Position < RoadLength && l.cycle() > 0 {
+			valid = append(valid, l)
+		}
+	}
+	s.TrafficLights = valid
+}
+
+// trafficLightStates вычисляет текущие фазы всех светофоров
+func (s *Simulation) trafficLightStates() []TrafficLightState {
+	states := make([]TrafficLightState, len(s.TrafficLights))
+	vehicles := s.emergencyVehicles()
+	for i, l := range s.TrafficLights {
+		state, remaining := l.phase(s.Time)
+		states[i] = TrafficLightState{TrafficLight: l, State: state, Remaining: remaining}
+		if preempted(l, vehicles) {
+			states[i].State, states[i].Remaining, states[i].Preempted = LightGreen, 0, true
+		}
+	}
+	return states
+}
+
+// stopLineAhead возвращает ближайшую стоп-линию впереди автомобиля, перед которой
+// он должен остановиться: на красный всегда, на жёлтый - если успевает затормозить
+// с замедлением своего типа. Светофор, пропускающий спецмашину, горит зелёным.
+func (s *Simulation) stopLineAhead(car *Car) (float64, bool) {
+	nearest, found := 0.0, false
+	best := math.MaxFloat64
+	for _, l := range s.TrafficLights {
+		distance := s.aheadDistance(car.Position, l.Position)
+		if distance <= 0 || distance >= best || preempted(l, s.emergencies) {
+			continue
+		}
+		state, _ := l.phase(s.Time)
+		stop := state == LightRed
+		if state == LightYellow {
+			stop = distance >= car.Speed*car.Speed/(2*s.carBraking(car))
+		}
+		if stop {
+			nearest, found, best = l.Position, true, distance
+		}
+	}
+	return nearest, found
+}
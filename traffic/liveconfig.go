@@ -0,0 +1,102 @@
+package traffic
+
+import "fmt"
+
+// LiveConfig параметры потока, которые можно менять на ходу без сброса симуляции:
+// интервал появления машин, диапазон желаемых скоростей и спрос. Поля совпадают
+// с полями команды config; отсутствующие поля не меняются.
+type LiveConfig struct {
+	SpawnInterval   *float64      `json:"spawnInterval"`             // секунды между машинами
+	Demand          *float64      `json:"demand"`                    // авто/ч, задаёт интервал вместо spawnInterval
+	MinSpeed        *float64      `json:"minSpeed"`                  // км/ч
+	MaxSpeed        *float64      `json:"maxSpeed"`                  // км/ч
+	DemandProfile   []DemandPoint `json:"demandProfile"`             // пустой список отключает профиль
+	DemandBins      []float64     `json:"demandBins"`                // авто/ч по интервалам demandBinLength, заменяет demandProfile
+	DemandBinLength float64       `json:"demandBinLength,omitempty"` // секунды, длительность интервала demandBins (0 - 300)
+}
+
+// ParseLiveConfig разбирает параметры потока в формате YAML или JSON;
+// неизвестные поля, в том числе параметры, требующие сброса, считаются ошибкой
+func ParseLiveConfig(data []byte) (LiveConfig, error) {
+	var config LiveConfig
+	err := decodeDocument(data, &config)
+	return config, err
+}
+
+// Validate проверяет параметры потока
+func (c LiveConfig) Validate() error {
+	var e ValidationError
+	if c.SpawnInterval != nil && *c.SpawnInterval <= 0 {
+		e.add("spawnInterval", "must be positive")
+	}
+	// Спрос только пересчитывается в интервал, поэтому нулевой нечем применить
+	if c.Demand != nil && *c.Demand <= 0 {
+		e.add("demand", "must be positive")
+	}
+	if c.MinSpeed != nil {
+		e.nonNegative("minSpeed", *c.MinSpeed)
+	}
+	if c.MaxSpeed != nil {
+		e.nonNegative("maxSpeed", *c.MaxSpeed)
+	}
+	if c.MinSpeed != nil && c.MaxSpeed != nil && *c.MinSpeed > *c.MaxSpeed {
+		e.add("minSpeed", "must not exceed maxSpeed")
+	}
+	for i, p := range c.DemandProfile {
+		e.nonNegative(fmt.Sprintf("demandProfile[%d].spawnInterval", i), p.SpawnInterval)
+		e.nonNegative(fmt.Sprintf("demandProfile[%d].demand", i), p.Demand)
+	}
+	for i, rate := range c.DemandBins {
+		e.nonNegative(fmt.Sprintf("demandBins[%d]", i), rate)
+	}
+	e.nonNegative("demandBinLength", c.DemandBinLength)
+	if c.DemandProfile != nil && c.DemandBins != nil {
+		e.add("demandBins", "must not be combined with demandProfile")
+	}
+	return e.result()
+}
+
+// UpdateLiveConfig проверяет и применяет параметры потока; при ошибке ничего не
+// меняется. Скорость, заданная без пары, проверяется против текущей скорости
+// симуляции. Машины на дороге сохраняют свои скорости, новые параметры действуют
+// для следующих появлений.
+func (s *Simulation) UpdateLiveConfig(c LiveConfig) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	minSpeed, maxSpeed := s.MinSpeed, s.MaxSpeed
+	if c.MinSpeed != nil {
+		minSpeed = kmhToMs(*c.MinSpeed)
+	}
+	if c.MaxSpeed != nil {
+		maxSpeed = kmhToMs(*c.MaxSpeed)
+	}
+	if minSpeed > maxSpeed {
+		var e ValidationError
+		if c.MinSpeed != nil {
+			e.add("minSpeed", fmt.Sprintf("must not exceed the current maxSpeed %.1f km/h", msToKmh(maxSpeed)))
+		} else {
+			e.add("maxSpeed", fmt.Sprintf("must not be below the current minSpeed %.1f km/h", msToKmh(minSpeed)))
+		}
+		return &e
+	}
+
+	if c.SpawnInterval != nil {
+		s.SpawnInterval = *c.SpawnInterval
+	}
+	if c.Demand != nil {
+		s.SpawnInterval = 3600 / *c.Demand
+	}
+	s.MinSpeed, s.MaxSpeed = minSpeed, maxSpeed
+	if c.DemandProfile != nil {
+		s.setDemandProfile(c.DemandProfile)
+	}
+	if c.DemandBins != nil {
+		s.setDemandProfile(demandBins(c.DemandBins, c.DemandBinLength))
+	}
+	return nil
+}
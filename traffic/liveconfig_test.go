@@ -0,0 +1,56 @@
+package traffic
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestUpdateLiveConfig проверяет, что скорость, заданная без пары, сверяется с текущей
+// скоростью симуляции, нулевой спрос отвергается, а отвергнутые параметры не меняют ничего
+func TestUpdateLiveConfig(t *testing.T) {
+	value := func(v float64) *float64 { return &v }
+	tests := []struct {
+		name      string
+		config    LiveConfig
+		field     string  // поле ошибки ("" - параметры применяются)
+		wantMin   float64 // км/ч
+		wantMax   float64 // км/ч
+		wantSpawn float64 // секунды
+	}{
+		{name: "min speed within running range", config: LiveConfig{MinSpeed: value(80)}, wantMin: 80, wantMax: 110, wantSpawn: 2},
+		{name: "min speed above running max", config: LiveConfig{MinSpeed: value(130)}, field: "minSpeed"},
+		{name: "max speed below running min", config: LiveConfig{MaxSpeed: value(50)}, field: "maxSpeed"},
+		{name: "both speeds move past each other", config: LiveConfig{MinSpeed: value(130), MaxSpeed: value(150)}, wantMin: 130, wantMax: 150, wantSpawn: 2},
+		{name: "zero demand", config: LiveConfig{Demand: value(0)}, field: "demand"},
+		{name: "zero demand with a valid speed", config: LiveConfig{Demand: value(0), MinSpeed: value(80)}, field: "demand"},
+		{name: "demand sets interval", config: LiveConfig{Demand: value(900)}, wantMin: 60, wantMax: 110, wantSpawn: 4},
+		{name: "demand wins over interval", config: LiveConfig{SpawnInterval: value(5), Demand: value(3600)}, wantMin: 60, wantMax: 110, wantSpawn: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := New(WithConfig(SimulationConfig{SpawnInterval: 2, MinSpeed: 60, MaxSpeed: 110}))
+
+			err := sim.UpdateLiveConfig(tt.config)
+			if tt.field != "" {
+				var validation *ValidationError
+				if !errors.As(err, &validation) || validation.Fields[tt.field] == "" {
+					t.Fatalf("err = %v, want an error for %s", err, tt.field)
+				}
+				tt.wantMin, tt.wantMax, tt.wantSpawn = 60, 110, 2
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := msToKmh(sim.MinSpeed); math.Abs(got-tt.wantMin) > 1e-9 {
+				t.Errorf("min speed = %g km/h, want %g", got, tt.wantMin)
+			}
+			if got := msToKmh(sim.MaxSpeed); math.Abs(got-tt.wantMax) > 1e-9 {
+				t.Errorf("max speed = %g km/h, want %g", got, tt.wantMax)
+			}
+			if math.Abs(sim.SpawnInterval-tt.wantSpawn) > 1e-9 {
+				t.Errorf("spawn interval = %g s, want %g", sim.SpawnInterval, tt.wantSpawn)
+			}
+		})
+	}
+}
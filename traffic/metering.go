@@ -0,0 +1,90 @@
+package traffic
+
+import "math"
+
+// MeteringConfig параметры светофора на рампе с регулированием ALINEA.
+// Нулевые параметры заменяются значениями по умолчанию.
+type MeteringConfig struct {
+	TargetOccupancy float64 `json:"targetOccupancy"` // целевая занятость ниже слияния, доля (0.2)
+	Gain            float64 `json:"gain"`            // авто/ч на процент отклонения занятости (70)
+	Detector        float64 `json:"detector"`        // метры, детектор ниже слияния (точка слияния + 300)
+	Period          float64 `json:"period"`          // секунды между пересчётами интенсивности (60)
+	MinRate         float64 `json:"minRate"`         // авто/ч (240)
+	MaxRate         float64 `json:"maxRate"`         // авто/ч (1800)
+	MaxQueue        int     `json:"maxQueue"`        // машин, при большей очереди рампа выпускает с MaxRate (0 - без ограничения)
+}
+
+// RampMetering светофор на рампе: параметры и текущая интенсивность выпуска
+type RampMetering struct {
+	MeteringConfig
+	Rate        float64 `json:"rate"`       // авто/ч, текущая интенсивность выпуска
+	QueueFlush  bool    `json:"queueFlush"` // очередь превысила MaxQueue, выпуск с MaxRate
+	lastUpdate  float64
+	lastRelease float64
+}
+
+// newRampMetering создаёт светофор рампы в точке position с начальной интенсивностью MaxRate
+func newRampMetering(config MeteringConfig, position float64) *RampMetering {
+	if config.TargetOccupancy <= 0 {
+		config.TargetOccupancy = 0.2
+	}
+	if config.Gain <= 0 {
+		config.Gain = 70
+	}
+	if config.Detector <= 0 {
+		config.Detector = math.Min(position+300, RoadLength-1)
+	}
+	if config.Period <= 0 {
+		config.Period = 60
+	}
+	if config.MinRate <= 0 {
+		config.MinRate = 240
+	}
+	if config.MaxRate <= 0 {
+		config.MaxRate = 1800
+	}
+	return &RampMetering{MeteringConfig: config, Rate: config.MaxRate}
+}
+
+// updateMetering раз в период пересчитывает интенсивность выпуска по ALINEA:
+// r(k) = r(k-1) + K_R · (o* - o(k)), где o(k) - занятость детектора ниже слияния в процентах
+// за последний завершённый интервал; при очереди длиннее MaxQueue выпуск идёт с MaxRate
+func (s *Simulation) updateMetering() {
+	ramp := s.OnRamp
+	if ramp == nil || ramp.Metering == nil {
+		return
+	}
+	m := ramp.Metering
+	m.QueueFlush = m.MaxQueue > 0 && ramp.Queue > m.MaxQueue
+	if s.Time-m.lastUpdate < m.Period {
+		return
+	}
+	m.lastUpdate = s.Time
+	d := s.detectorAt(m.Detector)
+	if d == nil || len(d.Series) == 0 {
+		return
+	}
+	occupancy := d.Series[len(d.Series)-1].Occupancy
+	m.Rate += m.Gain * 100 * (m.TargetOccupancy - occupancy)
+	m.Rate = math.Max(m.MinRate, math.Min(m.MaxRate, m.Rate))
+}
+
+// meteringGreen сообщает, может ли светофор рампы выпустить следующую машину
+func (s *Simulation) meteringGreen() bool {
+	m := s.OnRamp.Metering
+	if m == nil {
+		return true
+	}
+	rate := m.Rate
+	if m.QueueFlush {
+		rate = m.MaxRate
+	}
+	return m.lastRelease == 0 || s.Time-m.lastRelease >= 3600/rate
+}
+
+// ensureDetector устанавливает детектор в точке position, если его там нет
+func (s *Simulation) ensureDetector(position float64) {
+	if s.detectorAt(position) == nil && position > 0 && position < RoadLength {
+		s.Detectors = append(s.Detectors, &Detector{Position: position, intervalStart: s.Time})
+	}
+}
@@ -0,0 +1,97 @@
+package traffic
+
+import "math"
+
+// Правила перестроения
+const (
+	LaneChangeSpace = "space" // на полосу с заметно большим свободным местом впереди
+	LaneChangeMOBIL = "mobil" // по критериям MOBIL
+)
+
+// MOBILParams параметры модели перестроения MOBIL (Kesting, Treiber, Helbing, 2007)
+type MOBILParams struct {
+	Politeness       float64 `json:"politeness"`       // вес выгоды и потерь соседей (0 - эгоистичный водитель)
+	SafeDeceleration float64 `json:"safeDeceleration"` // м/с², наибольшее торможение, которое манёвр может вызвать у новой машины сзади
+	Threshold        float64 `json:"threshold"`        // м/с², наименьший выигрыш в ускорении для перестроения
+}
+
+// defaultMOBILParams значения из исходной работы для автострады
+func defaultMOBILParams() MOBILParams {
+	return MOBILParams{
+		Politeness:       0.2,
+		SafeDeceleration: 4.0,
+		Threshold:        0.1,
+	}
+}
+
+// merge заменяет параметры ненулевыми значениями из update;
+// отрицательная вежливость делает водителей эгоистичными (0)
+func (p *MOBILParams) merge(update MOBILParams) {
+	if update.Politeness > 0 {
+		p.Politeness = update.Politeness
+	} else if update.Politeness < 0 {
+		p.Politeness = 0
+	}
+	if update.SafeDeceleration > 0 {
+		p.SafeDeceleration = update.SafeDeceleration
+	}
+	if update.Threshold > 0 {
+		p.Threshold = update.Threshold
+	}
+}
+
+// mobilAcceleration оценивает ускорение машины за лидером для критериев MOBIL по IDM
+// (nil - впереди свободно). MOBIL сравнивает малые разности ускорений, поэтому нужна
+// гладкая модель независимо от выбранной модели следования: эвристика simple и
+// автомат nasch дают только торможение, ноль или разгон.
+func (s *Simulation) mobilAcceleration(car, leader *Car) float64 {
+	if leader != nil && gapTo(car, leader) <= 0 {
+		return math.Inf(-1)
+	}
+	return idmAcceleration(s.weatherIDM(), car, leader)
+}
+
+// mobilIncentive возвращает выигрыш от перестроения машины на полосу lane по MOBIL
+// и false, если манёвр небезопасен. Выигрыш - изменение ускорения машины и,
+// с весом Politeness, сумма изменений ускорений старой и новой машин сзади:
+//
+//	ã_c - a_c + p·[(ã_n - a_n) + (ã_o - a_o)]
+//
+// Манёвр безопасен, если новая машина сзади и сама машина тормозят не резче SafeDeceleration.
+func (s *Simulation) mobilIncentive(car *Car, lane int) (float64, bool) {
+	p := s.MOBIL
+	leader := s.obstacleAhead(car, car.Lane)
+	newLeader := s.obstacleAhead(car, lane)
+	changed := s.mobilAcceleration(car, newLeader)
+	if changed < -p.SafeDeceleration {
+		return 0, false
+	}
+
+	var neighbours float64
+	if follower := s.followerIn(lane, car.Position, car); follower != nil {
+		after := s.mobilAcceleration(follower, car)
+		if after < -p.SafeDeceleration {
+			return 0, false
+		}
+		neighbours += after - s.mobilAcceleration(follower, newLeader)
+	}
+	if follower := s.followerIn(car.Lane, car.Position, car); follower != nil {
+		neighbours += s.mobilAcceleration(follower, leader) - s.mobilAcceleration(follower, car)
+	}
+	return changed - s.mobilAcceleration(car, leader) + p.Politeness*neighbours, true
+}
+
+// mobilLane выбирает соседнюю полосу с наибольшим выигрышем выше порога Threshold
+// (-1 - перестроение не нужно или небезопасно)
+func (s *Simulation) mobilLane(car *Car) int {
+	best, bestIncentive := -1, s.MOBIL.Threshold
+	for _, lane := range []int{car.Lane - 1, car.Lane + 1} {
+		if lane < 0 || lane >= s.Lanes || s.laneBlockedBeside(car, lane) {
+			continue
+		}
+		if incentive, safe := s.mobilIncentive(car, lane); safe && incentive > bestIncentive {
+			best, bestIncentive = lane, incentive
+		}
+	}
+	return best
+}
@@ -0,0 +1,147 @@
+package traffic
+
+import (
+	"math"
+	"sync"
+)
+
+const (
+	brakingAccel = -0.5 // м/с², ускорение, ниже которого машина считается тормозящей
+	cruiseAccel  = 0.05 // м/с², ускорение, выше которого машина считается разгоняющейся
+)
+
+// FollowingModel модель следования за лидером. Accel возвращает ускорение автомобиля
+// (м/с², отрицательное - торможение) на шаге dt; leader равен nil, если впереди свободно.
+// Скорость после шага ограничивается снизу нулём.
+type FollowingModel interface {
+	Accel(car, leader *Car, dt float64) float64
+}
+
+// ModelFactory создаёт экземпляр модели для симуляции s. Модель вызывается внутри
+// Step под блокировкой симуляции и может читать её параметры напрямую. В больших
+// парках Accel вызывается для разных машин одновременно из нескольких горутин
+// (см. updateCars), поэтому модель не должна менять ничего, кроме полей car.
+type ModelFactory func(s *Simulation) FollowingModel
+
+var (
+	modelsMu       sync.RWMutex
+	modelFactories = map[string]ModelFactory{}
+	modelNames     []string
+)
+
+func init() {
+	RegisterModel("simple", func(s *Simulation) FollowingModel { return &simpleModel{s: s} })
+	RegisterModel("idm", func(s *Simulation) FollowingModel { return &idmModel{s: s} })
+	RegisterModel("nasch", func(s *Simulation) FollowingModel { return &naschModel{s: s} })
+	RegisterModel("ovm", func(s *Simulation) FollowingModel { return &ovmModel{s: s} })
+}
+
+// RegisterModel регистрирует модель следования под именем name;
+// повторная регистрация заменяет фабрику
+func RegisterModel(name string, factory ModelFactory) {
+	modelsMu.Lock()
+	defer modelsMu.Unlock()
+
+	if _, ok := modelFactories[name]; !ok {
+		modelNames = append(modelNames, name)
+	}
+	modelFactories[name] = factory
+}
+
+// Models возвращает имена зарегистрированных моделей следования в порядке регистрации
+func Models() []string {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+
+	names := make([]string, len(modelNames))
+	copy(names, modelNames)
+	return names
+}
+
+// knownModel сообщает, зарегистрирована ли модель следования с таким именем
+func knownModel(name string) bool {
+	modelsMu.RLock()
+	defer modelsMu.RUnlock()
+
+	_, ok := modelFactories[name]
+	return ok
+}
+
+// followingModel возвращает экземпляр текущей модели s.Model, создавая его при смене модели
+func (s *Simulation) followingModel() FollowingModel {
+	if s.model == nil || s.modelName != s.Model {
+		modelsMu.RLock()
+		factory, ok := modelFactories[s.Model]
+		if !ok {
+			factory = modelFactories["simple"]
+		}
+		modelsMu.RUnlock()
+		s.model = factory(s)
+		s.modelName = s.Model
+	}
+	return s.model
+}
+
+// applyAcceleration меняет скорость автомобиля, определяет его состояние по ускорению
+// и считает торможения (не чаще одного в секунду)
+func (s *Simulation) applyAcceleration(car *Car, accel, dt float64) {
+	car.Speed = math.Max(0, car.Speed+accel*dt)
+
+	switch {
+	case accel < brakingAccel:
+		car.State = "braking"
+	case accel > cruiseAccel:
+		car.State = "accelerating"
+	case accel == 0 && car.State == "braking":
+		// Пауза реакции между торможениями: состояние сохраняется
+	default:
+		car.State = "normal"
+	}
+
+	if car.State == "braking" && (car.lastBrakeTime == 0 || s.Time-car.lastBrakeTime > 1.0) {
+		car.BrakeCount++
+		s.BrakeEvents++
+		car.lastBrakeTime = s.Time
+		s.emitExport(Event{Type: "brake", Time: s.Time, Cars: []int{car.ID}, Lane: car.Lane, Position: car.Position, Speed: car.Speed})
+	}
+}
+
+// simpleModel исходная эвристика: торможение с постоянным замедлением, если дистанция
+// меньше безопасной (с гистерезисом и паузой реакции), иначе разгон до целевой скорости
+type simpleModel struct {
+	s *Simulation
+}
+
+func (m *simpleModel) Accel(car, leader *Car, dt float64) float64 {
+	s := m.s
+	if leader != nil {
+		distance := gapTo(car, leader)
+		speedDiff := car.Speed - leader.Speed
+		safeDistance := getSafeDistance(speedDiff, s.SafetyMultiplier) * driverProfile(car).Headway * s.weatherEffect().Headway
+
+		// Гистерезис: торможение начинается ниже нижней границы полосы
+		// и продолжается, пока дистанция не превысит верхнюю границу
+		shouldBrake := distance < safeDistance*(1-s.HysteresisBand)
+		if car.State == "braking" {
+			shouldBrake = distance < safeDistance*(1+s.HysteresisBand)
+		}
+
+		if shouldBrake {
+			// Повторное торможение не раньше, чем через время реакции
+			if car.State != "braking" || s.Time-car.lastBrakeTime > s.carReactionTime(car) {
+				return -s.carBraking(car)
+			}
+			return 0
+		}
+	}
+
+	// Можно ускоряться, но не выше целевой скорости; выше целевой скорости
+	// (после въезда в зону ограничения) машина замедляется так же плавно
+	if car.Speed < car.TargetSpeed {
+		return math.Min(s.carAcceleration(car), (car.TargetSpeed-car.Speed)/dt)
+	}
+	if car.Speed > car.TargetSpeed {
+		return -math.Min(s.carAcceleration(car), (car.Speed-car.TargetSpeed)/dt)
+	}
+	return 0
+}
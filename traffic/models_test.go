@@ -0,0 +1,63 @@
+package traffic
+
+import (
+	"math"
+	"testing"
+)
+
+// TestHysteresisNoChatter проверяет, что дистанция, колеблющаяся у порога безопасной
+// в пределах полосы гистерезиса, не переключает машину между торможением и разгоном
+func TestHysteresisNoChatter(t *testing.T) {
+	const (
+		dt     = 0.05
+		steps  = 400
+		jitter = 0.05 // доля безопасной дистанции, на которую колеблется зазор
+	)
+	tests := []struct {
+		name     string
+		band     float64
+		braking  bool // машина тормозит к моменту, когда зазор подошёл к порогу
+		chatters bool // без полосы состояние следует за порогом
+	}{
+		{name: "band 10% approaching", band: 0.1, braking: true},
+		{name: "band 10% cruising", band: 0.1, braking: false},
+		{name: "band 20% approaching", band: 0.2, braking: true},
+		{name: "no band", band: 0, braking: true, chatters: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Полоса задаётся командой physics, где 0 - отключение гистерезиса, а не «не менять»
+			sim := New(WithModel("simple"), WithPhysics(PhysicsConfig{HysteresisBand: &tt.band}))
+			if sim.HysteresisBand != tt.band {
+				t.Fatalf("hysteresis band = %g, want %g", sim.HysteresisBand, tt.band)
+			}
+			model := &simpleModel{s: sim}
+
+			car := &Car{ID: 1, Type: "car", Driver: DriverNormal, Speed: 20, TargetSpeed: 30, State: "normal"}
+			if tt.braking {
+				car.State = "braking"
+			}
+			leader := &Car{ID: 2, Type: "car", Driver: DriverNormal}
+			safe := getSafeDistance(0, sim.SafetyMultiplier) * driverProfile(car).Headway * sim.weatherEffect().Headway
+
+			flips := 0
+			for i := 0; i < steps; i++ {
+				sim.Time += dt
+				// Лидер едет с той же скоростью, зазор колеблется вокруг безопасной дистанции
+				leader.Speed = car.Speed
+				leader.Position = car.Position + leader.length() + safe*(1+jitter*math.Sin(float64(i)))
+				wasBraking := car.State == "braking"
+				sim.applyAcceleration(car, model.Accel(car, leader, dt), dt)
+				if (car.State == "braking") != wasBraking {
+					flips++
+				}
+			}
+			switch {
+			case tt.chatters && flips == 0:
+				t.Fatal("without a band the state should follow the threshold, got no flips")
+			case !tt.chatters && flips > 0:
+				t.Fatalf("state flipped %d times within the hysteresis band", flips)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package traffic
+
+import "math"
+
+// NaSchParams параметры клеточного автомата Нагеля - Шрекенберга (Nagel, Schreckenberg, 1992)
+type NaSchParams struct {
+	CellLength float64 `json:"cellLength"` // метры, длина клетки (место одной машины в заторе)
+	Tick       float64 `json:"tick"`       // секунды, такт автомата
+	Slowdown   float64 `json:"slowdown"`   // вероятность случайного замедления на такте
+}
+
+// defaultNaSchParams классические значения: клетка 7.5 м, такт 1 с, p = 0.3
+func defaultNaSchParams() NaSchParams {
+	return NaSchParams{
+		CellLength: 7.5,
+		Tick:       1.0,
+		Slowdown:   0.3,
+	}
+}
+
+// merge заменяет параметры ненулевыми значениями из update;
+// отрицательная вероятность замедления отключает случайные замедления
+func (p *NaSchParams) merge(update NaSchParams) {
+	if update.CellLength > 0 {
+		p.CellLength = update.CellLength
+	}
+	if update.Tick > 0 {
+		p.Tick = update.Tick
+	}
+	if update.Slowdown > 0 {
+		p.Slowdown = math.Min(1, update.Slowdown)
+	} else if update.Slowdown < 0 {
+		p.Slowdown = 0
+	}
+}
+
+// naschModel дискретная модель: скорость машины - целое число клеток за такт, дистанция
+// до лидера считается целыми свободными клетками. В начале каждого такта все машины
+// одновременно по состоянию на начало шага (параллельное обновление) применяют правила:
+//  1. разгон: v = min(v+1, vmax), где vmax - целевая скорость машины в клетках за такт;
+//  2. торможение: v = min(v, d), d - свободные клетки до лидера;
+//  3. случайное замедление: с вероятностью p v = max(v-1, 0);
+//
+// затем едут с постоянной скоростью до следующего такта, проходя за такт v клеток.
+type naschModel struct {
+	s *Simulation
+}
+
+func (m *naschModel) Accel(car, leader *Car, dt float64) float64 {
+	s := m.s
+	p := s.NaSch
+	// Такт начинается на шаге, пересекающем границу кратную p.Tick
+	tick := math.Floor(s.Time/p.Tick + 1e-9)
+	if tick == math.Floor((s.Time-dt)/p.Tick+1e-9) {
+		return 0
+	}
+
+	cellSpeed := p.CellLength / p.Tick // м/с за одну клетку в такт
+	v := math.Round(car.Speed / cellSpeed)
+	vmax := math.Round(car.TargetSpeed / cellSpeed)
+	if car.TargetSpeed > 0 {
+		vmax = math.Max(vmax, 1)
+	}
+	v = math.Min(v+1, vmax)
+	if leader != nil {
+		v = math.Min(v, math.Max(0, math.Floor(gapTo(car, leader)/p.CellLength)))
+	}
+	if v > 0 && naschRandom(s.Seed, car.ID, tick) < p.Slowdown {
+		v--
+	}
+
+	accel := (v*cellSpeed - car.Speed) / dt
+	if accel == 0 && car.State == "braking" {
+		// Скорость между тактами постоянна: машина, не сбавившая скорость на новом
+		// такте, больше не тормозит (иначе applyAcceleration сохранит торможение)
+		car.State = "normal"
+	}
+	return accel
+}
+
+// naschRandom возвращает равномерное на [0, 1) число для машины id на такте tick.
+// Число зависит только от зерна, машины и такта, а не от порядка расчёта, поэтому
+// прогон с тем же зерном повторяется при любом числе горутин и не сдвигает
+// поток основного генератора.
+func naschRandom(seed int64, id int, tick float64) float64 {
+	// Перемешивание splitmix64
+	x := uint64(seed) ^ uint64(id)*0x9e3779b97f4a7c15 ^ uint64(int64(tick))*0xbf58476d1ce4e5b9
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11) / (1 << 53)
+}
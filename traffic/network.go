@@ -0,0 +1,625 @@
+package traffic
+
+import (
+	"container/heap"
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	networkMergeZone = 200.0 // метры до узла, на которых машины разных звеньев договариваются о порядке въезда
+)
+
+// Network дорожная сеть: узлы (развилки и слияния) и соединяющие их звенья. Каждое
+// звено - прямая дорога со своими полосами и машинами, позиции машин отсчитываются
+// от начала звена. Машины появляются в узлах-источниках по матрице спроса Demand
+// и едут по кратчайшему маршруту до узла назначения.
+type Network struct {
+	Nodes  []NetworkNode  `json:"nodes"`
+	Links  []*NetworkLink `json:"links"`
+	Demand []ODDemand     `json:"demand"`
+
+	links    map[string]*NetworkLink
+	signals  map[string]*NodeSignal // светофоры узлов по идентификатору узла
+	ports    map[string]*Roundabout // развязки узлов въезда-выезда по идентификатору узла
+	routes   [][]*NetworkLink       // маршрут каждой пары спроса (nil - назначение недостижимо)
+	arrivals []float64              // время следующего прибытия каждой пары спроса
+}
+
+// NetworkNode узел сети; координаты нужны только для отрисовки
+type NetworkNode struct {
+	ID     string      `json:"id"`
+	X      float64     `json:"x"`                // метры
+	Y      float64     `json:"y"`                // метры
+	Signal *NodeSignal `json:"signal,omitempty"` // светофор перекрёстка (nil - нерегулируемый узел)
+
+	Roundabout *Roundabout `json:"roundabout,omitempty"` // кольцевая развязка в узле (см. Roundabout)
+	Port       string      `json:"port,omitempty"`       // развязка, которой принадлежит узел въезда-выезда
+}
+
+// NetworkLink звено сети от узла From до узла To
+type NetworkLink struct {
+	ID         string  `json:"id"`
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Length     float64 `json:"length"`           // метры
+	Lanes      int     `json:"lanes"`            // число полос (0 - одна)
+	SpeedLimit float64 `json:"speedLimit"`       // км/ч (0 - без ограничения)
+	Cars       []*Car  `json:"cars"`             // машины звена по убыванию позиции
+	Signal     string  `json:"signal,omitempty"` // сигнал светофора в конце звена: "green", "yellow", "red" или ""
+
+	// Статистика подхода к узлу
+	Exited        int     `json:"exited"`        // машин проехали звено до конца
+	Delay         float64 `json:"delay"`         // секунды, суммарная задержка проехавших машин
+	MeanDelay     float64 `json:"meanDelay"`     // секунды, средняя задержка на машину
+	MaxDelay      float64 `json:"maxDelay"`      // секунды, наибольшая задержка машины
+	Queue         int     `json:"queue"`         // машин в очереди у конца звена на самой длинной полосе
+	MaxQueue      int     `json:"maxQueue"`      // наибольшая очередь прогона
+	SpillbackTime float64 `json:"spillbackTime"` // секунды, когда очередь доходила до начала звена
+}
+
+// ODDemand спрос между парой узлов
+type ODDemand struct {
+	From string  `json:"from"`
+	To   string  `json:"to"`
+	Rate float64 `json:"rate"` // авто/ч
+}
+
+// Validate проверяет граф сети: узлы и звенья с уникальными идентификаторами,
+// звенья между существующими узлами, достижимые назначения спроса
+func (n Network) Validate() error {
+	var e ValidationError
+	nodes := make(map[string]bool, len(n.Nodes))
+	for i, node := range n.Nodes {
+		switch {
+		case node.ID == "":
+			e.add(fmt.Sprintf("nodes[%d].id", i), "must not be empty")
+		case nodes[node.ID]:
+			e.add(fmt.Sprintf("nodes[%d].id", i), fmt.Sprintf("duplicate node %q", node.ID))
+		}
+		nodes[node.ID] = true
+	}
+	links := make(map[string]bool, len(n.Links))
+	for i, link := range n.Links {
+		if link == nil {
+			e.add(fmt.Sprintf("links[%d]", i), "must not be null")
+			continue
+		}
+		switch {
+		case link.ID == "":
+			e.add(fmt.Sprintf("links[%d].id", i), "must not be empty")
+		case links[link.ID]:
+			e.add(fmt.Sprintf("links[%d].id", i), fmt.Sprintf("duplicate link %q", link.ID))
+		}
+		links[link.ID] = true
+		if !nodes[link.From] {
+			e.add(fmt.Sprintf("links[%d].from", i), fmt.Sprintf("unknown node %q", link.From))
+		}
+		if !nodes[link.To] {
+			e.add(fmt.Sprintf("links[%d].to", i), fmt.Sprintf("unknown node %q", link.To))
+		}
+		if link.From == link.To {
+			e.add(fmt.Sprintf("links[%d].to", i), "must differ from from")
+		}
+		if link.Length <= 0 {
+			e.add(fmt.Sprintf("links[%d].length", i), "must be positive")
+		}
+		if link.Lanes < 0 || link.Lanes > MaxLanes {
+			e.add(fmt.Sprintf("links[%d].lanes", i), fmt.Sprintf("must be between 1 and %d", MaxLanes))
+		}
+		e.nonNegative(fmt.Sprintf("links[%d].speedLimit", i), link.SpeedLimit)
+	}
+	if len(e.Fields) > 0 {
+		return e.result()
+	}
+	for i, node := range n.Nodes {
+		if node.Signal != nil {
+			e.nest(fmt.Sprintf("nodes[%d].signal", i), node.Signal.Validate(node.ID, n.Links))
+		}
+		if node.Roundabout != nil {
+			e.nest(fmt.Sprintf("nodes[%d].roundabout", i), node.Roundabout.Validate())
+			if node.Signal != nil {
+				e.add(fmt.Sprintf("nodes[%d].signal", i), "must not be set on a roundabout")
+			}
+		}
+	}
+
+	graph := n.build()
+	for i, d := range n.Demand {
+		e.nonNegative(fmt.Sprintf("demand[%d].rate", i), d.Rate)
+		switch {
+		case !nodes[d.From]:
+			e.add(fmt.Sprintf("demand[%d].from", i), fmt.Sprintf("unknown node %q", d.From))
+		case !nodes[d.To]:
+			e.add(fmt.Sprintf("demand[%d].to", i), fmt.Sprintf("unknown node %q", d.To))
+		case d.From == d.To:
+			e.add(fmt.Sprintf("demand[%d].to", i), "must differ from from")
+		case graph.shortestRoute(d.From, d.To) == nil:
+			e.add(fmt.Sprintf("demand[%d].to", i), fmt.Sprintf("no route from %q", d.From))
+		}
+	}
+	return e.result()
+}
+
+// build копирует граф сети без машин и строит маршруты пар спроса
+func (n Network) build() *Network {
+	network := &Network{
+		Nodes:    make([]NetworkNode, len(n.Nodes)),
+		Links:    make([]*NetworkLink, 0, len(n.Links)),
+		Demand:   append([]ODDemand(nil), n.Demand...),
+		arrivals: make([]float64, len(n.Demand)),
+	}
+	for i, node := range n.Nodes {
+		network.Nodes[i] = node
+		if node.Signal != nil {
+			signal := *node.Signal
+			network.Nodes[i].Signal = &signal
+		}
+		if node.Roundabout != nil {
+			roundabout := *node.Roundabout
+			network.Nodes[i].Roundabout = &roundabout
+		}
+	}
+	for _, link := range n.Links {
+		network.Links = append(network.Links, &NetworkLink{
+			ID: link.ID, From: link.From, To: link.To, Length: link.Length, Lanes: link.Lanes,
+			SpeedLimit: link.SpeedLimit, Cars: make([]*Car, 0),
+		})
+	}
+	network.expandRoundabouts()
+	network.prepare()
+	return network
+}
+
+// prepare строит индекс звеньев и маршруты пар спроса (после build и загрузки состояния)
+func (n *Network) prepare() {
+	n.links = make(map[string]*NetworkLink, len(n.Links))
+	for _, link := range n.Links {
+		link.Lanes = max(1, link.Lanes)
+		n.links[link.ID] = link
+	}
+	n.signals = make(map[string]*NodeSignal)
+	n.ports = make(map[string]*Roundabout)
+	for _, node := range n.Nodes {
+		if node.Signal != nil {
+			n.signals[node.ID] = node.Signal
+		}
+		if node.Port != "" {
+			if center := n.node(node.Port); center != nil {
+				n.ports[node.ID] = center.Roundabout
+			}
+		}
+	}
+	n.routes = make([][]*NetworkLink, len(n.Demand))
+	for i, d := range n.Demand {
+		n.routes[i] = n.shortestRoute(d.From, d.To)
+	}
+	if len(n.arrivals) != len(n.Demand) {
+		n.arrivals = make([]float64, len(n.Demand))
+	}
+}
+
+// shortestRoute ищет кратчайший по длине маршрут из узла from в узел to
+// алгоритмом Дейкстры (nil - маршрута нет)
+func (n *Network) shortestRoute(from, to string) []*NetworkLink {
+	dist := map[string]float64{from: 0}
+	via := make(map[string]*NetworkLink)
+	queue := &routeQueue{{node: from}}
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(routeItem)
+		if item.dist > dist[item.node] {
+			continue
+		}
+		if item.node == to {
+			break
+		}
+		for _, link := range n.Links {
+			if link.From != item.node {
+				continue
+			}
+			d := item.dist + link.Length
+			if known, ok := dist[link.To]; !ok || d < known {
+				dist[link.To] = d
+				via[link.To] = link
+				heap.Push(queue, routeItem{node: link.To, dist: d})
+			}
+		}
+	}
+	if _, ok := via[to]; !ok {
+		return nil
+	}
+	var route []*NetworkLink
+	for node := to; node != from; node = via[node].From {
+		route = append(route, via[node])
+	}
+	for i, j := 0, len(route)-1; i < j; i, j = i+1, j-1 {
+		route[i], route[j] = route[j], route[i]
+	}
+	return route
+}
+
+// routeItem узел в очереди алгоритма Дейкстры
+type routeItem struct {
+	node string
+	dist float64
+}
+
+// routeQueue очередь с приоритетом по расстоянию для container/heap
+type routeQueue []routeItem
+
+func (q routeQueue) Len() int           { return len(q) }
+func (q routeQueue) Less(i, j int) bool { return q[i].dist < q[j].dist }
+func (q routeQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *routeQueue) Push(x any)        { *q = append(*q, x.(routeItem)) }
+func (q *routeQueue) Pop() any {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// networkMode сообщает, моделируется ли сеть вместо одной дороги
+func (s *Simulation) networkMode() bool {
+	return s.RoadType == RoadNetwork && s.Network != nil
+}
+
+// setNetwork заменяет граф сети; машины прежней сети убираются
+func (s *Simulation) setNetwork(config Network) {
+	s.Network = config.build()
+	for i := range s.Network.arrivals {
+		s.Network.arrivals[i] = s.Time
+	}
+}
+
+// resetNetwork убирает машины сети, обнуляет статистику звеньев и начинает прибытия заново
+func (s *Simulation) resetNetwork() {
+	if s.Network == nil {
+		return
+	}
+	for _, link := range s.Network.Links {
+		link.Cars = make([]*Car, 0)
+		link.Exited, link.Delay, link.MeanDelay, link.MaxDelay = 0, 0, 0, 0
+		link.Queue, link.MaxQueue, link.SpillbackTime = 0, 0, 0
+	}
+	clear(s.Network.arrivals)
+}
+
+// networkState копирует сеть для рассылки: списки машин звеньев не меняются на месте
+// (каждый шаг строит новые), поэтому копируются только сами звенья
+func (s *Simulation) networkState() *Network {
+	if s.Network == nil {
+		return nil
+	}
+	network := *s.Network
+	network.Links = make([]*NetworkLink, len(s.Network.Links))
+	for i, link := range s.Network.Links {
+		copied := *link
+		network.Links[i] = &copied
+	}
+	return &network
+}
+
+// networkCars возвращает все машины сети
+func (s *Simulation) networkCars() []*Car {
+	if s.Network == nil {
+		return nil
+	}
+	var cars []*Car
+	for _, link := range s.Network.Links {
+		cars = append(cars, link.Cars...)
+	}
+	return cars
+}
+
+// nextLink возвращает следующее звено маршрута машины (nil - звено последнее)
+func (n *Network) nextLink(car *Car) *NetworkLink {
+	if car.routeStep+1 >= len(car.Route) {
+		return nil
+	}
+	return n.links[car.Route[car.routeStep+1]]
+}
+
+// entryLane возвращает полосу звена next, на которую въезжает машина с полосы lane:
+// при сужении машины с исчезающих полос переходят на крайнюю левую оставшуюся
+func entryLane(next *NetworkLink, lane int) int {
+	return min(lane, next.Lanes-1)
+}
+
+// stepNetwork продвигает сеть на dt секунд: светофоры и прибытия по матрице спроса,
+// ускорения всех машин по состоянию на начало шага, перемещение, переход на следующие
+// звенья маршрутов, проверка аварий и очереди на звеньях
+func (s *Simulation) stepNetwork(dt float64) {
+	n := s.Network
+	s.updateSignals()
+	s.spawnNetwork()
+
+	model := s.followingModel()
+	_, cellular := model.(*naschModel)
+	var seen Car
+	accels := make([][]float64, len(n.Links))
+	for i, link := range n.Links {
+		accels[i] = make([]float64, len(link.Cars))
+		for j, car := range link.Cars {
+			if car.crashed() {
+				continue
+			}
+			s.adaptLinkSpeed(car, link)
+			ahead := s.linkObstacle(link, j)
+			leader := s.perceiveLeader(car, ahead, &seen)
+			switch {
+			case car.automated():
+				accels[i][j] = s.accAcceleration(car, leader, dt)
+			case cellular:
+				accels[i][j] = model.Accel(car, ahead, dt)
+			default:
+				accels[i][j] = model.Accel(car, leader, dt)
+			}
+		}
+	}
+
+	for i, link := range n.Links {
+		for j, car := range link.Cars {
+			if car.crashed() {
+				continue
+			}
+			prevSpeed := car.Speed
+			s.applyAcceleration(car, accels[i][j], dt)
+			s.burnFuel(car, prevSpeed, dt)
+			car.Position += car.Speed * dt
+			s.trackTrip(car, car.Speed*dt, dt)
+			if limit := kmhToMs(link.SpeedLimit); limit > 0 && car.Speed > limit {
+				car.OverLimitTime += dt
+				s.OverLimitTime += dt
+			}
+		}
+	}
+
+	s.transferNetworkCars()
+	s.detectNetworkCollisions()
+	s.updateLinkQueues(dt)
+
+	if s.TotalCarsMade >= s.MaxCars && len(s.networkCars()) == 0 {
+		s.Running = false
+		summary := s.summary()
+		s.emit(Event{Type: "runComplete", Time: s.Time, Summary: &summary})
+	}
+}
+
+// spawnNetwork выпускает машины пар спроса с пуассоновскими интервалами со средним
+// 3600/Rate секунд. Машина появляется в начале первого звена маршрута на полосе
+// с наибольшим свободным местом; если места нет, прибытие ждёт.
+func (s *Simulation) spawnNetwork() {
+	n := s.Network
+	for i, d := range n.Demand {
+		route := n.routes[i]
+		if route == nil || d.Rate <= 0 || s.Time < n.arrivals[i] {
+			continue
+		}
+		if s.TotalCarsMade >= s.MaxCars {
+			return
+		}
+		first := route[0]
+		lane, ok := linkEntryLane(first)
+		if !ok {
+			continue
+		}
+		car := s.newCar(lane, 0)
+		car.Route = make([]string, len(route))
+		for k, link := range route {
+			car.Route[k] = link.ID
+		}
+		s.adaptLinkSpeed(car, first)
+		car.Speed = car.TargetSpeed
+		s.enterLink(car)
+		// Машины звена упорядочены по убыванию позиции: новая машина в начале - последняя
+		first.Cars = append(first.Cars, car)
+		s.TotalCarsMade++
+		s.emitExport(Event{Type: "car:spawned", Time: s.Time, Cars: []int{car.ID}, Position: car.Position, Speed: car.Speed, Link: first.ID})
+		n.arrivals[i] = s.Time + 3600/d.Rate*s.rng.ExpFloat64()
+	}
+}
+
+// linkEntryLane выбирает полосу звена с наибольшим свободным местом в начале
+// и сообщает, свободно ли на ней не меньше spawnClearance
+func linkEntryLane(link *NetworkLink) (int, bool) {
+	space := make([]float64, link.Lanes)
+	for lane := range space {
+		space[lane] = math.MaxFloat64
+	}
+	for i := len(link.Cars) - 1; i >= 0; i-- {
+		car := link.Cars[i]
+		if car.Lane < link.Lanes && space[car.Lane] == math.MaxFloat64 {
+			space[car.Lane] = car.Position - car.length()
+		}
+	}
+	best, bestSpace := 0, -1.0
+	for lane, free := range space {
+		if free > bestSpace {
+			best, bestSpace = lane, free
+		}
+	}
+	return best, bestSpace >= spawnClearance
+}
+
+// adaptLinkSpeed выбирает целевую скорость машины на звене: водитель не превышает
+// ограничение звена, умноженное на множитель скорости его профиля
+func (s *Simulation) adaptLinkSpeed(car *Car, link *NetworkLink) {
+	car.TargetSpeed = car.desiredSpeed * s.weatherEffect().Speed
+	if link.SpeedLimit > 0 {
+		car.TargetSpeed = math.Min(car.TargetSpeed, kmhToMs(link.SpeedLimit)*driverProfile(car).TargetSpeed)
+	}
+}
+
+// linkObstacle возвращает препятствие впереди j-й машины звена: машину впереди на её
+// полосе, иначе стоп-линию светофора в конце звена или последнюю машину следующего
+// звена маршрута на полосе въезда (копией с позицией от начала текущего звена).
+// Перед слиянием ближайшей препятствием может оказаться машина другого звена,
+// которую нужно пропустить (см. mergeLeader).
+func (s *Simulation) linkObstacle(link *NetworkLink, j int) *Car {
+	car := link.Cars[j]
+	for k := j - 1; k >= 0; k-- {
+		if link.Cars[k].Lane == car.Lane {
+			return link.Cars[k]
+		}
+	}
+
+	if stop := s.signalStop(link, car); stop != nil {
+		return stop
+	}
+	next := s.Network.nextLink(car)
+	if next == nil {
+		return nil
+	}
+	var leader *Car
+	lane := entryLane(next, car.Lane)
+	for k := len(next.Cars) - 1; k >= 0; k-- {
+		if other := next.Cars[k]; other.Lane == lane {
+			shifted := *other
+			shifted.Position += link.Length
+			leader = &shifted
+			break
+		}
+	}
+	for _, rival := range []*Car{s.mergeLeader(link, car, next), s.yieldStop(link, car, next)} {
+		if rival != nil && (leader == nil || rival.Position < leader.Position) {
+			leader = rival
+		}
+	}
+	return leader
+}
+
+// mergeLeader возвращает машину, которую первая на своей полосе машина пропускает
+// на въезде на звено next (nil - пропускать некого). За networkMergeZone метров до
+// узла первые машины полос всех звеньев, въезжающие на ту же полосу next, въезжают
+// по очереди «застёжкой»: раньше едет машина ближе к узлу, при равенстве - с более
+// раннего звена и более правой полосы. Пропускаемая машина возвращается копией,
+// перенесённой на текущее звено на том же расстоянии до узла, и водитель держит
+// дистанцию до неё, как до лидера.
+func (s *Simulation) mergeLeader(link *NetworkLink, car *Car, next *NetworkLink) *Car {
+	distance := link.Length - car.Position
+	if distance > networkMergeZone {
+		return nil
+	}
+	lane := entryLane(next, car.Lane)
+	order := s.linkOrder(link)
+	var leader *Car
+	// На развязке кольцо не уступает подходу, а подход въезжает по промежуткам (см. yieldStop)
+	roundabout := s.Network.ports[link.To] != nil
+	for i, other := range s.Network.Links {
+		if other.To != link.To || (roundabout && other != link) {
+			continue
+		}
+		for _, rival := range firstInLanes(other) {
+			// Машина, стоящая на красный, не въезжает и не задерживает остальных
+			if rival == car || s.Network.nextLink(rival) != next || entryLane(next, rival.Lane) != lane || s.signalStop(other, rival) != nil {
+				continue
+			}
+			d := other.Length - rival.Position
+			ahead := d < distance || (d == distance && (i < order || (i == order && rival.Lane < car.Lane)))
+			if !ahead || (leader != nil && link.Length-d <= leader.Position) {
+				continue
+			}
+			projected := *rival
+			projected.Lane = car.Lane
+			projected.Position = link.Length - d
+			leader = &projected
+		}
+	}
+	return leader
+}
+
+// linkOrder возвращает номер звена в сети
+func (s *Simulation) linkOrder(link *NetworkLink) int {
+	for i, other := range s.Network.Links {
+		if other == link {
+			return i
+		}
+	}
+	return -1
+}
+
+// firstInLanes возвращает первые (ближайшие к концу звена) машины каждой полосы
+func firstInLanes(link *NetworkLink) []*Car {
+	first := make([]*Car, 0, link.Lanes)
+	seen := 0
+	for _, car := range link.Cars {
+		if seen&(1<<car.Lane) == 0 {
+			seen |= 1 << car.Lane
+			first = append(first, car)
+		}
+	}
+	return first
+}
+
+// transferNetworkCars переводит машины, проехавшие конец звена, на следующее звено
+// маршрута, а доехавшие до назначения убирает с учётом поездки. Списки машин звеньев
+// строятся заново, а не меняются на месте: состояние для рассылки отдаёт их без копирования.
+func (s *Simulation) transferNetworkCars() {
+	n := s.Network
+	entering := make(map[*NetworkLink][]*Car)
+	for _, link := range n.Links {
+		cars := make([]*Car, 0, len(link.Cars))
+		for _, car := range link.Cars {
+			if s.cleared(car) {
+				continue
+			}
+			if car.Position < link.Length {
+				cars = append(cars, car)
+				continue
+			}
+			s.exitLink(link, car)
+			next := n.nextLink(car)
+			if next == nil {
+				s.completeTrip(car)
+				continue
+			}
+			car.Position -= link.Length
+			car.Lane = entryLane(next, car.Lane)
+			car.routeStep++
+			s.enterLink(car)
+			entering[next] = append(entering[next], car)
+		}
+		link.Cars = cars
+	}
+	for _, link := range n.Links {
+		if cars := entering[link]; len(cars) > 0 {
+			link.Cars = append(append(make([]*Car, 0, len(link.Cars)+len(cars)), link.Cars...), cars...)
+			sort.SliceStable(link.Cars, func(i, j int) bool { return link.Cars[i].Position > link.Cars[j].Position })
+		}
+	}
+}
+
+// detectNetworkCollisions находит на каждом звене машины, оказавшиеся ближе длины
+// лидера позади него (въехавшие в лидера или одновременно въехавшие на звено):
+// машина ставится вплотную за лидером, авария учитывается и публикуется событием
+// "collision" с идентификатором звена, как на основной дороге
+func (s *Simulation) detectNetworkCollisions() {
+	for _, link := range s.Network.Links {
+		last := make([]*Car, link.Lanes)
+		for _, car := range link.Cars {
+			leader := last[car.Lane]
+			last[car.Lane] = car
+			if leader == nil || gapTo(car, leader) >= 0 || car.crashed() {
+				continue
+			}
+			car.Position = leader.Position - leader.length()
+			car.Speed = leader.Speed
+			s.Collisions++
+			s.emit(Event{
+				Type:     "collision",
+				Time:     s.Time,
+				Cars:     []int{car.ID, leader.ID},
+				Lane:     car.Lane,
+				Position: car.Position,
+				Link:     link.ID,
+			})
+			if s.CrashClearance > 0 {
+				s.freezeCar(car)
+				if !leader.crashed() {
+					s.freezeCar(leader)
+				}
+			}
+		}
+	}
+}
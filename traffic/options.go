@@ -0,0 +1,71 @@
+package traffic
+
+// Option настраивает симуляцию при создании через New
+type Option func(*Simulation)
+
+// WithSeed задаёт зерно генератора случайных чисел
+func WithSeed(seed int64) Option {
+	return func(s *Simulation) {
+		s.Seed = seed
+	}
+}
+
+// WithConfig применяет конфигурацию спроса (скорости в км/ч), как команда config
+func WithConfig(config SimulationConfig) Option {
+	return func(s *Simulation) {
+		s.applyConfig(config)
+	}
+}
+
+// WithPhysics применяет параметры физики, как команда physics
+func WithPhysics(config PhysicsConfig) Option {
+	return func(s *Simulation) {
+		s.applyPhysics(config)
+	}
+}
+
+// WithSpawnInterval задаёт интервал появления машин в секундах
+func WithSpawnInterval(seconds float64) Option {
+	return func(s *Simulation) {
+		s.SpawnInterval = seconds
+	}
+}
+
+// WithSpeedRange задаёт диапазон целевых скоростей новых машин в км/ч
+func WithSpeedRange(minKmh, maxKmh float64) Option {
+	return func(s *Simulation) {
+		s.MinSpeed = kmhToMs(minKmh)
+		s.MaxSpeed = kmhToMs(maxKmh)
+	}
+}
+
+// WithMaxCars задаёт число машин, после которого генерация прекращается
+func WithMaxCars(n int) Option {
+	return func(s *Simulation) {
+		s.MaxCars = n
+	}
+}
+
+// WithTimeScale задаёт множитель скорости времени
+func WithTimeScale(scale float64) Option {
+	return func(s *Simulation) {
+		s.TimeScale = clampTimeScale(scale)
+	}
+}
+
+// WithModel выбирает модель следования за лидером из зарегистрированных
+func WithModel(name string) Option {
+	return func(s *Simulation) {
+		if knownModel(name) {
+			s.Model = name
+		}
+	}
+}
+
+// WithWorkers задаёт число горутин, между которыми делится расчёт машин на шаге
+// (0 - GOMAXPROCS, 1 - последовательно); результат от него не зависит
+func WithWorkers(n int) Option {
+	return func(s *Simulation) {
+		s.workers = n
+	}
+}
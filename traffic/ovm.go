@@ -0,0 +1,64 @@
+package traffic
+
+import "math"
+
+// OVMParams параметры модели оптимальной скорости (Optimal Velocity Model, Bando 1995)
+type OVMParams struct {
+	Sensitivity float64 `json:"sensitivity"` // 1/с, скорость подстройки под оптимальную скорость
+	Distance    float64 `json:"distance"`    // метры, дистанция, на которой оптимальная скорость растёт быстрее всего
+	Width       float64 `json:"width"`       // метры, ширина перехода от остановки к желаемой скорости
+}
+
+// defaultOVMParams значения, при которых поток средней плотности неустойчив
+// (критическая чувствительность для 100 км/ч около 2.8 1/с)
+func defaultOVMParams() OVMParams {
+	return OVMParams{
+		Sensitivity: 2.0,
+		Distance:    25.0,
+		Width:       10.0,
+	}
+}
+
+// merge заменяет параметры ненулевыми значениями из update
+func (p *OVMParams) merge(update OVMParams) {
+	if update.Sensitivity > 0 {
+		p.Sensitivity = update.Sensitivity
+	}
+	if update.Distance > 0 {
+		p.Distance = update.Distance
+	}
+	if update.Width > 0 {
+		p.Width = update.Width
+	}
+}
+
+// optimalVelocity возвращает оптимальную скорость при дистанции gap до лидера:
+// V(s) = v0 · [tanh((s - d)/w) + tanh(d/w)] / [1 + tanh(d/w)], V(0) = 0, V(∞) = v0
+func optimalVelocity(p OVMParams, v0, gap float64) float64 {
+	offset := math.Tanh(p.Distance / p.Width)
+	return math.Max(0, v0*(math.Tanh((gap-p.Distance)/p.Width)+offset)/(1+offset))
+}
+
+// ovmModel модель оптимальной скорости: dv/dt = a · [V(s) - v]. Однородный поток
+// неустойчив, если a < 2·V'(s): малое возмущение растёт в волну «стоп-старт»
+// без внешней причины. Разгон ограничен ускорением машины; торможение, как в исходной
+// модели, не ограничено (не больше a·v): с пределом 9 м/с² волна заканчивается авариями.
+type ovmModel struct {
+	s *Simulation
+}
+
+func (m *ovmModel) Accel(car, leader *Car, dt float64) float64 {
+	s := m.s
+	p := s.OVM
+	// Профиль водителя и погода растягивают дистанции, как интервал IDM
+	scale := driverProfile(car).Headway * s.weatherEffect().Headway
+	p.Distance *= scale
+	p.Width *= scale
+
+	target := car.TargetSpeed
+	if leader != nil {
+		target = optimalVelocity(p, car.TargetSpeed, gapTo(car, leader))
+	}
+	accel := p.Sensitivity * (target - car.Speed)
+	return math.Min(accel, s.carAcceleration(car))
+}
@@ -0,0 +1,190 @@
+package traffic
+
+import (
+	"runtime"
+	"sync"
+)
+
+// ParallelChunk наименьшее число машин на одну горутину обновления: парк меньше
+// двух таких частей обновляется последовательно, так как запуск горутин дороже расчёта
+const ParallelChunk = 256
+
+// carUpdate промежуточный результат расчёта машины на шаге
+type carUpdate struct {
+	ahead         *Car    // препятствие впереди в начале шага
+	leader        *Car    // препятствие, каким его видит водитель (с задержкой реакции)
+	seen          Car     // память для препятствия из прошлого, см. perceiveLeader
+	accel         float64 // ускорение, если ready
+	ready         bool    // ускорение рассчитано на параллельном этапе
+	incidentDelay float64 // вклад машины в IncidentDelay
+}
+
+// updateCars продвигает машины на dt секунд в три этапа:
+//  1. последовательно от головы потока к хвосту - перестроения, меняющие полосы других машин;
+//  2. параллельно (см. parallel) - поиск препятствия впереди, целевая скорость, восприятие
+//     водителя и ускорение по модели следования. Машины читают лидеров в состоянии на начало
+//     шага и меняют только себя, поэтому результат не зависит от числа горутин;
+//  3. последовательно от головы к хвосту - колонны и ACC, которым нужно ускорение
+//     предшественника на этом же шаге, затем скорость, позиция и накопители статистики.
+func (s *Simulation) updateCars(dt float64) {
+	for _, car := range s.Cars {
+		car.stepFrom = car.Position
+		// Перестраиваемся, если на соседней полосе свободнее, или обгоняем по встречной;
+		// принудительно тормозящая машина и машина игрока остаются на своей полосе,
+		// спецмашина и автобус маршрута, которому ещё предстоят остановки, покидают её
+		// только перед участком работ
+		if s.yieldToEmergency(car, s.emergencies) {
+			continue
+		}
+		if car.crashed() || car.forcedBraking(s.Time) || car.Player || s.leaveClosedLane(car) || car.emergency() || s.routeBus(car) {
+			continue
+		}
+		if s.twoWay() {
+			s.considerOvertaking(car)
+		} else {
+			s.considerLaneChange(car)
+		}
+	}
+
+	n := len(s.Cars)
+	if cap(s.carUpdates) < n {
+		s.carUpdates = make([]carUpdate, n)
+	}
+	updates := s.carUpdates[:n]
+	clear(updates)
+	// Экземпляр модели создаётся до запуска горутин
+	model := s.followingModel()
+	// Препятствия ищутся отдельным проходом: копии лидеров на кольце снимаются,
+	// пока ни одна машина не меняет своих полей. Машина, пропускающая перед собой
+	// машину с закрываемой полосы, следует за ней.
+	s.parallel(n, func(from, to int) {
+		for i := from; i < to; i++ {
+			car := s.Cars[i]
+			updates[i].ahead = s.obstacleAhead(car, car.Lane)
+			if merging := s.mergeCourtesy(car, updates[i].ahead); merging != nil {
+				updates[i].ahead = merging
+			}
+		}
+	})
+	s.parallel(n, func(from, to int) {
+		for i := from; i < to; i++ {
+			s.prepareCar(s.Cars[i], &updates[i], model, dt)
+		}
+	})
+
+	for i, car := range s.Cars {
+		s.moveCar(car, &updates[i], dt)
+	}
+}
+
+// prepareCar рассчитывает машину на параллельном этапе; меняет только саму машину
+func (s *Simulation) prepareCar(car *Car, u *carUpdate, model FollowingModel, dt float64) {
+	// Машины, попавшие в аварию, стоят до расчистки
+	if car.crashed() {
+		return
+	}
+	if s.upstreamOfBlockage(car) && car.TargetSpeed > 0 {
+		u.incidentDelay = dt * max(0, 1-car.Speed/car.TargetSpeed)
+	}
+
+	// Целевая скорость меняется при въезде в зону ограничения и выезде из неё
+	s.adaptTargetSpeed(car)
+
+	// Ускорение по выбранной модели следования; водитель реагирует на препятствие
+	// впереди с задержкой ReactionDelay. Команда car может заставить машину тормозить
+	// до остановки, автобус на остановке стоит, машину игрока ведут его педали. Автоматизированные машины
+	// рассчитываются на последовательном этапе.
+	u.leader = s.perceiveLeader(car, u.ahead, &u.seen)
+	u.ready = true
+	switch {
+	case car.forcedBraking(s.Time), car.Overtaking == OvertakeAborting, car.Dwelling:
+		u.accel = -s.carBraking(car)
+	case car.Player:
+		u.accel = s.playerAcceleration(car)
+	case car.automated():
+		u.ready = false
+	default:
+		leader := u.leader
+		if _, cellular := model.(*naschModel); cellular {
+			// Автомат не моделирует задержку реакции (её роль играет случайное замедление):
+			// правило торможения безопасно только для текущей дистанции
+			leader = u.ahead
+		}
+		u.accel = model.Accel(car, leader, dt)
+	}
+}
+
+// moveCar завершает шаг машины на последовательном этапе: автоматизированные машины
+// управляются ACC, а машины колонны - CACC по переданному состоянию предшественника
+func (s *Simulation) moveCar(car *Car, u *carUpdate, dt float64) {
+	if car.crashed() {
+		s.sampleDiagram(car.Position, 0, dt)
+		return
+	}
+	s.IncidentDelay += u.incidentDelay
+
+	s.updatePlatoon(car, u.ahead)
+	accel := u.accel
+	if !u.ready {
+		if car.inPlatoon() {
+			accel = s.caccAcceleration(car, u.ahead, dt)
+		} else {
+			accel = s.accAcceleration(car, u.leader, dt)
+		}
+	}
+	// Скорость клеточного автомата меняется только на тактах, уклон её не меняет
+	if _, cellular := s.model.(*naschModel); !cellular {
+		accel = s.gradeAcceleration(car, accel)
+	}
+	prevSpeed := car.Speed
+	s.applyAcceleration(car, accel, dt)
+	s.burnFuel(car, prevSpeed, dt)
+
+	// Обновляем позицию
+	prevPosition := car.Position
+	car.Position += car.Speed * dt
+	s.countSegmentExits(prevPosition, car.Position)
+	s.updateDetectors(car, prevPosition, car.Position, dt)
+	s.sampleDiagram(prevPosition, car.Position-prevPosition, dt)
+	s.trackTrip(car, car.Position-prevPosition, dt)
+	s.trackWorkZone(car, prevPosition, dt)
+	s.trackCameras(car, prevPosition)
+	s.trackHeadway(car, prevPosition)
+	if s.ring() && car.Position >= RoadLength {
+		// Круг пройден: машина продолжает движение с начала кольца
+		car.Position -= RoadLength
+		s.completeTrip(car)
+	}
+
+	// Учитываем время движения с превышением ограничения; спецмашина ограничений не соблюдает
+	if limit := s.applicableLimit(car); limit > 0 && car.Speed > limit && !car.emergency() {
+		car.OverLimitTime += dt
+		s.OverLimitTime += dt
+	}
+}
+
+// parallel делит индексы [0, n) на непрерывные части и выполняет run для каждой
+// в своей горутине: частей не больше WithWorkers (по умолчанию GOMAXPROCS) и в каждой
+// не меньше ParallelChunk машин; при одной части run выполняется в текущей горутине
+func (s *Simulation) parallel(n int, run func(from, to int)) {
+	workers := s.workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	chunks := min(workers, n/ParallelChunk)
+	if chunks <= 1 {
+		run(0, n)
+		return
+	}
+
+	size := (n + chunks - 1) / chunks
+	var wg sync.WaitGroup
+	for from := 0; from < n; from += size {
+		wg.Add(1)
+		go func(from, to int) {
+			defer wg.Done()
+			run(from, to)
+		}(from, min(from+size, n))
+	}
+	wg.Wait()
+}
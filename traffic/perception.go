@@ -0,0 +1,127 @@
+package traffic
+
+import "math"
+
+// perceptionSample наблюдение водителем препятствия впереди в момент Time
+type perceptionSample struct {
+	Time   float64 `json:"time"`
+	Leader bool    `json:"leader"` // впереди было препятствие (иначе остальные поля не заполнены)
+	ID     int     `json:"id"`
+	Type   string  `json:"type"`
+	Gap    float64 `json:"gap"`    // метры, свободное расстояние до препятствия
+	Speed  float64 `json:"speed"`  // м/с, скорость препятствия
+	Length float64 `json:"length"` // метры
+}
+
+// perceptionBuffer кольцевой буфер последних наблюдений водителя; растёт,
+// если в нём не помещается история за время реакции
+type perceptionBuffer struct {
+	samples []perceptionSample
+	start   int // индекс самого старого наблюдения
+	count   int
+}
+
+// perceptionBufferSize начальная ёмкость буфера наблюдений
+const perceptionBufferSize = 16
+
+// push добавляет наблюдение, вытесняя самое старое, если оно уже не нужно
+// для задержки delay; иначе буфер увеличивается
+func (b *perceptionBuffer) push(sample perceptionSample, delay float64) {
+	if b.count == len(b.samples) {
+		if b.count > 1 && b.at(1).Time <= sample.Time-delay {
+			b.start = (b.start + 1) % len(b.samples)
+			b.count--
+		} else {
+			b.grow()
+		}
+	}
+	b.samples[(b.start+b.count)%len(b.samples)] = sample
+	b.count++
+}
+
+// restorePerception восстанавливает буфер из сохранённых наблюдений с той ёмкостью,
+// которую он набрал при записи: от ёмкости зависит, когда вытесняются старые
+// наблюдения, поэтому иначе продолжение после загрузки расходится с исходным
+func restorePerception(samples []perceptionSample) perceptionBuffer {
+	size := perceptionBufferSize
+	for size < len(samples) {
+		size *= 2
+	}
+	buffer := make([]perceptionSample, size)
+	copy(buffer, samples)
+	return perceptionBuffer{samples: buffer, count: len(samples)}
+}
+
+// grow удваивает ёмкость буфера, сохраняя порядок наблюдений
+func (b *perceptionBuffer) grow() {
+	samples := make([]perceptionSample, max(perceptionBufferSize, 2*len(b.samples)))
+	for i := 0; i < b.count; i++ {
+		samples[i] = b.at(i)
+	}
+	b.samples, b.start = samples, 0
+}
+
+// at возвращает i-е наблюдение от самого старого
+func (b *perceptionBuffer) at(i int) perceptionSample {
+	return b.samples[(b.start+i)%len(b.samples)]
+}
+
+// lookup возвращает последнее наблюдение не позже момента t; если все
+// наблюдения позже (машина только появилась), возвращается самое старое
+func (b *perceptionBuffer) lookup(t float64) perceptionSample {
+	for i := b.count - 1; i > 0; i-- {
+		if sample := b.at(i); sample.Time <= t {
+			return sample
+		}
+	}
+	return b.at(0)
+}
+
+// list возвращает наблюдения от самого старого для сохранения состояния
+func (b *perceptionBuffer) list() []perceptionSample {
+	list := make([]perceptionSample, b.count)
+	for i := range list {
+		list[i] = b.at(i)
+	}
+	return list
+}
+
+// reactionSpreadFactor возвращает случайный множитель времени реакции водителя:
+// логнормальное распределение со средним 1 и коэффициентом вариации около ReactionSpread.
+// Генератор не используется при нулевом разбросе.
+func (s *Simulation) reactionSpreadFactor() float64 {
+	if s.ReactionSpread <= 0 {
+		return 1
+	}
+	sigma := math.Sqrt(math.Log(1 + s.ReactionSpread*s.ReactionSpread))
+	return math.Exp(sigma*s.rng.NormFloat64() - sigma*sigma/2)
+}
+
+// perceiveLeader запоминает текущее препятствие впереди машины и возвращает препятствие
+// таким, каким водитель видел его ReactionDelay секунд назад: с дистанцией и скоростью
+// того момента, отложенными от текущей позиции машины (nil - впереди было свободно).
+// Препятствие из прошлого записывается в seen, чтобы не выделять память на каждом шаге.
+func (s *Simulation) perceiveLeader(car, leader, seen *Car) *Car {
+	car.ReactionDelay = s.carReactionTime(car)
+	sample := perceptionSample{Time: s.Time}
+	if leader != nil {
+		sample = perceptionSample{
+			Time: s.Time, Leader: true, ID: leader.ID, Type: leader.Type,
+			Gap: gapTo(car, leader), Speed: leader.Speed, Length: leader.length(),
+		}
+	}
+	car.perception.push(sample, car.ReactionDelay)
+
+	past := car.perception.lookup(s.Time - car.ReactionDelay)
+	if !past.Leader {
+		return nil
+	}
+	if past.Time == s.Time && leader != nil {
+		return leader
+	}
+	*seen = Car{
+		ID: past.ID, Type: past.Type, Length: past.Length, Lane: car.Lane,
+		Position: car.Position + past.Gap + past.Length, Speed: past.Speed, State: "normal",
+	}
+	return seen
+}
@@ -0,0 +1,102 @@
+package traffic
+
+import "math"
+
+// Параметры кооперативного адаптивного круиз-контроля (CACC): автоматизированные машины,
+// идущие друг за другом, объединяются в колонны и обмениваются ускорениями по связи
+const (
+	PlatoonTimeGap     = 0.5  // секунды, интервал между машинами колонны
+	PlatoonJoinGap     = 40.0 // метры, дистанция до машины колонны, с которой можно присоединиться
+	PlatoonSplitGap    = 60.0 // метры, дистанция, при превышении которой колонна разделяется
+	PlatoonMaxSize     = 8    // наибольшее число машин в колонне
+	PlatoonFeedforward = 0.8  // доля ускорения предшественника, учитываемая регулятором
+)
+
+// inPlatoon сообщает, ведёт ли машину колонна (машина идёт в колонне не первой)
+func (car *Car) inPlatoon() bool {
+	return car.Platoon != 0 && car.PlatoonIndex > 0
+}
+
+// updatePlatoon пересматривает членство автоматизированной машины в колонне по
+// препятствию впереди ahead. Машина присоединяется к автоматизированной машине впереди
+// на той же полосе не дальше PlatoonJoinGap, если колонна не заполнена; если у той ещё
+// нет колонны, она становится лидером новой. Машина покидает колонну, если впереди
+// больше нет её машины или дистанция превысила PlatoonSplitGap; машины позади неё
+// при этом остаются с ней и образуют отдельную колонну.
+func (s *Simulation) updatePlatoon(car, ahead *Car) {
+	if !car.automated() {
+		return
+	}
+	joinGap := PlatoonJoinGap
+	if car.inPlatoon() && ahead != nil && ahead.Platoon == car.Platoon {
+		joinGap = PlatoonSplitGap
+	}
+	if ahead == nil || ahead.Lane != car.Lane || !ahead.automated() || ahead.crashed() || gapTo(car, ahead) > joinGap ||
+		ahead.PlatoonIndex+1 >= PlatoonMaxSize {
+		if car.inPlatoon() {
+			car.Platoon, car.PlatoonIndex = 0, 0
+		}
+		return
+	}
+	if ahead.Platoon == 0 {
+		s.nextPlatoonID++
+		ahead.Platoon, ahead.PlatoonIndex = s.nextPlatoonID, 0
+	}
+	car.Platoon, car.PlatoonIndex = ahead.Platoon, ahead.PlatoonIndex+1
+}
+
+// settlePlatoons завершает пересмотр колонн за шаг: машины в аварии и лидеры,
+// от которых отстали все машины, выходят из колонн; считается число колонн
+func (s *Simulation) settlePlatoons() {
+	members := make(map[int]int)
+	for _, car := range s.Cars {
+		if car.crashed() {
+			car.Platoon, car.PlatoonIndex = 0, 0
+		}
+		if car.Platoon != 0 {
+			members[car.Platoon]++
+		}
+	}
+	s.Platoons = 0
+	for _, car := range s.Cars {
+		if car.Platoon == 0 {
+			continue
+		}
+		if members[car.Platoon] < 2 {
+			car.Platoon, car.PlatoonIndex = 0, 0
+		} else if car.PlatoonIndex == 0 {
+			s.Platoons++
+		}
+	}
+}
+
+// caccAcceleration вычисляет ускорение машины колонны по её предшественнику ahead,
+// состояние которого передаётся по связи без задержки: регулятор постоянного интервала
+// PlatoonTimeGap с учётом ускорения предшественника. Торможение предшественника сильнее
+// комфортного повторяется сразу, без ограничения рывка, поэтому экстренное торможение
+// лидера за один шаг передаётся всей колонне.
+func (s *Simulation) caccAcceleration(car, ahead *Car, dt float64) float64 {
+	effect := s.weatherEffect()
+	maxAccel, comfort := math.Min(AVMaxAcceleration, s.carAcceleration(car)), AVComfortBraking*effect.Braking
+
+	v := car.Speed
+	gap := gapTo(car, ahead)
+	accel := math.Min(AVCruiseGain*(car.TargetSpeed-v),
+		AVGapGain*(gap-AVMinGap-v*PlatoonTimeGap*effect.Headway)+AVSpeedGain*(ahead.Speed-v)+PlatoonFeedforward*ahead.accel)
+	accel = math.Max(-comfort, math.Min(maxAccel, accel))
+	if dt > 0 {
+		previous := math.Max(-comfort, math.Min(maxAccel, car.accel))
+		accel = math.Max(previous-AVMaxJerk*dt, math.Min(previous+AVMaxJerk*dt, accel))
+	}
+	if ahead.accel < -comfort {
+		// Согласованное торможение
+		accel = math.Min(accel, ahead.accel)
+	}
+	if dv := v - ahead.Speed; dv > 0 {
+		if required := dv * dv / (2 * math.Max(gap-AVMinGap, 0.1)); required > comfort && -required < accel {
+			accel = math.Max(-required, -s.maxDeceleration())
+		}
+	}
+	car.accel = accel
+	return accel
+}
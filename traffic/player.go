@@ -0,0 +1,76 @@
+package traffic
+
+import (
+	"errors"
+	"math"
+)
+
+// PlayerMaxSpeed км/ч, скорость, к которой стремится машина игрока при полном газе
+const PlayerMaxSpeed = 180.0
+
+// Ошибки управления машиной игроком
+var (
+	ErrCarNotFound = errors.New("car not found")
+	ErrCarClaimed  = errors.New("car is already driven by a player")
+	ErrNotPlayer   = errors.New("car is not driven by a player")
+)
+
+// Pedals положение педалей машины игрока
+type Pedals struct {
+	Throttle float64 `json:"throttle"` // 0..1, доля ускорения типа машины
+	Brake    float64 `json:"brake"`    // 0..1, доля замедления типа машины
+}
+
+// ClaimCar передаёт машину id под управление игрока: машина больше не следует модели
+// и не перестраивается, а разгоняется и тормозит педалями DriveCar; остальные машины
+// реагируют на неё как обычно. Машина начинает с отпущенными педалями.
+func (s *Simulation) ClaimCar(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	car := s.carByID(id)
+	if car == nil {
+		return ErrCarNotFound
+	}
+	if car.Player {
+		return ErrCarClaimed
+	}
+	car.Player = true
+	car.pedals = Pedals{}
+	car.Platoon, car.PlatoonIndex = 0, 0
+	return nil
+}
+
+// ReleaseCar возвращает машину игрока под управление модели следования
+func (s *Simulation) ReleaseCar(id int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if car := s.carByID(id); car != nil {
+		car.Player = false
+		car.pedals = Pedals{}
+	}
+}
+
+// DriveCar задаёт положение педалей машины игрока; педали действуют до следующей команды
+func (s *Simulation) DriveCar(id int, pedals Pedals) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	car := s.carByID(id)
+	if car == nil {
+		return ErrCarNotFound
+	}
+	if !car.Player {
+		return ErrNotPlayer
+	}
+	car.pedals = pedals
+	return nil
+}
+
+// playerAcceleration вычисляет ускорение машины игрока по педалям: газ - доля ускорения
+// типа машины, убывающая к PlayerMaxSpeed, тормоз - доля замедления типа машины
+func (s *Simulation) playerAcceleration(car *Car) float64 {
+	free := 1 - math.Pow(car.Speed/kmhToMs(PlayerMaxSpeed), 4)
+	return car.pedals.Throttle*s.carAcceleration(car)*free - car.pedals.Brake*s.carBraking(car)
+}
@@ -0,0 +1,99 @@
+package traffic
+
+import "math"
+
+// defaultMergeSpeed км/ч, скорость въезда с рампы по умолчанию
+const defaultMergeSpeed = 60.0
+
+// OnRamp въезд на основную дорогу: машины прибывают на рампу с интервалом
+// SpawnInterval, ждут в очереди и вливаются в правую полосу в точке Position,
+// когда на ней есть приемлемый промежуток
+type OnRamp struct {
+	Position      float64       `json:"position"`      // метры, точка слияния
+	SpawnInterval float64       `json:"spawnInterval"` // секунды между прибытиями на рампу
+	MergeSpeed    float64       `json:"mergeSpeed"`    // м/с, скорость въезда на основную дорогу
+	Queue         int           `json:"queue"`         // машин ждут слияния
+	Merged        int           `json:"merged"`        // машин влились в поток
+	Metering      *RampMetering `json:"metering"`      // светофор на рампе (nil = нет)
+	lastArrival   float64
+}
+
+// setOnRamp включает рампу по конфигурации или убирает её при неположительном интервале
+func (s *Simulation) setOnRamp(config OnRampConfig) {
+	if config.SpawnInterval <= 0 || config.Position <= 0 || config.Position >= RoadLength {
+		s.OnRamp = nil
+		return
+	}
+	mergeSpeed := config.MergeSpeed
+	if mergeSpeed <= 0 {
+		mergeSpeed = defaultMergeSpeed
+	}
+	ramp := &OnRamp{}
+	if s.OnRamp != nil {
+		*ramp = *s.OnRamp
+	}
+	ramp.Position = config.Position
+	ramp.SpawnInterval = config.SpawnInterval
+	ramp.MergeSpeed = kmhToMs(mergeSpeed)
+	ramp.Metering = nil
+	if config.Metering != nil {
+		ramp.Metering = newRampMetering(*config.Metering, config.Position)
+		s.ensureDetector(ramp.Metering.Detector)
+	}
+	s.OnRamp = ramp
+}
+
+// updateOnRamp ставит прибывшие машины в очередь рампы и вливает первую из очереди,
+// если светофор рампы её выпускает, а промежуток на правой полосе в точке слияния
+// безопасен для неё и машины сзади
+func (s *Simulation) updateOnRamp() {
+	ramp := s.OnRamp
+	if ramp == nil {
+		return
+	}
+	if s.Time-ramp.lastArrival >= ramp.SpawnInterval && s.TotalCarsMade+ramp.Queue < s.MaxCars {
+		ramp.Queue++
+		ramp.lastArrival = s.Time
+	}
+	s.updateMetering()
+	if ramp.Queue == 0 || !s.meteringGreen() {
+		return
+	}
+
+	candidate := &Car{Lane: 0, Position: ramp.Position, Speed: ramp.MergeSpeed}
+	if !s.gapAcceptable(candidate, 0) {
+		return
+	}
+	car := s.spawnCarAt(0, ramp.Position)
+	car.Speed = math.Min(ramp.MergeSpeed, car.TargetSpeed)
+	ramp.Queue--
+	ramp.Merged++
+	if ramp.Metering != nil {
+		ramp.Metering.lastRelease = s.Time
+	}
+}
+
+// resetOnRamp очищает очередь и счётчики рампы и возвращает светофор к начальной интенсивности
+func (s *Simulation) resetOnRamp() {
+	ramp := s.OnRamp
+	if ramp == nil {
+		return
+	}
+	ramp.Queue, ramp.Merged, ramp.lastArrival = 0, 0, 0
+	if m := ramp.Metering; m != nil {
+		m.Rate, m.QueueFlush, m.lastUpdate, m.lastRelease = m.MaxRate, false, 0, 0
+	}
+}
+
+// onRampState возвращает копию рампы для рассылки (nil, если рампы нет)
+func (s *Simulation) onRampState() *OnRamp {
+	if s.OnRamp == nil {
+		return nil
+	}
+	ramp := *s.OnRamp
+	if s.OnRamp.Metering != nil {
+		metering := *s.OnRamp.Metering
+		ramp.Metering = &metering
+	}
+	return &ramp
+}
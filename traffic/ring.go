@@ -0,0 +1,59 @@
+package traffic
+
+import "math"
+
+// Типы дороги
+const (
+	RoadStraight = "straight" // машины въезжают в начале и покидают дорогу в конце
+	RoadRing     = "ring"     // кольцо: машины не покидают дорогу, их число фиксировано
+	RoadTwoWay   = "twoway"   // двусторонняя дорога: полоса в каждом направлении, обгон по встречной
+	RoadNetwork  = "network"  // дорожная сеть из звеньев с маршрутами машин (см. Network)
+)
+
+// ring сообщает, замкнута ли дорога в кольцо
+func (s *Simulation) ring() bool {
+	return s.RoadType == RoadRing
+}
+
+// aheadDistance возвращает расстояние по ходу движения от точки from до точки to.
+// На прямой дороге оно отрицательно для точек позади, на кольце всегда лежит в [0, RoadLength).
+func (s *Simulation) aheadDistance(from, to float64) float64 {
+	d := to - from
+	if s.ring() {
+		d = math.Mod(d, RoadLength)
+		if d < 0 {
+			d += RoadLength
+		}
+	}
+	return d
+}
+
+// relativeTo возвращает копию машины other, сдвинутую на длину кольца так, чтобы
+// её позиция отличалась от position на расстояние по ходу движения; на прямой
+// дороге и без перехода через начало кольца возвращается сама машина
+func relativeTo(other *Car, position, distance float64) *Car {
+	if shifted := position + distance; shifted != other.Position {
+		copied := *other
+		copied.Position = shifted
+		return &copied
+	}
+	return other
+}
+
+// populateRing равномерно расставляет MaxCars машин по полосам пустого кольца
+func (s *Simulation) populateRing() {
+	perLane := s.MaxCars / s.Lanes
+	// Между машинами остаётся не меньше длины легкового автомобиля
+	if limit := int(RoadLength / (s.maxVehicleLength() + CarLength)); perLane > limit {
+		perLane = limit
+	}
+	if perLane == 0 {
+		return
+	}
+	spacing := RoadLength / float64(perLane)
+	for lane := 0; lane < s.Lanes; lane++ {
+		for i := 0; i < perLane; i++ {
+			s.spawnCarAt(lane, float64(i)*spacing)
+		}
+	}
+}
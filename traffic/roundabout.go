@@ -0,0 +1,196 @@
+package traffic
+
+import (
+	"math"
+	"sort"
+)
+
+// Параметры кольцевой развязки по умолчанию
+const (
+	DefaultRoundaboutCircumference = 100.0 // метры по осевой кольца
+	DefaultRoundaboutSpeed         = 30.0  // км/ч на кольце
+	DefaultCriticalGap             = 4.0   // секунды
+)
+
+// Roundabout кольцевая развязка в узле сети. Узел разворачивается в однополосное
+// кольцо против часовой стрелки: у каждого примыкающего узла-соседа появляется узел
+// въезда-выезда (NetworkNode.Port), соединённый с соседними по кольцу звеньями.
+// Машины на кольце имеют приоритет, въезжающие ждут промежутка не меньше CriticalGap.
+type Roundabout struct {
+	Circumference float64 `json:"circumference"` // метры по осевой кольца (0 - 100)
+	SpeedLimit    float64 `json:"speedLimit"`    // км/ч на кольце (0 - 30)
+	CriticalGap   float64 `json:"criticalGap"`   // секунды, наименьший принимаемый промежуток в потоке по кольцу (0 - 4)
+}
+
+// withDefaults возвращает параметры с заполненными нулевыми полями
+func (r Roundabout) withDefaults() Roundabout {
+	if r.Circumference <= 0 {
+		r.Circumference = DefaultRoundaboutCircumference
+	}
+	if r.SpeedLimit <= 0 {
+		r.SpeedLimit = DefaultRoundaboutSpeed
+	}
+	if r.CriticalGap <= 0 {
+		r.CriticalGap = DefaultCriticalGap
+	}
+	return r
+}
+
+// Validate проверяет параметры развязки
+func (r Roundabout) Validate() error {
+	var e ValidationError
+	e.nonNegative("circumference", r.Circumference)
+	e.nonNegative("speedLimit", r.SpeedLimit)
+	e.nonNegative("criticalGap", r.CriticalGap)
+	return e.result()
+}
+
+// expandRoundabouts разворачивает узлы-развязки с примыкающими звеньями в кольца.
+// Звенья подходов и выездов сохраняют идентификаторы и лишь переключаются на узлы
+// въезда-выезда, поэтому их статистику можно сравнить со светофором в том же узле.
+// Развёрнутая развязка звеньев не имеет, так что повторный вызов её не меняет.
+func (n *Network) expandRoundabouts() {
+	for i := range n.Nodes {
+		center := n.Nodes[i]
+		if center.Roundabout == nil {
+			continue
+		}
+		var arms []string
+		seen := make(map[string]bool)
+		for _, link := range n.Links {
+			neighbor := ""
+			switch center.ID {
+			case link.To:
+				neighbor = link.From
+			case link.From:
+				neighbor = link.To
+			}
+			if neighbor != "" && !seen[neighbor] {
+				seen[neighbor] = true
+				arms = append(arms, neighbor)
+			}
+		}
+		if len(arms) == 0 {
+			continue
+		}
+
+		// Рукава упорядочиваются по направлению на соседа; без координат - по порядку звеньев
+		angles := make(map[string]float64, len(arms))
+		for j, arm := range arms {
+			angles[arm] = 2 * math.Pi * float64(j) / float64(len(arms))
+			if node := n.node(arm); node != nil && (node.X != center.X || node.Y != center.Y) {
+				angles[arm] = math.Atan2(node.Y-center.Y, node.X-center.X)
+			}
+		}
+		sort.SliceStable(arms, func(a, b int) bool { return angles[arms[a]] < angles[arms[b]] })
+
+		rb := center.Roundabout.withDefaults()
+		radius := rb.Circumference / (2 * math.Pi)
+		port := func(arm string) string { return center.ID + "/" + arm }
+		for _, arm := range arms {
+			n.Nodes = append(n.Nodes, NetworkNode{
+				ID: port(arm), Port: center.ID,
+				X: center.X + radius*math.Cos(angles[arm]), Y: center.Y + radius*math.Sin(angles[arm]),
+			})
+		}
+		for _, link := range n.Links {
+			if link.To == center.ID {
+				link.To = port(link.From)
+			}
+			if link.From == center.ID {
+				link.From = port(link.To)
+			}
+		}
+		for j, arm := range arms {
+			next := arms[(j+1)%len(arms)]
+			n.Links = append(n.Links, &NetworkLink{
+				ID: port(arm) + "-" + next, From: port(arm), To: port(next),
+				Length: rb.Circumference / float64(len(arms)), Lanes: 1, SpeedLimit: rb.SpeedLimit, Cars: make([]*Car, 0),
+			})
+		}
+	}
+}
+
+// node возвращает узел сети по идентификатору (nil - узла нет)
+func (n *Network) node(id string) *NetworkNode {
+	for i := range n.Nodes {
+		if n.Nodes[i].ID == id {
+			return &n.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// ringLink сообщает, лежит ли звено на кольце развязки
+func (n *Network) ringLink(link *NetworkLink) bool {
+	return n.ports[link.From] != nil && n.ports[link.From] == n.ports[link.To]
+}
+
+// yieldStop возвращает линию уступи дорогу в конце подхода к развязке для первой
+// на своей полосе машины, если промежуток в потоке по кольцу для въезда мал
+// (nil - машина въезжает или звено не ведёт к развязке)
+func (s *Simulation) yieldStop(link *NetworkLink, car *Car, next *NetworkLink) *Car {
+	n := s.Network
+	rb := n.ports[link.To]
+	if rb == nil || n.ringLink(link) {
+		return nil
+	}
+	if s.acceptsGap(link, car, next, rb.withDefaults().CriticalGap) {
+		return nil
+	}
+	return stopObstacle(link.Length)
+}
+
+// acceptsGap сообщает, примет ли машина подхода link промежуток для въезда на звено
+// кольца next: ближайшая машина на кольце, которая проедет узел въезда, должна
+// подъехать к нему не раньше, чем через critical секунд после въезжающей. Время
+// подъезда обеих машин оценивается разгоном с ускорением Acceleration от текущей
+// скорости: для машины на кольце оценка осторожная, стоящая машина тоже тронется.
+func (s *Simulation) acceptsGap(link *NetworkLink, car *Car, next *NetworkLink, critical float64) bool {
+	entry := s.reachTime(car, link.Length-car.Position)
+
+	// Кольцевой поток ищется назад по кольцу от узла въезда, пока он может успеть к узлу
+	horizon := (entry + critical) * kmhToMs(s.Network.ports[link.To].withDefaults().SpeedLimit) * 2
+	upstream, behind := next, 0.0
+	for range s.Network.Links {
+		var from *NetworkLink
+		for _, other := range s.Network.Links {
+			if other.To == upstream.From && s.Network.ringLink(other) {
+				from = other
+				break
+			}
+		}
+		if from == nil || from == next {
+			return true
+		}
+		for _, circulating := range from.Cars {
+			if !s.passesThrough(circulating, next) {
+				continue
+			}
+			return s.reachTime(circulating, behind+from.Length-circulating.Position) >= entry+critical
+		}
+		behind += from.Length
+		if behind > horizon {
+			return true
+		}
+		upstream = from
+	}
+	return true
+}
+
+// reachTime возвращает время, за которое машина, разгоняясь с ускорением Acceleration
+// от текущей скорости, проедет distance метров
+func (s *Simulation) reachTime(car *Car, distance float64) float64 {
+	accel := math.Max(s.Acceleration, 0.1)
+	return (math.Sqrt(car.Speed*car.Speed+2*accel*math.Max(0, distance)) - car.Speed) / accel
+}
+
+// passesThrough сообщает, проедет ли машина звено link дальше по маршруту
+func (s *Simulation) passesThrough(car *Car, link *NetworkLink) bool {
+	for _, id := range car.Route[car.routeStep+1:] {
+		if id == link.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package traffic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario декларативное описание эксперимента: дорога, спрос, светофоры, участки,
+// дорожные работы и инциденты по расписанию. Поля совпадают с данными соответствующих команд;
+// отсутствующие списки означают, что их нет, отсутствующие config и physics -
+// что параметры не меняются.
+type Scenario struct {
+	Name       string            `json:"name"`
+	Config     *SimulationConfig `json:"config"`     // как команда config: дорога, полосы, спрос, состав потока, зерно
+	Physics    *PhysicsConfig    `json:"physics"`    // как команда physics
+	SpeedZones []SpeedZone       `json:"speedZones"` // как команда zones
+	Cameras    []SpeedCamera     `json:"cameras"`    // как команда cameras
+	Grades     []GradeSegment    `json:"grades"`     // как команда grades
+	Lights     []TrafficLight    `json:"lights"`     // как команда lights
+	Crosswalks []Crosswalk       `json:"crosswalks"` // как команда crosswalks
+	Segments   []Segment         `json:"segments"`   // как команда segments
+	Detectors  *DetectorConfig   `json:"detectors"`  // как команда detectors
+	VSL        *VSLConfig        `json:"vsl"`        // как команда vsl
+	Buses      *BusConfig        `json:"buses"`      // как команда buses
+	WorkZone   *WorkZone         `json:"workZone"`   // как команда workzone
+	Incidents  []IncidentAt      `json:"incidents"`  // инциденты по расписанию
+	Start      bool              `json:"start"`      // запустить симуляцию после загрузки
+}
+
+// IncidentAt инцидент расписания, который устанавливается в момент At
+type IncidentAt struct {
+	At float64 `json:"at"` // секунды симуляции
+	IncidentConfig
+}
+
+// ParseScenario разбирает сценарий в формате YAML или JSON (JSON - частный случай YAML).
+// Имена полей - как в JSON-командах; неизвестные поля считаются ошибкой.
+func ParseScenario(data []byte) (Scenario, error) {
+	var scenario Scenario
+	err := decodeDocument(data, &scenario)
+	return scenario, err
+}
+
+// decodeDocument разбирает документ YAML или JSON в v по JSON-именам полей;
+// неизвестные поля считаются ошибкой
+func decodeDocument(data []byte, v any) error {
+	var document any
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return err
+	}
+	// Документ перекодируется в JSON, чтобы использовать JSON-имена полей и их разбор
+	encoded, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// Validate проверяет все части сценария; имена полей ошибок включают путь
+// в сценарии: "config.spawnInterval", "incidents[0].position"
+func (sc Scenario) Validate() error {
+	var e ValidationError
+	if sc.Config != nil {
+		e.nest("config", sc.Config.Validate())
+	}
+	if sc.Physics != nil {
+		e.nest("physics", sc.Physics.Validate())
+	}
+	e.nest("speedZones", ValidateSpeedZones(sc.SpeedZones))
+	e.nest("cameras", ValidateCameras(sc.Cameras))
+	e.nest("grades", ValidateGrades(sc.Grades))
+	e.nest("lights", ValidateTrafficLights(sc.Lights))
+	e.nest("crosswalks", ValidateCrosswalks(sc.Crosswalks))
+	e.nest("segments", ValidateSegments(sc.Segments))
+	if sc.Detectors != nil {
+		e.nest("detectors", sc.Detectors.Validate())
+	}
+	if sc.VSL != nil {
+		e.nest("vsl", sc.VSL.Validate())
+	}
+	if sc.Buses != nil {
+		e.nest("buses", sc.Buses.Validate())
+	}
+	if sc.WorkZone != nil {
+		e.nest("workZone", sc.WorkZone.Validate())
+	}
+	for i, incident := range sc.Incidents {
+		field := fmt.Sprintf("incidents[%d]", i)
+		e.nonNegative(field+".at", incident.At)
+		e.nest(field, incident.Validate())
+	}
+	return e.result()
+}
+
+// LoadScenario сбрасывает симуляцию и применяет сценарий, как последовательность
+// команд reset, config, physics, zones, cameras, grades, lights, crosswalks, segments,
+// detectors, vsl, buses и workzone;
+// инциденты устанавливаются по расписанию в ходе прогона
+func (s *Simulation) LoadScenario(sc Scenario) {
+	s.Reset()
+	if sc.Config != nil {
+		s.UpdateConfig(*sc.Config)
+	}
+	if sc.Physics != nil {
+		s.UpdatePhysics(*sc.Physics)
+	}
+	s.SetSpeedZones(sc.SpeedZones)
+	s.SetCameras(sc.Cameras)
+	s.SetGrades(sc.Grades)
+	s.SetTrafficLights(sc.Lights)
+	s.SetCrosswalks(sc.Crosswalks)
+	s.SetSegments(sc.Segments)
+	detectors := DetectorConfig{}
+	if sc.Detectors != nil {
+		detectors = *sc.Detectors
+	}
+	s.SetDetectors(detectors)
+	vsl := VSLConfig{}
+	if sc.VSL != nil {
+		vsl = *sc.VSL
+	}
+	s.SetVSL(vsl)
+	buses := BusConfig{}
+	if sc.Buses != nil {
+		buses = *sc.Buses
+	}
+	s.SetBuses(buses)
+	zone := WorkZone{}
+	if sc.WorkZone != nil {
+		zone = *sc.WorkZone
+	}
+	s.SetWorkZone(zone)
+	s.SetSchedule(sc.Incidents)
+	if sc.Start {
+		s.Start()
+	}
+}
+
+// SetSchedule задаёт инциденты по расписанию; инцидент устанавливается, когда время
+// симуляции проходит его момент At, и снова - при повторном прогоне после сброса
+func (s *Simulation) SetSchedule(incidents []IncidentAt) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	schedule := append(make([]IncidentAt, 0, len(incidents)), incidents...)
+	sort.SliceStable(schedule, func(i, j int) bool { return schedule[i].At < schedule[j].At })
+	s.Schedule = schedule
+}
+
+// runSchedule устанавливает инциденты расписания с моментом в [from, s.Time)
+func (s *Simulation) runSchedule(from float64) {
+	for _, incident := range s.Schedule {
+		if incident.At >= s.Time {
+			break
+		}
+		if incident.At >= from {
+			s.addIncident(incident.IncidentConfig)
+		}
+	}
+}
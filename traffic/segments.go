@@ -0,0 +1,147 @@
+package traffic
+
+import "math"
+
+// Segment именованный участок дороги, по которому считается статистика
+type Segment struct {
+	Name  string  `json:"name"`
+	Start float64 `json:"start"` // метры
+	End   float64 `json:"end"`   // метры
+}
+
+// SegmentStats агрегированные показатели участка дороги
+type SegmentStats struct {
+	Name       string  `json:"name"`
+	Start      float64 `json:"start"`      // метры
+	End        float64 `json:"end"`        // метры
+	Cars       int     `json:"cars"`       // машин на участке
+	AvgSpeed   float64 `json:"avgSpeed"`   // м/с
+	Density    float64 `json:"density"`    // авто/км
+	Throughput int     `json:"throughput"` // машин, покинувших участок
+	Flow       float64 `json:"flow"`       // авто/ч
+}
+
+// SetSegments задаёт именованные участки дороги и сбрасывает их счётчики
+func (s *Simulation) SetSegments(segments []Segment) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	valid := make([]Segment, 0, len(segments))
+	for _, seg := range segments {
+		seg.Start = math.Max(0, seg.Start)
+		seg.End = math.Min(RoadLength, seg.End)
+		if seg.End > seg.Start {
+			valid = append(valid, seg)
+		}
+	}
+	s.Segments = valid
+	s.segmentExits = make([]int, len(valid))
+}
+
+// countSegmentExits учитывает пересечение автомобилем конца участков за шаг
+func (s *Simulation) countSegmentExits(from, to float64) {
+	for i, seg := range s.Segments {
+		if from < seg.End && to >= seg.End {
+			s.segmentExits[i]++
+		}
+	}
+}
+
+// segmentStats вычисляет показатели каждого участка по машинам, находящимся на нём
+func (s *Simulation) segmentStats() []SegmentStats {
+	stats := make([]SegmentStats, len(s.Segments))
+	for i, seg := range s.Segments {
+		st := SegmentStats{
+			Name:       seg.Name,
+			Start:      seg.Start,
+			End:        seg.End,
+			Throughput: s.segmentExits[i],
+		}
+		totalSpeed := 0.0
+		for _, car := range s.Cars {
+			if car.Position >= seg.Start && car.Position < seg.End {
+				st.Cars++
+				totalSpeed += car.Speed
+			}
+		}
+		if st.Cars > 0 {
+			st.AvgSpeed = totalSpeed / float64(st.Cars)
+		}
+		st.Density = float64(st.Cars) / ((seg.End - seg.Start) / 1000)
+		if s.Time > 0 {
+			st.Flow = float64(st.Throughput) / s.Time * 3600
+		}
+		stats[i] = st
+	}
+	return stats
+}
+
+// BinLength метры, длина ячейки дороги для показателей в рассылке
+const BinLength = 100.0
+
+// BinStats мгновенные показатели ячейки дороги длиной BinLength: плотность и средняя
+// скорость машин в ячейке, поток - их произведение (q = k·v)
+type BinStats struct {
+	From    float64    `json:"from"`    // метры, начало ячейки
+	Density float64    `json:"density"` // авто/км по всем полосам
+	Speed   float64    `json:"speed"`   // км/ч, средняя скорость (0 - машин нет)
+	Flow    float64    `json:"flow"`    // авто/ч по всем полосам
+	Lanes   []LaneBins `json:"lanes"`   // те же показатели по полосам, первой идёт правая
+}
+
+// LaneBins мгновенные показатели одной полосы в ячейке дороги
+type LaneBins struct {
+	Density float64 `json:"density"` // авто/км
+	Speed   float64 `json:"speed"`   // км/ч (0 - машин нет)
+	Flow    float64 `json:"flow"`    // авто/ч
+}
+
+// binStats возвращает показатели ячеек дороги по текущим положениям машин, чтобы
+// клиентам не пересчитывать тепловую карту самим; в дорожной сети ячеек нет
+func (s *Simulation) binStats() []BinStats {
+	if s.networkMode() {
+		return nil
+	}
+	n := int(math.Ceil(RoadLength / BinLength))
+	cars := make([][]int, n)
+	speeds := make([][]float64, n)
+	for i := range cars {
+		cars[i] = make([]int, s.Lanes)
+		speeds[i] = make([]float64, s.Lanes)
+	}
+	for _, car := range s.Cars {
+		if car.Lane < 0 || car.Lane >= s.Lanes {
+			continue
+		}
+		i := min(max(int(car.Position/BinLength), 0), n-1)
+		cars[i][car.Lane]++
+		speeds[i][car.Lane] += car.Speed
+	}
+	bins := make([]BinStats, n)
+	for i := range bins {
+		from := float64(i) * BinLength
+		km := (math.Min(RoadLength, from+BinLength) - from) / 1000
+		bin := BinStats{From: from, Lanes: make([]LaneBins, s.Lanes)}
+		total, speedSum := 0, 0.0
+		for lane := range bin.Lanes {
+			count := cars[i][lane]
+			total += count
+			speedSum += speeds[i][lane]
+			bin.Lanes[lane] = binStat(count, speeds[i][lane], km)
+		}
+		all := binStat(total, speedSum, km)
+		bin.Density, bin.Speed, bin.Flow = all.Density, all.Speed, all.Flow
+		bins[i] = bin
+	}
+	return bins
+}
+
+// binStat считает показатели count машин с суммой скоростей speedSum (м/с) на km километрах
+func binStat(count int, speedSum, km float64) LaneBins {
+	stat := LaneBins{Density: float64(count) / km}
+	if count > 0 {
+		stat.Speed = msToKmh(speedSum / float64(count))
+		stat.Flow = stat.Density * stat.Speed
+	}
+	return stat
+}
@@ -0,0 +1,125 @@
+package traffic
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSegmentStats проверяет, что показатели участка учитывают только машины на нём,
+// а суммы по участкам, покрывающим всю дорогу, совпадают с общими показателями
+func TestSegmentStats(t *testing.T) {
+	segments := []Segment{
+		{Name: "west", Start: 0, End: 2000},
+		{Name: "east", Start: 2000, End: RoadLength},
+	}
+	type placed struct {
+		position, speed float64
+	}
+	tests := []struct {
+		name      string
+		cars      []placed
+		wantCars  []int
+		wantSpeed []float64 // м/с, средняя скорость каждого участка
+	}{
+		{
+			name:      "both segments",
+			cars:      []placed{{100, 10}, {1500, 20}, {2500, 30}, {4000, 5}, {4999, 25}},
+			wantCars:  []int{2, 3},
+			wantSpeed: []float64{15, 20},
+		},
+		{
+			name:      "boundary belongs to the next segment",
+			cars:      []placed{{1999.9, 12}, {2000, 18}},
+			wantCars:  []int{1, 1},
+			wantSpeed: []float64{12, 18},
+		},
+		{
+			name:      "one segment empty",
+			cars:      []placed{{3000, 14}, {3500, 16}},
+			wantCars:  []int{0, 2},
+			wantSpeed: []float64{0, 15},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := New()
+			sim.SetSegments(segments)
+			for i, c := range tt.cars {
+				sim.Cars = append(sim.Cars, &Car{ID: i + 1, Type: "car", Position: c.position, Speed: c.speed})
+			}
+
+			stats := sim.Snapshot().Segments
+			if len(stats) != len(segments) {
+				t.Fatalf("got %d segment stats, want %d", len(stats), len(segments))
+			}
+			totalCars, totalSpeed := 0, 0.0
+			for i, st := range stats {
+				if st.Cars != tt.wantCars[i] {
+					t.Errorf("%s: cars = %d, want %d", st.Name, st.Cars, tt.wantCars[i])
+				}
+				if math.Abs(st.AvgSpeed-tt.wantSpeed[i]) > 1e-9 {
+					t.Errorf("%s: avgSpeed = %.3f, want %.3f", st.Name, st.AvgSpeed, tt.wantSpeed[i])
+				}
+				wantDensity := float64(st.Cars) / ((st.End - st.Start) / 1000)
+				if math.Abs(st.Density-wantDensity) > 1e-9 {
+					t.Errorf("%s: density = %.3f, want %.3f", st.Name, st.Density, wantDensity)
+				}
+				totalCars += st.Cars
+				totalSpeed += st.AvgSpeed * float64(st.Cars)
+			}
+
+			speedSum := 0.0
+			for _, c := range tt.cars {
+				speedSum += c.speed
+			}
+			if totalCars != len(tt.cars) {
+				t.Errorf("segments cover %d cars, road has %d", totalCars, len(tt.cars))
+			}
+			if math.Abs(totalSpeed-speedSum) > 1e-9 {
+				t.Errorf("segment speed sum = %.3f, road total %.3f", totalSpeed, speedSum)
+			}
+		})
+	}
+}
+
+// TestSegmentThroughput проверяет, что сумма выездов с участков, покрывающих дорогу,
+// равна числу машин, пересёкших их концы, а выезд с последнего - числу доехавших
+func TestSegmentThroughput(t *testing.T) {
+	seed := int64(3)
+	noHistory := 0.0
+	sim := New(WithConfig(SimulationConfig{
+		SpawnInterval: 1,
+		MinSpeed:      90,
+		MaxSpeed:      110,
+		MaxCars:       500,
+		Lanes:         2,
+		Seed:          &seed,
+		HistoryWindow: &noHistory,
+	}))
+	sim.SetSegments([]Segment{
+		{Name: "west", Start: 0, End: 2500},
+		{Name: "east", Start: 2500, End: RoadLength},
+	})
+	sim.Advance(6000, DefaultStep)
+
+	state := sim.Snapshot()
+	west, east := state.Segments[0], state.Segments[1]
+	if state.CarsCompleted == 0 {
+		t.Fatal("no car reached the end of the road")
+	}
+	if east.Throughput != state.CarsCompleted {
+		t.Fatalf("east throughput = %d, cars completed = %d", east.Throughput, state.CarsCompleted)
+	}
+	if west.Throughput < east.Throughput {
+		t.Fatalf("west throughput %d below east %d", west.Throughput, east.Throughput)
+	}
+	onEast := 0
+	for _, car := range state.Cars {
+		if car.Position >= 2500 {
+			onEast++
+		}
+	}
+	if west.Throughput != east.Throughput+onEast {
+		t.Fatalf("west throughput = %d, want east throughput %d + %d cars still on east", west.Throughput, east.Throughput, onEast)
+	}
+}
@@ -0,0 +1,915 @@
+// Package traffic реализует модель движения автомобилей по прямому участку
+// автострады: появление машин, следование за лидером, торможение и ускорение.
+// Пакет не зависит от HTTP-сервера и может встраиваться в другие программы:
+//
+//	sim := traffic.New(traffic.WithSeed(42), traffic.WithMaxCars(200))
+//	sim.Start()
+//	for sim.Snapshot().Running {
+//		sim.Step(traffic.DefaultStep)
+//	}
+package traffic
+
+import (
+	"math"
+	"math/rand"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	RoadLength  = 5000.0 // метры (5 км)
+	CarLength   = 4.5    // метры
+	EntryZone   = 250.0  // метры от начала, по которым оценивается плотность на въезде
+	DefaultStep = 0.05   // секунды, шаг симуляции по умолчанию
+	MinTimeStep = 0.01   // секунды, наименьший шаг физики
+	MaxTimeStep = 0.2    // секунды, наибольший шаг физики
+)
+
+// Simulation представляет симуляцию движения
+type Simulation struct {
+	Cars              []*Car         `json:"cars"`
+	Time              float64        `json:"time"`
+	CarsCompleted     int            `json:"carsCompleted"`
+	TravelTime        float64        `json:"travelTime"` // суммарное время в пути машин, завершивших проезд, с
+	TotalCarsMade     int            `json:"totalCarsMade"`
+	Running           bool           `json:"running"`
+	Paused            bool           `json:"paused"`            // прогон приостановлен командой pause и продвигается по шагам
+	SpawnInterval     float64        `json:"spawnInterval"`     // секунды между машинами
+	MinSpeed          float64        `json:"minSpeed"`          // м/с
+	MaxSpeed          float64        `json:"maxSpeed"`          // м/с
+	TimeScale         float64        `json:"timeScale"`         // множитель скорости времени (1.0 = нормально)
+	Turbo             bool           `json:"turbo"`             // ускоренный режим: шаги без ожидания таймера
+	TimeStep          float64        `json:"dt"`                // секунды, наибольший шаг физики
+	MaxCars           int            `json:"maxCars"`           // максимальное количество машин для генерации
+	ReactionTime      float64        `json:"reactionTime"`      // секунды задержки реакции
+	SafetyMultiplier  float64        `json:"safetyMultiplier"`  // коэффициент безопасной дистанции
+	BrakeDeceleration float64        `json:"brakeDeceleration"` // м/с² торможение
+	Acceleration      float64        `json:"acceleration"`      // м/с² ускорение
+	HysteresisBand    float64        `json:"hysteresisBand"`    // доля безопасной дистанции для гистерезиса торможения
+	Model             string         `json:"model"`             // модель следования за лидером
+	IDM               IDMParams      `json:"idm"`               // параметры модели IDM
+	NaSch             NaSchParams    `json:"nasch"`             // параметры клеточного автомата nasch
+	OVM               OVMParams      `json:"ovm"`               // параметры модели оптимальной скорости
+	LaneChange        string         `json:"laneChange"`        // правило перестроения: "space" или "mobil"
+	MOBIL             MOBILParams    `json:"mobil"`             // параметры перестроения MOBIL
+	SpawnSpeedMode    string         `json:"spawnSpeedMode"`    // "random" или "density"
+	SpawnDistribution string         `json:"spawnDistribution"` // распределение интервалов между машинами
+	Seed              int64          `json:"seed"`              // зерно генератора случайных чисел
+	SpeedLimit        float64        `json:"speedLimit"`        // м/с, ограничение скорости (0 = нет)
+	OverLimitTime     float64        `json:"overLimitTime"`     // суммарное время превышения по всем машинам, авто·с
+	DemandProfile     []DemandPoint  `json:"demandProfile"`     // расписание интервала появления машин
+	Blockages         []*Blockage    `json:"blockages"`         // активные перекрытия дороги
+	TrafficLights     []TrafficLight `json:"trafficLights"`     // светофоры
+	Crosswalks        []*Crosswalk   `json:"crosswalks"`        // нерегулируемые пешеходные переходы
+	OnRamp            *OnRamp        `json:"onRamp"`            // въезд с рампы (nil = нет)
+	RoadType          string         `json:"roadType"`          // "straight", "ring" или "twoway"
+	TruckPercentage   float64        `json:"truckPercentage"`   // доля грузовиков среди новых машин, %
+	CrashClearance    float64        `json:"crashClearance"`    // секунды остановки участников аварии (0 - без остановки)
+	Collisions        int            `json:"collisions"`        // число аварий
+	Detectors         []*Detector    `json:"detectors"`         // виртуальные индукционные петли
+	DetectorInterval  float64        `json:"detectorInterval"`  // секунды агрегации детекторов
+	Recording         bool           `json:"recording"`         // идёт запись траекторий
+	BusPercentage     float64        `json:"busPercentage"`     // доля автобусов среди новых машин, %
+	IncidentDelay     float64        `json:"incidentDelay"`     // суммарная задержка из-за перекрытий, авто·с
+	Segments          []Segment      `json:"segments"`          // именованные участки для статистики
+	SpeedZones        []SpeedZone    `json:"speedZones"`        // участки с собственным ограничением скорости
+	Cameras           []*SpeedCamera `json:"cameras"`           // камеры контроля скорости
+	Grades            []GradeSegment `json:"grades"`            // участки с продольным уклоном
+	Schedule          []IncidentAt   `json:"schedule"`          // инциденты по расписанию
+	VSL               *VSLConfig     `json:"vsl"`               // регулятор переменного ограничения скорости (nil = нет)
+	Lanes             int            `json:"lanes"`             // число полос
+	LaneChanges       int            `json:"laneChanges"`       // выполненных перестроений
+	BrakeEvents       int            `json:"brakeEvents"`       // торможений всех машин, включая покинувшие дорогу
+	AggressiveDrivers float64        `json:"aggressiveDrivers"` // доля агрессивных водителей среди новых машин, %
+	CautiousDrivers   float64        `json:"cautiousDrivers"`   // доля осторожных водителей среди новых машин, %
+	ReactionSpread    float64        `json:"reactionSpread"`    // коэффициент вариации времени реакции водителей
+	AVShare           float64        `json:"avShare"`           // доля автоматизированных машин (ACC) среди новых машин, %
+	WaveThreshold     float64        `json:"waveThreshold"`     // м/с, скорость машин в волне «стоп-старт» (0 - волны не ищутся)
+	Waves             []*Wave        `json:"waves"`             // текущие волны «стоп-старт»
+	WavesDetected     int            `json:"wavesDetected"`     // обнаружено волн с начала прогона
+	HistoryWindow     float64        `json:"historyWindow"`     // секунды симуляции, хранимые для перемотки (0 - не хранятся)
+	FuelUsed          float64        `json:"fuelUsed"`          // литры топлива всех машин, включая покинувшие дорогу
+	CO2Emitted        float64        `json:"co2Emitted"`        // кг CO₂ всех машин, включая покинувшие дорогу
+	Platoons          int            `json:"platoons"`          // текущих колонн автоматизированных машин
+	Weather           string         `json:"weather"`           // погода: "dry", "rain", "snow" или "fog"
+	Oncoming          []*Car         `json:"oncoming"`          // встречный поток двусторонней дороги, позиции от его въезда
+	OncomingDemand    float64        `json:"oncomingDemand"`    // авто/ч встречного потока (0 - встречных машин нет)
+	Overtakes         int            `json:"overtakes"`         // завершённых обгонов по встречной полосе
+	OvertakesAborted  int            `json:"overtakesAborted"`  // прерванных обгонов
+	HeadOnCollisions  int            `json:"headOnCollisions"`  // лобовых столкновений (входят в Collisions)
+	Buses             *BusConfig     `json:"buses"`             // маршрут автобусов с остановками (nil = нет)
+	BusesDispatched   int            `json:"busesDispatched"`   // автобусов маршрута, вышедших на дорогу
+	BusStopsServed    int            `json:"busStopsServed"`    // стоянок автобусов на остановках
+	WorkZone          *WorkZone      `json:"workZone"`          // участок дорожных работ с закрытой полосой (nil = нет)
+	Network           *Network       `json:"network"`           // дорожная сеть режима "network" (nil - одна дорога)
+	segmentExits      []int          // машин, покинувших каждый участок
+	mu                revisionMutex
+	frame             atomic.Pointer[stateFrame] // последний JSON состояния, см. StateJSON
+	lastSpawn         float64
+	spawnGap          float64 // множитель интервала до появления следующей машины
+	lastOncoming      float64 // время появления последней встречной машины
+	oncomingGap       float64 // секунды до появления следующей встречной машины
+	nextBus           float64 // время выхода следующего автобуса маршрута по расписанию
+	emergencies       []*Car  // спецмашины на дороге в начале текущего шага
+	vslUpdated        float64 // время последнего пересчёта ограничений VSL
+	wavesUpdated      float64 // время последнего обновления волн
+	nextWaveID        int
+	nextPlatoonID     int
+	diagram           []DiagramPoint // завершённые окна фундаментальной диаграммы
+	diagramStart      float64
+	diagramMeters     []float64 // метры пробега машин на каждом участке в текущем окне
+	diagramTime       []float64 // машино-секунды на каждом участке в текущем окне
+	trips             []Trip    // последние MaxTrips завершённых поездок
+	longestQueue      Queue     // самая длинная очередь прогона
+	headways          []float64 // последние MaxHeadways интервалов в HeadwayReference
+	headwayCrossings  []float64 // время последнего пересечения HeadwayReference на каждой полосе (<0 - не было)
+	nextCarID         int
+	nextBlockageID    int
+	rng               *rand.Rand
+	rngSource         *countingSource
+	events            []Event // события, ещё не забранные DrainEvents
+	exportEvents      bool    // записывать подробные события для экспорта (SetEventExport)
+	exports           []Event // подробные события, ещё не забранные DrainExportEvents
+	jammed            bool    // на дороге есть очередь не короче CongestionCars машин с момента jammedSince
+	jammedSince       float64
+	congested         bool // событие "congestion" опубликовано и ещё не снято
+	trajectories      []TrajectorySample
+	lastTrajectory    float64
+	history           []historyFrame // кадры для перемотки за последние HistoryWindow секунд
+	model             FollowingModel // экземпляр модели Model
+	modelName         string
+	workers           int         // горутин обновления машин (0 - GOMAXPROCS), см. WithWorkers
+	carUpdates        []carUpdate // промежуточные результаты шага, см. updateCars
+}
+
+// New создает новую симуляцию с параметрами по умолчанию, изменёнными опциями
+func New(opts ...Option) *Simulation {
+	s := &Simulation{
+		Cars:              make([]*Car, 0),
+		Blockages:         make([]*Blockage, 0),
+		TrafficLights:     make([]TrafficLight, 0),
+		Crosswalks:        make([]*Crosswalk, 0),
+		RoadType:          RoadStraight,
+		OncomingDemand:    DefaultOncomingDemand,
+		Weather:           WeatherDry,
+		DetectorInterval:  DefaultDetectorInterval,
+		Segments:          make([]Segment, 0),
+		SpeedZones:        make([]SpeedZone, 0),
+		Cameras:           make([]*SpeedCamera, 0),
+		Grades:            make([]GradeSegment, 0),
+		Schedule:          make([]IncidentAt, 0),
+		DemandProfile:     make([]DemandPoint, 0),
+		Waves:             make([]*Wave, 0),
+		WaveThreshold:     kmhToMs(DefaultWaveThreshold),
+		HistoryWindow:     DefaultHistoryWindow,
+		SpawnInterval:     2.0,
+		MinSpeed:          kmhToMs(50),
+		MaxSpeed:          kmhToMs(80),
+		TimeScale:         1.0,
+		TimeStep:          DefaultStep,
+		MaxCars:           100,
+		Lanes:             1,
+		Running:           false,
+		ReactionTime:      0.2,  // секунды
+		SafetyMultiplier:  3.0,  // коэффициент
+		BrakeDeceleration: 6.67, // м/с²
+		Acceleration:      2.0,  // м/с²
+		HysteresisBand:    0.1,  // ±10% безопасной дистанции
+		Model:             "simple",
+		IDM:               defaultIDMParams(),
+		NaSch:             defaultNaSchParams(),
+		OVM:               defaultOVMParams(),
+		LaneChange:        LaneChangeSpace,
+		MOBIL:             defaultMOBILParams(),
+		SpawnSpeedMode:    "random",
+		SpawnDistribution: SpawnFixed,
+		spawnGap:          1,
+		segmentExits:      make([]int, 0),
+		Seed:              time.Now().UnixNano(),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.setRNG(0)
+	return s
+}
+
+// kmhToMs конвертирует км/ч в м/с
+func kmhToMs(kmh float64) float64 {
+	return kmh / 3.6
+}
+
+// msToKmh конвертирует м/с в км/ч
+func msToKmh(ms float64) float64 {
+	return ms * 3.6
+}
+
+// randomSpeed возвращает случайную скорость в диапазоне
+func (s *Simulation) randomSpeed() float64 {
+	return s.MinSpeed + s.rng.Float64()*(s.MaxSpeed-s.MinSpeed)
+}
+
+// entryOccupancy возвращает заполненность зоны въезда от 0 (пусто) до 1 (затор)
+func (s *Simulation) entryOccupancy() float64 {
+	count := 0
+	for _, car := range s.Cars {
+		if car.Position < EntryZone {
+			count++
+		}
+	}
+	// В заторе машины стоят с минимальной безопасной дистанцией в две длины
+	jamCapacity := EntryZone / (CarLength * 3) * float64(s.Lanes)
+	return math.Min(1, float64(count)/jamCapacity)
+}
+
+// spawnSpeed возвращает целевую скорость новой машины с учётом режима SpawnSpeedMode
+func (s *Simulation) spawnSpeed() float64 {
+	if s.SpawnSpeedMode != "density" {
+		return s.randomSpeed()
+	}
+	// Чем плотнее въезд, тем ближе верхняя граница выбора к MinSpeed
+	return s.MinSpeed + s.rng.Float64()*(s.MaxSpeed-s.MinSpeed)*(1-s.entryOccupancy())
+}
+
+// randomColor возвращает случайный цвет для автомобиля
+func (s *Simulation) randomColor() string {
+	colors := []string{"#FF6B6B", "#4ECDC4", "#45B7D1", "#FFA07A", "#98D8C8", "#F7DC6F", "#BB8FCE", "#85C1E2"}
+	return colors[s.rng.Intn(len(colors))]
+}
+
+// SpawnCar создает новый автомобиль в начале самой свободной полосы
+func (s *Simulation) SpawnCar() {
+	lane, _ := s.entryLane()
+	s.spawnCar(lane)
+}
+
+// spawnCar создает новый автомобиль в начале полосы lane
+func (s *Simulation) spawnCar(lane int) {
+	s.spawnCarAt(lane, 0)
+}
+
+// spawnCarAt создает новый автомобиль на полосе lane в точке position
+func (s *Simulation) spawnCarAt(lane int, position float64) *Car {
+	car := s.newCar(lane, position)
+	// Машина, появившаяся в зоне ограничения, сразу едет с разрешённой скоростью
+	s.adaptTargetSpeed(car)
+	car.Speed = car.TargetSpeed
+	s.insertCar(car)
+	s.TotalCarsMade++
+	s.emitExport(Event{Type: "car:spawned", Time: s.Time, Cars: []int{car.ID}, Lane: car.Lane, Position: car.Position, Speed: car.Speed})
+	return car
+}
+
+// newCar создаёт автомобиль случайного типа с водителем и желаемой скоростью по
+// параметрам спроса и выдаёт ему следующий идентификатор
+func (s *Simulation) newCar(lane int, position float64) *Car {
+	return s.newVehicle(s.spawnVehicleType(), lane, position)
+}
+
+// newVehicle создаёт машину типа vehicle со случайными водителем и желаемой скоростью
+func (s *Simulation) newVehicle(vehicle string, lane int, position float64) *Car {
+	speed := s.vehicleSpeed(vehicle)
+	driver := s.spawnDriver()
+	speed *= DriverProfiles[driver].TargetSpeed
+	reactionFactor := DriverProfiles[driver].ReactionTime * s.reactionSpreadFactor()
+	car := &Car{
+		ID:             s.nextCarID,
+		Type:           vehicle,
+		Driver:         driver,
+		Length:         VehicleTypes[vehicle].Length,
+		Lane:           lane,
+		Position:       position,
+		Speed:          speed,
+		TargetSpeed:    speed,
+		Color:          s.randomColor(),
+		State:          "normal",
+		ReactionDelay:  s.ReactionTime * reactionFactor,
+		spawnTime:      s.Time,
+		reactionFactor: reactionFactor,
+		desiredSpeed:   speed,
+	}
+	s.nextCarID++
+	return car
+}
+
+// getSafeDistance вычисляет безопасную дистанцию
+func getSafeDistance(speedDiff float64, safetyMultiplier float64) float64 {
+	// Преобразуем в км/ч для расчета (как в оригинале: 1 фут на милю/час разницы)
+	speedDiffKmh := msToKmh(math.Abs(speedDiff))
+	// 1 миля/час ≈ 1.6 км/ч, 1 фут ≈ 0.3 м
+	safeDistance := (speedDiffKmh / 1.6) * 0.3 * safetyMultiplier
+	return math.Max(safeDistance, CarLength*2)
+}
+
+// applicableLimit возвращает ограничение скорости, действующее для автомобиля (0 = нет):
+// ограничение зоны, в которой он находится, иначе общее ограничение SpeedLimit
+func (s *Simulation) applicableLimit(car *Car) float64 {
+	if limit, ok := s.zoneLimit(car.Position); ok {
+		return limit
+	}
+	return s.SpeedLimit
+}
+
+// Step продвигает симуляцию на dt секунд реального времени (с учётом TimeScale)
+func (s *Simulation) Step(dt float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.Running {
+		return
+	}
+	s.substep(dt * s.TimeScale)
+}
+
+// StepTurbo в ускоренном режиме продвигает запущенную симуляцию ровно на один шаг
+// физики TimeStep без учёта TimeScale; возвращает false, если симуляция
+// не запущена или ускоренный режим выключен
+func (s *Simulation) StepTurbo() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.Running || !s.Turbo {
+		return false
+	}
+	s.step(s.TimeStep)
+	return true
+}
+
+// Advance продвигает симуляцию ровно на steps шагов по dt секунд реального времени
+// (с учётом TimeScale), даже если она остановлена или приостановлена, и оставляет её
+// на паузе; прогон, завершившийся на одном из шагов, останавливается как обычно
+func (s *Simulation) Advance(steps int, dt float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Running = true
+	for i := 0; i < steps && s.Running; i++ {
+		s.substep(dt * s.TimeScale)
+	}
+	s.Paused = s.Running
+	s.Running = false
+}
+
+// substep продвигает запущенную симуляцию на dt секунд симуляционного времени
+// равными подшагами не длиннее TimeStep: при большой скорости времени машины
+// не проскакивают сквозь лидеров за один крупный шаг
+func (s *Simulation) substep(dt float64) {
+	n := max(1, int(math.Ceil(dt/s.TimeStep-1e-9)))
+	for i := 0; i < n && s.Running; i++ {
+		s.step(dt / float64(n))
+	}
+}
+
+// step продвигает запущенную симуляцию на dt секунд симуляционного времени;
+// вызывающий держит блокировку
+func (s *Simulation) step(dt float64) {
+	from := s.Time
+	s.Time += dt
+	s.runSchedule(from)
+	if s.networkMode() {
+		// Сеть моделируется отдельно: зоны, светофоры, детекторы и перестроения одной дороги к ней не относятся
+		s.stepNetwork(dt)
+		s.recordHistory()
+		return
+	}
+	s.clearExpiredBlockages()
+	s.emergencies = s.emergencyVehicles()
+
+	if s.ring() {
+		// На кольце число машин фиксировано: они расставляются один раз на пустой дороге
+		if s.TotalCarsMade == 0 {
+			s.populateRing()
+		}
+	} else {
+		// Создаем новые автомобили на полосе с наибольшим свободным местом в начале дороги;
+		// допуск 1e-9 не даёт накопленной ошибке s.Time откладывать машину на лишний шаг
+		interval := s.effectiveSpawnInterval()
+		if interval > 0 && s.Time-s.lastSpawn >= interval*s.spawnGap-1e-9 && s.TotalCarsMade < s.MaxCars {
+			if lane, ok := s.entryLane(); ok {
+				s.spawnCar(lane)
+				s.lastSpawn = s.Time
+				s.spawnGap = s.nextSpawnGap()
+			}
+		}
+		s.dispatchBus()
+		s.updateOnRamp()
+		if s.twoWay() {
+			s.spawnOncoming()
+		}
+	}
+	s.updateCrosswalks(dt)
+
+	// Обновляем автомобили: расчёт больших парков выполняется параллельно
+	s.updateCars(dt)
+	if s.twoWay() {
+		s.updateOncoming(dt)
+		s.detectHeadOn()
+	}
+
+	s.detectCollisions()
+	s.enforceSpacing()
+	s.settlePlatoons()
+	s.closeDetectorIntervals()
+	s.closeDiagramWindow()
+	s.updateVSL()
+	s.updateWaves()
+	s.updateCongestion(s.updateQueues())
+	s.recordTrajectories()
+	s.recordHistory()
+
+	// Удаляем автомобили, которые прошли дорогу, и машины с расчищенных аварий
+	// Новый срез, а не фильтрация на месте: Snapshot отдаёт s.Cars без копирования
+	newCars := make([]*Car, 0, len(s.Cars))
+	for _, car := range s.Cars {
+		if s.cleared(car) {
+			continue
+		}
+		if car.Position < RoadLength {
+			newCars = append(newCars, car)
+		} else {
+			s.completeTrip(car)
+		}
+	}
+	s.Cars = newCars
+	s.sortCars()
+	s.updateBusStops()
+
+	// Автоматически останавливаем симуляцию, если достигнут лимит машин и все прошли дорогу
+	if !s.ring() && s.TotalCarsMade >= s.MaxCars && len(s.Cars) == 0 {
+		s.Running = false
+		summary := s.summary()
+		s.emit(Event{Type: "runComplete", Time: s.Time, Summary: &summary})
+	}
+}
+
+// State снимок состояния симуляции для рассылки клиентам
+type State struct {
+	Cars              []*Car              `json:"cars"`
+	Time              float64             `json:"time"`
+	CarsCompleted     int                 `json:"carsCompleted"`
+	TravelTime        float64             `json:"travelTime"`
+	TotalCarsMade     int                 `json:"totalCarsMade"`
+	Running           bool                `json:"running"`
+	Paused            bool                `json:"paused"`
+	RoadLength        float64             `json:"roadLength"`
+	TimeScale         float64             `json:"timeScale"`
+	Turbo             bool                `json:"turbo"`
+	TimeStep          float64             `json:"dt"`
+	MaxCars           int                 `json:"maxCars"`
+	ReactionTime      float64             `json:"reactionTime"`
+	SafetyMultiplier  float64             `json:"safetyMultiplier"`
+	BrakeDeceleration float64             `json:"brakeDeceleration"`
+	Acceleration      float64             `json:"acceleration"`
+	HysteresisBand    float64             `json:"hysteresisBand"`
+	SpawnSpeedMode    string              `json:"spawnSpeedMode"`
+	SpawnDistribution string              `json:"spawnDistribution"`
+	Blockages         []*Blockage         `json:"blockages"`
+	TrafficLights     []TrafficLightState `json:"trafficLights"`
+	Crosswalks        []Crosswalk         `json:"crosswalks"`
+	OnRamp            *OnRamp             `json:"onRamp"`
+	RoadType          string              `json:"roadType"`
+	TruckPercentage   float64             `json:"truckPercentage"`
+	CrashClearance    float64             `json:"crashClearance"`
+	Collisions        int                 `json:"collisions"`
+	Detectors         []DetectorState     `json:"detectors"`
+	DetectorInterval  float64             `json:"detectorInterval"`
+	Recording         bool                `json:"recording"`
+	TrajectorySamples int                 `json:"trajectorySamples"`
+	BusPercentage     float64             `json:"busPercentage"`
+	IncidentDelay     float64             `json:"incidentDelay"`
+	Segments          []SegmentStats      `json:"segments"`
+	Bins              []BinStats          `json:"bins"`
+	SpeedZones        []SpeedZone         `json:"speedZones"`
+	Cameras           []SpeedCamera       `json:"cameras"`
+	Grades            []GradeSegment      `json:"grades"`
+	Schedule          []IncidentAt        `json:"schedule"`
+	VSL               *VSLConfig          `json:"vsl"`
+	SpeedLimit        float64             `json:"speedLimit"`
+	OverLimitTime     float64             `json:"overLimitTime"`
+	SpawnInterval     float64             `json:"spawnInterval"` // действующий интервал появления машин
+	Demand            float64             `json:"demand"`        // авто/ч, действующая интенсивность появления машин
+	DemandProfile     []DemandPoint       `json:"demandProfile"`
+	Lanes             int                 `json:"lanes"`
+	LaneChanges       int                 `json:"laneChanges"`
+	BrakeEvents       int                 `json:"brakeEvents"`
+	AggressiveDrivers float64             `json:"aggressiveDrivers"`
+	CautiousDrivers   float64             `json:"cautiousDrivers"`
+	ReactionSpread    float64             `json:"reactionSpread"`
+	AVShare           float64             `json:"avShare"`
+	WaveThreshold     float64             `json:"waveThreshold"`
+	HistoryWindow     float64             `json:"historyWindow"`
+	HistoryStart      float64             `json:"historyStart"` // самый ранний момент, доступный для перемотки
+	Waves             []Wave              `json:"waves"`
+	WavesDetected     int                 `json:"wavesDetected"`
+	FuelUsed          float64             `json:"fuelUsed"`
+	CO2Emitted        float64             `json:"co2Emitted"`
+	Platoons          int                 `json:"platoons"`
+	Weather           string              `json:"weather"`
+	Model             string              `json:"model"`
+	IDM               IDMParams           `json:"idm"`
+	NaSch             NaSchParams         `json:"nasch"`
+	OVM               OVMParams           `json:"ovm"`
+	LaneChange        string              `json:"laneChange"`
+	MOBIL             MOBILParams         `json:"mobil"`
+	Seed              int64               `json:"seed"`
+	Oncoming          []*Car              `json:"oncoming"`
+	OncomingDemand    float64             `json:"oncomingDemand"`
+	Overtakes         int                 `json:"overtakes"`
+	OvertakesAborted  int                 `json:"overtakesAborted"`
+	HeadOnCollisions  int                 `json:"headOnCollisions"`
+	Buses             *BusConfig          `json:"buses"`
+	BusesDispatched   int                 `json:"busesDispatched"`
+	BusStopsServed    int                 `json:"busStopsServed"`
+	WorkZone          *WorkZone           `json:"workZone"`
+	Network           *Network            `json:"network"`
+}
+
+// Snapshot возвращает текущее состояние симуляции
+func (s *Simulation) Snapshot() State {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.currentState()
+}
+
+// Metrics мгновенные показатели симуляции для мониторинга
+type Metrics struct {
+	Time          float64 `json:"time"`          // секунды симуляции
+	Running       bool    `json:"running"`       // прогон запущен
+	Paused        bool    `json:"paused"`        // прогон приостановлен
+	Cars          int     `json:"cars"`          // машин на дороге
+	CarsCompleted int     `json:"carsCompleted"` // машин, покинувших дорогу (кругов на кольце)
+	MeanSpeed     float64 `json:"meanSpeed"`     // м/с, средняя скорость машин на дороге
+	BrakeEvents   int     `json:"brakeEvents"`
+	Collisions    int     `json:"collisions"`
+}
+
+// Metrics возвращает показатели для мониторинга; в отличие от Snapshot, значения
+// собираются под блокировкой и не ссылаются на машины, которые меняет шаг
+func (s *Simulation) Metrics() Metrics {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	m := Metrics{
+		Time:          s.Time,
+		Running:       s.Running,
+		Paused:        s.Paused,
+		Cars:          len(s.Cars),
+		CarsCompleted: s.CarsCompleted,
+		BrakeEvents:   s.BrakeEvents,
+		Collisions:    s.Collisions,
+	}
+	for _, car := range s.Cars {
+		m.MeanSpeed += car.Speed
+	}
+	if len(s.Cars) > 0 {
+		m.MeanSpeed /= float64(len(s.Cars))
+	}
+	return m
+}
+
+// currentState собирает состояние для Snapshot; вызывающий держит блокировку
+func (s *Simulation) currentState() State {
+	return State{
+		Cars:              s.Cars,
+		Time:              s.Time,
+		CarsCompleted:     s.CarsCompleted,
+		TravelTime:        s.TravelTime,
+		TotalCarsMade:     s.TotalCarsMade,
+		Running:           s.Running,
+		Paused:            s.Paused,
+		RoadLength:        RoadLength,
+		TimeScale:         s.TimeScale,
+		Turbo:             s.Turbo,
+		TimeStep:          s.TimeStep,
+		MaxCars:           s.MaxCars,
+		ReactionTime:      s.ReactionTime,
+		SafetyMultiplier:  s.SafetyMultiplier,
+		BrakeDeceleration: s.BrakeDeceleration,
+		Acceleration:      s.Acceleration,
+		HysteresisBand:    s.HysteresisBand,
+		SpawnSpeedMode:    s.SpawnSpeedMode,
+		SpawnDistribution: s.SpawnDistribution,
+		Blockages:         s.Blockages,
+		TrafficLights:     s.trafficLightStates(),
+		Crosswalks:        s.crosswalkStates(),
+		OnRamp:            s.onRampState(),
+		RoadType:          s.RoadType,
+		TruckPercentage:   s.TruckPercentage,
+		CrashClearance:    s.CrashClearance,
+		Collisions:        s.Collisions,
+		Detectors:         s.detectorStates(),
+		DetectorInterval:  s.DetectorInterval,
+		Recording:         s.Recording,
+		TrajectorySamples: len(s.trajectories),
+		BusPercentage:     s.BusPercentage,
+		IncidentDelay:     s.IncidentDelay,
+		Segments:          s.segmentStats(),
+		Bins:              s.binStats(),
+		SpeedZones:        s.SpeedZones,
+		Cameras:           s.cameraStates(),
+		Grades:            s.Grades,
+		Schedule:          s.Schedule,
+		VSL:               s.vslState(),
+		SpeedLimit:        s.SpeedLimit,
+		OverLimitTime:     s.OverLimitTime,
+		SpawnInterval:     s.effectiveSpawnInterval(),
+		Demand:            s.effectiveDemand(),
+		DemandProfile:     s.DemandProfile,
+		Lanes:             s.Lanes,
+		LaneChanges:       s.LaneChanges,
+		BrakeEvents:       s.BrakeEvents,
+		AggressiveDrivers: s.AggressiveDrivers,
+		CautiousDrivers:   s.CautiousDrivers,
+		ReactionSpread:    s.ReactionSpread,
+		AVShare:           s.AVShare,
+		WaveThreshold:     s.WaveThreshold,
+		HistoryWindow:     s.HistoryWindow,
+		HistoryStart:      s.historyStart(),
+		Waves:             s.waveStates(),
+		WavesDetected:     s.WavesDetected,
+		FuelUsed:          s.FuelUsed,
+		CO2Emitted:        s.CO2Emitted,
+		Platoons:          s.Platoons,
+		Weather:           s.Weather,
+		Model:             s.Model,
+		IDM:               s.IDM,
+		NaSch:             s.NaSch,
+		OVM:               s.OVM,
+		LaneChange:        s.LaneChange,
+		MOBIL:             s.MOBIL,
+		Oncoming:          s.Oncoming,
+		OncomingDemand:    s.OncomingDemand,
+		Overtakes:         s.Overtakes,
+		OvertakesAborted:  s.OvertakesAborted,
+		HeadOnCollisions:  s.HeadOnCollisions,
+		Buses:             s.Buses,
+		BusesDispatched:   s.BusesDispatched,
+		BusStopsServed:    s.BusStopsServed,
+		WorkZone:          s.workZoneState(),
+		Network:           s.networkState(),
+		Seed:              s.Seed,
+	}
+}
+
+// Start запускает симуляцию или продолжает приостановленную
+func (s *Simulation) Start() {
+	s.mu.Lock()
+	// Запуск с начала (а не продолжение после паузы или остановки) начинает прогон
+	if !s.Running && s.Time == 0 {
+		s.emit(Event{Type: "runStart", Time: s.Time})
+	}
+	s.Running = true
+	s.Paused = false
+	s.mu.Unlock()
+}
+
+// Stop останавливает симуляцию
+func (s *Simulation) Stop() {
+	s.mu.Lock()
+	s.Running = false
+	s.Paused = false
+	s.mu.Unlock()
+}
+
+// Pause приостанавливает симуляцию: время не идёт, пока она не будет продолжена
+// командой Start или продвинута по шагам Advance; в отличие от Stop состояние
+// сообщает, что прогон приостановлен
+func (s *Simulation) Pause() {
+	s.mu.Lock()
+	s.Running = false
+	s.Paused = true
+	s.mu.Unlock()
+}
+
+// Reset сбрасывает симуляцию
+func (s *Simulation) Reset() {
+	s.mu.Lock()
+	s.Cars = make([]*Car, 0)
+	s.Time = 0
+	s.CarsCompleted = 0
+	s.TravelTime = 0
+	s.TotalCarsMade = 0
+	s.Running = false
+	s.Paused = false
+	s.lastSpawn = 0
+	s.spawnGap = 1
+	s.nextCarID = 0
+	s.Blockages = make([]*Blockage, 0)
+	s.IncidentDelay = 0
+	s.OverLimitTime = 0
+	s.LaneChanges = 0
+	s.BrakeEvents = 0
+	s.Collisions = 0
+	s.Oncoming = nil
+	s.lastOncoming = 0
+	s.oncomingGap = 0
+	s.Overtakes = 0
+	s.OvertakesAborted = 0
+	s.HeadOnCollisions = 0
+	s.resetBuses()
+	s.resetNetwork()
+	s.FuelUsed = 0
+	s.CO2Emitted = 0
+	s.Platoons = 0
+	s.nextPlatoonID = 0
+	s.resetDetectors()
+	s.resetCrosswalks()
+	s.resetWorkZone()
+	s.resetCameras()
+	s.resetVSL()
+	s.resetWaves()
+	s.resetCongestion()
+	s.resetDiagram()
+	s.trips = nil
+	s.longestQueue = Queue{}
+	s.resetHeadways()
+	s.trajectories = nil
+	s.lastTrajectory = -TrajectoryInterval
+	s.history = nil
+	s.events = nil
+	s.exports = nil
+	s.nextBlockageID = 0
+	s.segmentExits = make([]int, len(s.Segments))
+	s.resetOnRamp()
+	// Генератор начинается заново, чтобы прогон после сброса повторял предыдущий
+	s.setRNG(0)
+	s.mu.Unlock()
+}
+
+// UpdateConfig обновляет конфигурацию
+func (s *Simulation) UpdateConfig(config SimulationConfig) {
+	s.mu.Lock()
+	s.applyConfig(config)
+	s.mu.Unlock()
+}
+
+// applyConfig применяет конфигурацию; вызывающий держит блокировку
+func (s *Simulation) applyConfig(config SimulationConfig) {
+	s.SpawnInterval = config.SpawnInterval
+	s.MinSpeed = kmhToMs(config.MinSpeed)
+	s.MaxSpeed = kmhToMs(config.MaxSpeed)
+	if config.Demand > 0 {
+		s.SpawnInterval = 3600 / config.Demand
+	}
+	if config.SpawnDistribution == SpawnFixed || config.SpawnDistribution == SpawnPoisson || config.SpawnDistribution == SpawnJitter {
+		s.SpawnDistribution = config.SpawnDistribution
+	}
+	if config.MaxCars > 0 {
+		s.MaxCars = config.MaxCars
+	}
+	if config.SpawnSpeedMode == "random" || config.SpawnSpeedMode == "density" {
+		s.SpawnSpeedMode = config.SpawnSpeedMode
+	}
+	if config.SpeedLimit != 0 {
+		s.SpeedLimit = kmhToMs(math.Max(0, config.SpeedLimit))
+	}
+	if config.DemandProfile != nil {
+		s.setDemandProfile(config.DemandProfile)
+	}
+	if config.DemandBins != nil {
+		s.setDemandProfile(demandBins(config.DemandBins, config.DemandBinLength))
+	}
+	if config.Lanes > 0 && config.Lanes <= MaxLanes {
+		s.setLanes(config.Lanes)
+	}
+	if knownModel(config.Model) {
+		s.Model = config.Model
+	}
+	if config.IDM != nil {
+		s.IDM.merge(*config.IDM)
+	}
+	if config.NaSch != nil {
+		s.NaSch.merge(*config.NaSch)
+	}
+	if config.OVM != nil {
+		s.OVM.merge(*config.OVM)
+	}
+	if config.LaneChange == LaneChangeSpace || config.LaneChange == LaneChangeMOBIL {
+		s.LaneChange = config.LaneChange
+	}
+	if config.MOBIL != nil {
+		s.MOBIL.merge(*config.MOBIL)
+	}
+	if config.OnRamp != nil {
+		s.setOnRamp(*config.OnRamp)
+	}
+	s.setWeather(config.Weather)
+	if config.RoadType == RoadStraight || config.RoadType == RoadRing || config.RoadType == RoadTwoWay || config.RoadType == RoadNetwork {
+		s.RoadType = config.RoadType
+	}
+	if s.RoadType != RoadNetwork {
+		s.Network = nil
+	} else if config.Network != nil {
+		// Новый граф сети заменяет прежний вместе с его машинами
+		s.setNetwork(*config.Network)
+	}
+	if s.twoWay() {
+		// Своя полоса и встречная; число полос из config не применяется
+		s.setLanes(2)
+	} else {
+		s.Oncoming = nil
+	}
+	if config.OncomingDemand != nil {
+		s.OncomingDemand = math.Max(0, *config.OncomingDemand)
+	}
+	if config.TruckPercentage != nil {
+		s.TruckPercentage = math.Max(0, math.Min(100, *config.TruckPercentage))
+	}
+	if config.BusPercentage != nil {
+		s.BusPercentage = math.Max(0, math.Min(100-s.TruckPercentage, *config.BusPercentage))
+	}
+	if config.AggressiveDrivers != nil {
+		s.AggressiveDrivers = math.Max(0, math.Min(100, *config.AggressiveDrivers))
+	}
+	if config.CautiousDrivers != nil {
+		s.CautiousDrivers = math.Max(0, math.Min(100-s.AggressiveDrivers, *config.CautiousDrivers))
+	}
+	if config.ReactionSpread != nil {
+		s.ReactionSpread = math.Max(0, *config.ReactionSpread)
+	}
+	if config.AVShare != nil {
+		s.AVShare = math.Max(0, math.Min(100, *config.AVShare))
+	}
+	if config.TimeStep != nil {
+		s.TimeStep = math.Min(math.Max(MinTimeStep, *config.TimeStep), MaxTimeStep)
+	}
+	if config.HistoryWindow != nil {
+		s.HistoryWindow = math.Min(math.Max(0, *config.HistoryWindow), MaxHistoryWindow)
+	}
+	if config.WaveThreshold != nil {
+		s.WaveThreshold = kmhToMs(math.Max(0, *config.WaveThreshold))
+	}
+	if config.CrashClearance != nil {
+		s.CrashClearance = math.Max(0, *config.CrashClearance)
+	}
+	if config.RecordTrajectories != nil {
+		s.setRecording(*config.RecordTrajectories)
+	}
+	if config.Seed != nil {
+		s.Seed = *config.Seed
+		s.setRNG(0)
+	}
+}
+
+// setLanes меняет число полос; машины с исчезнувших полос переходят на крайнюю оставшуюся
+func (s *Simulation) setLanes(lanes int) {
+	s.Lanes = lanes
+	for _, car := range s.Cars {
+		if car.Lane >= lanes {
+			car.Lane = lanes - 1
+		}
+	}
+}
+
+// UpdatePhysics обновляет параметры физики
+func (s *Simulation) UpdatePhysics(config PhysicsConfig) {
+	s.mu.Lock()
+	s.applyPhysics(config)
+	s.mu.Unlock()
+}
+
+// applyPhysics применяет параметры физики; вызывающий держит блокировку
+func (s *Simulation) applyPhysics(config PhysicsConfig) {
+	if config.ReactionTime > 0 {
+		s.ReactionTime = config.ReactionTime
+	}
+	if config.SafetyMultiplier > 0 {
+		s.SafetyMultiplier = config.SafetyMultiplier
+	}
+	if config.BrakeDeceleration > 0 {
+		s.BrakeDeceleration = config.BrakeDeceleration
+	}
+	if config.Acceleration > 0 {
+		s.Acceleration = config.Acceleration
+	}
+	if band := config.HysteresisBand; band != nil && *band >= 0 && *band < 1 {
+		s.HysteresisBand = *band
+	}
+}
+
+// SetTimeScale устанавливает скорость времени
+func (s *Simulation) SetTimeScale(scale float64) {
+	s.mu.Lock()
+	s.TimeScale = clampTimeScale(scale)
+	s.mu.Unlock()
+}
+
+// SetTurbo включает или выключает ускоренный режим: сервер выполняет шаги
+// физики TimeStep без ожидания таймера, а состояние рассылает с обычной частотой
+func (s *Simulation) SetTurbo(turbo bool) {
+	s.mu.Lock()
+	s.Turbo = turbo
+	s.mu.Unlock()
+}
+
+// clampTimeScale ограничивает множитель времени значениями от 0.2x до 20x
+func clampTimeScale(scale float64) float64 {
+	if scale < 0.2 {
+		scale = 0.2
+	}
+	if scale > 20.0 {
+		scale = 20.0
+	}
+	return scale
+}
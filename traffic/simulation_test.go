@@ -0,0 +1,88 @@
+package traffic
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDensitySpawnSpeed проверяет, что в режиме "density" средняя целевая скорость
+// новых машин ниже при более плотном въезде, а в режиме "random" от плотности не зависит
+func TestDensitySpawnSpeed(t *testing.T) {
+	const samples = 2000
+	// Машин в зоне въезда на каждой из трёх полос, от пустой дороги до затора
+	densities := []int{0, 3, 8, 20}
+	tests := []struct {
+		mode      string
+		decreases bool
+	}{
+		{mode: "density", decreases: true},
+		{mode: "random", decreases: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			means := make([]float64, len(densities))
+			for i, perLane := range densities {
+				seed := int64(11)
+				sim := New(WithConfig(SimulationConfig{MinSpeed: 40, MaxSpeed: 120, Lanes: 3, SpawnSpeedMode: tt.mode, Seed: &seed}))
+				for lane := 0; lane < sim.Lanes; lane++ {
+					for j := 0; j < perLane; j++ {
+						position := EntryZone * (float64(j) + 0.5) / float64(perLane)
+						sim.Cars = append(sim.Cars, &Car{ID: len(sim.Cars) + 1, Type: "car", Lane: lane, Position: position})
+					}
+				}
+				sum := 0.0
+				for j := 0; j < samples; j++ {
+					sum += sim.spawnSpeed()
+				}
+				means[i] = sum / samples
+				if means[i] < sim.MinSpeed-1e-9 || means[i] > sim.MaxSpeed+1e-9 {
+					t.Fatalf("%d cars per lane: mean %.2f outside [%.2f, %.2f]", perLane, means[i], sim.MinSpeed, sim.MaxSpeed)
+				}
+			}
+			for i := 1; i < len(means); i++ {
+				if tt.decreases && means[i] >= means[i-1] {
+					t.Errorf("%d cars per lane: mean %.2f not below %.2f at %d cars per lane",
+						densities[i], means[i], means[i-1], densities[i-1])
+				}
+				if !tt.decreases && means[i] != means[0] {
+					t.Errorf("%d cars per lane: mean %.2f differs from empty entry %.2f", densities[i], means[i], means[0])
+				}
+			}
+		})
+	}
+}
+
+// TestMetrics проверяет показатели мониторинга и то, что их можно снимать, пока
+// симуляция идёт в другой горутине (гонку ловит go test -race)
+func TestMetrics(t *testing.T) {
+	sim := New()
+	sim.Cars = []*Car{{ID: 1, Speed: 10}, {ID: 2, Speed: 20}, {ID: 3, Speed: 30}}
+	sim.CarsCompleted, sim.BrakeEvents, sim.Collisions = 4, 5, 1
+	m := sim.Metrics()
+	if m.Cars != 3 || m.CarsCompleted != 4 || m.BrakeEvents != 5 || m.Collisions != 1 {
+		t.Fatalf("unexpected counters %+v", m)
+	}
+	if math.Abs(m.MeanSpeed-20) > 1e-9 {
+		t.Fatalf("mean speed = %.3f, want 20", m.MeanSpeed)
+	}
+
+	sim = New(WithSpawnInterval(0.5))
+	sim.Start()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 500; i++ {
+			sim.Step(DefaultStep)
+		}
+	}()
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+		if m := sim.Metrics(); m.MeanSpeed < 0 {
+			t.Fatalf("negative mean speed %.3f", m.MeanSpeed)
+		}
+	}
+}
@@ -0,0 +1,393 @@
+package traffic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"slices"
+)
+
+// countingSource источник случайных чисел, считающий выборки, чтобы позицию потока
+// можно было сохранить и восстановить перемоткой от зерна
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func (c *countingSource) Int63() int64 {
+	c.draws++
+	return c.src.Int63()
+}
+
+func (c *countingSource) Uint64() uint64 {
+	c.draws++
+	return c.src.Uint64()
+}
+
+func (c *countingSource) Seed(seed int64) {
+	c.src.Seed(seed)
+	c.draws = 0
+}
+
+// MaxRNGDraws предел числа выборок генератора в снимке: перемотка на 2^26 выборок
+// занимает десятые доли секунды, а столько машины выбирают за недели симуляционного времени
+const MaxRNGDraws = 1 << 26
+
+// setRNG пересоздаёт генератор из s.Seed и перематывает его на draws выборок вперёд
+func (s *Simulation) setRNG(draws uint64) {
+	source := &countingSource{src: rand.NewSource(s.Seed).(rand.Source64)}
+	for i := uint64(0); i < draws; i++ {
+		source.Int63()
+	}
+	s.rngSource = source
+	s.rng = rand.New(source)
+}
+
+// carPrivate неэкспортируемое состояние автомобиля, необходимое для точного продолжения
+type carPrivate struct {
+	ID             int                `json:"id"`
+	LastBrakeTime  float64            `json:"lastBrakeTime"`
+	LastLaneChange float64            `json:"lastLaneChange"`
+	CrashedUntil   float64            `json:"crashedUntil"`
+	SpawnTime      float64            `json:"spawnTime"`
+	ReactionFactor float64            `json:"reactionFactor"`
+	DesiredSpeed   float64            `json:"desiredSpeed"`
+	Perception     []perceptionSample `json:"perception"`
+	Stops          int                `json:"stops"`
+	Stopped        bool               `json:"stopped"`
+	SlowTime       float64            `json:"slowTime"`
+	FreeFlowTime   float64            `json:"freeFlowTime"`
+	TripFuel       float64            `json:"tripFuel"`
+	TripCO2        float64            `json:"tripCO2"`
+	Accel          float64            `json:"accel"`
+	BrakeUntil     float64            `json:"brakeUntil"`
+	Pedals         Pedals             `json:"pedals"`
+	OvertakeTarget int                `json:"overtakeTarget"`
+	RouteStep      int                `json:"routeStep"`
+	LinkEnter      float64            `json:"linkEnter"`
+	LinkFreeFlow   float64            `json:"linkFreeFlow"`
+	BusRoute       bool               `json:"busRoute"`
+	NextStop       int                `json:"nextStop"`
+	DwellUntil     float64            `json:"dwellUntil"`
+}
+
+// detectorPrivate накопленные показатели текущего интервала детектора
+type detectorPrivate struct {
+	Count         int     `json:"count"`
+	SpeedSum      float64 `json:"speedSum"`
+	Occupied      float64 `json:"occupied"`
+	IntervalStart float64 `json:"intervalStart"`
+}
+
+// simulationSnapshot полное состояние симуляции: экспортируемые поля сериализуются
+// через встроенную Simulation, внутренние счётчики и позиция генератора - явно
+type simulationSnapshot struct {
+	*Simulation
+	LastSpawn         float64           `json:"lastSpawn"`
+	SpawnGap          float64           `json:"spawnGap"`
+	LastOncoming      float64           `json:"lastOncoming"`
+	OncomingGap       float64           `json:"oncomingGap"`
+	NextBus           float64           `json:"nextBus"`
+	NetworkArrivals   []float64         `json:"networkArrivals"`
+	NextCarID         int               `json:"nextCarID"`
+	NextBlockageID    int               `json:"nextBlockageID"`
+	SegmentExits      []int             `json:"segmentExits"`
+	CarsPrivate       []carPrivate      `json:"carsPrivate"`
+	RNGDraws          uint64            `json:"rngDraws"`
+	DetectorsPrivate  []detectorPrivate `json:"detectorsPrivate"`
+	CrosswalkArrivals []float64         `json:"crosswalkArrivals"`
+	RampLastArrival   float64           `json:"rampLastArrival"`
+	VSLUpdated        float64           `json:"vslUpdated"`
+	MeteringUpdated   float64           `json:"meteringUpdated"`
+	MeteringRelease   float64           `json:"meteringRelease"`
+	WavesUpdated      float64           `json:"wavesUpdated"`
+	Jammed            bool              `json:"jammed"`
+	JammedSince       float64           `json:"jammedSince"`
+	Congested         bool              `json:"congested"`
+	NextWaveID        int               `json:"nextWaveID"`
+	NextPlatoonID     int               `json:"nextPlatoonID"`
+	Diagram           []DiagramPoint    `json:"diagram"`
+	DiagramStart      float64           `json:"diagramStart"`
+	DiagramMeters     []float64         `json:"diagramMeters"`
+	DiagramTime       []float64         `json:"diagramTime"`
+	Trips             []Trip            `json:"trips"`
+	Headways          []float64         `json:"headways"`
+	HeadwayCrossings  []float64         `json:"headwayCrossings"`
+	LongestQueue      Queue             `json:"longestQueue"`
+}
+
+// SaveState сериализует полное состояние симуляции, включая позицию потока случайных чисел
+func (s *Simulation) SaveState() ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.saveState()
+}
+
+// saveState сериализует полное состояние симуляции; вызывающий держит блокировку
+func (s *Simulation) saveState() ([]byte, error) {
+	return json.Marshal(s.stateSnapshot())
+}
+
+// stateSnapshot собирает полное состояние симуляции для сериализации
+func (s *Simulation) stateSnapshot() simulationSnapshot {
+	snap := simulationSnapshot{
+		Simulation:       s,
+		LastSpawn:        s.lastSpawn,
+		SpawnGap:         s.spawnGap,
+		LastOncoming:     s.lastOncoming,
+		OncomingGap:      s.oncomingGap,
+		NextBus:          s.nextBus,
+		NextCarID:        s.nextCarID,
+		NextBlockageID:   s.nextBlockageID,
+		SegmentExits:     s.segmentExits,
+		CarsPrivate:      make([]carPrivate, 0, len(s.Cars)+len(s.Oncoming)),
+		RNGDraws:         s.rngSource.draws,
+		VSLUpdated:       s.vslUpdated,
+		WavesUpdated:     s.wavesUpdated,
+		Jammed:           s.jammed,
+		JammedSince:      s.jammedSince,
+		Congested:        s.congested,
+		NextWaveID:       s.nextWaveID,
+		NextPlatoonID:    s.nextPlatoonID,
+		Diagram:          s.diagram,
+		DiagramStart:     s.diagramStart,
+		DiagramMeters:    s.diagramMeters,
+		DiagramTime:      s.diagramTime,
+		Trips:            s.trips,
+		Headways:         s.headways,
+		HeadwayCrossings: s.headwayCrossings,
+		LongestQueue:     s.longestQueue,
+	}
+	if s.OnRamp != nil {
+		snap.RampLastArrival = s.OnRamp.lastArrival
+		if m := s.OnRamp.Metering; m != nil {
+			snap.MeteringUpdated, snap.MeteringRelease = m.lastUpdate, m.lastRelease
+		}
+	}
+	for _, d := range s.Detectors {
+		snap.DetectorsPrivate = append(snap.DetectorsPrivate, detectorPrivate{
+			Count: d.count, SpeedSum: d.speedSum, Occupied: d.occupied, IntervalStart: d.intervalStart,
+		})
+	}
+	for _, c := range s.Crosswalks {
+		snap.CrosswalkArrivals = append(snap.CrosswalkArrivals, c.nextArrival)
+	}
+	if s.Network != nil {
+		snap.NetworkArrivals = s.Network.arrivals
+	}
+	for _, cars := range [][]*Car{s.Cars, s.Oncoming, s.networkCars()} {
+		for _, car := range cars {
+			snap.CarsPrivate = append(snap.CarsPrivate, carPrivate{
+				ID: car.ID, LastBrakeTime: car.lastBrakeTime, LastLaneChange: car.lastLaneChange, CrashedUntil: car.crashedUntil,
+				SpawnTime: car.spawnTime, ReactionFactor: car.reactionFactor, DesiredSpeed: car.desiredSpeed,
+				Perception: car.perception.list(), Stops: car.stops, Stopped: car.stopped, SlowTime: car.slowTime,
+				FreeFlowTime: car.freeFlowTime, TripFuel: car.tripFuel, TripCO2: car.tripCO2, Accel: car.accel,
+				BrakeUntil: car.brakeUntil, Pedals: car.pedals, OvertakeTarget: car.overtakeTarget,
+				RouteStep: car.routeStep, LinkEnter: car.linkEnter, LinkFreeFlow: car.linkFreeFlow,
+				BusRoute: car.busRoute, NextStop: car.nextStop, DwellUntil: car.dwellUntil,
+			})
+		}
+	}
+	return snap
+}
+
+// LoadState восстанавливает состояние, сохранённое SaveState; после загрузки
+// симуляция продолжается так же, как продолжился бы оригинал. История перемотки
+// начинается заново.
+func (s *Simulation) LoadState(data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.loadState(data); err != nil {
+		return err
+	}
+	s.history = nil
+	return nil
+}
+
+// loadState восстанавливает состояние, сохранённое saveState; вызывающий держит блокировку
+func (s *Simulation) loadState(data []byte) error {
+	// Декодируем в отдельную симуляцию, чтобы ошибка не оставила s в промежуточном состоянии
+	loaded := New()
+	snap := simulationSnapshot{Simulation: loaded, SpawnGap: 1}
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+	// Генератор перематывается под блокировкой симуляции, поэтому число выборок из
+	// присланного снимка ограничено
+	if snap.RNGDraws > MaxRNGDraws {
+		var e ValidationError
+		e.add("rngDraws", fmt.Sprintf("must not exceed %d", uint64(MaxRNGDraws)))
+		return &e
+	}
+
+	private := make(map[int]carPrivate, len(snap.CarsPrivate))
+	for _, p := range snap.CarsPrivate {
+		private[p.ID] = p
+	}
+	for _, car := range slices.Concat(loaded.Cars, loaded.Oncoming, loaded.networkCars()) {
+		car.lastBrakeTime = private[car.ID].LastBrakeTime
+		car.lastLaneChange = private[car.ID].LastLaneChange
+		car.crashedUntil = private[car.ID].CrashedUntil
+		car.spawnTime = private[car.ID].SpawnTime
+		car.reactionFactor = private[car.ID].ReactionFactor
+		car.desiredSpeed = private[car.ID].DesiredSpeed
+		if car.desiredSpeed == 0 {
+			// Снимок сохранён до появления зон ограничения
+			car.desiredSpeed = car.TargetSpeed
+		}
+		car.perception = restorePerception(private[car.ID].Perception)
+		car.stops = private[car.ID].Stops
+		car.stopped = private[car.ID].Stopped
+		car.slowTime = private[car.ID].SlowTime
+		car.freeFlowTime = private[car.ID].FreeFlowTime
+		car.tripFuel = private[car.ID].TripFuel
+		car.tripCO2 = private[car.ID].TripCO2
+		car.accel = private[car.ID].Accel
+		car.brakeUntil = private[car.ID].BrakeUntil
+		car.pedals = private[car.ID].Pedals
+		car.overtakeTarget = private[car.ID].OvertakeTarget
+		car.routeStep = private[car.ID].RouteStep
+		car.linkEnter = private[car.ID].LinkEnter
+		car.linkFreeFlow = private[car.ID].LinkFreeFlow
+		car.busRoute = private[car.ID].BusRoute
+		car.nextStop = private[car.ID].NextStop
+		car.dwellUntil = private[car.ID].DwellUntil
+	}
+
+	s.Cars = loaded.Cars
+	s.sortCars()
+	s.Time = loaded.Time
+	s.CarsCompleted = loaded.CarsCompleted
+	s.TravelTime = loaded.TravelTime
+	s.TotalCarsMade = loaded.TotalCarsMade
+	s.Running = loaded.Running
+	s.Paused = loaded.Paused
+	s.SpawnInterval = loaded.SpawnInterval
+	s.MinSpeed = loaded.MinSpeed
+	s.MaxSpeed = loaded.MaxSpeed
+	s.TimeScale = loaded.TimeScale
+	s.Turbo = loaded.Turbo
+	s.TimeStep = loaded.TimeStep
+	s.MaxCars = loaded.MaxCars
+	s.ReactionTime = loaded.ReactionTime
+	s.SafetyMultiplier = loaded.SafetyMultiplier
+	s.BrakeDeceleration = loaded.BrakeDeceleration
+	s.Acceleration = loaded.Acceleration
+	s.HysteresisBand = loaded.HysteresisBand
+	s.Model = loaded.Model
+	s.IDM = loaded.IDM
+	s.NaSch = loaded.NaSch
+	if s.NaSch.CellLength <= 0 || s.NaSch.Tick <= 0 {
+		// Состояние сохранено до появления автомата
+		s.NaSch = defaultNaSchParams()
+	}
+	// Параметры, которых нет в старом сохранении, остаются по умолчанию
+	s.OVM = defaultOVMParams()
+	s.OVM.merge(loaded.OVM)
+	s.LaneChange = loaded.LaneChange
+	if s.LaneChange == "" {
+		s.LaneChange = LaneChangeSpace
+	}
+	s.MOBIL = loaded.MOBIL
+	if s.MOBIL.SafeDeceleration <= 0 {
+		s.MOBIL = defaultMOBILParams()
+	}
+	s.SpawnSpeedMode = loaded.SpawnSpeedMode
+	s.SpawnDistribution = loaded.SpawnDistribution
+	s.Seed = loaded.Seed
+	s.SpeedLimit = loaded.SpeedLimit
+	s.OverLimitTime = loaded.OverLimitTime
+	s.DemandProfile = loaded.DemandProfile
+	s.Blockages = loaded.Blockages
+	s.TrafficLights = loaded.TrafficLights
+	s.RoadType = loaded.RoadType
+	s.TruckPercentage = loaded.TruckPercentage
+	s.CrashClearance = loaded.CrashClearance
+	s.Collisions = loaded.Collisions
+	s.Oncoming = loaded.Oncoming
+	s.OncomingDemand = loaded.OncomingDemand
+	s.lastOncoming, s.oncomingGap = snap.LastOncoming, snap.OncomingGap
+	s.Overtakes = loaded.Overtakes
+	s.OvertakesAborted = loaded.OvertakesAborted
+	s.HeadOnCollisions = loaded.HeadOnCollisions
+	s.Buses = loaded.Buses
+	s.BusesDispatched = loaded.BusesDispatched
+	s.BusStopsServed = loaded.BusStopsServed
+	s.WorkZone = loaded.WorkZone
+	s.nextBus = snap.NextBus
+	s.Network = loaded.Network
+	if s.Network != nil {
+		s.Network.arrivals = snap.NetworkArrivals
+		s.Network.prepare()
+	}
+	s.Crosswalks = loaded.Crosswalks
+	for i, c := range s.Crosswalks {
+		c.nextArrival = -1
+		if i < len(snap.CrosswalkArrivals) {
+			c.nextArrival = snap.CrosswalkArrivals[i]
+		}
+	}
+	s.Detectors = loaded.Detectors
+	s.DetectorInterval = loaded.DetectorInterval
+	for i, d := range s.Detectors {
+		if i < len(snap.DetectorsPrivate) {
+			p := snap.DetectorsPrivate[i]
+			d.count, d.speedSum, d.occupied, d.intervalStart = p.Count, p.SpeedSum, p.Occupied, p.IntervalStart
+		}
+	}
+	s.BusPercentage = loaded.BusPercentage
+	s.OnRamp = loaded.OnRamp
+	if s.OnRamp != nil {
+		s.OnRamp.lastArrival = snap.RampLastArrival
+		if m := s.OnRamp.Metering; m != nil {
+			m.lastUpdate, m.lastRelease = snap.MeteringUpdated, snap.MeteringRelease
+		}
+	}
+	s.IncidentDelay = loaded.IncidentDelay
+	s.Segments = loaded.Segments
+	s.SpeedZones = loaded.SpeedZones
+	s.Cameras = loaded.Cameras
+	s.Grades = loaded.Grades
+	s.Schedule = loaded.Schedule
+	s.VSL = loaded.VSL
+	s.vslUpdated = snap.VSLUpdated
+	s.Lanes = loaded.Lanes
+	s.LaneChanges = loaded.LaneChanges
+	s.BrakeEvents = loaded.BrakeEvents
+	s.AggressiveDrivers = loaded.AggressiveDrivers
+	s.CautiousDrivers = loaded.CautiousDrivers
+	s.AVShare = loaded.AVShare
+	s.Weather = loaded.Weather
+	s.ReactionSpread = loaded.ReactionSpread
+	s.WaveThreshold = loaded.WaveThreshold
+	s.HistoryWindow = loaded.HistoryWindow
+	s.Waves = loaded.Waves
+	s.WavesDetected = loaded.WavesDetected
+	s.wavesUpdated = snap.WavesUpdated
+	s.jammed, s.jammedSince, s.congested = snap.Jammed, snap.JammedSince, snap.Congested
+	s.nextWaveID = snap.NextWaveID
+	s.Platoons = loaded.Platoons
+	s.nextPlatoonID = snap.NextPlatoonID
+	s.FuelUsed = loaded.FuelUsed
+	s.CO2Emitted = loaded.CO2Emitted
+	s.diagram = snap.Diagram
+	s.trips = snap.Trips
+	s.headways, s.headwayCrossings = snap.Headways, snap.HeadwayCrossings
+	s.longestQueue = snap.LongestQueue
+	s.diagramStart = snap.DiagramStart
+	s.diagramMeters, s.diagramTime = nil, nil
+	if len(snap.DiagramMeters) == diagramCells() && len(snap.DiagramTime) == diagramCells() {
+		s.diagramMeters, s.diagramTime = snap.DiagramMeters, snap.DiagramTime
+	}
+
+	s.lastSpawn = snap.LastSpawn
+	s.spawnGap = snap.SpawnGap
+	s.nextCarID = snap.NextCarID
+	s.nextBlockageID = snap.NextBlockageID
+	s.segmentExits = make([]int, len(s.Segments))
+	copy(s.segmentExits, snap.SegmentExits)
+	s.setRNG(snap.RNGDraws)
+	return nil
+}
@@ -0,0 +1,114 @@
+package traffic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestSnapshotDeterminism проверяет, что продолжение симуляции после сохранения и после
+// загрузки того же снимка в новую симуляцию даёт побайтно одинаковые состояния
+func TestSnapshotDeterminism(t *testing.T) {
+	const before, after = 1200, 1200
+	trucks := 30.0
+	tests := []struct {
+		name   string
+		config SimulationConfig
+	}{
+		{name: "simple straight", config: SimulationConfig{Lanes: 2, Model: "simple"}},
+		{name: "idm three lanes", config: SimulationConfig{Lanes: 3, Model: "idm", SpawnSpeedMode: "density"}},
+		{name: "nasch ring", config: SimulationConfig{Lanes: 1, Model: "nasch", RoadType: RoadRing, MaxCars: 60}},
+		{name: "ovm with trucks", config: SimulationConfig{Lanes: 2, Model: "ovm", TruckPercentage: &trucks}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed := int64(42)
+			noHistory := 0.0
+			tt.config.Seed = &seed
+			tt.config.HistoryWindow = &noHistory
+			if tt.config.SpawnInterval == 0 {
+				tt.config.SpawnInterval = 1
+			}
+			if tt.config.MaxSpeed == 0 {
+				tt.config.MinSpeed, tt.config.MaxSpeed = 60, 120
+			}
+			original := New(WithConfig(tt.config))
+			original.Advance(before, DefaultStep)
+			saved, err := original.SaveState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			original.Advance(after, DefaultStep)
+
+			restored := New()
+			if err := restored.LoadState(saved); err != nil {
+				t.Fatal(err)
+			}
+			restored.Advance(after, DefaultStep)
+
+			want, err := original.SaveState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := restored.SaveState()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				i := 0
+				for i < len(got) && i < len(want) && got[i] == want[i] {
+					i++
+				}
+				from := max(0, i-120)
+				t.Fatalf("restored run diverged from the original at byte %d:\n got %.240s\nwant %.240s", i, got[from:], want[from:])
+			}
+			wantJSON, _ := original.StateJSON()
+			gotJSON, _ := restored.StateJSON()
+			if !bytes.Equal(gotJSON, wantJSON) {
+				t.Fatal("broadcast state of the restored run differs from the original")
+			}
+		})
+	}
+}
+
+// TestLoadStateRNGDrawsLimit проверяет, что снимок с числом выборок генератора выше
+// MaxRNGDraws отвергается сразу и не меняет симуляцию
+func TestLoadStateRNGDrawsLimit(t *testing.T) {
+	sim := New(WithSeed(5))
+	sim.Start()
+	sim.Advance(200, DefaultStep)
+	data, err := sim.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	fields["rngDraws"] = json.RawMessage(strconv.FormatUint(math.MaxUint64, 10))
+	tampered, err := json.Marshal(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err = sim.LoadState(tampered)
+	var validation *ValidationError
+	if !errors.As(err, &validation) || validation.Fields["rngDraws"] == "" {
+		t.Fatalf("err = %v, want an rngDraws validation error", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("rejecting the snapshot took %v", elapsed)
+	}
+	after, err := sim.SaveState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(after, data) {
+		t.Fatal("rejected snapshot changed the simulation")
+	}
+}
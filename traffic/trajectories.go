@@ -0,0 +1,162 @@
+package traffic
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+)
+
+const (
+	// TrajectoryInterval секунды симуляции между записями траекторий
+	TrajectoryInterval = 0.5
+	// MaxTrajectorySamples предел числа записей; при его достижении запись останавливается
+	MaxTrajectorySamples = 1000000
+)
+
+// TrajectorySample положение и скорость одной машины в момент времени
+type TrajectorySample struct {
+	Time     float64 `json:"time"`     // секунды симуляции
+	CarID    int     `json:"carId"`    //
+	Lane     int     `json:"lane"`     //
+	Position float64 `json:"position"` // метры
+	Speed    float64 `json:"speed"`    // м/с
+	State    string  `json:"state"`    //
+}
+
+// setRecording включает или выключает запись траекторий; включение начинает новую запись
+func (s *Simulation) setRecording(on bool) {
+	if on && !s.Recording {
+		s.trajectories = nil
+		s.lastTrajectory = -TrajectoryInterval
+	}
+	s.Recording = on
+}
+
+// recordTrajectories записывает положение всех машин не чаще раза в TrajectoryInterval
+func (s *Simulation) recordTrajectories() {
+	if !s.Recording || s.Time-s.lastTrajectory < TrajectoryInterval {
+		return
+	}
+	if len(s.trajectories)+len(s.Cars) > MaxTrajectorySamples {
+		s.Recording = false
+		return
+	}
+	for _, car := range s.Cars {
+		s.trajectories = append(s.trajectories, TrajectorySample{
+			Time:     s.Time,
+			CarID:    car.ID,
+			Lane:     car.Lane,
+			Position: car.Position,
+			Speed:    car.Speed,
+			State:    car.State,
+		})
+	}
+	s.lastTrajectory = s.Time
+}
+
+// Trajectories возвращает записанные траектории; срез только дописывается
+// симуляцией, поэтому вызывающий не должен его менять
+func (s *Simulation) Trajectories() []TrajectorySample {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.trajectories
+}
+
+// WriteTrajectoriesCSV пишет записанные траектории в w в формате CSV
+// (time,car_id,lane,position,speed,state), например для построения пространственно-временной диаграммы
+func (s *Simulation) WriteTrajectoriesCSV(w io.Writer) error {
+	// Записи только дописываются, поэтому достаточно снять срез под блокировкой
+	s.mu.RLock()
+	samples := s.trajectories
+	s.mu.RUnlock()
+
+	return WriteTrajectorySamplesCSV(w, samples)
+}
+
+// WriteTrajectorySamplesCSV пишет записи траекторий в CSV в формате WriteTrajectoriesCSV
+func WriteTrajectorySamplesCSV(w io.Writer, samples []TrajectorySample) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"time", "car_id", "lane", "position", "speed", "state"})
+	for _, t := range samples {
+		out.Write([]string{
+			strconv.FormatFloat(t.Time, 'f', 2, 64),
+			strconv.Itoa(t.CarID),
+			strconv.Itoa(t.Lane),
+			strconv.FormatFloat(t.Position, 'f', 2, 64),
+			strconv.FormatFloat(t.Speed, 'f', 2, 64),
+			t.State,
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// MaxTimeSpaceRows предел числа строк (окон времени) пространственно-временной диаграммы
+const MaxTimeSpaceRows = 2000
+
+// TimeSpaceDiagram пространственно-временная диаграмма скоростей по записанным
+// траекториям: Speeds[i][j] - средняя скорость записей в окне времени i длиной
+// Resolution секунд от From и в ячейке дороги j длиной CellLength метров
+type TimeSpaceDiagram struct {
+	From       float64     `json:"from"`       // секунды симуляции, начало первого окна
+	To         float64     `json:"to"`         // секунды симуляции, конец диаграммы
+	Resolution float64     `json:"resolution"` // секунды, длина окна времени
+	CellLength float64     `json:"cellLength"` // метры, длина ячейки дороги
+	Speeds     [][]float64 `json:"speeds"`     // км/ч по окнам времени и ячейкам (-1 - записей не было)
+}
+
+// TimeSpace строит пространственно-временную диаграмму записанных траекторий за
+// [from, to) с окнами по resolution секунд и ячейками по BinLength метров. Нулевые
+// from и to означают начало и конец записи; окно увеличивается, если строк
+// получилось бы больше MaxTimeSpaceRows.
+func (s *Simulation) TimeSpace(from, to, resolution float64) TimeSpaceDiagram {
+	// Записи только дописываются, поэтому достаточно снять срез под блокировкой
+	s.mu.RLock()
+	samples := s.trajectories
+	s.mu.RUnlock()
+
+	if len(samples) > 0 {
+		if from <= 0 {
+			from = samples[0].Time
+		}
+		if to <= 0 {
+			to = samples[len(samples)-1].Time + TrajectoryInterval
+		}
+	}
+	resolution = math.Max(resolution, TrajectoryInterval)
+	resolution = math.Max(resolution, (to-from)/MaxTimeSpaceRows)
+	diagram := TimeSpaceDiagram{From: from, To: to, Resolution: resolution, CellLength: BinLength, Speeds: [][]float64{}}
+	if to <= from {
+		return diagram
+	}
+	rows := min(int(math.Ceil((to-from)/resolution)), MaxTimeSpaceRows)
+	cells := int(math.Ceil(RoadLength / BinLength))
+	sums := make([][]float64, rows)
+	counts := make([][]int, rows)
+	for i := range sums {
+		sums[i] = make([]float64, cells)
+		counts[i] = make([]int, cells)
+	}
+	for _, t := range samples {
+		if t.Time < from || t.Time >= to {
+			continue
+		}
+		i := min(int((t.Time-from)/resolution), rows-1)
+		j := min(max(int(t.Position/BinLength), 0), cells-1)
+		sums[i][j] += t.Speed
+		counts[i][j]++
+	}
+	diagram.Speeds = make([][]float64, rows)
+	for i := range sums {
+		diagram.Speeds[i] = make([]float64, cells)
+		for j, n := range counts[i] {
+			diagram.Speeds[i][j] = -1
+			if n > 0 {
+				diagram.Speeds[i][j] = msToKmh(sums[i][j] / float64(n))
+			}
+		}
+	}
+	return diagram
+}
@@ -0,0 +1,240 @@
+package traffic
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+)
+
+const (
+	// SlowSpeed км/ч, скорость, время ниже которой учитывается в поездке
+	SlowSpeed = 20.0
+	// StopSpeed км/ч, скорость, опускание ниже которой считается остановкой;
+	// следующая остановка засчитывается после разгона выше 2·StopSpeed
+	StopSpeed = 5.0
+	// MaxTrips число хранимых завершённых поездок
+	MaxTrips = 20000
+	// QueueMaxGap метры, наибольший промежуток между соседними стоящими машинами одной очереди
+	QueueMaxGap = 15.0
+)
+
+// Trip показатели поездки машины, завершившей проезд дороги (на кольце - круг)
+type Trip struct {
+	CarID      int     `json:"carId"`
+	Type       string  `json:"type"`
+	Driver     string  `json:"driver"`
+	Start      float64 `json:"start"`      // секунды симуляции, появление на дороге или начало круга
+	TravelTime float64 `json:"travelTime"` // секунды
+	Stops      int     `json:"stops"`      // число остановок
+	SlowTime   float64 `json:"slowTime"`   // секунды движения медленнее SlowSpeed
+	Delay      float64 `json:"delay"`      // секунды сверх проезда того же пути с целевой скоростью
+	Fuel       float64 `json:"fuel"`       // литры топлива
+	CO2        float64 `json:"co2"`        // кг CO₂
+}
+
+// Percentiles распределение показателя по поездкам
+type Percentiles struct {
+	Mean float64 `json:"mean"`
+	P50  float64 `json:"p50"`
+	P90  float64 `json:"p90"`
+	P99  float64 `json:"p99"`
+}
+
+// TripStats распределения показателей хранимых поездок
+type TripStats struct {
+	Count      int         `json:"count"`
+	TravelTime Percentiles `json:"travelTime"` // секунды
+	Delay      Percentiles `json:"delay"`      // секунды
+	Stops      Percentiles `json:"stops"`
+	SlowTime   Percentiles `json:"slowTime"` // секунды
+	Fuel       Percentiles `json:"fuel"`     // литры
+	CO2        Percentiles `json:"co2"`      // кг
+}
+
+// Queue очередь подряд стоящих машин на полосе
+type Queue struct {
+	Lane   int     `json:"lane"`
+	Cars   int     `json:"cars"`
+	Head   float64 `json:"head"`   // метры, передний край очереди
+	Length float64 `json:"length"` // метры, от головы первой машины до хвоста последней
+	Time   float64 `json:"time"`   // секунды симуляции
+}
+
+// RunSummary итоги прогона: счётчики симуляции и распределения показателей поездок
+type RunSummary struct {
+	Time          float64   `json:"time"` // секунды симуляции
+	CarsMade      int       `json:"carsMade"`
+	CarsCompleted int       `json:"carsCompleted"`
+	Throughput    float64   `json:"throughput"` // авто/ч, завершивших проезд
+	BrakeEvents   int       `json:"brakeEvents"`
+	LaneChanges   int       `json:"laneChanges"`
+	Collisions    int       `json:"collisions"`
+	IncidentDelay float64   `json:"incidentDelay"` // авто·с
+	FuelUsed      float64   `json:"fuelUsed"`      // литры, включая машины на дороге
+	CO2Emitted    float64   `json:"co2Emitted"`    // кг, включая машины на дороге
+	MaxQueue      Queue     `json:"maxQueue"`      // самая длинная очередь за прогон
+	Trips         TripStats `json:"trips"`
+}
+
+// trackTrip учитывает за шаг dt остановки машины, время медленнее SlowSpeed и время
+// проезда пройденного пути distance с целевой скоростью
+func (s *Simulation) trackTrip(car *Car, distance, dt float64) {
+	switch {
+	case car.Speed < kmhToMs(StopSpeed) && !car.stopped:
+		car.stopped = true
+		car.stops++
+	case car.Speed > kmhToMs(2*StopSpeed):
+		car.stopped = false
+	}
+	if car.Speed < kmhToMs(SlowSpeed) {
+		car.slowTime += dt
+	}
+	if car.TargetSpeed > 0 {
+		car.freeFlowTime += distance / car.TargetSpeed
+	}
+}
+
+// completeTrip учитывает завершение проезда машиной, записывает поездку
+// и начинает следующую (на кольце - новый круг)
+func (s *Simulation) completeTrip(car *Car) {
+	travel := s.Time - car.spawnTime
+	s.CarsCompleted++
+	s.emitExport(Event{Type: "car:completed", Time: s.Time, Cars: []int{car.ID}, Lane: car.Lane, Position: car.Position, TravelTime: travel})
+	s.TravelTime += travel
+	s.trips = append(s.trips, Trip{
+		CarID: car.ID, Type: car.Type, Driver: car.Driver, Start: car.spawnTime, TravelTime: travel,
+		Stops: car.stops, SlowTime: car.slowTime, Delay: math.Max(0, travel-car.freeFlowTime),
+		Fuel: car.tripFuel, CO2: car.tripCO2,
+	})
+	if len(s.trips) > MaxTrips {
+		s.trips = s.trips[len(s.trips)-MaxTrips:]
+	}
+	car.spawnTime = s.Time
+	car.stops, car.slowTime, car.freeFlowTime = 0, 0, 0
+	car.tripFuel, car.tripCO2 = 0, 0
+}
+
+// updateQueues находит на каждой полосе очереди остановившихся машин с промежутками
+// не больше QueueMaxGap, запоминает самую длинную очередь прогона и возвращает
+// самую длинную очередь шага
+func (s *Simulation) updateQueues() Queue {
+	var longest Queue
+	queues := make([]Queue, s.Lanes)
+	for _, car := range s.Cars {
+		q := &queues[car.Lane]
+		if !car.stopped {
+			q.Cars = 0
+			continue
+		}
+		tail := car.Position - car.length()
+		if q.Cars == 0 || q.Head-q.Length-car.Position > QueueMaxGap {
+			*q = Queue{Lane: car.Lane, Head: car.Position}
+		}
+		q.Cars++
+		q.Length = q.Head - tail
+		if q.Length > longest.Length {
+			longest = *q
+		}
+		if q.Length > s.longestQueue.Length {
+			s.longestQueue = *q
+			s.longestQueue.Time = s.Time
+		}
+	}
+	return longest
+}
+
+// percentiles возвращает среднее и перцентили значений по методу ближайшего ранга
+func percentiles(values []float64) Percentiles {
+	if len(values) == 0 {
+		return Percentiles{}
+	}
+	sort.Float64s(values)
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(values)))) - 1
+		return values[max(i, 0)]
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return Percentiles{Mean: sum / float64(len(values)), P50: rank(0.5), P90: rank(0.9), P99: rank(0.99)}
+}
+
+// tripStats вычисляет распределения показателей хранимых поездок
+func (s *Simulation) tripStats() TripStats {
+	n := len(s.trips)
+	travel, delay, stops, slow := make([]float64, n), make([]float64, n), make([]float64, n), make([]float64, n)
+	fuel, co2 := make([]float64, n), make([]float64, n)
+	for i, t := range s.trips {
+		travel[i], delay[i], stops[i], slow[i] = t.TravelTime, t.Delay, float64(t.Stops), t.SlowTime
+		fuel[i], co2[i] = t.Fuel, t.CO2
+	}
+	return TripStats{
+		Count:      n,
+		TravelTime: percentiles(travel),
+		Delay:      percentiles(delay),
+		Stops:      percentiles(stops),
+		SlowTime:   percentiles(slow),
+		Fuel:       percentiles(fuel),
+		CO2:        percentiles(co2),
+	}
+}
+
+// summary возвращает итоги прогона на текущий момент; вызывающий держит блокировку
+func (s *Simulation) summary() RunSummary {
+	summary := RunSummary{
+		Time:          s.Time,
+		CarsMade:      s.TotalCarsMade,
+		CarsCompleted: s.CarsCompleted,
+		BrakeEvents:   s.BrakeEvents,
+		LaneChanges:   s.LaneChanges,
+		Collisions:    s.Collisions,
+		IncidentDelay: s.IncidentDelay,
+		FuelUsed:      s.FuelUsed,
+		CO2Emitted:    s.CO2Emitted,
+		MaxQueue:      s.longestQueue,
+		Trips:         s.tripStats(),
+	}
+	if s.Time > 0 {
+		summary.Throughput = float64(s.CarsCompleted) / s.Time * 3600
+	}
+	return summary
+}
+
+// Summary возвращает итоги прогона на текущий момент; по завершении прогона
+// они также рассылаются событием "runComplete"
+func (s *Simulation) Summary() RunSummary {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.summary()
+}
+
+// WriteTripsCSV пишет хранимые поездки в w в формате CSV
+func (s *Simulation) WriteTripsCSV(w io.Writer) error {
+	s.mu.RLock()
+	trips := append([]Trip(nil), s.trips...)
+	s.mu.RUnlock()
+
+	format := func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) }
+	out := csv.NewWriter(w)
+	out.Write([]string{"car_id", "type", "driver", "start", "travel_time", "stops", "slow_time", "delay", "fuel", "co2"})
+	for _, t := range trips {
+		out.Write([]string{
+			strconv.Itoa(t.CarID),
+			t.Type,
+			t.Driver,
+			format(t.Start),
+			format(t.TravelTime),
+			strconv.Itoa(t.Stops),
+			format(t.SlowTime),
+			format(t.Delay),
+			strconv.FormatFloat(t.Fuel, 'f', 4, 64),
+			strconv.FormatFloat(t.CO2, 'f', 4, 64),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
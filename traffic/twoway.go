@@ -0,0 +1,303 @@
+package traffic
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	OncomingLane          = 1     // встречная полоса двусторонней дороги
+	DefaultOncomingDemand = 400.0 // авто/ч встречного потока по умолчанию
+	overtakeSpeedGain     = 2.8   // м/с (10 км/ч), на сколько лидер должен быть медленнее желаемой скорости
+	overtakeMargin        = 50.0  // метры запаса до встречной машины в конце обгона при его начале
+	overtakeHeadway       = 0.5   // секунды, интервал до обогнанной машины при возвращении
+	overtakeStep          = 0.25  // секунды, шаг оценки времени обгона
+	overtakeMaxTime       = 60.0  // секунды, обгон дольше считается невозможным
+)
+
+// Этапы обгона (Car.Overtaking)
+const (
+	OvertakePassing  = "passing"  // машина обгоняет по встречной полосе
+	OvertakeAborting = "aborting" // обгон прерван: машина тормозит, чтобы вернуться за обгоняемым
+)
+
+// twoWay сообщает, двусторонняя ли дорога
+func (s *Simulation) twoWay() bool {
+	return s.RoadType == RoadTwoWay
+}
+
+// oncomingFront возвращает координату передней части встречной машины в системе
+// отсчёта основного направления: встречные машины отсчитывают позицию от своего въезда
+func oncomingFront(car *Car) float64 {
+	return RoadLength - car.Position
+}
+
+// nearestOncoming возвращает ближайшую встречную машину, задняя часть которой
+// впереди точки position основного направления (nil - встречная полоса свободна)
+func (s *Simulation) nearestOncoming(position float64) *Car {
+	// Встречные машины упорядочены по убыванию своей позиции, то есть по возрастанию oncomingFront
+	i := sort.Search(len(s.Oncoming), func(i int) bool {
+		other := s.Oncoming[i]
+		return oncomingFront(other)+other.length() > position
+	})
+	if i == len(s.Oncoming) {
+		return nil
+	}
+	return s.Oncoming[i]
+}
+
+// spawnOncoming выпускает встречную машину с пуассоновскими интервалами со средним
+// 3600/OncomingDemand секунд, когда в начале встречной полосы достаточно места
+func (s *Simulation) spawnOncoming() {
+	if s.OncomingDemand <= 0 || s.Time-s.lastOncoming < s.oncomingGap {
+		return
+	}
+	if n := len(s.Oncoming); n > 0 {
+		if last := s.Oncoming[n-1]; last.Position-last.length() < spawnClearance {
+			return
+		}
+	}
+	car := s.newCar(OncomingLane, 0)
+	s.Oncoming = append(s.Oncoming, car)
+	s.lastOncoming = s.Time
+	s.oncomingGap = 3600 / s.OncomingDemand * s.rng.ExpFloat64()
+}
+
+// updateOncoming продвигает встречный поток: машины следуют за встречным лидером по
+// текущей модели следования и останавливаются перед разбитыми на встречной полосе
+// или прервавшими обгон машинами основного направления. Встречные водители не
+// уступают обгоняющим, а статистика, зоны, светофоры и перекрытия основного
+// направления к ним не относятся.
+func (s *Simulation) updateOncoming(dt float64) {
+	var wrecks []*Car
+	for _, car := range s.Cars {
+		if car.Lane == OncomingLane && (car.crashed() || car.Overtaking == OvertakeAborting) {
+			wrecks = append(wrecks, car)
+		}
+	}
+
+	// Ускорения считаются по положению на начало шага, как у основного потока
+	model := s.followingModel()
+	for i, car := range s.Oncoming {
+		if car.crashed() {
+			continue
+		}
+		var leader *Car
+		if i > 0 {
+			leader = s.Oncoming[i-1]
+		}
+		for _, wreck := range wrecks {
+			// Машина ставится неподвижным препятствием в системе отсчёта встречной полосы
+			if front := oncomingFront(car); wreck.Position < front {
+				if obstacle := stopObstacle(RoadLength - wreck.Position); leader == nil || obstacle.Position < leader.Position {
+					leader = obstacle
+				}
+			}
+		}
+		car.accel = model.Accel(car, leader, dt)
+	}
+
+	// Новый срез, а не фильтрация на месте: Snapshot отдаёт s.Oncoming без копирования
+	oncoming := make([]*Car, 0, len(s.Oncoming))
+	for _, car := range s.Oncoming {
+		if s.cleared(car) {
+			continue
+		}
+		if !car.crashed() {
+			car.Speed = math.Max(0, car.Speed+car.accel*dt)
+			car.State = "normal"
+			if car.accel < brakingAccel {
+				car.State = "braking"
+			}
+			car.Position += car.Speed * dt
+		}
+		if car.Position < RoadLength {
+			oncoming = append(oncoming, car)
+		}
+	}
+	s.Oncoming = oncoming
+}
+
+// detectHeadOn находит лобовые столкновения обгоняющих машин со встречными: авария
+// учитывается в Collisions и HeadOnCollisions и публикуется событием "collision".
+// Участники останавливаются на CrashClearance секунд, при нулевом времени расчистки
+// сразу убираются с дороги.
+func (s *Simulation) detectHeadOn() {
+	for _, car := range s.Cars {
+		if car.Lane != OncomingLane || car.crashed() {
+			continue
+		}
+		for _, other := range s.Oncoming {
+			front := oncomingFront(other)
+			if other.crashed() || front >= car.Position || front+other.length() <= car.Position-car.length() {
+				continue
+			}
+			s.Collisions++
+			s.HeadOnCollisions++
+			s.emit(Event{
+				Type:     "collision",
+				Time:     s.Time,
+				Cars:     []int{car.ID, other.ID},
+				Lane:     OncomingLane,
+				Position: car.Position,
+			})
+			car.Overtaking = ""
+			s.freezeCar(car)
+			s.freezeCar(other)
+			break
+		}
+	}
+}
+
+// considerOvertaking начинает, продолжает или завершает обгон по встречной полосе.
+// Машина начинает обгон, догнав лидера, который медленнее её желаемой скорости и
+// сам не стремится ехать быстрее, если перед лидером есть место, чтобы вернуться,
+// а ближайшая встречная машина дальше пути, который обе машины пройдут за время
+// обгона, с запасом overtakeMargin.
+func (s *Simulation) considerOvertaking(car *Car) {
+	if car.Lane == OncomingLane {
+		s.continueOvertaking(car)
+		return
+	}
+	if car.inPlatoon() || (car.lastLaneChange > 0 && s.Time-car.lastLaneChange < LaneChangeCooldown) {
+		return
+	}
+	ahead := s.obstacleAhead(car, 0)
+	// Перекрытия и стоп-линии (ID -1) не обгоняются, как и лидер, который медленнее
+	// лишь на время: разогнавшись, он затянет обгон
+	if ahead == nil || ahead.ID < 0 || math.Max(ahead.Speed, ahead.TargetSpeed) >= car.TargetSpeed-overtakeSpeedGain {
+		return
+	}
+	gap := gapTo(car, ahead)
+	if gap > 2*car.Speed+2*CarLength {
+		return
+	}
+	// Вернуться можно только на свободное место перед обгоняемым
+	back := s.overtakeReturnGap(car)
+	if ahead.Position+back+car.length() >= RoadLength {
+		return
+	}
+	if next := s.obstacleAhead(ahead, 0); next != nil && gapTo(ahead, next) < car.length()+2*back {
+		return
+	}
+	distance := gap + ahead.length() + back + car.length()
+	if !s.oncomingClear(car, s.overtakeTime(car, ahead, distance), overtakeMargin) || !s.gapAcceptable(car, OncomingLane) {
+		return
+	}
+
+	car.Lane = OncomingLane
+	car.Overtaking = OvertakePassing
+	car.overtakeTarget = ahead.ID
+	car.lastLaneChange = s.Time
+	s.LaneChanges++
+}
+
+// continueOvertaking ведёт машину на встречной полосе: возвращает её на свою полосу
+// перед обогнанной машиной или прерывает обгон, если встречная машина подъедет
+// раньше, чем обгон закончится. Прерывающая обгон машина тормозит (см. prepareCar)
+// и возвращается за обгоняемым, как только на своей полосе есть место.
+func (s *Simulation) continueOvertaking(car *Car) {
+	if car.Overtaking == OvertakeAborting {
+		if s.fitsInLane(car, 0) {
+			s.finishOvertaking(car)
+		}
+		return
+	}
+
+	var target *Car
+	if car.Overtaking == OvertakePassing {
+		target = s.carByID(car.overtakeTarget)
+	}
+	if target == nil || target.Lane != 0 || car.Position-car.length() > target.Position {
+		if s.gapAcceptable(car, 0) {
+			s.finishOvertaking(car)
+			return
+		}
+		// Обогнанная машина позади, но перед следующей не вернуться: теперь обгоняется она.
+		// Иначе машина продолжает обгон, пока не отъедет от обогнанной на безопасную дистанцию.
+		next := s.leaderIn(0, car.Position-car.length(), car)
+		if next != nil && gapTo(car, next) < s.overtakeReturnGap(car) {
+			target = next
+			car.overtakeTarget = next.ID
+		} else if target == nil || target.Lane != 0 {
+			return
+		}
+	}
+
+	remaining := target.Position + s.overtakeReturnGap(car) + car.length() - car.Position
+	// Без запаса overtakeMargin: иначе обгон, начатый на границе условия, сразу прерывается
+	if remaining > 0 && !s.oncomingClear(car, s.overtakeTime(car, target, remaining), 0) {
+		car.Overtaking = OvertakeAborting
+		s.OvertakesAborted++
+	}
+}
+
+// finishOvertaking возвращает машину со встречной полосы на свою
+func (s *Simulation) finishOvertaking(car *Car) {
+	if car.Overtaking == OvertakePassing {
+		s.Overtakes++
+	}
+	car.Lane = 0
+	car.Overtaking = ""
+	car.lastLaneChange = s.Time
+	s.LaneChanges++
+}
+
+// overtakeReturnGap возвращает дистанцию перед обогнанной машиной, на которую
+// обгоняющая возвращается на свою полосу
+func (s *Simulation) overtakeReturnGap(car *Car) float64 {
+	return CarLength + car.TargetSpeed*overtakeHeadway
+}
+
+// overtakeTime оценивает время, за которое машина, разгоняясь по модели следования
+// на свободной полосе до желаемой скорости, выиграет у обгоняемой машины target
+// расстояние distance (+Inf - не выиграет за overtakeMaxTime)
+func (s *Simulation) overtakeTime(car, target *Car, distance float64) float64 {
+	free := *car
+	gained := 0.0
+	for t := overtakeStep; t <= overtakeMaxTime; t += overtakeStep {
+		free.Speed = math.Min(free.TargetSpeed, free.Speed+s.freeAcceleration(&free)*overtakeStep)
+		gained += (free.Speed - target.Speed) * overtakeStep
+		if gained >= distance {
+			return t
+		}
+	}
+	return math.Inf(1)
+}
+
+// freeAcceleration возвращает ускорение машины на свободной дороге по модели следования.
+// Автомат nasch меняет скорость только в начале такта, на клетку за такт.
+func (s *Simulation) freeAcceleration(car *Car) float64 {
+	if _, ok := s.followingModel().(*naschModel); ok {
+		return s.NaSch.CellLength / (s.NaSch.Tick * s.NaSch.Tick)
+	}
+	return s.followingModel().Accel(car, nil, overtakeStep)
+}
+
+// oncomingClear сообщает, разъедется ли машина, обгоняющая duration секунд
+// с желаемой скоростью, с ближайшей встречной машиной с запасом margin метров.
+// Дальше конца дороги водитель не видит: если встречных машин нет, он ждёт
+// машину с той же желаемой скоростью из-за конца дороги.
+func (s *Simulation) oncomingClear(car *Car, duration, margin float64) bool {
+	distance, speed := RoadLength-car.Position, car.TargetSpeed
+	// Встречная машина рядом с обгоняющей тоже мешает: поиск от задней части машины
+	if other := s.nearestOncoming(car.Position - car.length()); other != nil {
+		distance, speed = oncomingFront(other)-car.Position, other.Speed
+	}
+	return distance > (car.TargetSpeed+speed)*duration+margin
+}
+
+// fitsInLane сообщает, помещается ли машина между соседями на полосе lane без
+// пересечения с ними; прерывающая обгон машина возвращается и в тесный промежуток
+func (s *Simulation) fitsInLane(car *Car, lane int) bool {
+	if s.laneBlockedBeside(car, lane) {
+		return false
+	}
+	if leader := s.obstacleAhead(car, lane); leader != nil && gapTo(car, leader) < CarLength {
+		return false
+	}
+	if follower := s.followerIn(lane, car.Position, car); follower != nil && gapTo(follower, car) < CarLength {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,464 @@
+package traffic
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ValidationError ошибка проверки команды: сообщение для каждого некорректного поля.
+// Имена полей совпадают с JSON-именами, для вложенных полей и элементов списков -
+// через точку и индекс: "onRamp.position", "demandProfile[1].spawnInterval".
+type ValidationError struct {
+	Fields map[string]string `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	fields := make([]string, 0, len(e.Fields))
+	for field, message := range e.Fields {
+		fields = append(fields, field+": "+message)
+	}
+	sort.Strings(fields)
+	return "invalid fields: " + strings.Join(fields, "; ")
+}
+
+// add запоминает ошибку поля field
+func (e *ValidationError) add(field, message string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string]string)
+	}
+	e.Fields[field] = message
+}
+
+// nonNegative отмечает поле с отрицательным значением
+func (e *ValidationError) nonNegative(field string, value float64) {
+	if value < 0 {
+		e.add(field, "must not be negative")
+	}
+}
+
+// insideRoad отмечает позицию за пределами дороги
+func (e *ValidationError) insideRoad(field string, position float64) {
+	if position <= 0 || position >= RoadLength {
+		e.add(field, fmt.Sprintf("must be between 0 and %g", RoadLength))
+	}
+}
+
+// nest добавляет ошибки вложенной проверки err с префиксом prefix: "config.spawnInterval",
+// "speedZones[0].limit"; ошибка не *ValidationError относится ко всему полю prefix
+func (e *ValidationError) nest(prefix string, err error) {
+	if err == nil {
+		return
+	}
+	nested, ok := err.(*ValidationError)
+	if !ok {
+		e.add(prefix, err.Error())
+		return
+	}
+	for field, message := range nested.Fields {
+		if strings.HasPrefix(field, "[") {
+			e.add(prefix+field, message)
+		} else {
+			e.add(prefix+"."+field, message)
+		}
+	}
+}
+
+// result возвращает nil, если ошибок нет
+func (e *ValidationError) result() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+// oneOf отмечает строковое поле, значение которого не пусто и не входит в allowed
+func (e *ValidationError) oneOf(field, value string, allowed ...string) {
+	if value == "" {
+		return
+	}
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+	e.add(field, fmt.Sprintf("must be one of %q", allowed))
+}
+
+// Validate проверяет конфигурацию; значения, которые UpdateConfig молча пропустил бы
+// или применил некорректно, возвращаются как *ValidationError
+func (c SimulationConfig) Validate() error {
+	var e ValidationError
+	if c.Demand < 0 {
+		e.add("demand", "must not be negative")
+	} else if c.Demand == 0 && c.SpawnInterval <= 0 {
+		e.add("spawnInterval", "must be positive")
+	}
+	e.nonNegative("minSpeed", c.MinSpeed)
+	e.nonNegative("maxSpeed", c.MaxSpeed)
+	e.nonNegative("maxCars", float64(c.MaxCars))
+	e.oneOf("spawnSpeedMode", c.SpawnSpeedMode, "random", "density")
+	e.oneOf("spawnDistribution", c.SpawnDistribution, SpawnFixed, SpawnPoisson, SpawnJitter)
+	e.oneOf("roadType", c.RoadType, RoadStraight, RoadRing, RoadTwoWay, RoadNetwork)
+	if c.Network != nil {
+		e.nest("network", c.Network.Validate())
+	} else if c.RoadType == RoadNetwork {
+		e.add("network", "required for roadType \"network\"")
+	}
+	if c.OncomingDemand != nil {
+		e.nonNegative("oncomingDemand", *c.OncomingDemand)
+	}
+	e.oneOf("weather", c.Weather, WeatherDry, WeatherRain, WeatherSnow, WeatherFog)
+	if c.Lanes < 0 || c.Lanes > MaxLanes {
+		e.add("lanes", fmt.Sprintf("must be between 1 and %d", MaxLanes))
+	}
+	if c.Model != "" && !knownModel(c.Model) {
+		e.add("model", fmt.Sprintf("must be one of %q", Models()))
+	}
+	for i, p := range c.DemandProfile {
+		e.nonNegative(fmt.Sprintf("demandProfile[%d].spawnInterval", i), p.SpawnInterval)
+		e.nonNegative(fmt.Sprintf("demandProfile[%d].demand", i), p.Demand)
+	}
+	for i, rate := range c.DemandBins {
+		e.nonNegative(fmt.Sprintf("demandBins[%d]", i), rate)
+	}
+	e.nonNegative("demandBinLength", c.DemandBinLength)
+	if c.DemandProfile != nil && c.DemandBins != nil {
+		e.add("demandBins", "must not be combined with demandProfile")
+	}
+	if c.IDM != nil {
+		e.nonNegative("idm.timeHeadway", c.IDM.TimeHeadway)
+		e.nonNegative("idm.maxAcceleration", c.IDM.MaxAcceleration)
+		e.nonNegative("idm.comfortDeceleration", c.IDM.ComfortDeceleration)
+		e.nonNegative("idm.minGap", c.IDM.MinGap)
+	}
+	if c.NaSch != nil {
+		e.nonNegative("nasch.cellLength", c.NaSch.CellLength)
+		e.nonNegative("nasch.tick", c.NaSch.Tick)
+		if c.NaSch.Slowdown > 1 {
+			e.add("nasch.slowdown", "must not exceed 1")
+		}
+	}
+	if c.OVM != nil {
+		e.nonNegative("ovm.sensitivity", c.OVM.Sensitivity)
+		e.nonNegative("ovm.distance", c.OVM.Distance)
+		e.nonNegative("ovm.width", c.OVM.Width)
+	}
+	e.oneOf("laneChange", c.LaneChange, LaneChangeSpace, LaneChangeMOBIL)
+	if c.MOBIL != nil {
+		e.nonNegative("mobil.safeDeceleration", c.MOBIL.SafeDeceleration)
+		e.nonNegative("mobil.threshold", c.MOBIL.Threshold)
+	}
+	if c.OnRamp != nil && c.OnRamp.SpawnInterval > 0 {
+		e.insideRoad("onRamp.position", c.OnRamp.Position)
+		e.nonNegative("onRamp.mergeSpeed", c.OnRamp.MergeSpeed)
+		if m := c.OnRamp.Metering; m != nil {
+			if m.TargetOccupancy < 0 || m.TargetOccupancy > 1 {
+				e.add("onRamp.metering.targetOccupancy", "must be between 0 and 1")
+			}
+			e.nonNegative("onRamp.metering.gain", m.Gain)
+			if m.Detector != 0 && (m.Detector <= c.OnRamp.Position || m.Detector >= RoadLength) {
+				e.add("onRamp.metering.detector", fmt.Sprintf("must be between onRamp.position and %g", RoadLength))
+			}
+			e.nonNegative("onRamp.metering.period", m.Period)
+			e.nonNegative("onRamp.metering.minRate", m.MinRate)
+			e.nonNegative("onRamp.metering.maxRate", m.MaxRate)
+			if m.MinRate > 0 && m.MaxRate > 0 && m.MinRate > m.MaxRate {
+				e.add("onRamp.metering.minRate", "must not exceed maxRate")
+			}
+			e.nonNegative("onRamp.metering.maxQueue", float64(m.MaxQueue))
+		}
+	}
+	if c.TruckPercentage != nil && (*c.TruckPercentage < 0 || *c.TruckPercentage > 100) {
+		e.add("truckPercentage", "must be between 0 and 100")
+	}
+	if c.BusPercentage != nil && (*c.BusPercentage < 0 || *c.BusPercentage > 100) {
+		e.add("busPercentage", "must be between 0 and 100")
+	} else if c.TruckPercentage != nil && c.BusPercentage != nil && *c.TruckPercentage+*c.BusPercentage > 100 {
+		e.add("busPercentage", "truckPercentage + busPercentage must not exceed 100")
+	}
+	if c.AggressiveDrivers != nil && (*c.AggressiveDrivers < 0 || *c.AggressiveDrivers > 100) {
+		e.add("aggressiveDrivers", "must be between 0 and 100")
+	}
+	if c.CautiousDrivers != nil && (*c.CautiousDrivers < 0 || *c.CautiousDrivers > 100) {
+		e.add("cautiousDrivers", "must be between 0 and 100")
+	} else if c.AggressiveDrivers != nil && c.CautiousDrivers != nil && *c.AggressiveDrivers+*c.CautiousDrivers > 100 {
+		e.add("cautiousDrivers", "aggressiveDrivers + cautiousDrivers must not exceed 100")
+	}
+	if c.ReactionSpread != nil {
+		e.nonNegative("reactionSpread", *c.ReactionSpread)
+	}
+	if c.AVShare != nil && (*c.AVShare < 0 || *c.AVShare > 100) {
+		e.add("avShare", "must be between 0 and 100")
+	}
+	if c.CrashClearance != nil {
+		e.nonNegative("crashClearance", *c.CrashClearance)
+	}
+	if c.TimeStep != nil && (*c.TimeStep < MinTimeStep || *c.TimeStep > MaxTimeStep) {
+		e.add("dt", fmt.Sprintf("must be between %g and %g", MinTimeStep, MaxTimeStep))
+	}
+	if c.HistoryWindow != nil && (*c.HistoryWindow < 0 || *c.HistoryWindow > MaxHistoryWindow) {
+		e.add("historyWindow", fmt.Sprintf("must be between 0 and %g", MaxHistoryWindow))
+	}
+	if c.WaveThreshold != nil {
+		e.nonNegative("waveThreshold", *c.WaveThreshold)
+	}
+	return e.result()
+}
+
+// Validate проверяет параметры физики
+func (c PhysicsConfig) Validate() error {
+	var e ValidationError
+	e.nonNegative("reactionTime", c.ReactionTime)
+	e.nonNegative("safetyMultiplier", c.SafetyMultiplier)
+	e.nonNegative("brakeDeceleration", c.BrakeDeceleration)
+	e.nonNegative("acceleration", c.Acceleration)
+	if c.HysteresisBand != nil && (*c.HysteresisBand < 0 || *c.HysteresisBand >= 1) {
+		e.add("hysteresisBand", "must be between 0 and 1")
+	}
+	return e.result()
+}
+
+// Validate проверяет параметры перекрытия
+func (c BlockageConfig) Validate() error {
+	var e ValidationError
+	if c.Position < 0 || c.Position >= RoadLength {
+		e.add("position", fmt.Sprintf("must be between 0 and %g", RoadLength))
+	}
+	e.nonNegative("span", c.Span)
+	if c.Duration <= 0 {
+		e.add("duration", "must be positive")
+	}
+	for i, lane := range c.Lanes {
+		if lane < 0 || lane >= MaxLanes {
+			e.add(fmt.Sprintf("lanes[%d]", i), fmt.Sprintf("must be between 0 and %d", MaxLanes-1))
+		}
+	}
+	return e.result()
+}
+
+// Validate проверяет параметры инцидента
+func (c IncidentConfig) Validate() error {
+	var e ValidationError
+	e.oneOf("type", c.Type, IncidentStalled, IncidentClosure, IncidentShoulder)
+	if c.Position < 0 || c.Position >= RoadLength {
+		e.add("position", fmt.Sprintf("must be between 0 and %g", RoadLength))
+	}
+	if c.Duration <= 0 {
+		e.add("duration", "must be positive")
+	}
+	if c.Lane < 0 || c.Lane >= MaxLanes {
+		e.add("lane", fmt.Sprintf("must be between 0 and %d", MaxLanes-1))
+	}
+	e.oneOf("vehicle", c.Vehicle, VehicleCar, VehicleTruck, VehicleBus)
+	for i, lane := range c.Lanes {
+		if lane < 0 || lane >= MaxLanes {
+			e.add(fmt.Sprintf("lanes[%d]", i), fmt.Sprintf("must be between 0 and %d", MaxLanes-1))
+		}
+	}
+	e.nonNegative("span", c.Span)
+	if c.Rubbernecking < 0 || c.Rubbernecking >= 1 {
+		e.add("rubbernecking", "must be between 0 and 1")
+	}
+	return e.result()
+}
+
+// Validate проверяет параметры добавления машины
+func (c CarConfig) Validate() error {
+	var e ValidationError
+	if c.Position < 0 || c.Position >= RoadLength {
+		e.add("position", fmt.Sprintf("must be between 0 and %g", RoadLength))
+	}
+	if c.Lane < 0 || c.Lane >= MaxLanes {
+		e.add("lane", fmt.Sprintf("must be between 0 and %d", MaxLanes-1))
+	}
+	e.nonNegative("speed", c.Speed)
+	e.nonNegative("targetSpeed", c.TargetSpeed)
+	e.oneOf("type", c.Type, VehicleCar, VehicleTruck, VehicleBus)
+	e.oneOf("driver", c.Driver, DriverNormal, DriverAggressive, DriverCautious, DriverAutomated)
+	return e.result()
+}
+
+// Validate проверяет параметры выпуска спецмашины
+func (c EmergencyConfig) Validate() error {
+	var e ValidationError
+	if c.Position < 0 || c.Position >= RoadLength {
+		e.add("position", fmt.Sprintf("must be between 0 and %g", RoadLength))
+	}
+	e.nonNegative("speed", c.Speed)
+	return e.result()
+}
+
+// Validate проверяет параметры управления машиной
+func (c CarControl) Validate() error {
+	var e ValidationError
+	if c.TargetSpeed != nil && *c.TargetSpeed <= 0 {
+		e.add("targetSpeed", "must be positive")
+	}
+	e.nonNegative("brake", c.Brake)
+	if c.TargetSpeed == nil && c.Brake == 0 {
+		e.add("targetSpeed", "targetSpeed or brake is required")
+	}
+	return e.result()
+}
+
+// Validate проверяет положение педалей машины игрока
+func (p Pedals) Validate() error {
+	var e ValidationError
+	if p.Throttle < 0 || p.Throttle > 1 {
+		e.add("throttle", "must be between 0 and 1")
+	}
+	if p.Brake < 0 || p.Brake > 1 {
+		e.add("brake", "must be between 0 and 1")
+	}
+	return e.result()
+}
+
+// Validate проверяет параметры размещения детекторов
+func (c DetectorConfig) Validate() error {
+	var e ValidationError
+	for i, p := range c.Positions {
+		e.insideRoad(fmt.Sprintf("positions[%d]", i), p)
+	}
+	e.nonNegative("spacing", c.Spacing)
+	e.nonNegative("interval", c.Interval)
+	return e.result()
+}
+
+// ValidateCameras проверяет список камер контроля скорости для SetCameras
+func ValidateCameras(cameras []SpeedCamera) error {
+	var e ValidationError
+	for i, c := range cameras {
+		e.insideRoad(fmt.Sprintf("[%d].position", i), c.Position)
+		if c.Limit <= 0 {
+			e.add(fmt.Sprintf("[%d].limit", i), "must be positive")
+		}
+		e.nonNegative(fmt.Sprintf("[%d].margin", i), c.Margin)
+	}
+	return e.result()
+}
+
+// ValidateCrosswalks проверяет список пешеходных переходов для SetCrosswalks
+func ValidateCrosswalks(crosswalks []Crosswalk) error {
+	var e ValidationError
+	for i, c := range crosswalks {
+		e.insideRoad(fmt.Sprintf("[%d].position", i), c.Position)
+		e.nonNegative(fmt.Sprintf("[%d].rate", i), c.Rate)
+		e.nonNegative(fmt.Sprintf("[%d].crossingTime", i), c.CrossingTime)
+	}
+	return e.result()
+}
+
+// ValidateSegments проверяет список участков для SetSegments
+func ValidateSegments(segments []Segment) error {
+	var e ValidationError
+	for i, seg := range segments {
+		if seg.End <= seg.Start || seg.End <= 0 || seg.Start >= RoadLength {
+			e.add(fmt.Sprintf("[%d]", i), "end must be greater than start and the segment must overlap the road")
+		}
+	}
+	return e.result()
+}
+
+// ValidateSpeedZones проверяет список зон ограничения скорости для SetSpeedZones
+func ValidateSpeedZones(zones []SpeedZone) error {
+	var e ValidationError
+	for i, zone := range zones {
+		if zone.End <= zone.Start || zone.End <= 0 || zone.Start >= RoadLength {
+			e.add(fmt.Sprintf("[%d]", i), "end must be greater than start and the zone must overlap the road")
+		}
+		if zone.Limit <= 0 {
+			e.add(fmt.Sprintf("[%d].limit", i), "must be positive")
+		}
+	}
+	return e.result()
+}
+
+// ValidateGrades проверяет список участков с уклоном для SetGrades: уклон
+// в пределах ±MaxGrade, участки не пересекаются
+func ValidateGrades(grades []GradeSegment) error {
+	var e ValidationError
+	for i, g := range grades {
+		if g.End <= g.Start || g.End <= 0 || g.Start >= RoadLength {
+			e.add(fmt.Sprintf("[%d]", i), "end must be greater than start and the segment must overlap the road")
+		}
+		if math.Abs(g.Grade) > MaxGrade {
+			e.add(fmt.Sprintf("[%d].grade", i), fmt.Sprintf("must be between %g and %g", -MaxGrade, MaxGrade))
+		}
+		for j := range i {
+			if g.Start < grades[j].End && grades[j].Start < g.End {
+				e.add(fmt.Sprintf("[%d]", i), fmt.Sprintf("must not overlap segment %d", j))
+			}
+		}
+	}
+	return e.result()
+}
+
+// Validate проверяет параметры регулятора переменного ограничения скорости
+func (c VSLConfig) Validate() error {
+	var e ValidationError
+	e.nonNegative("period", c.Period)
+	e.nonNegative("congestionSpeed", c.CongestionSpeed)
+	if c.CongestionOccupancy < 0 || c.CongestionOccupancy > 1 {
+		e.add("congestionOccupancy", "must be between 0 and 1")
+	}
+	e.nonNegative("step", c.Step)
+	e.nonNegative("minLimit", c.MinLimit)
+	e.nonNegative("maxLimit", c.MaxLimit)
+	if d := c.withDefaults(); d.MinLimit > d.MaxLimit {
+		e.add("minLimit", "must not exceed maxLimit")
+	}
+	for i, seg := range c.Segments {
+		if seg.End <= seg.Start || seg.End <= 0 || seg.Start >= RoadLength {
+			e.add(fmt.Sprintf("segments[%d]", i), "end must be greater than start and the segment must overlap the road")
+		}
+		e.insideRoad(fmt.Sprintf("segments[%d].detector", i), seg.Detector)
+	}
+	return e.result()
+}
+
+// Validate проверяет маршрут автобусов: остановки на дороге, неотрицательные интервалы и стоянки
+func (c BusConfig) Validate() error {
+	var e ValidationError
+	e.nonNegative("headway", c.Headway)
+	e.nonNegative("offset", c.Offset)
+	for i, stop := range c.Stops {
+		e.insideRoad(fmt.Sprintf("stops[%d].position", i), stop.Position)
+		e.nonNegative(fmt.Sprintf("stops[%d].dwell", i), stop.Dwell)
+	}
+	return e.result()
+}
+
+// Validate проверяет участок работ; участок с end не больше start убирает зону и не проверяется
+func (z WorkZone) Validate() error {
+	var e ValidationError
+	if z.End <= z.Start {
+		return nil
+	}
+	if z.Lane < 0 || z.Lane >= MaxLanes {
+		e.add("lane", fmt.Sprintf("must be between 0 and %d", MaxLanes-1))
+	}
+	e.insideRoad("start", z.Start)
+	if z.End > RoadLength {
+		e.add("end", fmt.Sprintf("must not exceed %g", RoadLength))
+	}
+	e.nonNegative("taper", z.Taper)
+	e.oneOf("merge", z.Merge, MergeEarly, MergeZipper)
+	return e.result()
+}
+
+// ValidateTrafficLights проверяет список светофоров для SetTrafficLights
+func ValidateTrafficLights(lights []TrafficLight) error {
+	var e ValidationError
+	for i, l := range lights {
+		e.insideRoad(fmt.Sprintf("[%d].position", i), l.Position)
+		e.nonNegative(fmt.Sprintf("[%d].green", i), l.Green)
+		e.nonNegative(fmt.Sprintf("[%d].yellow", i), l.Yellow)
+		e.nonNegative(fmt.Sprintf("[%d].red", i), l.Red)
+		if l.cycle() <= 0 {
+			e.add(fmt.Sprintf("[%d]", i), "cycle must be positive")
+		}
+	}
+	return e.result()
+}
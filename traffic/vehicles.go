@@ -0,0 +1,111 @@
+package traffic
+
+import "math"
+
+// Типы транспортных средств
+const (
+	VehicleCar   = "car"
+	VehicleTruck = "truck"
+	VehicleBus   = "bus"
+	// VehicleEmergency спецмашина, см. DispatchEmergency; среди новых машин не появляется
+	VehicleEmergency = "emergency"
+)
+
+// VehicleType параметры типа транспортного средства. Нулевые значения динамики
+// и скоростей означают параметры симуляции (Acceleration, BrakeDeceleration,
+// MinSpeed/MaxSpeed), поэтому легковые машины следуют настройкам физики;
+// нулевая удельная мощность не ограничивает разгон на подъёме.
+type VehicleType struct {
+	Length            float64 `json:"length"`            // метры
+	MaxAcceleration   float64 `json:"maxAcceleration"`   // м/с²
+	BrakeDeceleration float64 `json:"brakeDeceleration"` // м/с²
+	MinSpeed          float64 `json:"minSpeed"`          // км/ч, нижняя граница желаемой скорости
+	MaxSpeed          float64 `json:"maxSpeed"`          // км/ч, верхняя граница желаемой скорости
+	PowerToWeight     float64 `json:"powerToWeight"`     // Вт/кг, удельная мощность на подъёме
+}
+
+// VehicleTypes параметры известных типов транспортных средств
+var VehicleTypes = map[string]VehicleType{
+	VehicleCar:       {Length: CarLength},
+	VehicleTruck:     {Length: 12, MaxAcceleration: 0.8, BrakeDeceleration: 4.0, MinSpeed: 70, MaxSpeed: 90, PowerToWeight: 6},
+	VehicleBus:       {Length: 12, MaxAcceleration: 1.0, BrakeDeceleration: 4.5, MinSpeed: 60, MaxSpeed: 90, PowerToWeight: 10},
+	VehicleEmergency: {Length: 6.5, MaxAcceleration: 2.5, MinSpeed: 110, MaxSpeed: 130},
+}
+
+// length возвращает длину автомобиля; у виртуальных препятствий она равна CarLength
+func (c *Car) length() float64 {
+	if c.Length > 0 {
+		return c.Length
+	}
+	return CarLength
+}
+
+// gapTo возвращает свободное расстояние от передней части car до задней части leader
+func gapTo(car, leader *Car) float64 {
+	return leader.Position - leader.length() - car.Position
+}
+
+// vehicleType возвращает параметры типа автомобиля (легковой для неизвестного типа)
+func vehicleType(car *Car) VehicleType {
+	if vt, ok := VehicleTypes[car.Type]; ok {
+		return vt
+	}
+	return VehicleTypes[VehicleCar]
+}
+
+// carAcceleration возвращает максимальное ускорение автомобиля с учётом его типа и водителя
+func (s *Simulation) carAcceleration(car *Car) float64 {
+	accel := s.Acceleration
+	if vt := vehicleType(car); vt.MaxAcceleration > 0 {
+		accel = vt.MaxAcceleration
+	}
+	return accel * driverProfile(car).Acceleration
+}
+
+// carBraking возвращает замедление при торможении автомобиля с учётом его типа и погоды
+func (s *Simulation) carBraking(car *Car) float64 {
+	braking := s.BrakeDeceleration
+	if vt := vehicleType(car); vt.BrakeDeceleration > 0 {
+		braking = vt.BrakeDeceleration
+	}
+	return braking * s.weatherEffect().Braking
+}
+
+// spawnVehicleType выбирает тип новой машины по долям TruckPercentage и BusPercentage.
+// Генератор не используется, если доли нулевые, чтобы не менять поток однородных прогонов.
+func (s *Simulation) spawnVehicleType() string {
+	if s.TruckPercentage <= 0 && s.BusPercentage <= 0 {
+		return VehicleCar
+	}
+	roll := s.rng.Float64() * 100
+	switch {
+	case roll < s.TruckPercentage:
+		return VehicleTruck
+	case roll < s.TruckPercentage+s.BusPercentage:
+		return VehicleBus
+	default:
+		return VehicleCar
+	}
+}
+
+// vehicleSpeed возвращает желаемую скорость новой машины типа vehicle: у легковых
+// по настройкам симуляции, у остальных - из диапазона типа
+func (s *Simulation) vehicleSpeed(vehicle string) float64 {
+	vt := VehicleTypes[vehicle]
+	if vt.MaxSpeed <= 0 {
+		return s.spawnSpeed()
+	}
+	return kmhToMs(vt.MinSpeed + s.rng.Float64()*(vt.MaxSpeed-vt.MinSpeed))
+}
+
+// maxVehicleLength возвращает длину самого длинного типа, который может появиться на дороге
+func (s *Simulation) maxVehicleLength() float64 {
+	length := CarLength
+	if s.TruckPercentage > 0 {
+		length = math.Max(length, VehicleTypes[VehicleTruck].Length)
+	}
+	if s.BusPercentage > 0 || s.Buses != nil {
+		length = math.Max(length, VehicleTypes[VehicleBus].Length)
+	}
+	return length
+}
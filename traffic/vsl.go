@@ -0,0 +1,146 @@
+package traffic
+
+import "math"
+
+// VSLConfig параметры регулятора переменного ограничения скорости (variable speed limit).
+// Нулевые параметры заменяются значениями по умолчанию, пустой список участков отключает регулятор.
+type VSLConfig struct {
+	Period              float64      `json:"period"`              // секунды между пересчётами ограничений (60)
+	CongestionSpeed     float64      `json:"congestionSpeed"`     // км/ч, средняя скорость на детекторе, ниже которой впереди затор (50)
+	CongestionOccupancy float64      `json:"congestionOccupancy"` // занятость детектора, выше которой впереди затор (0.25)
+	Step                float64      `json:"step"`                // км/ч, изменение ограничения за пересчёт (20)
+	MinLimit            float64      `json:"minLimit"`            // км/ч, нижняя граница ограничения (40)
+	MaxLimit            float64      `json:"maxLimit"`            // км/ч, ограничение без затора (120)
+	Segments            []VSLSegment `json:"segments"`            // управляемые участки
+}
+
+// VSLSegment участок, ограничение скорости на котором регулятор выбирает
+// по детектору ниже по потоку
+type VSLSegment struct {
+	Name      string  `json:"name"`
+	Start     float64 `json:"start"`     // метры
+	End       float64 `json:"end"`       // метры
+	Detector  float64 `json:"detector"`  // метры, положение детектора ниже по потоку
+	Limit     float64 `json:"limit"`     // км/ч, действующее ограничение
+	Congested bool    `json:"congested"` // детектор сообщил о заторе при последнем пересчёте
+}
+
+// withDefaults возвращает конфигурацию с заполненными значениями по умолчанию
+func (c VSLConfig) withDefaults() VSLConfig {
+	defaults := VSLConfig{Period: 60, CongestionSpeed: 50, CongestionOccupancy: 0.25, Step: 20, MinLimit: 40, MaxLimit: 120}
+	if c.Period <= 0 {
+		c.Period = defaults.Period
+	}
+	if c.CongestionSpeed <= 0 {
+		c.CongestionSpeed = defaults.CongestionSpeed
+	}
+	if c.CongestionOccupancy <= 0 {
+		c.CongestionOccupancy = defaults.CongestionOccupancy
+	}
+	if c.Step <= 0 {
+		c.Step = defaults.Step
+	}
+	if c.MinLimit <= 0 {
+		c.MinLimit = defaults.MinLimit
+	}
+	if c.MaxLimit <= 0 {
+		c.MaxLimit = defaults.MaxLimit
+	}
+	return c
+}
+
+// SetVSL включает регулятор переменного ограничения скорости; на позициях детекторов
+// участков, где детекторов ещё нет, они устанавливаются. Пустой список участков отключает регулятор.
+func (s *Simulation) SetVSL(config VSLConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(config.Segments) == 0 {
+		s.VSL = nil
+		return
+	}
+	config = config.withDefaults()
+	segments := make([]VSLSegment, 0, len(config.Segments))
+	for _, seg := range config.Segments {
+		seg.Start = math.Max(0, seg.Start)
+		seg.End = math.Min(RoadLength, seg.End)
+		if seg.End <= seg.Start {
+			continue
+		}
+		seg.Limit, seg.Congested = config.MaxLimit, false
+		segments = append(segments, seg)
+		s.ensureDetector(seg.Detector)
+	}
+	config.Segments = segments
+	s.VSL = &config
+	s.vslUpdated = s.Time
+}
+
+// detectorAt возвращает детектор в точке position (nil - нет)
+func (s *Simulation) detectorAt(position float64) *Detector {
+	for _, d := range s.Detectors {
+		if d.Position == position {
+			return d
+		}
+	}
+	return nil
+}
+
+// updateVSL раз в период пересчитывает ограничения: при заторе у детектора ограничение
+// участка снижается на Step до MinLimit, без затора - повышается до MaxLimit
+func (s *Simulation) updateVSL() {
+	if s.VSL == nil || s.Time-s.vslUpdated < s.VSL.Period {
+		return
+	}
+	s.vslUpdated = s.Time
+	vsl := s.VSL
+	for i := range vsl.Segments {
+		seg := &vsl.Segments[i]
+		d := s.detectorAt(seg.Detector)
+		if d == nil || len(d.Series) == 0 {
+			continue
+		}
+		last := d.Series[len(d.Series)-1]
+		seg.Congested = last.Occupancy > vsl.CongestionOccupancy ||
+			(last.Count > 0 && msToKmh(last.MeanSpeed) < vsl.CongestionSpeed)
+		if seg.Congested {
+			seg.Limit = math.Max(vsl.MinLimit, seg.Limit-vsl.Step)
+		} else {
+			seg.Limit = math.Min(vsl.MaxLimit, seg.Limit+vsl.Step)
+		}
+	}
+}
+
+// resetVSL возвращает ограничения участков к MaxLimit
+func (s *Simulation) resetVSL() {
+	s.vslUpdated = 0
+	if s.VSL == nil {
+		return
+	}
+	for i := range s.VSL.Segments {
+		s.VSL.Segments[i].Limit, s.VSL.Segments[i].Congested = s.VSL.MaxLimit, false
+	}
+}
+
+// vslState возвращает копию регулятора для рассылки клиентам
+func (s *Simulation) vslState() *VSLConfig {
+	if s.VSL == nil {
+		return nil
+	}
+	state := *s.VSL
+	state.Segments = append([]VSLSegment(nil), s.VSL.Segments...)
+	return &state
+}
+
+// cloneVSL возвращает копию регулятора с начальными ограничениями
+func (s *Simulation) cloneVSL() *VSLConfig {
+	if s.VSL == nil {
+		return nil
+	}
+	clone := *s.VSL
+	clone.Segments = append([]VSLSegment(nil), s.VSL.Segments...)
+	for i := range clone.Segments {
+		clone.Segments[i].Limit, clone.Segments[i].Congested = clone.MaxLimit, false
+	}
+	return &clone
+}
@@ -0,0 +1,164 @@
+package traffic
+
+const (
+	DefaultWaveThreshold = 30.0 // км/ч, скорость, ниже которой машина считается попавшей в волну
+	WaveMinCars          = 3    // машин в скоплении, начиная с которого оно считается волной
+	WaveMaxGap           = 50.0 // метры, наибольший промежуток между соседними машинами одной волны
+	WaveInterval         = 1.0  // секунды между обновлениями волн
+)
+
+// Wave волна «стоп-старт»: скопление медленных машин на полосе, отслеживаемое
+// между обновлениями под одним идентификатором
+type Wave struct {
+	ID        int     `json:"id"`
+	Lane      int     `json:"lane"`
+	Head      float64 `json:"head"`      // метры, передний (нижний по потоку) край скопления
+	Tail      float64 `json:"tail"`      // метры, задний край скопления
+	Cars      int     `json:"cars"`      // машин в волне
+	MeanSpeed float64 `json:"meanSpeed"` // км/ч, средняя скорость машин в волне
+	Born      float64 `json:"born"`      // время обнаружения, секунды симуляции
+	Travel    float64 `json:"travel"`    // метры, смещение середины волны с момента обнаружения
+	Speed     float64 `json:"speed"`     // км/ч, скорость распространения (отрицательная - против потока)
+}
+
+// waveCluster скопление медленных машин, найденное при очередном обновлении
+type waveCluster struct {
+	lane       int
+	head, tail float64
+	cars       int
+	speedSum   float64
+}
+
+// length возвращает протяжённость скопления
+func (c waveCluster) length(s *Simulation) float64 {
+	return s.aheadDistance(c.tail, c.head)
+}
+
+// center возвращает середину скопления
+func (c waveCluster) center(s *Simulation) float64 {
+	center := c.tail + c.length(s)/2
+	if s.ring() {
+		center = s.aheadDistance(0, center)
+	}
+	return center
+}
+
+// waveClusters находит на каждой полосе скопления подряд идущих машин медленнее
+// WaveThreshold с промежутками не больше WaveMaxGap; на кольце скопления,
+// сходящиеся через начало дороги, объединяются
+func (s *Simulation) waveClusters() []waveCluster {
+	perLane := make([][]waveCluster, s.Lanes)
+	open := make([]bool, s.Lanes)
+	for _, car := range s.Cars {
+		lane := car.Lane
+		if car.Speed >= s.WaveThreshold {
+			open[lane] = false
+			continue
+		}
+		clusters := perLane[lane]
+		if n := len(clusters); open[lane] && clusters[n-1].tail-car.Position <= WaveMaxGap {
+			c := &clusters[n-1]
+			c.tail = car.Position - car.length()
+			c.cars++
+			c.speedSum += car.Speed
+			continue
+		}
+		perLane[lane] = append(clusters, waveCluster{
+			lane: lane, head: car.Position, tail: car.Position - car.length(), cars: 1, speedSum: car.Speed,
+		})
+		open[lane] = true
+	}
+
+	var result []waveCluster
+	for _, clusters := range perLane {
+		// s.Cars упорядочены от конца дороги: первое скопление ближе к концу, последнее - к началу
+		if n := len(clusters); s.ring() && n > 1 {
+			front, back := clusters[0], clusters[n-1]
+			if s.aheadDistance(front.head, back.tail) <= WaveMaxGap {
+				clusters[n-1] = waveCluster{
+					lane: back.lane, head: back.head, tail: front.tail,
+					cars: front.cars + back.cars, speedSum: front.speedSum + back.speedSum,
+				}
+				clusters = clusters[1:]
+			}
+		}
+		for _, c := range clusters {
+			if c.cars >= WaveMinCars {
+				result = append(result, c)
+			}
+		}
+	}
+	return result
+}
+
+// wavesOverlap сообщает, пересекаются ли скопление и волна с допуском WaveMaxGap
+func (s *Simulation) wavesOverlap(c waveCluster, w *Wave) bool {
+	d := s.shift(c.tail, w.Tail)
+	return d <= c.length(s)+WaveMaxGap && d >= -s.aheadDistance(w.Tail, w.Head)-WaveMaxGap
+}
+
+// shift возвращает смещение от точки from до точки to; на кольце - кратчайшее
+// с учётом направления (отрицательное - против движения)
+func (s *Simulation) shift(from, to float64) float64 {
+	d := s.aheadDistance(from, to)
+	if s.ring() && d > RoadLength/2 {
+		d -= RoadLength
+	}
+	return d
+}
+
+// updateWaves раз в WaveInterval находит скопления медленных машин и сопоставляет их
+// с волнами прошлого обновления: скопление наследует идентификатор самой старой
+// пересекающейся с ним волны, ещё не занятой другим скоплением, иначе получает новый.
+// Скорость распространения - среднее смещение середины волны с момента обнаружения.
+func (s *Simulation) updateWaves() {
+	if s.WaveThreshold <= 0 || s.Time-s.wavesUpdated < WaveInterval {
+		return
+	}
+	s.wavesUpdated = s.Time
+
+	claimed := make(map[int]bool)
+	waves := make([]*Wave, 0, len(s.Waves))
+	for _, c := range s.waveClusters() {
+		var wave *Wave
+		for _, w := range s.Waves {
+			if w.Lane == c.lane && !claimed[w.ID] && s.wavesOverlap(c, w) && (wave == nil || w.ID < wave.ID) {
+				wave = w
+			}
+		}
+		if wave == nil {
+			wave = &Wave{ID: s.nextWaveID, Lane: c.lane, Born: s.Time}
+			s.nextWaveID++
+			s.WavesDetected++
+			s.emitExport(Event{Type: "wave", Time: s.Time, Lane: c.lane, Position: c.head, Wave: wave.ID})
+		} else {
+			previous := waveCluster{head: wave.Head, tail: wave.Tail}
+			wave.Travel += s.shift(previous.center(s), c.center(s))
+		}
+		claimed[wave.ID] = true
+		wave.Head, wave.Tail, wave.Cars = c.head, c.tail, c.cars
+		wave.MeanSpeed = msToKmh(c.speedSum / float64(c.cars))
+		if age := s.Time - wave.Born; age > 0 {
+			wave.Speed = msToKmh(wave.Travel / age)
+		}
+		waves = append(waves, wave)
+	}
+	s.Waves = waves
+}
+
+// resetWaves забывает обнаруженные волны
+func (s *Simulation) resetWaves() {
+	s.Waves = make([]*Wave, 0)
+	s.WavesDetected = 0
+	s.nextWaveID = 0
+	s.wavesUpdated = 0
+}
+
+// waveStates возвращает копии текущих волн для рассылки клиентам
+func (s *Simulation) waveStates() []Wave {
+	states := make([]Wave, len(s.Waves))
+	for i, w := range s.Waves {
+		states[i] = *w
+	}
+	return states
+}
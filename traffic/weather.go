@@ -0,0 +1,64 @@
+package traffic
+
+// Погодные условия
+const (
+	WeatherDry  = "dry"
+	WeatherRain = "rain"
+	WeatherSnow = "snow"
+	WeatherFog  = "fog"
+)
+
+// WeatherEffect множители параметров движения при погодных условиях
+type WeatherEffect struct {
+	Braking float64 `json:"braking"` // доступное замедление: торможение, комфортное и предельное замедление
+	Headway float64 `json:"headway"` // безопасная дистанция и желаемый интервал до лидера
+	Speed   float64 `json:"speed"`   // желаемая скорость
+}
+
+// WeatherEffects влияние известных погодных условий: мокрое и заснеженное покрытие
+// хуже сцепляется с шинами, в тумане водители сбавляют скорость и держат дистанцию
+var WeatherEffects = map[string]WeatherEffect{
+	WeatherDry:  {Braking: 1, Headway: 1, Speed: 1},
+	WeatherRain: {Braking: 0.7, Headway: 1.3, Speed: 0.85},
+	WeatherSnow: {Braking: 0.4, Headway: 1.8, Speed: 0.6},
+	WeatherFog:  {Braking: 1, Headway: 1.5, Speed: 0.7},
+}
+
+// weatherEffect возвращает влияние текущей погоды (сухо для неизвестной)
+func (s *Simulation) weatherEffect() WeatherEffect {
+	if effect, ok := WeatherEffects[s.Weather]; ok {
+		return effect
+	}
+	return WeatherEffects[WeatherDry]
+}
+
+// maxDeceleration возвращает физический предел торможения при текущей погоде
+func (s *Simulation) maxDeceleration() float64 {
+	return idmMaxDeceleration * s.weatherEffect().Braking
+}
+
+// SetWeather меняет погоду; машины на дороге сразу подстраивают скорость и дистанцию
+func (s *Simulation) SetWeather(weather string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.setWeather(weather)
+}
+
+// setWeather меняет погоду на известную и сообщает о смене событием "weather"
+func (s *Simulation) setWeather(weather string) {
+	if _, ok := WeatherEffects[weather]; !ok || weather == s.Weather {
+		return
+	}
+	s.Weather = weather
+	s.emit(Event{Type: "weather", Time: s.Time, Weather: weather})
+}
+
+// weatherIDM возвращает параметры IDM с учётом погоды
+func (s *Simulation) weatherIDM() IDMParams {
+	p, effect := s.IDM, s.weatherEffect()
+	p.TimeHeadway *= effect.Headway
+	p.MinGap *= effect.Headway
+	p.ComfortDeceleration *= effect.Braking
+	return p
+}
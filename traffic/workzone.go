@@ -0,0 +1,205 @@
+package traffic
+
+import "math"
+
+// Способы слияния перед участком работ
+const (
+	MergeEarly  = "early"  // водители покидают закрываемую полосу заранее
+	MergeZipper = "zipper" // водители доезжают до сужения и вливаются поочерёдно
+)
+
+const (
+	DefaultTaperLength = 150.0  // метры сужения перед закрытым участком по умолчанию
+	EarlyMergeDistance = 500.0  // метры перед сужением, с которых при раннем слиянии водители покидают закрываемую полосу
+	workZoneApproach   = 1000.0 // метры перед сужением, задержка на которых относится к участку работ
+	forcedMergeReach   = 15.0   // метры до начала закрытия, в пределах которых вставшую машину пропускают и при раннем слиянии
+	courtesyGap        = 1.0    // метры, наименьший промежуток до пропускающей машины сзади
+)
+
+// WorkZone участок дорожных работ: полоса Lane закрыта от Start до End, перед ней
+// сужение длиной Taper. Машины закрываемой полосы перестраиваются на соседнюю
+// открытую: при раннем слиянии - как только позволяет промежуток, начиная за
+// EarlyMergeDistance до сужения, при поочерёдном - в пределах сужения, и машины
+// соседней полосы пропускают их по одной. Остальные поля - показатели с начала прогона.
+type WorkZone struct {
+	Lane          int     `json:"lane"`          // закрытая полоса
+	Start         float64 `json:"start"`         // метры, начало закрытого участка (конец сужения)
+	End           float64 `json:"end"`           // метры, конец закрытого участка
+	Taper         float64 `json:"taper"`         // метры сужения (0 - DefaultTaperLength)
+	Merge         string  `json:"merge"`         // "early" или "zipper" (пусто - early)
+	Merges        int     `json:"merges"`        // перестроений с закрываемой полосы
+	ForcedMerges  int     `json:"forcedMerges"`  // из них - с места у начала закрытия
+	MergeDistance float64 `json:"mergeDistance"` // метры, средняя дистанция перестроения до начала закрытия
+	Passed        int     `json:"passed"`        // машин проехали участок
+	Delay         float64 `json:"delay"`         // авто·с, задержка на подходе к участку и на нём
+}
+
+// SetWorkZone закрывает полосу участком работ (предыдущий участок заменяется,
+// показатели начинаются заново). Участок с end не больше start убирает зону.
+func (s *Simulation) SetWorkZone(zone WorkZone) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if zone.End <= zone.Start {
+		s.WorkZone = nil
+		return
+	}
+	if zone.Taper <= 0 {
+		zone.Taper = DefaultTaperLength
+	}
+	if zone.Merge == "" {
+		zone.Merge = MergeEarly
+	}
+	s.WorkZone = &WorkZone{Lane: zone.Lane, Start: zone.Start, End: zone.End, Taper: zone.Taper, Merge: zone.Merge}
+}
+
+// resetWorkZone обнуляет показатели участка работ
+func (s *Simulation) resetWorkZone() {
+	if z := s.WorkZone; z != nil {
+		*z = WorkZone{Lane: z.Lane, Start: z.Start, End: z.End, Taper: z.Taper, Merge: z.Merge}
+	}
+}
+
+// activeWorkZone возвращает участок работ, если закрытая полоса есть на дороге и
+// рядом с ней остаётся открытая; на двусторонней дороге и в сети участка нет
+func (s *Simulation) activeWorkZone() *WorkZone {
+	z := s.WorkZone
+	if z == nil || s.Lanes < 2 || z.Lane >= s.Lanes || s.twoWay() || s.networkMode() {
+		return nil
+	}
+	return z
+}
+
+// mergeDistance возвращает метры до начала закрытия, с которых машины покидают
+// закрываемую полосу
+func (z *WorkZone) mergeDistance() float64 {
+	if z.Merge == MergeZipper {
+		return z.Taper
+	}
+	return z.Taper + EarlyMergeDistance
+}
+
+// workZoneAhead возвращает начало закрытия впереди машины на закрытой полосе lane
+func (s *Simulation) workZoneAhead(car *Car, lane int) (float64, bool) {
+	z := s.activeWorkZone()
+	if z == nil || lane != z.Lane || s.aheadDistance(car.Position, z.Start) < 0 {
+		return 0, false
+	}
+	return z.Start, true
+}
+
+// laneClosedBeside сообщает, закрыта ли полоса lane рядом с машиной: на участке
+// работ и перед ним, где с неё уже перестраиваются
+func (s *Simulation) laneClosedBeside(car *Car, lane int) bool {
+	z := s.activeWorkZone()
+	if z == nil || lane != z.Lane {
+		return false
+	}
+	return car.Position+car.length() > z.Start-z.mergeDistance() && car.Position < z.End+car.length()
+}
+
+// leaveClosedLane перестраивает машину закрываемой полосы на соседнюю открытую
+// полосу с большим свободным местом, когда машина подъехала к месту слияния и
+// промежуток безопасен; машина, не успевшая остановиться перед закрытием, покидает
+// полосу на самом участке. Сообщает, должна ли машина покинуть полосу: тогда других
+// перестроений у неё на этом шаге нет.
+func (s *Simulation) leaveClosedLane(car *Car) bool {
+	z := s.activeWorkZone()
+	if z == nil || car.Lane != z.Lane {
+		return false
+	}
+	distance := s.aheadDistance(car.Position, z.Start)
+	if distance < z.Start-z.End || distance > z.mergeDistance() {
+		return false
+	}
+	best, bestSpace := -1, -1.0
+	for _, lane := range []int{z.Lane - 1, z.Lane + 1} {
+		if lane < 0 || lane >= s.Lanes || !(s.gapAcceptable(car, lane) || s.courtesyGapAcceptable(car, lane)) {
+			continue
+		}
+		if space := s.freeSpace(car, lane); space > bestSpace {
+			best, bestSpace = lane, space
+		}
+	}
+	if best < 0 {
+		return true
+	}
+	if car.Speed < kmhToMs(StopSpeed) {
+		z.ForcedMerges++
+	}
+	z.Merges++
+	z.MergeDistance += (distance - z.MergeDistance) / float64(z.Merges)
+	car.Lane = best
+	car.lastLaneChange = s.Time
+	s.LaneChanges++
+	return true
+}
+
+// courtesyGapAcceptable проверяет промежуток на полосе lane, который открывает машине
+// закрываемой полосы пропускающая её машина сзади (см. mergeCourtesy): та уже
+// тормозит перед ней, поэтому достаточно, чтобы машины не перекрывались; до лидера
+// нужна обычная безопасная дистанция
+func (s *Simulation) courtesyGapAcceptable(car *Car, lane int) bool {
+	if s.laneBlockedBeside(car, lane) {
+		return false
+	}
+	follower := s.followerIn(lane, car.Position, car)
+	if follower == nil {
+		return false
+	}
+	if yielded := s.mergeCourtesy(follower, s.obstacleAhead(follower, lane)); yielded == nil || yielded.ID != car.ID {
+		return false
+	}
+	if leader := s.obstacleAhead(car, lane); leader != nil && gapTo(car, leader) < getSafeDistance(car.Speed-leader.Speed, s.SafetyMultiplier) {
+		return false
+	}
+	return true
+}
+
+// mergeCourtesy возвращает машину закрываемой полосы, которую водитель соседней
+// полосы пропускает перед собой, если она ближе препятствия ahead: при поочерёдном
+// слиянии - ближайшую впереди в пределах сужения, при раннем - только вставшую у
+// начала закрытия (nil - пропускать некого). Водитель, вплотную подъехавший к ней,
+// спецмашина и машина игрока не пропускают.
+func (s *Simulation) mergeCourtesy(car, ahead *Car) *Car {
+	z := s.activeWorkZone()
+	if z == nil || math.Abs(float64(car.Lane-z.Lane)) != 1 || car.crashed() || car.Player || car.emergency() {
+		return nil
+	}
+	merging := s.leaderIn(z.Lane, car.Position, car)
+	if merging == nil || merging.crashed() || gapTo(car, merging) < courtesyGap || (ahead != nil && ahead.Position <= merging.Position) {
+		return nil
+	}
+	distance := s.aheadDistance(merging.Position, z.Start)
+	if distance < 0 || distance > z.Taper {
+		return nil
+	}
+	if z.Merge != MergeZipper && (distance > forcedMergeReach || merging.Speed >= kmhToMs(StopSpeed)) {
+		return nil
+	}
+	return merging
+}
+
+// trackWorkZone учитывает за шаг задержку машины на подходе к участку работ и на
+// нём и проезд участка машиной, сдвинувшейся из from
+func (s *Simulation) trackWorkZone(car *Car, from, dt float64) {
+	z := s.activeWorkZone()
+	if z == nil {
+		return
+	}
+	if from < z.End && car.Position >= z.End {
+		z.Passed++
+	}
+	if car.TargetSpeed > 0 && car.Position >= z.Start-z.Taper-workZoneApproach && car.Position < z.End {
+		z.Delay += dt * max(0, 1-car.Speed/car.TargetSpeed)
+	}
+}
+
+// workZoneState возвращает копию участка работ для рассылки (nil, если участка нет)
+func (s *Simulation) workZoneState() *WorkZone {
+	if s.WorkZone == nil {
+		return nil
+	}
+	zone := *s.WorkZone
+	return &zone
+}
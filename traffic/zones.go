@@ -0,0 +1,75 @@
+package traffic
+
+import (
+	"math"
+	"sort"
+)
+
+// SpeedZone участок дороги с собственным ограничением скорости
+type SpeedZone struct {
+	Name  string  `json:"name"`
+	Start float64 `json:"start"` // метры
+	End   float64 `json:"end"`   // метры
+	Limit float64 `json:"limit"` // км/ч
+}
+
+// SetSpeedZones задаёт участки с ограничением скорости; участки обрезаются по длине дороги
+func (s *Simulation) SetSpeedZones(zones []SpeedZone) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	valid := make([]SpeedZone, 0, len(zones))
+	for _, zone := range zones {
+		zone.Start = math.Max(0, zone.Start)
+		zone.End = math.Min(RoadLength, zone.End)
+		if zone.End > zone.Start && zone.Limit > 0 {
+			valid = append(valid, zone)
+		}
+	}
+	sort.SliceStable(valid, func(i, j int) bool { return valid[i].Start < valid[j].Start })
+	s.SpeedZones = valid
+}
+
+// zoneLimit возвращает ограничение скорости в точке position (м/с) по зонам и участкам
+// регулятора VSL; на пересечении участков действует меньшее ограничение
+func (s *Simulation) zoneLimit(position float64) (float64, bool) {
+	limit, ok := 0.0, false
+	for _, zone := range s.SpeedZones {
+		if zone.Start > position {
+			break
+		}
+		if position < zone.End && (!ok || kmhToMs(zone.Limit) < limit) {
+			limit, ok = kmhToMs(zone.Limit), true
+		}
+	}
+	if s.VSL != nil {
+		for _, seg := range s.VSL.Segments {
+			if position >= seg.Start && position < seg.End && (!ok || kmhToMs(seg.Limit) < limit) {
+				limit, ok = kmhToMs(seg.Limit), true
+			}
+		}
+	}
+	return limit, ok
+}
+
+// adaptTargetSpeed выбирает целевую скорость машины на её участке: в зоне ограничения
+// водитель не превышает ограничение, умноженное на множитель скорости его профиля,
+// проезжая машину на обочине, снижает скорость, чтобы её разглядеть, а перед камерой
+// контроля скорости сбрасывает её до контролируемого ограничения
+func (s *Simulation) adaptTargetSpeed(car *Car) {
+	desired := car.desiredSpeed * s.weatherEffect().Speed
+	car.TargetSpeed = desired
+	if car.Yielding {
+		car.TargetSpeed = math.Min(desired, kmhToMs(emergencyYieldSpeed))
+		return
+	}
+	if limit, ok := s.zoneLimit(car.Position); ok && !car.emergency() {
+		car.TargetSpeed = math.Min(desired, limit*driverProfile(car).TargetSpeed)
+	}
+	if factor := s.rubberneckFactor(car.Position); factor > 0 && !car.emergency() {
+		car.TargetSpeed *= 1 - factor
+	}
+	if limit, ok := s.cameraLimit(car); ok {
+		car.TargetSpeed = limit
+	}
+}
@@ -0,0 +1,66 @@
+package traffic
+
+import (
+	"math"
+	"testing"
+)
+
+// TestOverLimitTime проверяет, что время превышения копится только на шагах, где
+// скорость машины выше действующего ограничения дороги или зоны
+func TestOverLimitTime(t *testing.T) {
+	const (
+		dt            = 0.05
+		stepsPerPhase = 40
+	)
+	limit := kmhToMs(90)
+	tests := []struct {
+		name   string
+		car    Car
+		zones  []SpeedZone
+		speeds []float64 // м/с, скорость на каждой фазе из stepsPerPhase шагов
+		over   []bool    // фаза считается превышением
+	}{
+		{name: "below limit", car: Car{Type: "car"}, speeds: []float64{20, 24.9}, over: []bool{false, false}},
+		{name: "exactly at limit", car: Car{Type: "car"}, speeds: []float64{limit}, over: []bool{false}},
+		{
+			name:   "alternating around limit",
+			car:    Car{Type: "car"},
+			speeds: []float64{20, 30, 24, 26, 10},
+			over:   []bool{false, true, false, true, false},
+		},
+		{name: "emergency vehicle exempt", car: Car{Type: VehicleEmergency}, speeds: []float64{35}, over: []bool{false}},
+		{
+			name:   "zone limit below road limit",
+			car:    Car{Type: "car", Position: 1000},
+			zones:  []SpeedZone{{Name: "works", Start: 900, End: 4000, Limit: 60}},
+			speeds: []float64{15, 20},
+			over:   []bool{false, true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sim := New()
+			sim.SpeedLimit = limit
+			sim.SpeedZones = tt.zones
+			car := tt.car
+			car.ID, car.Driver = 1, DriverNormal
+
+			want := 0.0
+			for phase, speed := range tt.speeds {
+				for i := 0; i < stepsPerPhase; i++ {
+					car.Speed = speed
+					sim.moveCar(&car, &carUpdate{ready: true}, dt)
+					if tt.over[phase] {
+						want += dt
+					}
+				}
+			}
+			if math.Abs(car.OverLimitTime-want) > 1e-9 {
+				t.Errorf("car over-limit time = %.3f s, want %.3f s", car.OverLimitTime, want)
+			}
+			if math.Abs(sim.OverLimitTime-want) > 1e-9 {
+				t.Errorf("total over-limit time = %.3f s, want %.3f s", sim.OverLimitTime, want)
+			}
+		})
+	}
+}
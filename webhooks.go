@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"drive-simulation/traffic"
+)
+
+const (
+	// MaxWebhooks предельное число зарегистрированных веб-хуков
+	MaxWebhooks = 32
+	// WebhookQueueSize число уведомлений в очереди одного веб-хука; при заполненной
+	// очереди новые уведомления отбрасываются
+	WebhookQueueSize = 256
+	// WebhookAttempts число попыток доставки уведомления
+	WebhookAttempts = 5
+	// WebhookBackoff пауза перед второй попыткой; перед каждой следующей она удваивается
+	WebhookBackoff = time.Second
+	// WebhookTimeout время ожидания ответа на одну попытку
+	WebhookTimeout = 5 * time.Second
+)
+
+// webhookEvents события, о которых уведомляют веб-хуки
+var webhookEvents = []string{"runStart", "runComplete", "collision", "congestion", "congestion:cleared"}
+
+var (
+	errTooManyWebhooks = fmt.Errorf("too many webhooks (limit %d)", MaxWebhooks)
+	errUnknownWebhook  = errors.New("unknown webhook")
+)
+
+// webhookClient HTTP-клиент доставки уведомлений
+var webhookClient = &http.Client{Timeout: WebhookTimeout}
+
+// webhookRequest тело POST /api/webhooks
+type webhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"` // типы событий (пусто - все из webhookEvents)
+	Sim    string   `json:"sim"`    // только события этой симуляции (пусто - всех)
+	Secret string   `json:"secret"` // ключ подписи тела в заголовке X-Drive-Signature
+}
+
+// Validate проверяет адрес и типы событий веб-хука
+func (req webhookRequest) Validate() error {
+	u, err := url.Parse(req.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url: must be an absolute http or https URL")
+	}
+	for _, event := range req.Events {
+		if !slices.Contains(webhookEvents, event) {
+			return fmt.Errorf("events: unknown event %q", event)
+		}
+	}
+	return nil
+}
+
+// Webhook зарегистрированный адрес уведомлений о событиях симуляций
+type Webhook struct {
+	ID        string   `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Sim       string   `json:"sim,omitempty"`
+	Delivered int      `json:"delivered"` // доставлено уведомлений
+	Failed    int      `json:"failed"`    // не доставлено после всех попыток или отброшено
+	secret    string
+	queue     chan webhookDelivery
+	cancel    context.CancelFunc
+}
+
+// webhookDelivery уведомление, ожидающее доставки
+type webhookDelivery struct {
+	id    string // идентификатор уведомления, одинаковый во всех попытках
+	event string
+	body  []byte
+}
+
+// webhookRegistry зарегистрированные веб-хуки; у каждого своя горутина доставки,
+// поэтому медленный получатель не задерживает остальных
+type webhookRegistry struct {
+	mu    sync.Mutex
+	ctx   context.Context
+	hooks map[string]*Webhook
+	next  int // номер последнего веб-хука
+	sent  int // номер последнего уведомления
+}
+
+var webhooks = &webhookRegistry{ctx: context.Background(), hooks: make(map[string]*Webhook)}
+
+// add регистрирует веб-хук и запускает его доставку
+func (r *webhookRegistry) add(req webhookRequest) (*Webhook, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.hooks) >= MaxWebhooks {
+		return nil, errTooManyWebhooks
+	}
+	r.next++
+	hook := &Webhook{
+		ID:     strconv.Itoa(r.next),
+		URL:    req.URL,
+		Events: req.Events,
+		Sim:    req.Sim,
+		secret: req.Secret,
+		queue:  make(chan webhookDelivery, WebhookQueueSize),
+	}
+	if len(hook.Events) == 0 {
+		hook.Events = webhookEvents
+	}
+	var ctx context.Context
+	ctx, hook.cancel = context.WithCancel(r.ctx)
+	r.hooks[hook.ID] = hook
+	go r.deliver(ctx, hook)
+	return hook, nil
+}
+
+// remove удаляет веб-хук; недоставленные уведомления теряются
+func (r *webhookRegistry) remove(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	hook, ok := r.hooks[id]
+	if !ok {
+		return errUnknownWebhook
+	}
+	hook.cancel()
+	delete(r.hooks, id)
+	return nil
+}
+
+// list возвращает копии веб-хуков, упорядоченные по номеру
+func (r *webhookRegistry) list() []Webhook {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := make([]Webhook, 0, len(r.hooks))
+	for _, hook := range r.hooks {
+		list = append(list, *hook)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, _ := strconv.Atoi(list[i].ID)
+		b, _ := strconv.Atoi(list[j].ID)
+		return a < b
+	})
+	return list
+}
+
+// notify ставит уведомление о событии симуляции sim в очереди подписанных веб-хуков
+func (r *webhookRegistry) notify(sim string, event traffic.Event) {
+	if !slices.Contains(webhookEvents, event.Type) {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.hooks) == 0 {
+		return
+	}
+	body, err := json.Marshal(map[string]any{"event": event.Type, "sim": sim, "data": event})
+	if err != nil {
+		slog.Error("JSON marshal error", "sim", sim, "err", err)
+		return
+	}
+	r.sent++
+	delivery := webhookDelivery{id: strconv.Itoa(r.sent), event: event.Type, body: body}
+	for _, hook := range r.hooks {
+		if (hook.Sim != "" && hook.Sim != sim) || !slices.Contains(hook.Events, event.Type) {
+			continue
+		}
+		select {
+		case hook.queue <- delivery:
+		default:
+			hook.Failed++
+			slog.Warn("Webhook queue full, notification dropped", "webhook", hook.ID, "event", event.Type)
+		}
+	}
+}
+
+// deliver доставляет уведомления веб-хука по очереди, пока не отменён ctx
+func (r *webhookRegistry) deliver(ctx context.Context, hook *Webhook) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case delivery := <-hook.queue:
+			err := hook.send(ctx, delivery)
+			r.mu.Lock()
+			if err != nil {
+				hook.Failed++
+			} else {
+				hook.Delivered++
+			}
+			r.mu.Unlock()
+			if err != nil && ctx.Err() == nil {
+				slog.Warn("Webhook delivery failed", "webhook", hook.ID, "event", delivery.event, "err", err)
+			}
+		}
+	}
+}
+
+// send отправляет уведомление, повторяя попытки с удваивающейся паузой при ошибке
+// сети, ответе 429 или 5xx; другой ответ 4xx повторять бесполезно
+func (hook *Webhook) send(ctx context.Context, delivery webhookDelivery) error {
+	backoff := WebhookBackoff
+	var err error
+	for attempt := 1; attempt <= WebhookAttempts; attempt++ {
+		var retry bool
+		if retry, err = hook.post(ctx, delivery); err == nil || !retry {
+			return err
+		}
+		if attempt == WebhookAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return fmt.Errorf("%d attempts: %w", WebhookAttempts, err)
+}
+
+// post выполняет одну попытку доставки; retry сообщает, стоит ли повторить её
+func (hook *Webhook) post(ctx context.Context, delivery webhookDelivery) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Drive-Event", delivery.event)
+	req.Header.Set("X-Drive-Delivery", delivery.id)
+	if hook.secret != "" {
+		mac := hmac.New(sha256.New, []byte(hook.secret))
+		mac.Write(delivery.body)
+		req.Header.Set("X-Drive-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %s", resp.Status)
+	}
+	return false, fmt.Errorf("status %s", resp.Status)
+}
+
+// handleAddWebhook регистрирует веб-хук (POST /api/webhooks)
+func handleAddWebhook(w http.ResponseWriter, r *http.Request) {
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %w", err))
+		return
+	}
+	if err := req.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	hook, err := webhooks.add(req)
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"id": hook.ID})
+}
+
+// handleListWebhooks возвращает веб-хуки со счётчиками доставки (GET /api/webhooks)
+func handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(webhooks.list())
+}
+
+// handleDeleteWebhook удаляет веб-хук (DELETE /api/webhooks/{id})
+func handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	if err := webhooks.remove(r.PathValue("id")); err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}